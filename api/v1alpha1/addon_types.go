@@ -22,7 +22,9 @@ import (
 	monv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // AddonSpec defines the desired state of Addon
@@ -74,6 +76,35 @@ type AddonSpec struct {
 	// UpgradePolicy enables status reporting via upgrade policies.
 	UpgradePolicy *AddonUpgradePolicy `json:"upgradePolicy,omitempty"`
 
+	// Defines how a new catalog image of OLM based Addons is rolled out.
+	// New catalog images are rolled out immediately when unset.
+	// +optional
+	UpgradeStrategy *AddonUpgradeStrategy `json:"upgradeStrategy,omitempty"`
+
+	// Restricts changes of the catalog image and channel and
+	// InstallPlan approvals of OLM based Addons to maintenance windows.
+	// Defaults to the maintenance configuration of the AddonOperator.
+	// +optional
+	Maintenance *AddonMaintenance `json:"maintenance,omitempty"`
+
+	// Addons that have to be available, before this Addon is installed.
+	// Addons cannot be deleted, while other Addons depend on them.
+	// +optional
+	DependsOn []AddonDependency `json:"dependsOn,omitempty"`
+
+	// Configuration of the Addon, validated against .spec.parametersSchema.
+	// Passed to OLM based Addons as JSON in the ADDON_PARAMETERS environment variable
+	// and to PackageOperator based Addons as "parameters" config,
+	// taking precedence over the addon-<name>-parameters Secret.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+
+	// JSON schema of .spec.parameters.
+	// Required when parameters are set.
+	// +optional
+	ParametersSchema *AddonParametersSchema `json:"parametersSchema,omitempty"`
+
 	// Defines how an addon is monitored.
 	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
 
@@ -83,6 +114,45 @@ type AddonSpec struct {
 	AddonPackageOperator *AddonPackageOperator `json:"packageOperator,omitempty"`
 }
 
+type AddonDependency struct {
+	// Name of the Addon.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Minimum version of the Addon,
+	// compared to its observed version.
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+}
+
+// Source of the JSON schema of the Addon parameters.
+// Exactly one of Inline and ConfigMap has to be set.
+type AddonParametersSchema struct {
+	// Schema shipped with the Addon bundle.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Inline *runtime.RawExtension `json:"inline,omitempty"`
+
+	// ConfigMap key containing the schema as JSON or YAML.
+	// +optional
+	ConfigMap *AddonParametersSchemaConfigMapReference `json:"configMap,omitempty"`
+}
+
+type AddonParametersSchemaConfigMapReference struct {
+	// Name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Key of the schema in the ConfigMap.
+	// +kubebuilder:default="schema.json"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
 type AddonPackageOperator struct {
 	Image string `json:"image"`
 }
@@ -101,6 +171,81 @@ type AddonSecretPropagationReference struct {
 type AddonUpgradePolicy struct {
 	// Upgrade policy id.
 	ID string `json:"id"`
+
+	// Time the Addon has to become Available in, after an upgrade was reported as started.
+	// DeadlineExceededValue is reported to the Upgrade Policy endpoint when the deadline is exceeded.
+	// A deadline of 0 disables the check.
+	// +kubebuilder:default="1h"
+	// +optional
+	Deadline metav1.Duration `json:"deadline,omitempty"`
+
+	// Upgrade policy value reported when the deadline is exceeded.
+	// Either way, the upgrade is still reported as completed, when the Addon becomes Available later on.
+	// +kubebuilder:validation:Enum={"failed","delayed"}
+	// +kubebuilder:default=failed
+	// +optional
+	DeadlineExceededValue AddonUpgradePolicyValue `json:"deadlineExceededValue,omitempty"`
+}
+
+type AddonUpgradeStrategyType string
+
+const (
+	// Moves the Subscription to a new catalog image right away.
+	AddonUpgradeStrategyImmediate AddonUpgradeStrategyType = "Immediate"
+	// Runs a new catalog image next to the previous one and moves the Subscription
+	// after a bake time, if the Addon is healthy. Rolls back to the previous
+	// catalog image, when the new version does not become healthy in time.
+	AddonUpgradeStrategyCanary AddonUpgradeStrategyType = "Canary"
+)
+
+type AddonUpgradeStrategy struct {
+	// Type of the upgrade strategy.
+	// +kubebuilder:validation:Enum={"Immediate","Canary"}
+	// +kubebuilder:default=Immediate
+	Type AddonUpgradeStrategyType `json:"type"`
+
+	// Settings of the Canary upgrade strategy.
+	// +optional
+	Canary *AddonCanaryUpgradeStrategy `json:"canary,omitempty"`
+
+	// Enables rolling back upgrades of the Immediate strategy.
+	// Canary rollouts always roll back.
+	// +optional
+	Rollback *AddonUpgradeRollback `json:"rollback,omitempty"`
+}
+
+type AddonUpgradeRollback struct {
+	// Time the ClusterServiceVersion of a new version has to succeed in.
+	// The upgrade is rolled back when the deadline is exceeded.
+	// +kubebuilder:default="30m"
+	// +optional
+	ProgressDeadline metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+type AddonCanaryUpgradeStrategy struct {
+	// Minimum time the CatalogSource of a new catalog image has to run,
+	// before the Subscription is moved over to it.
+	// +kubebuilder:default="10m"
+	// +optional
+	BakeTime metav1.Duration `json:"bakeTime,omitempty"`
+
+	// Time the new version has to pass all health gates in,
+	// after the Subscription was moved to the new catalog image.
+	// The upgrade is rolled back when the deadline is exceeded.
+	// +kubebuilder:default="30m"
+	// +optional
+	ProgressDeadline metav1.Duration `json:"progressDeadline,omitempty"`
+
+	// Skips waiting for the AddonInstance to report Healthy,
+	// for Addons that don't send heartbeats.
+	// +optional
+	SkipAddonInstanceHealthCheck bool `json:"skipAddonInstanceHealthCheck,omitempty"`
+
+	// PromQL query, that has to return at least one sample with a non-zero value
+	// for the Addon to be considered healthy.
+	// Requires the Addon Operator to be configured with a Prometheus API endpoint.
+	// +optional
+	PrometheusQuery string `json:"prometheusQuery,omitempty"`
 }
 
 type AddonUpgradePolicyValue string
@@ -108,6 +253,8 @@ type AddonUpgradePolicyValue string
 const (
 	AddonUpgradePolicyValueStarted   AddonUpgradePolicyValue = "started"
 	AddonUpgradePolicyValueCompleted AddonUpgradePolicyValue = "completed"
+	AddonUpgradePolicyValueFailed    AddonUpgradePolicyValue = "failed"
+	AddonUpgradePolicyValueDelayed   AddonUpgradePolicyValue = "delayed"
 )
 
 // Tracks the last state last reported to the Upgrade Policy endpoint.
@@ -121,6 +268,50 @@ type AddonUpgradePolicyStatus struct {
 	Version string `json:"version,omitempty"`
 	// The most recent generation a status update was based on.
 	ObservedGeneration int64 `json:"observedGeneration"`
+	// Time the upgrade to Version was first reported as started.
+	// +optional
+	StartedTime *metav1.Time `json:"startedTime,omitempty"`
+}
+
+type AddonVersionTransitionOutcome string
+
+const (
+	// The Addon is still moving to the new version.
+	AddonVersionTransitionInProgress AddonVersionTransitionOutcome = "InProgress"
+	// The Addon became available at the new version.
+	AddonVersionTransitionSucceeded AddonVersionTransitionOutcome = "Succeeded"
+	// The Addon did not become available within the UpgradePolicy deadline.
+	AddonVersionTransitionFailed AddonVersionTransitionOutcome = "Failed"
+	// The upgrade was rolled back to the previous version.
+	AddonVersionTransitionRolledBack AddonVersionTransitionOutcome = "RolledBack"
+	// Another version was requested before the transition finished.
+	AddonVersionTransitionSuperseded AddonVersionTransitionOutcome = "Superseded"
+)
+
+// A single install or upgrade of the Addon.
+type AddonVersionTransition struct {
+	// Version the Addon was at before, empty for the initial install.
+	// +optional
+	FromVersion string `json:"fromVersion,omitempty"`
+	// Version the Addon moved to.
+	ToVersion string `json:"toVersion"`
+	// Namespaced name of the ClusterServiceVersion observed available at ToVersion.
+	// +optional
+	CSV string `json:"csv,omitempty"`
+	// ID of the UpgradePolicy the transition was reported to.
+	// +optional
+	UpgradePolicyID string `json:"upgradePolicyID,omitempty"`
+	// Time the transition started.
+	StartTime metav1.Time `json:"startTime"`
+	// Time the transition finished, unset while in progress.
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+	// Time the Addon first became Available at ToVersion after the transition succeeded.
+	// +optional
+	AvailableTime *metav1.Time `json:"availableTime,omitempty"`
+	// Outcome of the transition.
+	// +kubebuilder:validation:Enum={"InProgress","Succeeded","Failed","RolledBack","Superseded"}
+	Outcome AddonVersionTransitionOutcome `json:"outcome"`
 }
 
 type MonitoringSpec struct {
@@ -179,12 +370,72 @@ type MonitoringFederationSpec struct {
 // AddonInstallSpec defines the desired Addon installation type.
 type AddonInstallSpec struct {
 	// Type of installation.
-	// +kubebuilder:validation:Enum={"OLMOwnNamespace","OLMAllNamespaces"}
+	// +kubebuilder:validation:Enum={"OLMOwnNamespace","OLMAllNamespaces","Helm","Manifests"}
 	Type AddonInstallType `json:"type"`
 	// OLMAllNamespaces config parameters. Present only if Type = OLMAllNamespaces.
 	OLMAllNamespaces *AddonInstallOLMAllNamespaces `json:"olmAllNamespaces,omitempty"`
 	// OLMOwnNamespace config parameters. Present only if Type = OLMOwnNamespace.
 	OLMOwnNamespace *AddonInstallOLMOwnNamespace `json:"olmOwnNamespace,omitempty"`
+	// Helm config parameters. Present only if Type = Helm.
+	Helm *AddonInstallHelm `json:"helm,omitempty"`
+	// Manifests config parameters. Present only if Type = Manifests.
+	Manifests *AddonInstallManifests `json:"manifests,omitempty"`
+}
+
+// Helm specific Addon installation parameters.
+type AddonInstallHelm struct {
+	// Namespace to install the chart into.
+	// Namespaced objects rendered without a namespace are placed here.
+	// Only objects of supported namespaced kinds within this namespace can be installed.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// OCI reference of the chart without a tag,
+	// e.g. oci://quay.io/osd-addons/charts/reference-addon.
+	// +kubebuilder:validation:MinLength=1
+	Chart string `json:"chart"`
+
+	// Version of the chart to install, used as the tag of the OCI reference.
+	// +kubebuilder:validation:MinLength=1
+	ChartVersion string `json:"chartVersion"`
+
+	// Reference to a secret of type kubernetes.io/dockerconfigjson
+	// in the addon operators installation namespace,
+	// used to authenticate against the chart registry.
+	// +optional
+	PullSecretName string `json:"pullSecretName,omitempty"`
+
+	// Values to merge over the default values of the chart.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Values *runtime.RawExtension `json:"values,omitempty"`
+}
+
+// Manifests specific Addon installation parameters.
+// Exactly one of ConfigMapName and Image has to be set.
+type AddonInstallManifests struct {
+	// Namespace to install the manifests into.
+	// Namespaced objects without a namespace are placed here.
+	// Only objects of supported namespaced kinds within this namespace can be installed.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of a ConfigMap in the addon operators installation namespace,
+	// every key of which contains one or more YAML or JSON documents.
+	// Changes to the ConfigMap are picked up with the next reconciliation of the Addon.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Image containing the manifests as .yaml, .yml or .json files,
+	// e.g. quay.io/osd-addons/reference-addon-manifests:v1.0.0.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Reference to a secret of type kubernetes.io/dockerconfigjson
+	// in the addon operators installation namespace,
+	// used to authenticate against the registry of Image.
+	// +optional
+	PullSecretName string `json:"pullSecretName,omitempty"`
 }
 
 // Common Addon installation parameters.
@@ -216,14 +467,124 @@ type AddonInstallOLMCommon struct {
 	// +optional
 	Config *SubscriptionConfig `json:"config,omitempty"`
 
+	// Settings of the CatalogSource,
+	// also used for additional CatalogSources without settings of their own.
+	// +optional
+	CatalogSourceConfig *CatalogSourceConfig `json:"catalogSourceConfig,omitempty"`
+
 	// Additional catalog source objects to be created in the cluster
 	// +optional
 	AdditionalCatalogSources []AdditionalCatalogSource `json:"additionalCatalogSources,omitempty"`
+
+	// Policy to approve InstallPlans of the Addon with.
+	// When set, the Subscription is switched to manual InstallPlan approval
+	// and the addon-operator approves InstallPlans matching the policy.
+	// +optional
+	InstallPlanApproval *AddonInstallPlanApprovalPolicy `json:"installPlanApproval,omitempty"`
+}
+
+type AddonInstallPlanApprovalPolicy struct {
+	// Rules to approve InstallPlans with.
+	// An InstallPlan is approved as soon as one of the rules matches,
+	// otherwise it stays pending until a rule matches or it is approved manually.
+	// InstallPlans of the initial installation are always approved.
+	// +kubebuilder:validation:MinItems=1
+	Rules []AddonInstallPlanApprovalRule `json:"rules"`
+}
+
+type AddonInstallPlanApprovalRuleType string
+
+const (
+	// Approves upgrades to a new patch version of the same major and minor version.
+	AddonInstallPlanApprovalRulePatchVersion AddonInstallPlanApprovalRuleType = "PatchVersion"
+	// Approves InstallPlans while one of the maintenance windows is open.
+	AddonInstallPlanApprovalRuleMaintenanceWindow AddonInstallPlanApprovalRuleType = "MaintenanceWindow"
+	// Approves InstallPlans while the OCM UpgradePolicy of the Addon is scheduled.
+	AddonInstallPlanApprovalRuleUpgradePolicyScheduled AddonInstallPlanApprovalRuleType = "UpgradePolicyScheduled"
+)
+
+type AddonInstallPlanApprovalRule struct {
+	// Type of the rule.
+	// +kubebuilder:validation:Enum={"PatchVersion","MaintenanceWindow","UpgradePolicyScheduled"}
+	Type AddonInstallPlanApprovalRuleType `json:"type"`
+
+	// Windows to approve InstallPlans in, required for type MaintenanceWindow.
+	// +optional
+	MaintenanceWindows []AddonMaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// Recurring time window to make changes to an Addon in.
+type AddonMaintenanceWindow struct {
+	// Cron expression in the standard five field format,
+	// describing when the window opens.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// How long the window stays open.
+	Duration metav1.Duration `json:"duration"`
+
+	// IANA name of the time zone the schedule is evaluated in.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type AddonMaintenance struct {
+	// Recurring windows to roll out changes in.
+	// +kubebuilder:validation:MinItems=1
+	Windows []AddonMaintenanceWindow `json:"windows"`
+
+	// Dates in YYYY-MM-DD format no window opens on,
+	// evaluated in the time zone of each window.
+	// +optional
+	BlackoutDates []string `json:"blackoutDates,omitempty"`
 }
 
+// Configures the operator Deployment of OLM based Addons,
+// passed on to the OLM Subscription.
 type SubscriptionConfig struct {
 	// Array of env variables to be passed to the subscription object.
-	EnvironmentVariables []EnvObject `json:"env"`
+	// +optional
+	EnvironmentVariables []EnvObject `json:"env,omitempty"`
+
+	// Sources to populate environment variables in the operator container from.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Compute resources of the operator container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Node selector of the operator Pod.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the operator Pod.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity of the operator Pod.
+	// Validated by OLM, the schema is omitted to keep the CRD small.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Volumes added to the operator Pod.
+	// Validated by OLM, the schema is omitted to keep the CRD small.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// Volume mounts added to the operator container.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Annotations added to the operator Deployment and Pod.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type AdditionalCatalogSource struct {
@@ -233,15 +594,71 @@ type AdditionalCatalogSource struct {
 	// Image url of the additional catalog source
 	// +kubebuilder:validation:MinLength=1
 	Image string `json:"image"`
+	// Settings of the additional catalog source.
+	// Defaults to the settings of the main catalog source.
+	// +optional
+	Config *CatalogSourceConfig `json:"config,omitempty"`
+}
+
+// Configures the OLM CatalogSource of an Addon.
+type CatalogSourceConfig struct {
+	// Defines how updated catalog images are pulled.
+	// +optional
+	UpdateStrategy *CatalogSourceUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// Priority of the CatalogSource, OLM prefers CatalogSources with a higher
+	// priority when resolving dependencies provided by several CatalogSources.
+	// +optional
+	Priority int `json:"priority,omitempty"`
+
+	// Settings of the Pod serving the catalog.
+	// +optional
+	GrpcPodConfig *CatalogSourceGrpcPodConfig `json:"grpcPodConfig,omitempty"`
+}
+
+type CatalogSourceUpdateStrategy struct {
+	// Polls the registry for new versions of the catalog image.
+	// Requires a tag instead of a digest in the catalog image reference.
+	// +optional
+	RegistryPoll *CatalogSourceRegistryPoll `json:"registryPoll,omitempty"`
+}
+
+type CatalogSourceRegistryPoll struct {
+	// Time between checks of the registry for a new version of the catalog image.
+	Interval metav1.Duration `json:"interval"`
+}
+
+type CatalogSourceGrpcPodConfig struct {
+	// Node selector of the catalog Pod.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the catalog Pod.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Name of the PriorityClass of the catalog Pod.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Memory the catalog Pod requests, also set as soft limit of the catalog server.
+	// +optional
+	MemoryTarget *resource.Quantity `json:"memoryTarget,omitempty"`
 }
 
+// Exactly one of Value and ValueFrom has to be set.
 type EnvObject struct {
 	// Name of the environment variable
 	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 	// Value of the environment variable
 	// +kubebuilder:validation:MinLength=1
-	Value string `json:"value"`
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Source of the value of the environment variable,
+	// e.g. a key of a Secret or ConfigMap in the Addon namespace.
+	// +optional
+	ValueFrom *corev1.EnvVarSource `json:"valueFrom,omitempty"`
 }
 
 // AllNamespaces specific Addon installation parameters.
@@ -266,6 +683,12 @@ const (
 	// The Operator will only watch and be made available for use in this single namespace.
 	// Maps directly to the OLM install mode "specific namespace"
 	OLMOwnNamespace AddonInstallType = "OLMOwnNamespace"
+	// Renders a Helm chart pulled from an OCI registry and
+	// applies the resulting objects into a single namespace.
+	Helm AddonInstallType = "Helm"
+	// Applies plain manifests from a ConfigMap or an image,
+	// for addons that ship no operator.
+	Manifests AddonInstallType = "Manifests"
 )
 
 // Annotation keys for delete signal from OCM.
@@ -322,6 +745,9 @@ const (
 	// Addon upgrade has succeeded.
 	AddonReasonUpgradeSucceeded = "AddonUpgradeSucceeded"
 
+	// Addon did not become Available within the upgrade deadline.
+	AddonReasonUpgradeFailed = "AddonUpgradeFailed"
+
 	// Addon has successfully been uninstalled.
 	AddonReasonInstalled = "AddonInstalled"
 
@@ -345,6 +771,33 @@ const (
 
 	// Addon's Install Plan is pending due to some condition such as a manual approval.
 	AddonReasonInstallPlanPending = "AddonInstallPlanPending"
+
+	// Addon's Helm chart could not be pulled or rendered.
+	AddonReasonHelmChartError = "HelmChartError"
+
+	// Addon has unready workloads rendered from its Helm chart.
+	AddonReasonUnreadyHelmRelease = "UnreadyHelmRelease"
+
+	// Addon's manifest bundle could not be loaded.
+	AddonReasonManifestBundleError = "ManifestBundleError"
+
+	// Addon has unready workloads from its manifest bundle.
+	AddonReasonUnreadyManifests = "UnreadyManifests"
+
+	// Addon upgrade was rolled back, because the new CSV failed.
+	AddonReasonCSVFailed = "CSVFailed"
+
+	// Addon upgrade was rolled back, because it did not become available in time.
+	AddonReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+	// Addon changes are deferred, because no maintenance window is open.
+	AddonReasonMaintenanceWindowClosed = "MaintenanceWindowClosed"
+
+	// Addon is not installed, because Addons it depends on are not available.
+	AddonReasonWaitingForDependencies = "WaitingForDependencies"
+
+	// Addon is not deleted, while other Addons depend on it.
+	AddonReasonWaitingForDependents = "WaitingForDependents"
 )
 
 type AddonNamespace struct {
@@ -374,6 +827,18 @@ const (
 	// UpgradeSucceeded condition indicates that the addon upgrade has succeeded.
 	UpgradeSucceeded = "UpgradeSucceeded"
 
+	// RolledBack condition indicates that the addon upgrade has failed
+	// and was rolled back to the previously available version.
+	RolledBack = "RolledBack"
+
+	// UpgradeFailed condition indicates that the addon did not become available
+	// within the deadline of its upgrade policy.
+	UpgradeFailed = "UpgradeFailed"
+
+	// WaitingForMaintenanceWindow condition indicates that changes to the addon
+	// are deferred until the next maintenance window opens.
+	WaitingForMaintenanceWindow = "WaitingForMaintenanceWindow"
+
 	// Installed condition indicates that the addon has been installed successfully
 	// and was available atleast once.
 	Installed = "Installed"
@@ -399,6 +864,10 @@ type AddonStatus struct {
 	// Tracks last reported upgrade policy status.
 	// +optional
 	UpgradePolicy *AddonUpgradePolicyStatus `json:"upgradePolicy,omitempty"`
+	// Most recent install and upgrade transitions of the Addon, oldest first.
+	// +kubebuilder:validation:MaxItems=10
+	// +optional
+	History []AddonVersionTransition `json:"history,omitempty"`
 	// Tracks the last addon status reported to OCM.
 	// +optional
 	OCMReportedStatusHash *OCMAddOnStatusHash `json:"ocmReportedStatusHash,omitempty"`
@@ -408,6 +877,112 @@ type AddonStatus struct {
 	// Namespaced name of the csv(available) that was last observed.
 	// +optional
 	LastObservedAvailableCSV string `json:"lastObservedAvailableCSV,omitempty"`
+	// Progress of the rollout of a new catalog image,
+	// when using the Canary upgrade strategy.
+	// +optional
+	CanaryRollout *AddonCanaryRolloutStatus `json:"canaryRollout,omitempty"`
+	// Decisions of the InstallPlan approval policy, latest last.
+	// +optional
+	InstallPlanApprovals []AddonInstallPlanApprovalDecision `json:"installPlanApprovals,omitempty"`
+	// Tracks an upgrade that was rolled back, while the Subscription
+	// is pinned to the previously available csv.
+	// +optional
+	UpgradeRollback *AddonUpgradeRollbackStatus `json:"upgradeRollback,omitempty"`
+	// InstallPlanApproval of the Subscription before it was set to Manual,
+	// so the addon-operator approves InstallPlans following the approval policy
+	// or maintenance windows of the Addon.
+	// Restored once neither is configured anymore.
+	// +optional
+	OverriddenInstallPlanApproval string `json:"overriddenInstallPlanApproval,omitempty"`
+	// Time the next maintenance window opens,
+	// while changes to the Addon are waiting for it.
+	// +optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+	// Changes to the Addon waiting for the next maintenance window,
+	// in the order they were deferred.
+	// +optional
+	DeferredChanges []string `json:"deferredChanges,omitempty"`
+	// Objects applied from the manifest bundle of install type Manifests.
+	// Objects that are removed from the bundle are pruned based on this list.
+	// +optional
+	ManifestObjects []AddonManifestObjectReference `json:"manifestObjects,omitempty"`
+	// Objects applied from the Helm chart of install type Helm.
+	// +optional
+	HelmObjects []AddonManifestObjectReference `json:"helmObjects,omitempty"`
+}
+
+type AddonInstallPlanApprovalDecision struct {
+	// Name of the InstallPlan.
+	InstallPlan string `json:"installPlan"`
+	// ClusterServiceVersions installed by the InstallPlan.
+	// +optional
+	ClusterServiceVersions []string `json:"clusterServiceVersions,omitempty"`
+	// Whether the InstallPlan was approved.
+	Approved bool `json:"approved"`
+	// Rule that approved the InstallPlan.
+	// +optional
+	Rule AddonInstallPlanApprovalRuleType `json:"rule,omitempty"`
+	// Human readable reason of the decision.
+	Message string `json:"message"`
+	// Time the decision was made.
+	Time metav1.Time `json:"time"`
+}
+
+type AddonUpgradeRollbackStatus struct {
+	// Namespaced name of the csv the Subscription is pinned to.
+	PinnedCSV string `json:"pinnedCSV"`
+	// Namespaced name of the csv that failed.
+	FailedCSV string `json:"failedCSV"`
+	// Version of the Addon that was rolled back.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Catalog image that was rolled back.
+	CatalogSourceImage string `json:"catalogSourceImage"`
+	// InstallPlanApproval of the Subscription before it was pinned.
+	// Restored once a new version or catalog image is rolled out.
+	// +optional
+	InstallPlanApproval string `json:"installPlanApproval,omitempty"`
+}
+
+type AddonCanaryRolloutPhase string
+
+const (
+	// The CatalogSource of the new catalog image runs next to the previous one,
+	// while the Subscription still points to the previous one.
+	AddonCanaryRolloutPhaseBaking AddonCanaryRolloutPhase = "Baking"
+	// The Subscription was moved to the new catalog image
+	// and the new version is waiting to pass all health gates.
+	AddonCanaryRolloutPhaseVerifying AddonCanaryRolloutPhase = "Verifying"
+	// The new version did not pass all health gates in time
+	// and the Subscription was moved back to the previous catalog image.
+	AddonCanaryRolloutPhaseRolledBack AddonCanaryRolloutPhase = "RolledBack"
+)
+
+type AddonCanaryRolloutStatus struct {
+	// Phase of the rollout.
+	Phase AddonCanaryRolloutPhase `json:"phase"`
+	// Catalog image being rolled out.
+	CatalogSourceImage string `json:"catalogSourceImage"`
+	// Last good catalog image, which the Addon was running before the rollout started.
+	StableCatalogSourceImage string `json:"stableCatalogSourceImage"`
+	// Namespaced name of the csv that was available before the rollout started,
+	// as recorded in .status.lastObservedAvailableCSV. Reinstalled on rollback.
+	// +optional
+	StableCSV string `json:"stableCSV,omitempty"`
+	// Last time the rollout entered a new phase.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Human readable details about the progress of the rollout.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// References an object applied from a manifest bundle or Helm chart.
+type AddonManifestObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
 }
 
 type AddOnStatusCondition struct {
@@ -495,11 +1070,18 @@ func (a *Addon) IsAvailable() bool {
 }
 
 func (a *Addon) SetUpgradePolicyStatus(val AddonUpgradePolicyValue) {
+	var startedTime *metav1.Time
+	if prev := a.Status.UpgradePolicy; prev != nil &&
+		prev.ID == a.Spec.UpgradePolicy.ID && prev.Version == a.Spec.Version {
+		startedTime = prev.StartedTime
+	}
+
 	a.Status.UpgradePolicy = &AddonUpgradePolicyStatus{
 		ID:                 a.Spec.UpgradePolicy.ID,
 		Value:              val,
 		Version:            a.Spec.Version,
 		ObservedGeneration: a.Generation,
+		StartedTime:        startedTime,
 	}
 }
 
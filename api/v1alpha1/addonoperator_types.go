@@ -18,6 +18,29 @@ const (
 
 	// Addon operator has resumed reconciliation
 	AddonOperatorReasonUnpaused = "AddonOperatorUnpaused"
+
+	// Addon operator is connected to the OCM API
+	AddonOperatorReasonOCMConnected = "OCMConnected"
+
+	// Addon operator could not read the OCM credentials
+	AddonOperatorReasonOCMCredentialsError = "OCMCredentialsError"
+
+	// Addon operator could not connect to the OCM API
+	AddonOperatorReasonOCMConnectionFailed = "OCMConnectionFailed"
+
+	// Addons on the cluster match the addon installations in OCM
+	AddonOperatorReasonOCMAddonsSynced = "OCMAddonsSynced"
+
+	// Addons on the cluster differ from the addon installations in OCM
+	// in ways the addon operator does not correct
+	AddonOperatorReasonOCMAddonsDrifted = "OCMAddonsDrifted"
+
+	// Addon operator could not sync Addons from OCM
+	AddonOperatorReasonOCMAddonsSyncFailed = "OCMAddonsSyncFailed"
+
+	// Addon operator did not delete Addons missing from OCM,
+	// because too many of them are missing at once
+	AddonOperatorReasonOCMAddonsDeletionRefused = "OCMAddonsDeletionRefused"
 )
 
 // AddonOperatorSpec defines the desired state of Addon operator.
@@ -37,6 +60,10 @@ type AddonOperatorSpec struct {
 	// e.g. push status reporting, etc.
 	// +optional
 	OCM *AddonOperatorOCM `json:"ocm,omitempty"`
+	// Maintenance configuration of all Addons
+	// that do not specify their own.
+	// +optional
+	Maintenance *AddonMaintenance `json:"maintenance,omitempty"`
 }
 
 type AddonOperatorFeatureToggles struct {
@@ -52,8 +79,121 @@ type AddonOperatorOCM struct {
 
 	// Secret to authenticate to the OCM API Endpoint.
 	// Only supports secrets of type "kubernetes.io/dockerconfigjson"
+	// for the default AccessToken auth type,
+	// other auth types document the keys they read.
 	// https://kubernetes.io/docs/concepts/configuration/secret/#secret-types
 	Secret ClusterSecretReference `json:"secret"`
+
+	// Authentication to the OCM API Endpoint.
+	// Defaults to the AccessToken auth type.
+	// +optional
+	Auth *AddonOperatorOCMAuth `json:"auth,omitempty"`
+
+	// Creates, updates and deletes Addons following the
+	// addon installations of the cluster in OCM,
+	// for clusters without another writer of Addon objects.
+	// +optional
+	PullMode *AddonOperatorOCMPullMode `json:"pullMode,omitempty"`
+
+	// Pace of the status and UpgradePolicy reports sent to OCM,
+	// shared by all Addons.
+	// Defaults apply when unset.
+	// +optional
+	Reporting *AddonOperatorOCMReporting `json:"reporting,omitempty"`
+}
+
+type AddonOperatorOCMReportingMode string
+
+const (
+	// Every Addon status is reported with its own request.
+	AddonOperatorOCMReportingIndividual AddonOperatorOCMReportingMode = "Individual"
+	// Pending Addon statuses are reported together
+	// through the bulk status endpoint.
+	AddonOperatorOCMReportingBulk AddonOperatorOCMReportingMode = "Bulk"
+)
+
+type AddonOperatorOCMReporting struct {
+	// Sustained number of requests per minute sent to OCM by the reporter.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=120
+	// +optional
+	RequestsPerMinute int32 `json:"requestsPerMinute,omitempty"`
+
+	// Number of requests that may be sent in a row
+	// before requestsPerMinute applies.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+
+	// Status changes of the same Addon within this window
+	// are reported only once, with the latest status.
+	// +kubebuilder:default="5s"
+	// +optional
+	CoalesceWindow metav1.Duration `json:"coalesceWindow,omitempty"`
+
+	// Whether Addon statuses are reported individually or in bulk.
+	// Bulk mode falls back to individual reports,
+	// when OCM does not serve the bulk status endpoint.
+	// +kubebuilder:validation:Enum=Individual;Bulk
+	// +kubebuilder:default=Individual
+	// +optional
+	Mode AddonOperatorOCMReportingMode `json:"mode,omitempty"`
+
+	// Maximum number of Addon statuses reported with a single request
+	// in Bulk mode.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=50
+	// +optional
+	MaxBulkSize int32 `json:"maxBulkSize,omitempty"`
+}
+
+type AddonOperatorOCMPullMode struct {
+	// Interval to fetch the addon installations of the cluster from OCM.
+	// +kubebuilder:default="5m"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+}
+
+type AddonOperatorOCMAuthType string
+
+const (
+	// Cluster specific access token read from the "cloud.openshift.com" auth
+	// of a "kubernetes.io/dockerconfigjson" secret.
+	AddonOperatorOCMAuthAccessToken AddonOperatorOCMAuthType = "AccessToken"
+	// Offline token read from the "offlineToken" key,
+	// exchanged for bearer tokens at the token URL.
+	// The OAuth2 client is read from the optional "clientID" key
+	// and defaults to "cloud-services".
+	AddonOperatorOCMAuthOfflineToken AddonOperatorOCMAuthType = "OfflineToken"
+	// OAuth2 client credentials read from the "clientID" and "clientSecret" keys,
+	// exchanged for bearer tokens at the token URL.
+	AddonOperatorOCMAuthClientCredentials AddonOperatorOCMAuthType = "ClientCredentials"
+	// Client certificate read from the "tls.crt" and "tls.key" keys.
+	// The OCM API is verified with the optional "ca.crt" key.
+	AddonOperatorOCMAuthClientCertificate AddonOperatorOCMAuthType = "ClientCertificate"
+)
+
+// Keys of the OCM secret read by the different auth types.
+const (
+	OCMSecretOfflineTokenKey = "offlineToken"
+	OCMSecretClientIDKey     = "clientID"
+	OCMSecretClientSecretKey = "clientSecret"
+	OCMSecretCAKey           = "ca.crt"
+)
+
+type AddonOperatorOCMAuth struct {
+	// Type of the credentials in the secret.
+	// +kubebuilder:validation:Enum=AccessToken;OfflineToken;ClientCredentials;ClientCertificate
+	// +kubebuilder:default=AccessToken
+	Type AddonOperatorOCMAuthType `json:"type"`
+	// OAuth2 token endpoint,
+	// required by the OfflineToken and ClientCredentials auth types.
+	// +optional
+	TokenURL string `json:"tokenURL,omitempty"`
+	// OAuth2 scopes to request.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // AddonOperatorStatus defines the observed state of Addon
@@ -69,6 +209,35 @@ type AddonOperatorStatus struct {
 	// it will go away as soon as kubectl can print conditions!
 	// Human readable status - please use .Conditions from code
 	Phase AddonPhase `json:"phase,omitempty"`
+	// Last sync of Addons from OCM, only present in pull mode.
+	// +optional
+	OCMPull *AddonOperatorOCMPullStatus `json:"ocmPull,omitempty"`
+}
+
+type AddonOperatorOCMPullStatus struct {
+	// Time Addons were last synced from OCM.
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+	// Differences between the addon installations in OCM
+	// and the Addons on the cluster, that are not corrected.
+	// +optional
+	Drift []AddonOperatorOCMDrift `json:"drift,omitempty"`
+}
+
+type AddonOperatorOCMDriftType string
+
+const (
+	// The Addon exists on the cluster, but is not installed in OCM.
+	AddonOperatorOCMDriftNotInOCM AddonOperatorOCMDriftType = "NotInOCM"
+	// The Addon was not created from OCM and its version differs from OCM.
+	AddonOperatorOCMDriftVersionMismatch AddonOperatorOCMDriftType = "VersionMismatch"
+)
+
+type AddonOperatorOCMDrift struct {
+	// Name of the Addon.
+	Addon string                    `json:"addon"`
+	Type  AddonOperatorOCMDriftType `json:"type"`
+	// Human readable description of the difference.
+	Message string `json:"message"`
 }
 
 const (
@@ -77,6 +246,16 @@ const (
 
 	// Paused condition indicates that the AddonOperator is paused entirely.
 	AddonOperatorPaused = "Paused"
+
+	// OCMConnected condition indicates whether the AddonOperator
+	// can reach the OCM API with the configured credentials.
+	// Only present when .spec.ocm is set.
+	AddonOperatorOCMConnected = "OCMConnected"
+
+	// OCMAddonsSynced condition indicates whether the Addons on the cluster
+	// match the addon installations in OCM.
+	// Only present when .spec.ocm.pullMode is set.
+	AddonOperatorOCMAddonsSynced = "OCMAddonsSynced"
 )
 
 // AddonOperator is the Schema for the AddonOperator API
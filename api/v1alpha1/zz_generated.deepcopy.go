@@ -7,6 +7,7 @@ package v1alpha1
 
 import (
 	"github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -29,6 +30,11 @@ func (in *AddOnStatusCondition) DeepCopy() *AddOnStatusCondition {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdditionalCatalogSource) DeepCopyInto(out *AdditionalCatalogSource) {
 	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(CatalogSourceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalCatalogSource.
@@ -68,6 +74,89 @@ func (in *Addon) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonCanaryRolloutStatus) DeepCopyInto(out *AddonCanaryRolloutStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonCanaryRolloutStatus.
+func (in *AddonCanaryRolloutStatus) DeepCopy() *AddonCanaryRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonCanaryRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonCanaryUpgradeStrategy) DeepCopyInto(out *AddonCanaryUpgradeStrategy) {
+	*out = *in
+	out.BakeTime = in.BakeTime
+	out.ProgressDeadline = in.ProgressDeadline
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonCanaryUpgradeStrategy.
+func (in *AddonCanaryUpgradeStrategy) DeepCopy() *AddonCanaryUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonCanaryUpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonDependency) DeepCopyInto(out *AddonDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonDependency.
+func (in *AddonDependency) DeepCopy() *AddonDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonInstallHelm) DeepCopyInto(out *AddonInstallHelm) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonInstallHelm.
+func (in *AddonInstallHelm) DeepCopy() *AddonInstallHelm {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonInstallHelm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonInstallManifests) DeepCopyInto(out *AddonInstallManifests) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonInstallManifests.
+func (in *AddonInstallManifests) DeepCopy() *AddonInstallManifests {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonInstallManifests)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonInstallOLMAllNamespaces) DeepCopyInto(out *AddonInstallOLMAllNamespaces) {
 	*out = *in
@@ -92,10 +181,22 @@ func (in *AddonInstallOLMCommon) DeepCopyInto(out *AddonInstallOLMCommon) {
 		*out = new(SubscriptionConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CatalogSourceConfig != nil {
+		in, out := &in.CatalogSourceConfig, &out.CatalogSourceConfig
+		*out = new(CatalogSourceConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AdditionalCatalogSources != nil {
 		in, out := &in.AdditionalCatalogSources, &out.AdditionalCatalogSources
 		*out = make([]AdditionalCatalogSource, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InstallPlanApproval != nil {
+		in, out := &in.InstallPlanApproval, &out.InstallPlanApproval
+		*out = new(AddonInstallPlanApprovalPolicy)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -125,6 +226,69 @@ func (in *AddonInstallOLMOwnNamespace) DeepCopy() *AddonInstallOLMOwnNamespace {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonInstallPlanApprovalDecision) DeepCopyInto(out *AddonInstallPlanApprovalDecision) {
+	*out = *in
+	if in.ClusterServiceVersions != nil {
+		in, out := &in.ClusterServiceVersions, &out.ClusterServiceVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonInstallPlanApprovalDecision.
+func (in *AddonInstallPlanApprovalDecision) DeepCopy() *AddonInstallPlanApprovalDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonInstallPlanApprovalDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonInstallPlanApprovalPolicy) DeepCopyInto(out *AddonInstallPlanApprovalPolicy) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]AddonInstallPlanApprovalRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonInstallPlanApprovalPolicy.
+func (in *AddonInstallPlanApprovalPolicy) DeepCopy() *AddonInstallPlanApprovalPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonInstallPlanApprovalPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonInstallPlanApprovalRule) DeepCopyInto(out *AddonInstallPlanApprovalRule) {
+	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]AddonMaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonInstallPlanApprovalRule.
+func (in *AddonInstallPlanApprovalRule) DeepCopy() *AddonInstallPlanApprovalRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonInstallPlanApprovalRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonInstallSpec) DeepCopyInto(out *AddonInstallSpec) {
 	*out = *in
@@ -138,6 +302,16 @@ func (in *AddonInstallSpec) DeepCopyInto(out *AddonInstallSpec) {
 		*out = new(AddonInstallOLMOwnNamespace)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Helm != nil {
+		in, out := &in.Helm, &out.Helm
+		*out = new(AddonInstallHelm)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = new(AddonInstallManifests)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonInstallSpec.
@@ -280,6 +454,62 @@ func (in *AddonList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonMaintenance) DeepCopyInto(out *AddonMaintenance) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]AddonMaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlackoutDates != nil {
+		in, out := &in.BlackoutDates, &out.BlackoutDates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonMaintenance.
+func (in *AddonMaintenance) DeepCopy() *AddonMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonMaintenanceWindow) DeepCopyInto(out *AddonMaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonMaintenanceWindow.
+func (in *AddonMaintenanceWindow) DeepCopy() *AddonMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonManifestObjectReference) DeepCopyInto(out *AddonManifestObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonManifestObjectReference.
+func (in *AddonManifestObjectReference) DeepCopy() *AddonManifestObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonManifestObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonNamespace) DeepCopyInto(out *AddonNamespace) {
 	*out = *in
@@ -387,6 +617,21 @@ func (in *AddonOperatorList) DeepCopyObject() runtime.Object {
 func (in *AddonOperatorOCM) DeepCopyInto(out *AddonOperatorOCM) {
 	*out = *in
 	out.Secret = in.Secret
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(AddonOperatorOCMAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullMode != nil {
+		in, out := &in.PullMode, &out.PullMode
+		*out = new(AddonOperatorOCMPullMode)
+		**out = **in
+	}
+	if in.Reporting != nil {
+		in, out := &in.Reporting, &out.Reporting
+		*out = new(AddonOperatorOCMReporting)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorOCM.
@@ -399,6 +644,94 @@ func (in *AddonOperatorOCM) DeepCopy() *AddonOperatorOCM {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonOperatorOCMAuth) DeepCopyInto(out *AddonOperatorOCMAuth) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorOCMAuth.
+func (in *AddonOperatorOCMAuth) DeepCopy() *AddonOperatorOCMAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonOperatorOCMAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonOperatorOCMDrift) DeepCopyInto(out *AddonOperatorOCMDrift) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorOCMDrift.
+func (in *AddonOperatorOCMDrift) DeepCopy() *AddonOperatorOCMDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonOperatorOCMDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonOperatorOCMPullMode) DeepCopyInto(out *AddonOperatorOCMPullMode) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorOCMPullMode.
+func (in *AddonOperatorOCMPullMode) DeepCopy() *AddonOperatorOCMPullMode {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonOperatorOCMPullMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonOperatorOCMPullStatus) DeepCopyInto(out *AddonOperatorOCMPullStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]AddonOperatorOCMDrift, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorOCMPullStatus.
+func (in *AddonOperatorOCMPullStatus) DeepCopy() *AddonOperatorOCMPullStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonOperatorOCMPullStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonOperatorOCMReporting) DeepCopyInto(out *AddonOperatorOCMReporting) {
+	*out = *in
+	out.CoalesceWindow = in.CoalesceWindow
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorOCMReporting.
+func (in *AddonOperatorOCMReporting) DeepCopy() *AddonOperatorOCMReporting {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonOperatorOCMReporting)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonOperatorSpec) DeepCopyInto(out *AddonOperatorSpec) {
 	*out = *in
@@ -406,7 +739,12 @@ func (in *AddonOperatorSpec) DeepCopyInto(out *AddonOperatorSpec) {
 	if in.OCM != nil {
 		in, out := &in.OCM, &out.OCM
 		*out = new(AddonOperatorOCM)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(AddonMaintenance)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -431,6 +769,11 @@ func (in *AddonOperatorStatus) DeepCopyInto(out *AddonOperatorStatus) {
 		}
 	}
 	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
+	if in.OCMPull != nil {
+		in, out := &in.OCMPull, &out.OCMPull
+		*out = new(AddonOperatorOCMPullStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonOperatorStatus.
@@ -458,6 +801,46 @@ func (in *AddonPackageOperator) DeepCopy() *AddonPackageOperator {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonParametersSchema) DeepCopyInto(out *AddonParametersSchema) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(AddonParametersSchemaConfigMapReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonParametersSchema.
+func (in *AddonParametersSchema) DeepCopy() *AddonParametersSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonParametersSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonParametersSchemaConfigMapReference) DeepCopyInto(out *AddonParametersSchemaConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonParametersSchemaConfigMapReference.
+func (in *AddonParametersSchemaConfigMapReference) DeepCopy() *AddonParametersSchemaConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonParametersSchemaConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonSecretPropagation) DeepCopyInto(out *AddonSecretPropagation) {
 	*out = *in
@@ -525,6 +908,31 @@ func (in *AddonSpec) DeepCopyInto(out *AddonSpec) {
 		*out = new(AddonUpgradePolicy)
 		**out = **in
 	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(AddonUpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(AddonMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]AddonDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ParametersSchema != nil {
+		in, out := &in.ParametersSchema, &out.ParametersSchema
+		*out = new(AddonParametersSchema)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Monitoring != nil {
 		in, out := &in.Monitoring, &out.Monitoring
 		*out = new(MonitoringSpec)
@@ -565,13 +973,56 @@ func (in *AddonStatus) DeepCopyInto(out *AddonStatus) {
 	if in.UpgradePolicy != nil {
 		in, out := &in.UpgradePolicy, &out.UpgradePolicy
 		*out = new(AddonUpgradePolicyStatus)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]AddonVersionTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.OCMReportedStatusHash != nil {
 		in, out := &in.OCMReportedStatusHash, &out.OCMReportedStatusHash
 		*out = new(OCMAddOnStatusHash)
 		**out = **in
 	}
+	if in.CanaryRollout != nil {
+		in, out := &in.CanaryRollout, &out.CanaryRollout
+		*out = new(AddonCanaryRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstallPlanApprovals != nil {
+		in, out := &in.InstallPlanApprovals, &out.InstallPlanApprovals
+		*out = make([]AddonInstallPlanApprovalDecision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeRollback != nil {
+		in, out := &in.UpgradeRollback, &out.UpgradeRollback
+		*out = new(AddonUpgradeRollbackStatus)
+		**out = **in
+	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.DeferredChanges != nil {
+		in, out := &in.DeferredChanges, &out.DeferredChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManifestObjects != nil {
+		in, out := &in.ManifestObjects, &out.ManifestObjects
+		*out = make([]AddonManifestObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.HelmObjects != nil {
+		in, out := &in.HelmObjects, &out.HelmObjects
+		*out = make([]AddonManifestObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonStatus.
@@ -587,6 +1038,7 @@ func (in *AddonStatus) DeepCopy() *AddonStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonUpgradePolicy) DeepCopyInto(out *AddonUpgradePolicy) {
 	*out = *in
+	out.Deadline = in.Deadline
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonUpgradePolicy.
@@ -602,6 +1054,10 @@ func (in *AddonUpgradePolicy) DeepCopy() *AddonUpgradePolicy {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AddonUpgradePolicyStatus) DeepCopyInto(out *AddonUpgradePolicyStatus) {
 	*out = *in
+	if in.StartedTime != nil {
+		in, out := &in.StartedTime, &out.StartedTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonUpgradePolicyStatus.
@@ -614,6 +1070,181 @@ func (in *AddonUpgradePolicyStatus) DeepCopy() *AddonUpgradePolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonUpgradeRollback) DeepCopyInto(out *AddonUpgradeRollback) {
+	*out = *in
+	out.ProgressDeadline = in.ProgressDeadline
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonUpgradeRollback.
+func (in *AddonUpgradeRollback) DeepCopy() *AddonUpgradeRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonUpgradeRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonUpgradeRollbackStatus) DeepCopyInto(out *AddonUpgradeRollbackStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonUpgradeRollbackStatus.
+func (in *AddonUpgradeRollbackStatus) DeepCopy() *AddonUpgradeRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonUpgradeRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonUpgradeStrategy) DeepCopyInto(out *AddonUpgradeStrategy) {
+	*out = *in
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(AddonCanaryUpgradeStrategy)
+		**out = **in
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(AddonUpgradeRollback)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonUpgradeStrategy.
+func (in *AddonUpgradeStrategy) DeepCopy() *AddonUpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonUpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonVersionTransition) DeepCopyInto(out *AddonVersionTransition) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AvailableTime != nil {
+		in, out := &in.AvailableTime, &out.AvailableTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonVersionTransition.
+func (in *AddonVersionTransition) DeepCopy() *AddonVersionTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonVersionTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSourceConfig) DeepCopyInto(out *CatalogSourceConfig) {
+	*out = *in
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(CatalogSourceUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GrpcPodConfig != nil {
+		in, out := &in.GrpcPodConfig, &out.GrpcPodConfig
+		*out = new(CatalogSourceGrpcPodConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSourceConfig.
+func (in *CatalogSourceConfig) DeepCopy() *CatalogSourceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSourceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSourceGrpcPodConfig) DeepCopyInto(out *CatalogSourceGrpcPodConfig) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MemoryTarget != nil {
+		in, out := &in.MemoryTarget, &out.MemoryTarget
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSourceGrpcPodConfig.
+func (in *CatalogSourceGrpcPodConfig) DeepCopy() *CatalogSourceGrpcPodConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSourceGrpcPodConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSourceRegistryPoll) DeepCopyInto(out *CatalogSourceRegistryPoll) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSourceRegistryPoll.
+func (in *CatalogSourceRegistryPoll) DeepCopy() *CatalogSourceRegistryPoll {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSourceRegistryPoll)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSourceUpdateStrategy) DeepCopyInto(out *CatalogSourceUpdateStrategy) {
+	*out = *in
+	if in.RegistryPoll != nil {
+		in, out := &in.RegistryPoll, &out.RegistryPoll
+		*out = new(CatalogSourceRegistryPoll)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSourceUpdateStrategy.
+func (in *CatalogSourceUpdateStrategy) DeepCopy() *CatalogSourceUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSourceUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterSecretReference) DeepCopyInto(out *ClusterSecretReference) {
 	*out = *in
@@ -632,6 +1263,11 @@ func (in *ClusterSecretReference) DeepCopy() *ClusterSecretReference {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvObject) DeepCopyInto(out *EnvObject) {
 	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(corev1.EnvVarSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvObject.
@@ -782,7 +1418,61 @@ func (in *SubscriptionConfig) DeepCopyInto(out *SubscriptionConfig) {
 	if in.EnvironmentVariables != nil {
 		in, out := &in.EnvironmentVariables, &out.EnvironmentVariables
 		*out = make([]EnvObject, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
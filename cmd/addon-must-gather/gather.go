@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	addoncontroller "github.com/openshift/addon-operator/controllers/addon"
+)
+
+// Namespace Events about cluster scoped objects like Addons are recorded in.
+const clusterEventsNamespace = "default"
+
+// Fetches the logs of a container.
+type podLogsFunc func(ctx context.Context, namespace, pod, container string) ([]byte, error)
+
+type gatherer struct {
+	client  client.Client
+	scheme  *runtime.Scheme
+	podLogs podLogsFunc
+	// Namespace the addon-operator is deployed into.
+	operatorNamespace string
+}
+
+// Bundle of everything gathered about a single Addon.
+type bundle struct {
+	addon *addonsv1alpha1.Addon
+	// Collected files by path within the bundle.
+	files map[string][]byte
+	// Related objects, for the summary.
+	catalogSources []*operatorsv1alpha1.CatalogSource
+	subscription   *operatorsv1alpha1.Subscription
+	installPlan    *operatorsv1alpha1.InstallPlan
+	csv            *operatorsv1alpha1.ClusterServiceVersion
+	addonInstance  *addonsv1alpha1.AddonInstance
+	namespaces     []*corev1.Namespace
+	events         []corev1.Event
+	// Related objects that do not exist.
+	missing []string
+	// Errors that did not stop gathering.
+	errors []string
+}
+
+func newBundle() *bundle {
+	return &bundle{files: map[string][]byte{}}
+}
+
+func (b *bundle) addError(format string, args ...interface{}) {
+	b.errors = append(b.errors, fmt.Sprintf(format, args...))
+}
+
+// Gathers the Addon and all objects related to it.
+func (g *gatherer) gather(ctx context.Context, addonName string) (*bundle, error) {
+	addon := &addonsv1alpha1.Addon{}
+	if err := g.client.Get(ctx, client.ObjectKey{Name: addonName}, addon); err != nil {
+		return nil, fmt.Errorf("getting Addon: %w", err)
+	}
+
+	b := newBundle()
+	b.addon = addon
+	g.addObject(b, "addon.yaml", addon)
+
+	g.gatherNamespaces(ctx, b)
+	if addoncontroller.GetCommonInstallOptions(addon).Namespace != "" {
+		g.gatherOLM(ctx, b)
+	}
+	g.gatherAddonInstance(ctx, b)
+	g.gatherServiceMonitors(ctx, b)
+	g.gatherSecrets(ctx, b)
+	g.gatherEvents(ctx, b)
+	g.gatherOperatorLogs(ctx, b)
+
+	b.files["summary.txt"] = []byte(summarize(b))
+	if len(b.errors) > 0 {
+		b.files["errors.txt"] = []byte(strings.Join(b.errors, "\n") + "\n")
+	}
+	return b, nil
+}
+
+// Namespaces the Addon is installed into, creates or is monitored in.
+func relatedNamespaces(addon *addonsv1alpha1.Addon) []string {
+	names := map[string]struct{}{}
+	for _, ns := range addon.Spec.Namespaces {
+		names[ns.Name] = struct{}{}
+	}
+	if ns := addoncontroller.GetAddonInstallNamespace(addon); len(ns) > 0 {
+		names[ns] = struct{}{}
+	}
+	if addon.Spec.Monitoring != nil && addon.Spec.Monitoring.Federation != nil {
+		names[addoncontroller.GetMonitoringNamespaceName(addon)] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func (g *gatherer) gatherNamespaces(ctx context.Context, b *bundle) {
+	for _, name := range relatedNamespaces(b.addon) {
+		ns := &corev1.Namespace{}
+		if !g.get(ctx, b, client.ObjectKey{Name: name}, ns) {
+			continue
+		}
+		b.namespaces = append(b.namespaces, ns)
+		g.addObject(b, path.Join("namespaces", name+".yaml"), ns)
+	}
+}
+
+func (g *gatherer) gatherOLM(ctx context.Context, b *bundle) {
+	addon := b.addon
+	commonOptions := addoncontroller.GetCommonInstallOptions(addon)
+	namespace := commonOptions.Namespace
+
+	catalogSourceNames := []string{addoncontroller.CatalogSourceName(addon)}
+	for _, additional := range commonOptions.AdditionalCatalogSources {
+		catalogSourceNames = append(catalogSourceNames, additional.Name)
+	}
+	for _, name := range catalogSourceNames {
+		catalogSource := &operatorsv1alpha1.CatalogSource{}
+		if g.get(ctx, b, client.ObjectKey{Name: name, Namespace: namespace}, catalogSource) {
+			b.catalogSources = append(b.catalogSources, catalogSource)
+			g.addObject(b, path.Join("olm", "catalogsource-"+name+".yaml"), catalogSource)
+		}
+	}
+
+	operator := &operatorsv1.Operator{}
+	if g.get(ctx, b, client.ObjectKey{Name: addoncontroller.OperatorResourceName(addon)}, operator) {
+		g.addObject(b, path.Join("olm", "operator.yaml"), operator)
+	}
+
+	subscription := &operatorsv1alpha1.Subscription{}
+	if !g.get(ctx, b, client.ObjectKey{
+		Name: addoncontroller.SubscriptionName(addon), Namespace: namespace,
+	}, subscription) {
+		return
+	}
+	b.subscription = subscription
+	g.addObject(b, path.Join("olm", "subscription.yaml"), subscription)
+
+	if ref := subscription.Status.InstallPlanRef; ref != nil {
+		installPlan := &operatorsv1alpha1.InstallPlan{}
+		if g.get(ctx, b, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, installPlan) {
+			b.installPlan = installPlan
+			g.addObject(b, path.Join("olm", "installplan.yaml"), installPlan)
+		}
+	}
+
+	csvName := subscription.Status.CurrentCSV
+	if len(csvName) == 0 {
+		csvName = subscription.Status.InstalledCSV
+	}
+	if len(csvName) > 0 {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		if g.get(ctx, b, client.ObjectKey{Name: csvName, Namespace: namespace}, csv) {
+			b.csv = csv
+			g.addObject(b, path.Join("olm", "csv.yaml"), csv)
+		}
+	}
+}
+
+func (g *gatherer) gatherAddonInstance(ctx context.Context, b *bundle) {
+	namespace := addoncontroller.GetAddonInstallNamespace(b.addon)
+	if len(namespace) == 0 {
+		return
+	}
+	addonInstance := &addonsv1alpha1.AddonInstance{}
+	if g.get(ctx, b, client.ObjectKey{
+		Name: addonsv1alpha1.DefaultAddonInstanceName, Namespace: namespace,
+	}, addonInstance) {
+		b.addonInstance = addonInstance
+		g.addObject(b, "addoninstance.yaml", addonInstance)
+	}
+}
+
+// Gathers the ServiceMonitors in the Namespaces of the Addon,
+// including the federation ServiceMonitor in the monitoring Namespace.
+func (g *gatherer) gatherServiceMonitors(ctx context.Context, b *bundle) {
+	for _, ns := range relatedNamespaces(b.addon) {
+		serviceMonitors := &monitoringv1.ServiceMonitorList{}
+		err := g.client.List(ctx, serviceMonitors, client.InNamespace(ns))
+		switch {
+		case meta.IsNoMatchError(err):
+			// Monitoring stack not installed.
+			return
+		case err != nil:
+			b.addError("listing ServiceMonitors in %s: %v", ns, err)
+			continue
+		}
+		for i := range serviceMonitors.Items {
+			serviceMonitor := &serviceMonitors.Items[i]
+			g.addObject(b, path.Join("monitoring", ns, serviceMonitor.Name+".yaml"), serviceMonitor)
+		}
+	}
+}
+
+// Gathers the source and propagated Secrets, without their data.
+func (g *gatherer) gatherSecrets(ctx context.Context, b *bundle) {
+	if b.addon.Spec.SecretPropagation == nil {
+		return
+	}
+	for _, ref := range b.addon.Spec.SecretPropagation.Secrets {
+		keys := []client.ObjectKey{{Name: ref.SourceSecret.Name, Namespace: g.operatorNamespace}}
+		for _, ns := range b.addon.Spec.Namespaces {
+			keys = append(keys, client.ObjectKey{Name: ref.DestinationSecret.Name, Namespace: ns.Name})
+		}
+
+		for _, key := range keys {
+			secret := &corev1.Secret{}
+			if !g.get(ctx, b, key, secret) {
+				continue
+			}
+			redactSecret(secret)
+			g.addObject(b, path.Join("secrets", key.Namespace, key.Name+".yaml"), secret)
+		}
+	}
+}
+
+// Replaces Secret values by their size.
+func redactSecret(secret *corev1.Secret) {
+	redacted := map[string]string{}
+	for k, v := range secret.Data {
+		redacted[k] = fmt.Sprintf("<redacted %d bytes>", len(v))
+	}
+	for k, v := range secret.StringData {
+		redacted[k] = fmt.Sprintf("<redacted %d bytes>", len(v))
+	}
+	secret.Data = nil
+	secret.StringData = redacted
+	delete(secret.Annotations, corev1.LastAppliedConfigAnnotation)
+	secret.ManagedFields = nil
+}
+
+// Gathers Events regarding the Addon and all Events in its Namespaces.
+func (g *gatherer) gatherEvents(ctx context.Context, b *bundle) {
+	addonEvents := &corev1.EventList{}
+	if err := g.client.List(ctx, addonEvents, client.InNamespace(clusterEventsNamespace)); err != nil {
+		b.addError("listing Events in %s: %v", clusterEventsNamespace, err)
+	} else {
+		var regarding []corev1.Event
+		for _, e := range addonEvents.Items {
+			if e.InvolvedObject.Kind == "Addon" && e.InvolvedObject.Name == b.addon.Name {
+				regarding = append(regarding, e)
+			}
+		}
+		g.addEvents(b, path.Join("events", "addon.yaml"), regarding)
+	}
+
+	for _, ns := range relatedNamespaces(b.addon) {
+		events := &corev1.EventList{}
+		if err := g.client.List(ctx, events, client.InNamespace(ns)); err != nil {
+			b.addError("listing Events in %s: %v", ns, err)
+			continue
+		}
+		g.addEvents(b, path.Join("events", ns+".yaml"), events.Items)
+	}
+}
+
+func (g *gatherer) addEvents(b *bundle, file string, events []corev1.Event) {
+	if len(events) == 0 {
+		return
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	b.events = append(b.events, events...)
+	g.addObject(b, file, &corev1.EventList{Items: events})
+}
+
+// Gathers the logs of the addon-operator manager containers.
+func (g *gatherer) gatherOperatorLogs(ctx context.Context, b *bundle) {
+	if g.podLogs == nil {
+		return
+	}
+	pods := &corev1.PodList{}
+	if err := g.client.List(ctx, pods,
+		client.InNamespace(g.operatorNamespace),
+		client.MatchingLabels{"app.kubernetes.io/name": "addon-operator"},
+	); err != nil {
+		b.addError("listing addon-operator Pods: %v", err)
+		return
+	}
+	for _, pod := range pods.Items {
+		logs, err := g.podLogs(ctx, pod.Namespace, pod.Name, "manager")
+		if err != nil {
+			b.addError("getting logs of Pod %s: %v", pod.Name, err)
+			continue
+		}
+		b.files[path.Join("logs", pod.Name+".log")] = logs
+	}
+}
+
+// Gets the object, returns false when it could not be gathered.
+func (g *gatherer) get(ctx context.Context, b *bundle, key client.ObjectKey, obj client.Object) bool {
+	err := g.client.Get(ctx, key, obj)
+	if err == nil {
+		return true
+	}
+
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, gvkErr := apiutil.GVKForObject(obj, g.scheme); gvkErr == nil {
+		kind = gvk.Kind
+	}
+	ref := strings.TrimPrefix(key.String(), "/")
+	switch {
+	case k8sApiErrors.IsNotFound(err):
+		b.missing = append(b.missing, fmt.Sprintf("%s %s", kind, ref))
+	case meta.IsNoMatchError(err):
+		// API not installed on the cluster, e.g. no monitoring stack.
+	default:
+		b.addError("getting %s %s: %v", kind, ref, err)
+	}
+	return false
+}
+
+// Adds the object as YAML, with apiVersion and kind set.
+func (g *gatherer) addObject(b *bundle, file string, obj runtime.Object) {
+	obj = obj.DeepCopyObject()
+	if gvk, err := apiutil.GVKForObject(obj, g.scheme); err == nil {
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		b.addError("marshaling %s: %v", file, err)
+		return
+	}
+	b.files[file] = data
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func TestGather(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-addon"},
+		Spec: addonsv1alpha1.AddonSpec{
+			Version:    "1.0.0",
+			Namespaces: []addonsv1alpha1.AddonNamespace{{Name: "test-ns"}},
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.OLMOwnNamespace,
+				OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{
+					AddonInstallOLMCommon: addonsv1alpha1.AddonInstallOLMCommon{
+						Namespace:   "test-ns",
+						PackageName: "test-package",
+					},
+				},
+			},
+			SecretPropagation: &addonsv1alpha1.AddonSecretPropagation{
+				Secrets: []addonsv1alpha1.AddonSecretPropagationReference{{
+					SourceSecret:      corev1.LocalObjectReference{Name: "source"},
+					DestinationSecret: corev1.LocalObjectReference{Name: "destination"},
+				}},
+			},
+		},
+		Status: addonsv1alpha1.AddonStatus{
+			Phase: addonsv1alpha1.PhasePending,
+			Conditions: []metav1.Condition{{
+				Type:    addonsv1alpha1.Available,
+				Status:  metav1.ConditionFalse,
+				Reason:  addonsv1alpha1.AddonReasonUnreadyCSV,
+				Message: "ClusterServiceVersion is not ready: failed",
+			}},
+		},
+	}
+	objs := []runtime.Object{
+		addon,
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-ns"},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&operatorsv1alpha1.CatalogSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-test-addon-catalog", Namespace: "test-ns"},
+			Status: operatorsv1alpha1.CatalogSourceStatus{
+				GRPCConnectionState: &operatorsv1alpha1.GRPCConnectionState{LastObservedState: "READY"},
+			},
+		},
+		&operatorsv1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-test-addon", Namespace: "test-ns"},
+			Status: operatorsv1alpha1.SubscriptionStatus{
+				CurrentCSV: "test-package.v1.0.0",
+				InstallPlanRef: &corev1.ObjectReference{
+					Name: "install-abcde", Namespace: "test-ns",
+				},
+			},
+		},
+		&operatorsv1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: "install-abcde", Namespace: "test-ns"},
+			Status:     operatorsv1alpha1.InstallPlanStatus{Phase: operatorsv1alpha1.InstallPlanPhaseComplete},
+		},
+		&operatorsv1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-package.v1.0.0", Namespace: "test-ns"},
+			Status: operatorsv1alpha1.ClusterServiceVersionStatus{
+				Phase:   operatorsv1alpha1.CSVPhaseFailed,
+				Reason:  operatorsv1alpha1.CSVReasonComponentFailed,
+				Message: "install strategy failed",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "destination", Namespace: "test-ns"},
+			Data:       map[string][]byte{"token": []byte("hunter2")},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "test-addon.1", Namespace: clusterEventsNamespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Addon", Name: "test-addon"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "CSVFailed",
+			Message:        "ClusterServiceVersion test-package.v1.0.0 failed.",
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "addon-operator-manager-1", Namespace: "addon-operator",
+				Labels: map[string]string{"app.kubernetes.io/name": "addon-operator"},
+			},
+		},
+	}
+
+	scheme, err := newScheme()
+	require.NoError(t, err)
+	g := &gatherer{
+		client:            fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		scheme:            scheme,
+		operatorNamespace: "addon-operator",
+		podLogs: func(_ context.Context, namespace, pod, container string) ([]byte, error) {
+			return []byte(namespace + "/" + pod + "/" + container + "\n"), nil
+		},
+	}
+
+	b, err := g.gather(context.Background(), "test-addon")
+	require.NoError(t, err)
+
+	for _, file := range []string{
+		"addon.yaml", "namespaces/test-ns.yaml",
+		"olm/catalogsource-addon-test-addon-catalog.yaml", "olm/subscription.yaml",
+		"olm/installplan.yaml", "olm/csv.yaml",
+		"secrets/test-ns/destination.yaml", "events/addon.yaml",
+		"logs/addon-operator-manager-1.log", "summary.txt",
+	} {
+		assert.Contains(t, b.files, file)
+	}
+	assert.Contains(t, string(b.files["olm/csv.yaml"]), "kind: ClusterServiceVersion")
+	assert.NotContains(t, string(b.files["secrets/test-ns/destination.yaml"]), "aHVudGVyMg")
+	assert.Contains(t, string(b.files["secrets/test-ns/destination.yaml"]), "<redacted 7 bytes>")
+	assert.Equal(t, "addon-operator/addon-operator-manager-1/manager\n",
+		string(b.files["logs/addon-operator-manager-1.log"]))
+
+	summary := string(b.files["summary.txt"])
+	assert.Contains(t, summary, "ClusterServiceVersion test-package.v1.0.0 is in phase Failed")
+	assert.Contains(t, summary, "Secret addon-operator/source does not exist.")
+	assert.Contains(t, summary, "Operator test-package.test-ns does not exist.")
+	assert.Contains(t, summary, "Addon/test-addon CSVFailed")
+	assert.NotContains(t, summary, "CatalogSource addon-test-addon-catalog")
+}
+
+func TestWriteTarball(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeTarball(buf, "root", map[string][]byte{
+		"b.txt":     []byte("b"),
+		"dir/a.txt": []byte("a"),
+	}))
+
+	gr, err := gzip.NewReader(buf)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	contents := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		contents[hdr.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{"root/b.txt": "b", "root/dir/a.txt": "a"}, contents)
+}
@@ -0,0 +1,161 @@
+// addon-must-gather collects an Addon and all objects related to it,
+// Events and addon-operator logs into a tarball for debugging.
+//
+// Usage:
+//
+//	addon-must-gather --addon <name> [--output <file.tar.gz>]
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func main() {
+	var (
+		addonName         string
+		output            string
+		operatorNamespace string
+		logLines          int64
+		timeout           time.Duration
+	)
+	flag.StringVar(&addonName, "addon", "", "Name of the Addon to gather.")
+	flag.StringVar(&output, "output", "",
+		"Path of the tarball to write, defaults to addon-must-gather-<addon>-<timestamp>.tar.gz.")
+	flag.StringVar(&operatorNamespace, "operator-namespace", "addon-operator",
+		"Namespace the addon-operator is deployed into.")
+	flag.Int64Var(&logLines, "log-lines", 2000, "Number of the most recent addon-operator log lines to gather.")
+	flag.DurationVar(&timeout, "timeout", 2*time.Minute, "Timeout for gathering.")
+	flag.Parse()
+
+	if len(addonName) == 0 {
+		fmt.Fprintln(os.Stderr, "--addon is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if len(output) == 0 {
+		output = fmt.Sprintf("addon-must-gather-%s-%s.tar.gz", addonName, time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	if err := run(addonName, output, operatorNamespace, logLines, timeout); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s\n", output)
+}
+
+func run(addonName, output, operatorNamespace string, logLines int64, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	scheme, err := newScheme()
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("creating client: %w", err)
+	}
+	coreClient, err := corev1client.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("creating core client: %w", err)
+	}
+
+	g := &gatherer{
+		client:            c,
+		scheme:            scheme,
+		operatorNamespace: operatorNamespace,
+		podLogs: func(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+			return coreClient.Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+				Container: container,
+				TailLines: &logLines,
+			}).DoRaw(ctx)
+		},
+	}
+	b, err := g.gather(ctx, addonName)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("creating output: %w", err)
+	}
+	defer f.Close()
+	if err := writeTarball(f, "addon-must-gather-"+addonName, b.files); err != nil {
+		return fmt.Errorf("writing tarball: %w", err)
+	}
+	return f.Close()
+}
+
+func newScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		operatorsv1.AddToScheme,
+		operatorsv1alpha1.AddToScheme,
+		monitoringv1.AddToScheme,
+		addonsv1alpha1.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			return nil, fmt.Errorf("building scheme: %w", err)
+		}
+	}
+	return scheme, nil
+}
+
+// Writes the files into a gzipped tarball below the root directory.
+func writeTarball(w io.Writer, root string, files map[string][]byte) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(root, name),
+			Mode:    0o644,
+			Size:    int64(len(files[name])),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// Number of the most recent Warning Events listed in the summary.
+const summaryWarningEvents = 10
+
+// Subscription conditions that block the install, when True.
+var blockingSubscriptionConditions = []operatorsv1alpha1.SubscriptionConditionType{
+	operatorsv1alpha1.SubscriptionCatalogSourcesUnhealthy,
+	operatorsv1alpha1.SubscriptionResolutionFailed,
+	operatorsv1alpha1.SubscriptionInstallPlanFailed,
+	operatorsv1alpha1.SubscriptionInstallPlanMissing,
+	operatorsv1alpha1.SubscriptionBundleUnpackFailed,
+}
+
+// Renders a human readable summary of the bundle,
+// explaining why the Addon is not Available.
+func summarize(b *bundle) string {
+	addon := b.addon
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "Addon: %s\n", addon.Name)
+	fmt.Fprintf(&s, "Version: %s (observed %s)\n", addon.Spec.Version, addon.Status.ObservedVersion)
+	fmt.Fprintf(&s, "Install type: %s\n", addon.Spec.Install.Type)
+	fmt.Fprintf(&s, "Phase: %s\n", addon.Status.Phase)
+	if available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available); available != nil {
+		fmt.Fprintf(&s, "Available: %s\n", formatCondition(*available))
+	} else {
+		fmt.Fprintf(&s, "Available: condition missing\n")
+	}
+
+	if meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Available) {
+		fmt.Fprintf(&s, "\nThe Addon is Available.\n")
+	} else {
+		writeSection(&s, "Why the Addon is not Available", diagnose(b))
+	}
+
+	var conditions []string
+	for _, cond := range addon.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s: %s", cond.Type, formatCondition(cond)))
+	}
+	writeSection(&s, "Conditions", conditions)
+	writeSection(&s, "Recent Warning Events", recentWarnings(b.events))
+	if len(b.errors) > 0 {
+		writeSection(&s, "Gathering errors", b.errors)
+	}
+	return s.String()
+}
+
+// Returns findings explaining why the Addon is not Available, most specific first.
+func diagnose(b *bundle) []string {
+	addon := b.addon
+	var findings []string
+
+	if !addon.DeletionTimestamp.IsZero() {
+		findings = append(findings, "The Addon is being deleted.")
+	}
+	if paused := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Paused); paused != nil &&
+		paused.Status == metav1.ConditionTrue {
+		findings = append(findings, fmt.Sprintf("Reconciliation is paused: %s.", paused.Reason))
+	}
+
+	for _, ns := range b.namespaces {
+		if ns.Status.Phase != corev1.NamespaceActive {
+			findings = append(findings, fmt.Sprintf("Namespace %s is %s.", ns.Name, ns.Status.Phase))
+		}
+	}
+	for _, missing := range b.missing {
+		findings = append(findings, fmt.Sprintf("%s does not exist.", missing))
+	}
+
+	for _, catalogSource := range b.catalogSources {
+		state := catalogSource.Status.GRPCConnectionState
+		switch {
+		case state == nil:
+			findings = append(findings, fmt.Sprintf(
+				"CatalogSource %s has no connection state yet.", catalogSource.Name))
+		case state.LastObservedState != "READY":
+			findings = append(findings, fmt.Sprintf(
+				"CatalogSource %s connection is %s.", catalogSource.Name, state.LastObservedState))
+		}
+	}
+
+	if sub := b.subscription; sub != nil {
+		for _, condType := range blockingSubscriptionConditions {
+			cond := sub.Status.GetCondition(condType)
+			if cond.Status == corev1.ConditionTrue {
+				findings = append(findings, fmt.Sprintf(
+					"Subscription %s is %s: %s: %s", sub.Name, condType, cond.Reason, cond.Message))
+			}
+		}
+	}
+
+	if ip := b.installPlan; ip != nil {
+		switch {
+		case ip.Status.Phase == operatorsv1alpha1.InstallPlanPhaseRequiresApproval && !ip.Spec.Approved:
+			findings = append(findings, fmt.Sprintf("InstallPlan %s is waiting for approval.", ip.Name))
+		case ip.Status.Phase != operatorsv1alpha1.InstallPlanPhaseComplete:
+			findings = append(findings, fmt.Sprintf("InstallPlan %s is in phase %s.", ip.Name, ip.Status.Phase))
+		}
+	}
+
+	if csv := b.csv; csv != nil && csv.Status.Phase != operatorsv1alpha1.CSVPhaseSucceeded {
+		findings = append(findings, fmt.Sprintf("ClusterServiceVersion %s is in phase %s: %s: %s",
+			csv.Name, csv.Status.Phase, csv.Status.Reason, csv.Status.Message))
+	}
+
+	if ai := b.addonInstance; ai != nil {
+		for _, condType := range []addonsv1alpha1.AddonInstanceCondition{
+			addonsv1alpha1.AddonInstanceConditionHealthy, addonsv1alpha1.AddonInstanceConditionInstalled,
+		} {
+			cond := meta.FindStatusCondition(ai.Status.Conditions, condType.String())
+			if cond != nil && cond.Status == metav1.ConditionFalse {
+				findings = append(findings, fmt.Sprintf("AddonInstance reports %s: %s",
+					condType, formatCondition(*cond)))
+			}
+		}
+	}
+
+	// The conditions of the Addon explain states the objects above do not cover.
+	if available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available); available != nil &&
+		available.Status != metav1.ConditionTrue {
+		findings = append(findings, fmt.Sprintf("Addon reports %s", formatCondition(*available)))
+	}
+	for _, condType := range []string{addonsv1alpha1.UpgradeFailed, addonsv1alpha1.RolledBack} {
+		if cond := meta.FindStatusCondition(addon.Status.Conditions, condType); cond != nil &&
+			cond.Status == metav1.ConditionTrue {
+			findings = append(findings, fmt.Sprintf("Addon reports %s: %s", condType, formatCondition(*cond)))
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, "No cause found, check the Events and operator logs.")
+	}
+	return findings
+}
+
+func formatCondition(cond metav1.Condition) string {
+	if len(cond.Message) == 0 {
+		return fmt.Sprintf("%s (%s)", cond.Status, cond.Reason)
+	}
+	return fmt.Sprintf("%s (%s): %s", cond.Status, cond.Reason, cond.Message)
+}
+
+func recentWarnings(events []corev1.Event) []string {
+	var warnings []corev1.Event
+	for _, e := range events {
+		if e.Type == corev1.EventTypeWarning {
+			warnings = append(warnings, e)
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return eventTime(warnings[i]).Before(eventTime(warnings[j]))
+	})
+	if len(warnings) > summaryWarningEvents {
+		warnings = warnings[len(warnings)-summaryWarningEvents:]
+	}
+
+	lines := make([]string, 0, len(warnings))
+	for _, e := range warnings {
+		lines = append(lines, fmt.Sprintf("%s %s/%s %s: %s",
+			eventTime(e).UTC().Format(time.RFC3339),
+			e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, strings.TrimSpace(e.Message)))
+	}
+	return lines
+}
+
+// Events API and core Events set different timestamps.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+func writeSection(s *strings.Builder, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(s, "\n%s:\n", title)
+	for _, line := range lines {
+		fmt.Fprintf(s, "- %s\n", line)
+	}
+}
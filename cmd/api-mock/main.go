@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	ioutil "io"
 	"log"
@@ -17,6 +18,34 @@ import (
 )
 
 func main() {
+	var scenarioFile string
+	flag.StringVar(&scenarioFile, "scenario", "",
+		"JSON or YAML file with the scenario of faults to inject at startup.")
+	flag.Parse()
+
+	faultInjector := NewFaultInjector()
+	if len(scenarioFile) > 0 {
+		scenario, err := LoadScenarioFile(scenarioFile)
+		if err != nil {
+			panic(err)
+		}
+		if err := faultInjector.SetScenario(scenario); err != nil {
+			panic(err)
+		}
+		log.Printf("loaded scenario with %d rules from %s\n", len(scenario.Rules), scenarioFile)
+	}
+
+	addr := ":8080"
+	log.Printf("listening on %s\n", addr)
+
+	//nolint: gosec
+	if err := http.ListenAndServe(addr, newRouter(faultInjector)); err != nil {
+		panic(err)
+	}
+}
+
+// Serves the OCM API endpoints, with faults injected into all requests to /api/.
+func newRouter(faultInjector *FaultInjector) http.Handler {
 	r := mux.NewRouter()
 	addonStatusStore := NewAddonStatusStore()
 	r.HandleFunc("/healthz", Health)
@@ -29,6 +58,11 @@ func main() {
 		"/api/clusters_mgmt/v1/clusters/{cluster_id}/addon_upgrade_policies/{upgrade_policy_id}/state",
 		NewUpgradePolicyStateEndpoint(),
 	)
+	// Registered first, so "bulk" is not taken as addon id.
+	r.Handle(
+		"/api/addons_mgmt/v1/clusters/{cluster_id}/status/bulk",
+		NewAddonStatusBulkEndpoint(addonStatusStore),
+	)
 	r.Handle(
 		"/api/addons_mgmt/v1/clusters/{cluster_id}/status/{addon_id}",
 		NewAddonStatusEndpoint(addonStatusStore),
@@ -37,15 +71,14 @@ func main() {
 		"/api/addons_mgmt/v1/clusters/{cluster_id}/status",
 		NewAddonStatusCreateEndpoint(addonStatusStore),
 	)
+	r.Handle(
+		"/api/addons_mgmt/v1/clusters/{cluster_id}/addons",
+		NewAddonInstallationsEndpoint(),
+	)
 	r.HandleFunc("/configure", configure).Methods(http.MethodPatch)
-
-	addr := ":8080"
-	log.Printf("listening on %s\n", addr)
-
-	//nolint: gosec
-	if err := http.ListenAndServe(addr, r); err != nil {
-		panic(err)
-	}
+	r.HandleFunc("/scenario", faultInjector.ServeScenario)
+	r.HandleFunc("/requests", faultInjector.ServeRequests)
+	return faultInjector.Wrap(r)
 }
 
 func Health(w http.ResponseWriter, r *http.Request) {
@@ -138,6 +171,7 @@ type addonStatus struct {
 	AddonVersion  string `json:"version"`
 	// We dont care about this unmarshalling this field.
 	StatusConditions []interface{} `json:"status_conditions"`
+	ocm.AddOnStatusDetails
 }
 
 func NewAddonStatusStore() *addonStatusStore {
@@ -220,6 +254,63 @@ func (a *AddonStatusCreateEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Req
 	}
 }
 
+type AddonStatusBulkEndpoint struct {
+	store *addonStatusStore
+}
+
+func NewAddonStatusBulkEndpoint(store *addonStatusStore) *AddonStatusBulkEndpoint {
+	return &AddonStatusBulkEndpoint{
+		store: store,
+	}
+}
+
+type addonStatusBulk struct {
+	Items []addonStatus `json:"items"`
+}
+
+func (a *AddonStatusBulkEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	a.store.dataMux.Lock()
+	defer a.store.dataMux.Unlock()
+	bulk := addonStatusBulk{}
+	if err := json.NewDecoder(r.Body).Decode(&bulk); err != nil {
+		log.Printf("unmarshalling request body: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintln(w, `{}`)
+		return
+	}
+	for _, status := range bulk.Items {
+		if len(status.AddonID) == 0 {
+			log.Printf("Missing addonID in addon status bulk request.")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, `{"code":"error","reason":"addonID missing"}`)
+			return
+		}
+	}
+
+	vars := mux.Vars(r)
+	for _, status := range bulk.Items {
+		a.store.data[addonStatusKey{
+			addonID:   status.AddonID,
+			clusterID: vars["cluster_id"],
+		}] = status
+	}
+	respBytes, err := json.Marshal(bulk)
+	if err != nil {
+		log.Printf("marshaling response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintln(w, `{}`)
+		return
+	}
+	log.Printf("%s %s: %d statuses\n", r.URL.String(), r.Method, len(bulk.Items))
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, string(respBytes))
+}
+
 type AddonStatusEndpoint struct {
 	store *addonStatusStore
 }
@@ -298,6 +389,66 @@ func (ase *AddonStatusEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// Serves the addon installations of a cluster,
+// which are replaced as a whole with PUT.
+type AddonInstallationsEndpoint struct {
+	data    map[string][]ocm.AddonInstallation
+	dataMux sync.RWMutex
+}
+
+func NewAddonInstallationsEndpoint() *AddonInstallationsEndpoint {
+	return &AddonInstallationsEndpoint{
+		data: map[string][]ocm.AddonInstallation{},
+	}
+}
+
+func (ai *AddonInstallationsEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	switch r.Method {
+	case http.MethodGet:
+		ai.dataMux.RLock()
+		defer ai.dataMux.RUnlock()
+
+		items := ai.data[vars["cluster_id"]]
+		respBytes, err := json.Marshal(ocm.AddonInstallationListResponse{
+			Kind:  "AddonInstallationList",
+			Page:  1,
+			Size:  len(items),
+			Total: len(items),
+			Items: items,
+		})
+		if err != nil {
+			log.Printf("marshaling response: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintln(w, `{}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, string(respBytes))
+		log.Printf("%s %s:\n", r.URL.String(), r.Method)
+
+	case http.MethodPut:
+		ai.dataMux.Lock()
+		defer ai.dataMux.Unlock()
+
+		var items []ocm.AddonInstallation
+		if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+			log.Printf("unmarshalling request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, `{}`)
+			return
+		}
+		ai.data[vars["cluster_id"]] = items
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, `{}`)
+		log.Printf("%s %s:\n", r.URL.String(), r.Method)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+}
+
 type UpgradePolicyStateKey struct {
 	ClusterID, UpgradePolicyID string
 }
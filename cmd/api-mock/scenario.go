@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
+)
+
+// Oldest recorded requests are dropped beyond this number.
+const maxRecordedRequests = 10000
+
+// Injects the faults of the current scenario into OCM API requests
+// and records all of them.
+type FaultInjector struct {
+	lock     sync.Mutex
+	scenario ocmtest.Scenario
+	paths    []*regexp.Regexp
+	applied  []int
+	requests []ocmtest.RecordedRequest
+}
+
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{}
+}
+
+// Reads a scenario from a JSON or YAML file.
+func LoadScenarioFile(path string) (ocmtest.Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ocmtest.Scenario{}, fmt.Errorf("reading scenario file: %w", err)
+	}
+	scenario := ocmtest.Scenario{}
+	if err := yaml.UnmarshalStrict(data, &scenario); err != nil {
+		return ocmtest.Scenario{}, fmt.Errorf("parsing scenario file: %w", err)
+	}
+	return scenario, nil
+}
+
+// Replaces the current scenario, restarting the count of every rule.
+func (fi *FaultInjector) SetScenario(scenario ocmtest.Scenario) error {
+	paths := make([]*regexp.Regexp, len(scenario.Rules))
+	for i, rule := range scenario.Rules {
+		re, err := regexp.Compile(rule.Path)
+		if err != nil {
+			return fmt.Errorf("rule %d: invalid path: %w", i, err)
+		}
+		paths[i] = re
+	}
+
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+	fi.scenario = scenario
+	fi.paths = paths
+	fi.applied = make([]int, len(scenario.Rules))
+	return nil
+}
+
+// Wraps the handler serving the OCM API.
+// Requests outside of /api/ are passed through untouched.
+func (fi *FaultInjector) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		req := ocmtest.RecordedRequest{
+			Time:   time.Now(),
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Token:  requestToken(r),
+			Body:   string(body),
+		}
+		rw := &statusRecorder{ResponseWriter: w}
+		defer func() { fi.record(req, rw.statusCode) }()
+
+		rule, ok := fi.match(r)
+		if !ok {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		req.Rule = rule.Name
+		log.Printf("%s %s: applying rule %q\n", r.URL.String(), r.Method, rule.Name)
+
+		if rule.Latency.Duration > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(rule.Latency.Duration):
+			}
+		}
+
+		switch {
+		case len(rule.Tokens) > 0 && !slices.Contains(rule.Tokens, req.Token):
+			writeOCMError(rw, http.StatusUnauthorized, "unauthorized", "invalid token")
+
+		case rule.DropConnection:
+			dropConnection(rw)
+
+		case rule.StatusCode != 0:
+			if rule.RetryAfter.Duration > 0 {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(rule.RetryAfter.Seconds())))
+			}
+			if len(rule.Body) == 0 {
+				writeOCMError(rw, rule.StatusCode, "injected", "injected by the api-mock")
+				return
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(rule.StatusCode)
+			_, _ = rw.Write(rule.Body)
+
+		default:
+			next.ServeHTTP(rw, r)
+		}
+	})
+}
+
+// Returns the first rule matching the request that did not run out of applications yet.
+func (fi *FaultInjector) match(r *http.Request) (ocmtest.FaultRule, bool) {
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+
+	for i, rule := range fi.scenario.Rules {
+		if len(rule.Method) > 0 && rule.Method != r.Method {
+			continue
+		}
+		if !fi.paths[i].MatchString(r.URL.Path) {
+			continue
+		}
+		if rule.Times > 0 && fi.applied[i] >= rule.Times {
+			continue
+		}
+		fi.applied[i]++
+		if len(rule.Name) == 0 {
+			rule.Name = strconv.Itoa(i)
+		}
+		return rule, true
+	}
+	return ocmtest.FaultRule{}, false
+}
+
+func (fi *FaultInjector) record(req ocmtest.RecordedRequest, statusCode int) {
+	req.StatusCode = statusCode
+
+	fi.lock.Lock()
+	defer fi.lock.Unlock()
+	fi.requests = append(fi.requests, req)
+	if dropped := len(fi.requests) - maxRecordedRequests; dropped > 0 {
+		fi.requests = fi.requests[dropped:]
+	}
+}
+
+// Serves the current scenario:
+// GET returns it, PUT replaces it and DELETE removes all rules.
+func (fi *FaultInjector) ServeScenario(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		fi.lock.Lock()
+		defer fi.lock.Unlock()
+		writeJSON(w, fi.scenario)
+
+	case http.MethodPut:
+		scenario := ocmtest.Scenario{}
+		if err := json.NewDecoder(r.Body).Decode(&scenario); err != nil {
+			writeOCMError(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+		if err := fi.SetScenario(scenario); err != nil {
+			writeOCMError(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+		log.Printf("scenario set with %d rules\n", len(scenario.Rules))
+		writeJSON(w, scenario)
+
+	case http.MethodDelete:
+		_ = fi.SetScenario(ocmtest.Scenario{})
+		log.Println("scenario reset")
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Serves the recorded requests:
+// GET lists them, optionally filtered by the "method" and "path" (regular expression) query parameters,
+// DELETE forgets them.
+func (fi *FaultInjector) ServeRequests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		method := r.URL.Query().Get("method")
+		path, err := regexp.Compile(r.URL.Query().Get("path"))
+		if err != nil {
+			writeOCMError(w, http.StatusBadRequest, "invalid", err.Error())
+			return
+		}
+
+		fi.lock.Lock()
+		defer fi.lock.Unlock()
+		requests := []ocmtest.RecordedRequest{}
+		for _, req := range fi.requests {
+			if (len(method) == 0 || req.Method == method) && path.MatchString(req.Path) {
+				requests = append(requests, req)
+			}
+		}
+		writeJSON(w, requests)
+
+	case http.MethodDelete:
+		fi.lock.Lock()
+		defer fi.lock.Unlock()
+		fi.requests = nil
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Returns the token of "AccessToken <cluster id>:<token>" and "Bearer <token>" headers.
+func requestToken(r *http.Request) string {
+	scheme, credential, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	switch scheme {
+	case "AccessToken":
+		_, token, _ := strings.Cut(credential, ":")
+		return token
+	case "Bearer":
+		return credential
+	default:
+		return ""
+	}
+}
+
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = conn.Close()
+}
+
+func writeOCMError(w http.ResponseWriter, statusCode int, code, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(ocm.OCMError{Code: code, Reason: reason})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("marshaling response: %v", err)
+	}
+}
+
+// Remembers the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Hijack lets rules drop the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
+)
+
+const clustersPath = "/api/clusters_mgmt/v1/clusters"
+
+func newScenarioTestServer(t *testing.T, scenario ocmtest.Scenario) *httptest.Server {
+	t.Helper()
+
+	fi := NewFaultInjector()
+	require.NoError(t, fi.SetScenario(scenario))
+	server := httptest.NewServer(newRouter(fi))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newScenarioTestClient(ctx context.Context, endpoint, token string) (*ocm.Client, error) {
+	return ocm.NewClient(ctx,
+		ocm.WithEndpoint(endpoint),
+		ocm.WithAccessToken(token),
+		ocm.WithClusterExternalID("external-id"),
+		ocm.WithRequestTimeout(100*time.Millisecond),
+		ocm.WithRetry(ocm.RetryOptions{
+			MaxRetries: 3,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   10 * time.Millisecond,
+		}),
+	)
+}
+
+func getRecordedRequests(t *testing.T, endpoint, query string) []ocmtest.RecordedRequest {
+	t.Helper()
+
+	res, err := http.Get(endpoint + "/requests?" + query)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var requests []ocmtest.RecordedRequest
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&requests))
+	return requests
+}
+
+func recordedStatusCodes(requests []ocmtest.RecordedRequest) []int {
+	statusCodes := make([]int, len(requests))
+	for i, req := range requests {
+		statusCodes[i] = req.StatusCode
+	}
+	return statusCodes
+}
+
+func TestFaultInjector_Outage(t *testing.T) {
+	server := newScenarioTestServer(t, ocmtest.Scenario{
+		Rules: []ocmtest.FaultRule{
+			{Name: "outage", Path: "^" + clustersPath + "$", Times: 2, StatusCode: http.StatusServiceUnavailable},
+			{Name: "reset", Path: "^" + clustersPath + "$", Times: 1, DropConnection: true},
+		},
+	})
+
+	c, err := newScenarioTestClient(context.Background(), server.URL, "token")
+	require.NoError(t, err)
+	id, _ := c.GetClusterIDAndName()
+	assert.Equal(t, ocmtest.MockClusterId, id)
+
+	requests := getRecordedRequests(t, server.URL, "path=clusters_mgmt")
+	assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, 0, http.StatusOK},
+		recordedStatusCodes(requests))
+	assert.Equal(t, "outage", requests[0].Rule)
+	assert.Equal(t, "reset", requests[2].Rule)
+	assert.Equal(t, "token", requests[0].Token)
+}
+
+func TestFaultInjector_AuthFailure(t *testing.T) {
+	server := newScenarioTestServer(t, ocmtest.Scenario{
+		Rules: []ocmtest.FaultRule{
+			{Path: "^/api/", Tokens: []string{"valid"}},
+		},
+	})
+
+	_, err := newScenarioTestClient(context.Background(), server.URL, "expired")
+	var ocmErr ocm.OCMError
+	require.ErrorAs(t, err, &ocmErr)
+	assert.Equal(t, http.StatusUnauthorized, ocmErr.StatusCode)
+
+	_, err = newScenarioTestClient(context.Background(), server.URL, "valid")
+	require.NoError(t, err)
+}
+
+func TestFaultInjector_SlowResponse(t *testing.T) {
+	server := newScenarioTestServer(t, ocmtest.Scenario{
+		Rules: []ocmtest.FaultRule{
+			{Path: "^" + clustersPath + "$", Latency: metav1.Duration{Duration: time.Second}},
+		},
+	})
+
+	// Every attempt runs into the request timeout.
+	_, err := newScenarioTestClient(context.Background(), server.URL, "token")
+	require.Error(t, err)
+	// Recorded once the handler notices the canceled request.
+	assert.Eventually(t, func() bool {
+		return len(getRecordedRequests(t, server.URL, "method=GET")) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFaultInjector_ControlAPI(t *testing.T) {
+	server := newScenarioTestServer(t, ocmtest.Scenario{})
+
+	put := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/scenario", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res
+	}
+
+	assert.Equal(t, http.StatusBadRequest, put(`{"rules":[{"path":"("}]}`).StatusCode)
+	assert.Equal(t, http.StatusOK,
+		put(`{"rules":[{"path":"/status/addon-1$","statusCode":404,"body":{"code":"gone"}}]}`).StatusCode)
+
+	res, err := http.Get(server.URL + "/api/addons_mgmt/v1/clusters/1ou/status/addon-1")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	// Control requests are not recorded.
+	require.Len(t, getRecordedRequests(t, server.URL, ""), 1)
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/requests", nil)
+	require.NoError(t, err)
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Empty(t, getRecordedRequests(t, server.URL, ""))
+}
+
+func TestLoadScenarioFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+- name: slow-status
+  path: /status$
+  method: POST
+  latency: 2s
+- path: /addons$
+  statusCode: 500
+  times: 3
+`), 0o600))
+
+	scenario, err := LoadScenarioFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, ocmtest.Scenario{
+		Rules: []ocmtest.FaultRule{
+			{Name: "slow-status", Path: "/status$", Method: http.MethodPost, Latency: metav1.Duration{Duration: 2 * time.Second}},
+			{Path: "/addons$", StatusCode: http.StatusInternalServerError, Times: 3},
+		},
+	}, scenario)
+}
@@ -0,0 +1,577 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	addoncontroller "github.com/openshift/addon-operator/controllers/addon"
+)
+
+// Default of the AddonInstance controller: a heartbeat is stale
+// after this many heartbeat update periods without a new one.
+const heartbeatThresholdMultiplier = 3
+
+type cli struct {
+	client client.Client
+	out    io.Writer
+	errOut io.Writer
+	now    func() time.Time
+}
+
+func newCLI(c client.Client, out io.Writer) *cli {
+	return &cli{
+		client: c,
+		out:    out,
+		errOut: os.Stderr,
+		now:    time.Now,
+	}
+}
+
+// Dispatches args to the subcommand they name.
+func (c *cli) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return c.usageError("missing command")
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		if _, err := c.parse(command, args, 0, nil); err != nil {
+			return err
+		}
+		return c.list(ctx)
+
+	case "describe":
+		names, err := c.parse(command, args, 1, nil)
+		if err != nil {
+			return err
+		}
+		return c.describe(ctx, names[0])
+
+	case "pause", "resume":
+		names, err := c.parse(command, args, 1, nil)
+		if err != nil {
+			return err
+		}
+		return c.setPaused(ctx, names[0], command == "pause")
+
+	case "delete":
+		var ackTimeout time.Duration
+		names, err := c.parse(command, args, 1, func(fs *flag.FlagSet) {
+			fs.DurationVar(&ackTimeout, "ack-timeout", 0,
+				"Time to wait for the addon to acknowledge the deletion, defaults to the operator default of 1h.")
+		})
+		if err != nil {
+			return err
+		}
+		return c.requestDeletion(ctx, names[0], ackTimeout)
+
+	case "approve-installplan":
+		names, err := c.parse(command, args, 1, nil)
+		if err != nil {
+			return err
+		}
+		return c.approveInstallPlan(ctx, names[0])
+
+	case "heartbeat":
+		if len(args) == 0 || args[0] != "status" {
+			return c.usageError("usage: heartbeat status <addon>")
+		}
+		names, err := c.parse("heartbeat status", args[1:], 1, nil)
+		if err != nil {
+			return err
+		}
+		return c.heartbeatStatus(ctx, names[0])
+
+	default:
+		return c.usageError(fmt.Sprintf("unknown command %q", command))
+	}
+}
+
+// Parses the flags of a subcommand, which may be given before or after
+// the positional arguments, and checks the number of positional arguments.
+func (c *cli) parse(
+	command string, args []string, positional int, setup func(*flag.FlagSet),
+) ([]string, error) {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(c.errOut)
+	if setup != nil {
+		setup(fs)
+	}
+
+	var names []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		names = append(names, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(names) != positional {
+		if positional == 0 {
+			return nil, c.usageError(fmt.Sprintf("%s takes no arguments", command))
+		}
+		return nil, c.usageError(fmt.Sprintf("usage: %s <addon>", command))
+	}
+	return names, nil
+}
+
+func (c *cli) usageError(msg string) error {
+	fmt.Fprintln(c.errOut, msg)
+	return errUsage
+}
+
+// Lists all Addons with their phase, versions and health.
+func (c *cli) list(ctx context.Context) error {
+	addons := &addonsv1alpha1.AddonList{}
+	if err := c.client.List(ctx, addons); err != nil {
+		return fmt.Errorf("listing Addons: %w", err)
+	}
+	sort.Slice(addons.Items, func(i, j int) bool {
+		return addons.Items[i].Name < addons.Items[j].Name
+	})
+
+	instances := &addonsv1alpha1.AddonInstanceList{}
+	if err := c.client.List(ctx, instances); err != nil {
+		return fmt.Errorf("listing AddonInstances: %w", err)
+	}
+	instancesByNamespace := map[string]*addonsv1alpha1.AddonInstance{}
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if instance.Name == addonsv1alpha1.DefaultAddonInstanceName {
+			instancesByNamespace[instance.Namespace] = instance
+		}
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPHASE\tVERSION\tOBSERVED\tAVAILABLE\tHEALTH\tAGE")
+	for i := range addons.Items {
+		addon := &addons.Items[i]
+		instance := instancesByNamespace[addoncontroller.GetAddonInstallNamespace(addon)]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			addon.Name,
+			orNone(string(addon.Status.Phase)),
+			orNone(addon.Spec.Version),
+			orNone(addon.Status.ObservedVersion),
+			conditionStatus(addon.Status.Conditions, addonsv1alpha1.Available),
+			instanceHealth(instance),
+			c.age(addon.CreationTimestamp),
+		)
+	}
+	return w.Flush()
+}
+
+// Prints the conditions, history and child resources of an Addon.
+func (c *cli) describe(ctx context.Context, name string) error {
+	addon, err := c.getAddon(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", addon.Name)
+	fmt.Fprintf(w, "Version:\t%s\n", orNone(addon.Spec.Version))
+	fmt.Fprintf(w, "Observed Version:\t%s\n", orNone(addon.Status.ObservedVersion))
+	fmt.Fprintf(w, "Install Type:\t%s\n", addon.Spec.Install.Type)
+	fmt.Fprintf(w, "Install Namespace:\t%s\n", orNone(addoncontroller.GetAddonInstallNamespace(addon)))
+	fmt.Fprintf(w, "Phase:\t%s\n", orNone(string(addon.Status.Phase)))
+	fmt.Fprintf(w, "Paused:\t%t\n", addon.Spec.Paused)
+	if _, ok := addon.Annotations[addonsv1alpha1.DeleteAnnotationFlag]; ok {
+		ackTimeout := addon.Annotations[addonsv1alpha1.DeleteTimeoutDuration]
+		fmt.Fprintf(w, "Deletion Requested:\ttrue (ack timeout %s)\n", orDefault(ackTimeout, "default"))
+	}
+	if !addon.DeletionTimestamp.IsZero() {
+		fmt.Fprintf(w, "Deleting Since:\t%s\n", addon.DeletionTimestamp.UTC().Format(time.RFC3339))
+	}
+	if hash := addon.Status.OCMReportedStatusHash; hash != nil {
+		fmt.Fprintf(w, "OCM Reported Status Hash:\t%s (generation %d)\n", hash.StatusHash, hash.ObservedGeneration)
+	} else {
+		fmt.Fprintf(w, "OCM Reported Status Hash:\t<none>\n")
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "\nConditions:")
+	w = tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tAGE\tMESSAGE")
+	for _, cond := range addon.Status.Conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+			cond.Type, cond.Status, cond.Reason, c.age(cond.LastTransitionTime), cond.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(addon.Status.History) > 0 {
+		fmt.Fprintln(c.out, "\nHistory:")
+		w = tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "  FROM\tTO\tOUTCOME\tSTARTED\tFINISHED")
+		for _, entry := range addon.Status.History {
+			finished := "<none>"
+			if entry.FinishTime != nil {
+				finished = entry.FinishTime.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+				orNone(entry.FromVersion), entry.ToVersion, entry.Outcome,
+				entry.StartTime.UTC().Format(time.RFC3339), finished)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	resources, err := c.childResources(ctx, addon)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "\nChild Resources:")
+	w = tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  KIND\tNAMESPACE\tNAME\tSTATUS")
+	for _, r := range resources {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", r.kind, orDefault(r.namespace, "-"), r.name, r.status)
+	}
+	return w.Flush()
+}
+
+type childResource struct {
+	kind, namespace, name string
+	status                string
+}
+
+// Looks up the objects the operator creates for an Addon.
+func (c *cli) childResources(ctx context.Context, addon *addonsv1alpha1.Addon) ([]childResource, error) {
+	var resources []childResource
+	lookup := func(kind string, key client.ObjectKey, obj client.Object, status func() string) error {
+		r := childResource{kind: kind, namespace: key.Namespace, name: key.Name}
+		err := c.client.Get(ctx, key, obj)
+		switch {
+		case k8sApiErrors.IsNotFound(err) || meta.IsNoMatchError(err):
+			r.status = "<missing>"
+		case err != nil:
+			return fmt.Errorf("getting %s %s: %w", kind, key, err)
+		default:
+			r.status = status()
+		}
+		resources = append(resources, r)
+		return nil
+	}
+
+	for _, ns := range addonNamespaces(addon) {
+		namespace := &corev1.Namespace{}
+		if err := lookup("Namespace", client.ObjectKey{Name: ns}, namespace, func() string {
+			return string(namespace.Status.Phase)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if namespace := addoncontroller.GetCommonInstallOptions(addon).Namespace; len(namespace) > 0 {
+		catalogSource := &operatorsv1alpha1.CatalogSource{}
+		if err := lookup("CatalogSource", client.ObjectKey{
+			Name: addoncontroller.CatalogSourceName(addon), Namespace: namespace,
+		}, catalogSource, func() string {
+			if state := catalogSource.Status.GRPCConnectionState; state != nil {
+				return state.LastObservedState
+			}
+			return "<unknown>"
+		}); err != nil {
+			return nil, err
+		}
+
+		subscription := &operatorsv1alpha1.Subscription{}
+		if err := lookup("Subscription", client.ObjectKey{
+			Name: addoncontroller.SubscriptionName(addon), Namespace: namespace,
+		}, subscription, func() string {
+			return orDefault(string(subscription.Status.State), "<unknown>")
+		}); err != nil {
+			return nil, err
+		}
+
+		if ref := subscription.Status.InstallPlanRef; ref != nil {
+			installPlan := &operatorsv1alpha1.InstallPlan{}
+			if err := lookup("InstallPlan", client.ObjectKey{
+				Name: ref.Name, Namespace: ref.Namespace,
+			}, installPlan, func() string {
+				status := string(installPlan.Status.Phase)
+				if !installPlan.Spec.Approved {
+					status += " (not approved)"
+				}
+				return status
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if csvName := orDefault(subscription.Status.InstalledCSV, subscription.Status.CurrentCSV); len(csvName) > 0 {
+			csv := &operatorsv1alpha1.ClusterServiceVersion{}
+			if err := lookup("ClusterServiceVersion", client.ObjectKey{
+				Name: csvName, Namespace: namespace,
+			}, csv, func() string {
+				return string(csv.Status.Phase)
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		operator := &operatorsv1.Operator{}
+		if err := lookup("Operator", client.ObjectKey{
+			Name: addoncontroller.OperatorResourceName(addon),
+		}, operator, func() string {
+			return "<present>"
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ref := range addon.Status.ManifestObjects {
+		resources = append(resources, childResource{
+			kind: ref.Kind, namespace: ref.Namespace, name: ref.Name, status: "<applied>",
+		})
+	}
+
+	if namespace := addoncontroller.GetAddonInstallNamespace(addon); len(namespace) > 0 {
+		instance := &addonsv1alpha1.AddonInstance{}
+		if err := lookup("AddonInstance", client.ObjectKey{
+			Name: addonsv1alpha1.DefaultAddonInstanceName, Namespace: namespace,
+		}, instance, func() string {
+			return "Healthy=" + instanceHealth(instance)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// Pauses or resumes reconciliation of an Addon.
+func (c *cli) setPaused(ctx context.Context, name string, paused bool) error {
+	addon, err := c.getAddon(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	verb := "paused"
+	if !paused {
+		verb = "resumed"
+	}
+	if addon.Spec.Paused == paused {
+		fmt.Fprintf(c.out, "addon %s already %s\n", name, verb)
+		return nil
+	}
+
+	patch := client.MergeFrom(addon.DeepCopy())
+	addon.Spec.Paused = paused
+	if err := c.client.Patch(ctx, addon, patch); err != nil {
+		return fmt.Errorf("patching Addon: %w", err)
+	}
+	fmt.Fprintf(c.out, "addon %s %s\n", name, verb)
+	return nil
+}
+
+// Requests the deletion of an Addon.
+// The operator waits for the addon to acknowledge it via its AddonInstance,
+// until the ack timeout expires.
+func (c *cli) requestDeletion(ctx context.Context, name string, ackTimeout time.Duration) error {
+	if ackTimeout < 0 {
+		return c.usageError("--ack-timeout must not be negative")
+	}
+
+	addon, err := c.getAddon(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(addon.DeepCopy())
+	if addon.Annotations == nil {
+		addon.Annotations = map[string]string{}
+	}
+	addon.Annotations[addonsv1alpha1.DeleteAnnotationFlag] = "true"
+	if ackTimeout > 0 {
+		addon.Annotations[addonsv1alpha1.DeleteTimeoutDuration] = ackTimeout.String()
+	}
+	if err := c.client.Patch(ctx, addon, patch); err != nil {
+		return fmt.Errorf("patching Addon: %w", err)
+	}
+	fmt.Fprintf(c.out, "addon %s deletion requested\n", name)
+	return nil
+}
+
+// Approves the InstallPlan the Subscription of an Addon is waiting on.
+func (c *cli) approveInstallPlan(ctx context.Context, name string) error {
+	addon, err := c.getAddon(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	namespace := addoncontroller.GetCommonInstallOptions(addon).Namespace
+	if len(namespace) == 0 {
+		return fmt.Errorf("addon %s is not installed via OLM", name)
+	}
+
+	subscription := &operatorsv1alpha1.Subscription{}
+	if err := c.client.Get(ctx, client.ObjectKey{
+		Name: addoncontroller.SubscriptionName(addon), Namespace: namespace,
+	}, subscription); err != nil {
+		return fmt.Errorf("getting Subscription: %w", err)
+	}
+	ref := subscription.Status.InstallPlanRef
+	if ref == nil {
+		return fmt.Errorf("subscription %s/%s references no InstallPlan", namespace, subscription.Name)
+	}
+
+	installPlan := &operatorsv1alpha1.InstallPlan{}
+	if err := c.client.Get(ctx, client.ObjectKey{
+		Name: ref.Name, Namespace: ref.Namespace,
+	}, installPlan); err != nil {
+		return fmt.Errorf("getting InstallPlan: %w", err)
+	}
+	if installPlan.Spec.Approved {
+		fmt.Fprintf(c.out, "installplan %s/%s already approved\n", installPlan.Namespace, installPlan.Name)
+		return nil
+	}
+
+	patch := client.MergeFrom(installPlan.DeepCopy())
+	installPlan.Spec.Approved = true
+	if err := c.client.Patch(ctx, installPlan, patch); err != nil {
+		return fmt.Errorf("patching InstallPlan: %w", err)
+	}
+	fmt.Fprintf(c.out, "installplan %s/%s approved (%s)\n",
+		installPlan.Namespace, installPlan.Name, strings.Join(installPlan.Spec.ClusterServiceVersionNames, ", "))
+	return nil
+}
+
+// Prints the heartbeat reported by the addon via its AddonInstance.
+func (c *cli) heartbeatStatus(ctx context.Context, name string) error {
+	addon, err := c.getAddon(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	instance := &addonsv1alpha1.AddonInstance{}
+	key := client.ObjectKey{
+		Name:      addonsv1alpha1.DefaultAddonInstanceName,
+		Namespace: addoncontroller.GetAddonInstallNamespace(addon),
+	}
+	if err := c.client.Get(ctx, key, instance); err != nil {
+		return fmt.Errorf("getting AddonInstance: %w", err)
+	}
+
+	period := instance.Spec.HeartbeatUpdatePeriod.Duration
+	if period <= 0 {
+		period = addonsv1alpha1.DefaultAddonInstanceHeartbeatUpdatePeriod
+	}
+	threshold := heartbeatThresholdMultiplier * period
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "AddonInstance:\t%s\n", key)
+	fmt.Fprintf(w, "Update Period:\t%s\n", period)
+	if last := instance.Status.LastHeartbeatTime; last.IsZero() {
+		fmt.Fprintf(w, "Last Heartbeat:\t<none>\n")
+		fmt.Fprintf(w, "Status:\tno heartbeat received\n")
+	} else {
+		age := c.now().Sub(last.Time)
+		status := "fresh"
+		if age > threshold {
+			status = fmt.Sprintf("stale (older than %s)", threshold)
+		}
+		fmt.Fprintf(w, "Last Heartbeat:\t%s (%s ago)\n",
+			last.UTC().Format(time.RFC3339), duration.HumanDuration(age))
+		fmt.Fprintf(w, "Status:\t%s\n", status)
+	}
+	for _, condType := range []addonsv1alpha1.AddonInstanceCondition{
+		addonsv1alpha1.AddonInstanceConditionHealthy,
+		addonsv1alpha1.AddonInstanceConditionDegraded,
+	} {
+		if cond := meta.FindStatusCondition(instance.Status.Conditions, condType.String()); cond != nil {
+			fmt.Fprintf(w, "%s:\t%s\n", string(condType), formatCondition(*cond))
+		}
+	}
+	return w.Flush()
+}
+
+func (c *cli) getAddon(ctx context.Context, name string) (*addonsv1alpha1.Addon, error) {
+	addon := &addonsv1alpha1.Addon{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: name}, addon); err != nil {
+		return nil, fmt.Errorf("getting Addon: %w", err)
+	}
+	return addon, nil
+}
+
+func (c *cli) age(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(c.now().Sub(t.Time))
+}
+
+// Namespaces of an Addon, sorted and without duplicates.
+func addonNamespaces(addon *addonsv1alpha1.Addon) []string {
+	names := map[string]struct{}{}
+	for _, ns := range addon.Spec.Namespaces {
+		names[ns.Name] = struct{}{}
+	}
+	if ns := addoncontroller.GetAddonInstallNamespace(addon); len(ns) > 0 {
+		names[ns] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func instanceHealth(instance *addonsv1alpha1.AddonInstance) string {
+	if instance == nil {
+		return "<none>"
+	}
+	return conditionStatus(instance.Status.Conditions, addonsv1alpha1.AddonInstanceConditionHealthy.String())
+}
+
+func conditionStatus(conditions []metav1.Condition, conditionType string) string {
+	cond := meta.FindStatusCondition(conditions, conditionType)
+	if cond == nil {
+		return string(metav1.ConditionUnknown)
+	}
+	return string(cond.Status)
+}
+
+func formatCondition(cond metav1.Condition) string {
+	if len(cond.Message) == 0 {
+		return fmt.Sprintf("%s (%s)", cond.Status, cond.Reason)
+	}
+	return fmt.Sprintf("%s (%s): %s", cond.Status, cond.Reason, cond.Message)
+}
+
+func orNone(s string) string {
+	return orDefault(s, "<none>")
+}
+
+func orDefault(s, def string) string {
+	if len(s) == 0 {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testAddon() *addonsv1alpha1.Addon {
+	return &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-addon",
+			CreationTimestamp: metav1.NewTime(testNow.Add(-48 * time.Hour)),
+		},
+		Spec: addonsv1alpha1.AddonSpec{
+			Version:    "1.1.0",
+			Namespaces: []addonsv1alpha1.AddonNamespace{{Name: "test-ns"}},
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.OLMOwnNamespace,
+				OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{
+					AddonInstallOLMCommon: addonsv1alpha1.AddonInstallOLMCommon{
+						Namespace:   "test-ns",
+						PackageName: "test-package",
+					},
+				},
+			},
+		},
+		Status: addonsv1alpha1.AddonStatus{
+			Phase:           addonsv1alpha1.PhaseReady,
+			ObservedVersion: "1.0.0",
+			Conditions: []metav1.Condition{{
+				Type:               addonsv1alpha1.Available,
+				Status:             metav1.ConditionTrue,
+				Reason:             addonsv1alpha1.AddonReasonFullyReconciled,
+				LastTransitionTime: metav1.NewTime(testNow.Add(-time.Hour)),
+			}},
+			History: []addonsv1alpha1.AddonVersionTransition{{
+				ToVersion: "1.0.0",
+				StartTime: metav1.NewTime(testNow.Add(-47 * time.Hour)),
+				Outcome:   addonsv1alpha1.AddonVersionTransitionSucceeded,
+			}},
+			OCMReportedStatusHash: &addonsv1alpha1.OCMAddOnStatusHash{
+				StatusHash:         "abc123",
+				ObservedGeneration: 4,
+			},
+		},
+	}
+}
+
+func testAddonInstance(lastHeartbeat time.Time) *addonsv1alpha1.AddonInstance {
+	return &addonsv1alpha1.AddonInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      addonsv1alpha1.DefaultAddonInstanceName,
+			Namespace: "test-ns",
+		},
+		Spec: addonsv1alpha1.AddonInstanceSpec{
+			HeartbeatUpdatePeriod: metav1.Duration{Duration: 10 * time.Second},
+		},
+		Status: addonsv1alpha1.AddonInstanceStatus{
+			LastHeartbeatTime: metav1.NewTime(lastHeartbeat),
+			Conditions: []metav1.Condition{{
+				Type:   addonsv1alpha1.AddonInstanceConditionHealthy.String(),
+				Status: metav1.ConditionTrue,
+				Reason: "Running",
+			}},
+		},
+	}
+}
+
+func newTestCLI(t *testing.T, objs ...runtime.Object) (*cli, client.Client, *bytes.Buffer) {
+	t.Helper()
+
+	scheme, err := newScheme()
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+
+	out := &bytes.Buffer{}
+	return &cli{
+		client: c,
+		out:    out,
+		errOut: io.Discard,
+		now:    func() time.Time { return testNow },
+	}, c, out
+}
+
+func TestList(t *testing.T) {
+	other := testAddon()
+	other.Name = "another-addon"
+	other.Spec.Install.OLMOwnNamespace.Namespace = "other-ns"
+	other.Status.Conditions = nil
+
+	c, _, out := newTestCLI(t, testAddon(), other, testAddonInstance(testNow))
+	require.NoError(t, c.run(context.Background(), []string{"list"}))
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"NAME", "PHASE", "VERSION", "OBSERVED", "AVAILABLE", "HEALTH", "AGE"},
+		fields(lines[0]))
+	assert.Equal(t, []string{"another-addon", "Ready", "1.1.0", "1.0.0", "Unknown", "<none>", "2d"},
+		fields(lines[1]))
+	assert.Equal(t, []string{"test-addon", "Ready", "1.1.0", "1.0.0", "True", "True", "2d"},
+		fields(lines[2]))
+}
+
+func TestDescribe(t *testing.T) {
+	c, _, out := newTestCLI(t,
+		testAddon(),
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-ns"},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&operatorsv1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-test-addon", Namespace: "test-ns"},
+			Status: operatorsv1alpha1.SubscriptionStatus{
+				State:        operatorsv1alpha1.SubscriptionStateUpgradePending,
+				InstalledCSV: "test-package.v1.0.0",
+				InstallPlanRef: &corev1.ObjectReference{
+					Name: "install-abcde", Namespace: "test-ns",
+				},
+			},
+		},
+		&operatorsv1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: "install-abcde", Namespace: "test-ns"},
+			Status: operatorsv1alpha1.InstallPlanStatus{
+				Phase: operatorsv1alpha1.InstallPlanPhaseRequiresApproval,
+			},
+		},
+		testAddonInstance(testNow),
+	)
+	require.NoError(t, c.run(context.Background(), []string{"describe", "test-addon"}))
+
+	output := out.String()
+	assert.Contains(t, output, "OCM Reported Status Hash:  abc123 (generation 4)")
+	assert.Regexp(t, `Available\s+True\s+FullyReconciled\s+60m`, output)
+	assert.Regexp(t, `<none>\s+1\.0\.0\s+Succeeded`, output)
+	assert.Regexp(t, `Namespace\s+-\s+test-ns\s+Active`, output)
+	assert.Regexp(t, `CatalogSource\s+test-ns\s+addon-test-addon-catalog\s+<missing>`, output)
+	assert.Regexp(t, `Subscription\s+test-ns\s+addon-test-addon\s+UpgradePending`, output)
+	assert.Regexp(t, `InstallPlan\s+test-ns\s+install-abcde\s+RequiresApproval \(not approved\)`, output)
+	assert.Regexp(t, `ClusterServiceVersion\s+test-ns\s+test-package\.v1\.0\.0\s+<missing>`, output)
+	assert.Regexp(t, `AddonInstance\s+test-ns\s+addon-instance\s+Healthy=True`, output)
+}
+
+func TestPauseResume(t *testing.T) {
+	c, kube, out := newTestCLI(t, testAddon())
+	ctx := context.Background()
+
+	require.NoError(t, c.run(ctx, []string{"pause", "test-addon"}))
+	addon := &addonsv1alpha1.Addon{}
+	require.NoError(t, kube.Get(ctx, client.ObjectKey{Name: "test-addon"}, addon))
+	assert.True(t, addon.Spec.Paused)
+
+	require.NoError(t, c.run(ctx, []string{"pause", "test-addon"}))
+	require.NoError(t, c.run(ctx, []string{"resume", "test-addon"}))
+	require.NoError(t, kube.Get(ctx, client.ObjectKey{Name: "test-addon"}, addon))
+	assert.False(t, addon.Spec.Paused)
+
+	assert.Equal(t, "addon test-addon paused\n"+
+		"addon test-addon already paused\n"+
+		"addon test-addon resumed\n", out.String())
+}
+
+func TestDelete(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args        []string
+		annotations map[string]string
+	}{
+		"default ack timeout": {
+			args: []string{"delete", "test-addon"},
+			annotations: map[string]string{
+				addonsv1alpha1.DeleteAnnotationFlag: "true",
+			},
+		},
+		"ack timeout": {
+			args: []string{"delete", "test-addon", "--ack-timeout", "30m"},
+			annotations: map[string]string{
+				addonsv1alpha1.DeleteAnnotationFlag:  "true",
+				addonsv1alpha1.DeleteTimeoutDuration: "30m0s",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, kube, _ := newTestCLI(t, testAddon())
+			ctx := context.Background()
+			require.NoError(t, c.run(ctx, tc.args))
+
+			addon := &addonsv1alpha1.Addon{}
+			require.NoError(t, kube.Get(ctx, client.ObjectKey{Name: "test-addon"}, addon))
+			assert.Equal(t, tc.annotations, addon.Annotations)
+			assert.True(t, addon.DeletionTimestamp.IsZero())
+		})
+	}
+}
+
+func TestApproveInstallPlan(t *testing.T) {
+	c, kube, out := newTestCLI(t,
+		testAddon(),
+		&operatorsv1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-test-addon", Namespace: "test-ns"},
+			Status: operatorsv1alpha1.SubscriptionStatus{
+				InstallPlanRef: &corev1.ObjectReference{
+					Name: "install-abcde", Namespace: "test-ns",
+				},
+			},
+		},
+		&operatorsv1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: "install-abcde", Namespace: "test-ns"},
+			Spec: operatorsv1alpha1.InstallPlanSpec{
+				ClusterServiceVersionNames: []string{"test-package.v1.1.0"},
+				Approval:                   operatorsv1alpha1.ApprovalManual,
+			},
+			Status: operatorsv1alpha1.InstallPlanStatus{
+				Phase: operatorsv1alpha1.InstallPlanPhaseRequiresApproval,
+			},
+		},
+	)
+	ctx := context.Background()
+
+	require.NoError(t, c.run(ctx, []string{"approve-installplan", "test-addon"}))
+	installPlan := &operatorsv1alpha1.InstallPlan{}
+	require.NoError(t, kube.Get(ctx, client.ObjectKey{Name: "install-abcde", Namespace: "test-ns"}, installPlan))
+	assert.True(t, installPlan.Spec.Approved)
+
+	require.NoError(t, c.run(ctx, []string{"approve-installplan", "test-addon"}))
+	assert.Equal(t, "installplan test-ns/install-abcde approved (test-package.v1.1.0)\n"+
+		"installplan test-ns/install-abcde already approved\n", out.String())
+}
+
+func TestHeartbeatStatus(t *testing.T) {
+	for name, tc := range map[string]struct {
+		lastHeartbeat time.Time
+		expected      string
+	}{
+		"fresh": {
+			lastHeartbeat: testNow.Add(-5 * time.Second),
+			expected:      `Status:\s+fresh\n`,
+		},
+		"stale": {
+			lastHeartbeat: testNow.Add(-time.Minute),
+			expected:      `Status:\s+stale \(older than 30s\)\n`,
+		},
+		"missing": {
+			expected: `Last Heartbeat:\s+<none>\nStatus:\s+no heartbeat received\n`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, _, out := newTestCLI(t, testAddon(), testAddonInstance(tc.lastHeartbeat))
+			require.NoError(t, c.run(context.Background(), []string{"heartbeat", "status", "test-addon"}))
+			assert.Regexp(t, tc.expected, out.String())
+			assert.Regexp(t, `Healthy:\s+True \(Running\)\n`, out.String())
+		})
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no command":             nil,
+		"unknown command":        {"frobnicate"},
+		"missing addon":          {"describe"},
+		"too many addons":        {"pause", "a", "b"},
+		"list with arguments":    {"list", "a"},
+		"heartbeat without verb": {"heartbeat", "test-addon"},
+		"unknown flag":           {"delete", "test-addon", "--force"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, _, _ := newTestCLI(t, testAddon())
+			assert.ErrorIs(t, c.run(context.Background(), args), errUsage)
+		})
+	}
+}
+
+func fields(line []byte) []string {
+	var out []string
+	for _, f := range bytes.Fields(line) {
+		out = append(out, string(f))
+	}
+	return out
+}
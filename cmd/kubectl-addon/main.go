@@ -0,0 +1,108 @@
+// kubectl-addon is a kubectl plugin to inspect and operate Addons.
+//
+// Usage:
+//
+//	kubectl addon list
+//	kubectl addon describe <addon>
+//	kubectl addon pause <addon>
+//	kubectl addon resume <addon>
+//	kubectl addon delete <addon> [--ack-timeout <duration>]
+//	kubectl addon approve-installplan <addon>
+//	kubectl addon heartbeat status <addon>
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+const usage = `Inspect and operate Addons.
+
+Usage:
+  kubectl addon list
+  kubectl addon describe <addon>
+  kubectl addon pause <addon>
+  kubectl addon resume <addon>
+  kubectl addon delete <addon> [--ack-timeout <duration>]
+  kubectl addon approve-installplan <addon>
+  kubectl addon heartbeat status <addon>
+
+Flags:
+`
+
+// Returned for invalid arguments, after usage has been printed.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	var timeout time.Duration
+	flag.DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for requests to the cluster.")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := run(ctx, flag.Args())
+	if errors.Is(err, errUsage) {
+		cancel()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		cancel()
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+	scheme, err := newScheme()
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("creating client: %w", err)
+	}
+
+	return newCLI(c, os.Stdout).run(ctx, args)
+}
+
+func newScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		operatorsv1.AddToScheme,
+		operatorsv1alpha1.AddToScheme,
+		addonsv1alpha1.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			return nil, fmt.Errorf("building scheme: %w", err)
+		}
+	}
+	return scheme, nil
+}
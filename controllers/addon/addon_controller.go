@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/otel/attribute"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -20,10 +23,15 @@ import (
 	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,7 +41,9 @@ import (
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	internalhandler "github.com/openshift/addon-operator/controllers/addon/handler"
+	"github.com/openshift/addon-operator/internal/helm"
 	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/tracing"
 )
 
 const (
@@ -47,11 +57,14 @@ type subReconcilerOrder int
 
 const (
 	AddonDeletionReconcilerOrder subReconcilerOrder = iota * 100
+	AddonDependencyReconcilerOrder
 	NamespaceReconcilerOrder
 	PackageReconcilerOrder
 	AddonSecretPropagationReconcilerOrder
 	AddonInstanceReconcilerOrder
 	OLMReconcilerOrder
+	HelmReconcilerOrder
+	ManifestsReconcilerOrder
 	MonitoringFederationReconcilerOrder
 	MonitoringStackReconcilerOrder
 )
@@ -72,9 +85,19 @@ type AddonReconciler struct {
 	statusReportingEnabled     bool
 	upgradePolicyStatusEnabled bool
 	addonRequeueCh             chan event.GenericEvent
+	clock                      clock
+	// Optional, emits Events about the lifecycle of Addons.
+	eventRecorder events.EventRecorder
 
 	ocmClient    ocmClient
 	ocmClientMux sync.RWMutex
+	// Optional, reports are sent while reconciling when unset.
+	ocmOutbox ocmOutbox
+	// Set once OCM answered that it does not serve the bulk status endpoint.
+	ocmBulkStatusUnsupported atomic.Bool
+	// Hashes of Addon status reports dropped by the outbox, by Addon name.
+	ocmDroppedStatusReports sync.Map
+	statusDetails           statusDetailsCache
 
 	// List of Addon sub-reconcilers.
 	// Reconcilers will run  serially
@@ -142,6 +165,7 @@ func NewAddonReconciler(
 		operatorResourceHandler:    operatorResourceHandler,
 		statusReportingEnabled:     enableStatusReporting,
 		upgradePolicyStatusEnabled: enableUpgradePolicyStatus,
+		clock:                      defaultClock{},
 		subReconcilers: []addonReconciler{
 			// Step 1: Check if addon is being deleted.
 			&addonDeletionReconciler{
@@ -152,13 +176,18 @@ func NewAddonReconciler(
 				},
 				recorder: recorder,
 			},
-			// Step 2: Reconcile Namespace
+			// Step 2: Wait for the Addons this Addon depends on
+			&addonDependencyReconciler{
+				client:   client,
+				recorder: recorder,
+			},
+			// Step 3: Reconcile Namespace
 			&namespaceReconciler{
 				client:   client,
 				scheme:   scheme,
 				recorder: recorder,
 			},
-			// Step 3: Reconcile Addon pull secrets
+			// Step 4: Reconcile Addon pull secrets
 			&addonSecretPropagationReconciler{
 				cachedClient:           client,
 				uncachedClient:         uncachedClient,
@@ -166,21 +195,40 @@ func NewAddonReconciler(
 				addonOperatorNamespace: addonOperatorNamespace,
 				recorder:               recorder,
 			},
-			// Step 4: Reconcile AddonInstance object
+			// Step 5: Reconcile AddonInstance object
 			&addonInstanceReconciler{
 				client:   client,
 				scheme:   scheme,
 				recorder: recorder,
 			},
-			// Step 5: Reconcile OLM objects
+			// Step 6: Reconcile OLM objects
 			&olmReconciler{
 				client:                  client,
 				uncachedClient:          uncachedClient,
 				scheme:                  scheme,
 				operatorResourceHandler: operatorResourceHandler,
 				recorder:                recorder,
+				clock:                   defaultClock{},
+			},
+			// Step 7: Reconcile Helm chart objects
+			&helmReconciler{
+				client:                 client,
+				uncachedClient:         uncachedClient,
+				scheme:                 scheme,
+				renderer:               newOCIChartRenderer(),
+				addonOperatorNamespace: addonOperatorNamespace,
+				recorder:               recorder,
 			},
-			// Step 6: Reconcile Monitoring Federation
+			// Step 8: Reconcile manifest bundle objects
+			&manifestsReconciler{
+				client:                 client,
+				uncachedClient:         uncachedClient,
+				scheme:                 scheme,
+				loader:                 newManifestBundleLoader(client, addonOperatorNamespace),
+				addonOperatorNamespace: addonOperatorNamespace,
+				recorder:               recorder,
+			},
+			// Step 9: Reconcile Monitoring Federation
 			&monitoringFederationReconciler{
 				client:                 client,
 				uncachedClient:         uncachedClient,
@@ -191,6 +239,13 @@ func NewAddonReconciler(
 		},
 	}
 
+	for _, r := range adoReconciler.subReconcilers {
+		if olm, ok := r.(*olmReconciler); ok {
+			olm.upgradePolicyState = adoReconciler.lookupUpgradePolicyState
+			olm.defaultMaintenance = adoReconciler.lookupDefaultMaintenance
+		}
+	}
+
 	for _, opt := range opts {
 		opt.ApplyToAddonReconciler(adoReconciler)
 	}
@@ -215,6 +270,10 @@ type ocmClient interface {
 		ctx context.Context,
 		req ocm.AddOnStatusPostRequest,
 	) (res ocm.AddOnStatusResponse, err error)
+	PostAddOnStatusBulk(
+		ctx context.Context,
+		req ocm.AddOnStatusBulkPostRequest,
+	) (res ocm.AddOnStatusBulkResponse, err error)
 	PatchAddOnStatus(
 		ctx context.Context,
 		addonID string,
@@ -251,6 +310,16 @@ func (r *AddonReconciler) InjectOCMClient(ctx context.Context, c *ocm.Client) er
 	return nil
 }
 
+// Returns the OCM client, nil until it is injected.
+// The client is safe for concurrent use,
+// so requests are sent without holding the lock.
+func (r *AddonReconciler) getOCMClient() ocmClient {
+	r.ocmClientMux.RLock()
+	defer r.ocmClientMux.RUnlock()
+
+	return r.ocmClient
+}
+
 func (r *AddonReconciler) GetOCMClusterInfo() OcmClusterInfo {
 	r.ocmClientMux.RLock()
 	defer r.ocmClientMux.RUnlock()
@@ -265,6 +334,20 @@ func (r *AddonReconciler) GetOCMClusterInfo() OcmClusterInfo {
 	}
 }
 
+// Returns the maintenance configuration of the AddonOperator,
+// which applies to all Addons without their own.
+func (r *AddonReconciler) lookupDefaultMaintenance(ctx context.Context) (*addonsv1alpha1.AddonMaintenance, error) {
+	addonOperator := &addonsv1alpha1.AddonOperator{}
+	err := r.Get(ctx, client.ObjectKey{Name: addonsv1alpha1.DefaultAddonOperatorName}, addonOperator)
+	if k8sApiErrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting AddonOperator: %w", err)
+	}
+	return addonOperator.Spec.Maintenance, nil
+}
+
 // Pauses reconciliation of all Addon objects. Concurrency safe.
 func (r *AddonReconciler) EnableGlobalPause(ctx context.Context) error {
 	return r.setGlobalPause(ctx, true)
@@ -287,6 +370,18 @@ func (r *AddonReconciler) setGlobalPause(ctx context.Context, paused bool) error
 }
 
 // requeue all addons that are currently in the local cache.
+// Requeues the Addon with the given name without blocking the caller.
+func (r *AddonReconciler) requeueAddon(name string) {
+	if r.addonRequeueCh == nil {
+		return
+	}
+	go func() {
+		r.addonRequeueCh <- event.GenericEvent{
+			Object: &addonsv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		}
+	}()
+}
+
 func (r *AddonReconciler) requeueAllAddons(ctx context.Context) error {
 	addonList := &addonsv1alpha1.AddonList{}
 	if err := r.List(ctx, addonList); err != nil {
@@ -309,6 +404,18 @@ func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager, opts ...AddonReconc
 		return fmt.Errorf("operatorResourceHandler cannot be nil")
 	}
 
+	// Charts are rendered against the versions of Kubernetes
+	// and the APIs served by the cluster, like `helm install` does.
+	dc, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("creating discovery client: %w", err)
+	}
+	for _, sub := range r.subReconcilers {
+		if helmRec, ok := sub.(*helmReconciler); ok {
+			helmRec.capabilities = helm.NewCapabilitiesCache(dc, clusterCapabilitiesTTL)
+		}
+	}
+
 	r.addonRequeueCh = make(chan event.GenericEvent)
 	adoControllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&addonsv1alpha1.Addon{}).
@@ -318,6 +425,12 @@ func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager, opts ...AddonReconc
 		Owns(&operatorsv1alpha1.Subscription{}).
 		Owns(&addonsv1alpha1.AddonInstance{}).
 		Owns(&monitoringv1.ServiceMonitor{}).
+		// Workloads installed by the Helm and Manifests install types,
+		// to notice when they finished rolling out.
+		Owns(&appsv1.Deployment{}, builder.OnlyMetadata).
+		Owns(&appsv1.StatefulSet{}, builder.OnlyMetadata).
+		Owns(&appsv1.DaemonSet{}, builder.OnlyMetadata).
+		Owns(&batchv1.Job{}, builder.OnlyMetadata).
 		Watches(&corev1.Secret{},
 			handler.EnqueueRequestForOwner(
 				mgr.GetScheme(),
@@ -326,6 +439,8 @@ func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager, opts ...AddonReconc
 			),
 		).
 		Watches(&operatorsv1.Operator{}, r.operatorResourceHandler, builder.OnlyMetadata).
+		Watches(&addonsv1alpha1.Addon{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAddonDependencies)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.enqueueAddonsForManifestsConfigMap)).
 		WatchesRawSource(
 			source.Channel(
 				r.addonRequeueCh,
@@ -343,18 +458,34 @@ func (r *AddonReconciler) SetupWithManager(mgr ctrl.Manager, opts ...AddonReconc
 // AddonReconciler/Controller entrypoint
 func (r *AddonReconciler) Reconcile(
 	ctx context.Context, req ctrl.Request,
-) (ctrl.Result, error) {
+) (res ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "Addon.Reconcile", attribute.String("addon.name", req.Name))
+	defer func() { tracing.End(span, err) }()
+
 	//nolint:staticcheck
 	logger := r.Log.WithValues("addon", req.NamespacedName.String())
+	if traceID := tracing.TraceID(ctx); len(traceID) > 0 {
+		logger = logger.WithValues("traceID", traceID)
+	}
 	ctx = controllers.ContextWithLogger(ctx, logger)
 	reconErr := metrics.NewReconcileError("addon", r.Recorder, false)
 
 	addon := &addonsv1alpha1.Addon{}
 	if err := r.Get(ctx, req.NamespacedName, addon); err != nil {
+		if k8sApiErrors.IsNotFound(err) {
+			r.forgetAddon(req.Name)
+		}
 		reconErr.Report(controllers.ErrGetAddon, addon.Name)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	span.SetAttributes(attribute.String("addon.version", addon.Spec.Version))
 
+	defer func(start time.Time) {
+		r.recordReconcileMetrics(addon.Name, time.Since(start), res, err)
+	}(time.Now())
+
+	previousHistory := slices.Clone(addon.Status.History)
+	previousConditions := slices.Clone(addon.Status.Conditions)
 	reconcileResult, reconcileErr := r.reconcile(ctx, addon, logger)
 
 	if err := r.recordAddonMetrics(ctx, addon); err != nil {
@@ -372,6 +503,17 @@ func (r *AddonReconciler) Reconcile(
 		}
 	}
 
+	// Report exceeded upgrade deadlines and stale heartbeats even when nothing else changes.
+	for _, requeueAfter := range []time.Duration{
+		r.upgradePolicyDeadlineRequeueAfter(addon),
+		r.heartbeatStaleRequeueAfter(ctx, addon),
+	} {
+		if requeueAfter > 0 &&
+			(reconcileResult.RequeueAfter == 0 || requeueAfter < reconcileResult.RequeueAfter) {
+			reconcileResult.RequeueAfter = requeueAfter
+		}
+	}
+
 	// append reconcilerErr
 	multiErr = multierror.Append(multiErr, reconcileErr)
 
@@ -383,6 +525,8 @@ func (r *AddonReconciler) Reconcile(
 		multiErr = multierror.Append(multiErr, statusErr)
 		return reconcile.Result{}, multiErr
 	}
+	r.recordHistoryMetrics(addon, previousHistory)
+	r.recordLifecycleEvents(addon, previousConditions)
 	return reconcileResult, multiErr.ErrorOrNil()
 }
 
@@ -390,19 +534,40 @@ func (r *AddonReconciler) syncWithExternalAPIs(ctx context.Context, logger logr.
 	// We don't immeadiately return on errors, we append them to a multi-error object.
 	var multiErr *multierror.Error
 
+	upgradePolicyCtx, span := tracing.Start(ctx, "UpgradePolicyStatusReporter")
 	upgradePolicyErr := r.handleUpgradePolicyStatusReporting(
-		ctx, logger.WithName("UpgradePolicyStatusReporter"), addon,
+		upgradePolicyCtx, logger.WithName("UpgradePolicyStatusReporter"), addon,
 	)
+	tracing.End(span, upgradePolicyErr)
 	multiErr = multierror.Append(multiErr, upgradePolicyErr)
 
+	statusReportingCtx, span := tracing.Start(ctx, "AddonStatusReporter")
 	ocmStatusReportingErr := r.handleOCMAddOnStatusReporting(
-		ctx, logger.WithName("AddonStatusReporter"), addon,
+		statusReportingCtx, logger.WithName("AddonStatusReporter"), addon,
 	)
+	tracing.End(span, ocmStatusReportingErr)
 	multiErr = multierror.Append(multiErr, ocmStatusReportingErr)
 
 	return multiErr
 }
 
+// forgetAddon drops the metrics and caches kept for an Addon that is gone.
+func (r *AddonReconciler) forgetAddon(addonName string) {
+	if r.Recorder != nil {
+		r.Recorder.DeleteAddonMetrics(addonName)
+	}
+	r.statusDetails.forget(addonName)
+	r.ocmDroppedStatusReports.Delete(addonName)
+	for _, sub := range r.subReconcilers {
+		switch sub := sub.(type) {
+		case *helmReconciler:
+			sub.renderer.Forget(addonName)
+		case *manifestsReconciler:
+			sub.loader.Forget(addonName)
+		}
+	}
+}
+
 func (r *AddonReconciler) reconcile(ctx context.Context, addon *addonsv1alpha1.Addon,
 	log logr.Logger,
 ) (ctrl.Result, error) {
@@ -435,7 +600,9 @@ func (r *AddonReconciler) reconcile(ctx context.Context, addon *addonsv1alpha1.A
 
 	// Check if the addon is being upgraded
 	// by comparing spec.version and status.ObservedVersion.
-	if addonIsBeingUpgraded(addon) {
+	// Canary rollouts report the upgrade as started,
+	// once the Subscription is moved to the new catalog image.
+	if addonIsBeingUpgraded(addon) && !isCanaryUpgrade(addon) {
 		reportAddonUpgradeStarted(addon)
 		return ctrl.Result{}, nil
 	}
@@ -456,7 +623,13 @@ func (r *AddonReconciler) reconcile(ctx context.Context, addon *addonsv1alpha1.A
 
 	// Run each sub reconciler serially
 	for _, reconciler := range r.getOrderedSubReconcilers() {
-		subReconcilerRes, err := reconciler.Reconcile(ctx, addon)
+		start := time.Now()
+		subCtx, span := tracing.Start(ctx, reconciler.Name())
+		subReconcilerRes, err := reconciler.Reconcile(subCtx, addon)
+		tracing.End(span, err)
+		if r.Recorder != nil {
+			r.Recorder.RecordAddonSubReconcilerDuration(addon.Name, reconciler.Name(), time.Since(start))
+		}
 		switch {
 		case err != nil:
 			subReconErr.Report(err, addon.Name)
@@ -470,7 +643,20 @@ func (r *AddonReconciler) reconcile(ctx context.Context, addon *addonsv1alpha1.A
 		}
 	}
 
-	return r.setAddonCRStatus(ctx, addon)
+	result, err := r.setAddonCRStatus(ctx, addon)
+	if err != nil || !result.IsZero() {
+		return result, err
+	}
+	switch {
+	case canaryRolloutInProgress(addon):
+		// Bake time and health gates of canary rollouts are not watched.
+		result.RequeueAfter = defaultRetryAfterTime
+	case addon.Status.NextMaintenanceWindow != nil:
+		// Roll out deferred changes once the maintenance window opens.
+		result.RequeueAfter = maintenanceWindowRequeueAfter(
+			addon.Status.NextMaintenanceWindow.Time, r.clock.Now())
+	}
+	return result, nil
 }
 
 func (r *AddonReconciler) setAddonCRStatus(ctx context.Context, addon *addonsv1alpha1.Addon) (ctrl.Result, error) {
@@ -509,6 +695,14 @@ func (r *AddonReconciler) listUnschedulableAddonPods(
 		if addon.Spec.Install.OLMOwnNamespace != nil {
 			targetNs = addon.Spec.Install.OLMOwnNamespace.Namespace
 		}
+	case addonsv1alpha1.Helm:
+		if addon.Spec.Install.Helm != nil {
+			targetNs = addon.Spec.Install.Helm.Namespace
+		}
+	case addonsv1alpha1.Manifests:
+		if addon.Spec.Install.Manifests != nil {
+			targetNs = addon.Spec.Install.Manifests.Namespace
+		}
 	}
 
 	if len(strings.TrimSpace(targetNs)) == 0 {
@@ -537,6 +731,45 @@ func (r *AddonReconciler) listUnschedulableAddonPods(
 
 }
 
+// Observes transitions finished or Available by this reconcile,
+// once they are persisted in .status.history.
+func (r *AddonReconciler) recordHistoryMetrics(
+	addon *addonsv1alpha1.Addon, previousHistory []addonsv1alpha1.AddonVersionTransition) {
+	if r.Recorder == nil {
+		return
+	}
+
+	for _, entry := range newlyFinishedTransitions(previousHistory, addon.Status.History) {
+		r.Recorder.RecordAddonUpgradeDuration(
+			addon.Name, string(entry.Outcome), entry.FinishTime.Sub(entry.StartTime.Time))
+	}
+	for _, entry := range newlyAvailableTransitions(previousHistory, addon.Status.History) {
+		trigger := "upgrade"
+		if len(entry.FromVersion) == 0 {
+			trigger = "install"
+		}
+		r.Recorder.RecordAddonTimeToAvailable(
+			addon.Name, trigger, entry.AvailableTime.Sub(entry.StartTime.Time))
+	}
+}
+
+// Records the duration and requeues of a reconcile.
+func (r *AddonReconciler) recordReconcileMetrics(name string, d time.Duration, res ctrl.Result, err error) {
+	if r.Recorder == nil {
+		return
+	}
+
+	r.Recorder.RecordAddonReconcileDuration(name, d)
+	switch {
+	case err != nil:
+		r.Recorder.IncAddonRequeues(name, metrics.RequeueTypeError)
+	case res.RequeueAfter > 0:
+		r.Recorder.IncAddonRequeues(name, metrics.RequeueTypeDelayed)
+	case res.Requeue: //nolint:staticcheck
+		r.Recorder.IncAddonRequeues(name, metrics.RequeueTypeImmediate)
+	}
+}
+
 // Gathers addon data for metric collection
 func (r *AddonReconciler) recordAddonMetrics(
 	ctx context.Context,
@@ -135,10 +135,13 @@ func TestReconcileErrorHandling(t *testing.T) {
 		}
 
 		// Return the prepared addon.
-		client.On("Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		client.On("Get", mock.Anything, mock.Anything, mock.IsType(&addonsv1alpha1.Addon{}), mock.Anything).Run(func(args mock.Arguments) {
 			passedAddon := (args.Get(2)).(*addonsv1alpha1.Addon)
 			*passedAddon = *addon
 		}).Return(nil)
+		// Objects reported along with the addon status do not exist.
+		client.On("Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(testutil.NewTestErrNotFound())
 
 		client.On("List", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 
@@ -23,7 +23,7 @@ type addonInstanceDeletionHandler struct {
 
 func (a *addonInstanceDeletionHandler) NotifyAddon(ctx context.Context, addon *addonsv1alpha1.Addon) error {
 	currentAddonInstance := &addonsv1alpha1.AddonInstance{}
-	addonNS := GetCommonInstallOptions(addon).Namespace
+	addonNS := GetAddonInstallNamespace(addon)
 	if err := a.fetchAddonInstance(ctx, addonNS, currentAddonInstance); err != nil {
 		if errors.IsNotFound(err) {
 			// We return without errors on notfound errors, as the addon instance obj would get created
@@ -43,7 +43,7 @@ func (a *addonInstanceDeletionHandler) AckReceivedFromAddon(
 	ctx context.Context,
 	addon *addonsv1alpha1.Addon) (bool, error) {
 	currentAddonInstance := &addonsv1alpha1.AddonInstance{}
-	addonNS := GetCommonInstallOptions(addon).Namespace
+	addonNS := GetAddonInstallNamespace(addon)
 	if err := a.fetchAddonInstance(ctx, addonNS, currentAddonInstance); err != nil {
 		// We return without errors on notfound errors, as the addon instance obj would get created
 		// eventually by the subsequent sub-reconcilers and we would be requeued on that event.
@@ -27,6 +27,10 @@ type legacyDeletionHandler struct {
 }
 
 func (l *legacyDeletionHandler) NotifyAddon(ctx context.Context, addon *addonsv1alpha1.Addon) error {
+	// The legacy strategy relies on the CSV, which only exists for OLM installs.
+	if !isOLMInstall(addon) {
+		return nil
+	}
 	currentDeleteCM := &corev1.ConfigMap{}
 	addonTargetNS := GetCommonInstallOptions(addon).Namespace
 
@@ -52,9 +56,12 @@ func (l *legacyDeletionHandler) NotifyAddon(ctx context.Context, addon *addonsv1
 }
 
 func (l *legacyDeletionHandler) AckReceivedFromAddon(ctx context.Context, addon *addonsv1alpha1.Addon) (bool, error) {
+	if !isOLMInstall(addon) {
+		return false, nil
+	}
 	operatorKey := client.ObjectKey{
 		Namespace: "",
-		Name:      generateOperatorResourceName(addon),
+		Name:      OperatorResourceName(addon),
 	}
 
 	operator := &operatorsv1.Operator{}
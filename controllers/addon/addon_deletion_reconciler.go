@@ -4,8 +4,10 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	"github.com/openshift/addon-operator/controllers"
@@ -36,6 +38,8 @@ type addonDeletionReconciler struct {
 	clock    clock
 	handlers []addonDeletionHandler
 	recorder *metrics.Recorder
+	// Optional, emits Events when the addon acknowledged the deletion or the deletion timed out.
+	eventRecorder events.EventRecorder
 }
 
 func (r *addonDeletionReconciler) Reconcile(ctx context.Context, addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
@@ -68,6 +72,8 @@ func (r *addonDeletionReconciler) Reconcile(ctx context.Context, addon *addonsv1
 			return resultNil, err
 		}
 		if ackReceived {
+			recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeNormal,
+				eventReasonDeletionAckReceived, "Delete", "Addon acknowledged the deletion.")
 			removeDeleteTimeoutCondition(addon)
 			reportAddonReadyToBeDeletedStatus(addon, metav1.ConditionTrue)
 			return resultNil, nil
@@ -76,6 +82,9 @@ func (r *addonDeletionReconciler) Reconcile(ctx context.Context, addon *addonsv1
 
 	// If deletion has timed out.
 	if r.deletionTimedOut(addon) {
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeWarning,
+			eventReasonDeletionTimedOut, "Delete", "Addon did not acknowledge the deletion within %s.",
+			deleteTimeoutInterval(addon))
 		reportAddonDeletionTimedOut(addon)
 		return resultNil, nil
 	}
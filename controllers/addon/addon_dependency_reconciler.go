@@ -0,0 +1,135 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/dependency"
+	"github.com/openshift/addon-operator/internal/metrics"
+)
+
+const ADDON_DEPENDENCY_RECONCILER_NAME = "addonDependencyReconciler"
+
+// Blocks the installation of an Addon,
+// until all Addons it depends on are available.
+type addonDependencyReconciler struct {
+	client   client.Client
+	recorder *metrics.Recorder
+}
+
+func (r *addonDependencyReconciler) Reconcile(ctx context.Context,
+	addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
+	// Dependencies becoming unavailable later on do not affect installed Addons.
+	if len(addon.Spec.DependsOn) == 0 ||
+		meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed) {
+		return resultNil, nil
+	}
+	reconErr := metrics.NewReconcileError("addon", r.recorder, true)
+
+	var unmet []string
+	for _, dep := range addon.Spec.DependsOn {
+		dependency := &addonsv1alpha1.Addon{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: dep.Name}, dependency)
+		if k8sApiErrors.IsNotFound(err) {
+			unmet = append(unmet, fmt.Sprintf("%s is missing", dep.Name))
+			continue
+		}
+		if err != nil {
+			err = reconErr.Join(fmt.Errorf("getting Addon %s: %w", dep.Name, err), controllers.ErrGetAddon)
+			return resultNil, err
+		}
+		if reason := unmetDependencyReason(dep, dependency); len(reason) > 0 {
+			unmet = append(unmet, reason)
+		}
+	}
+
+	if len(unmet) > 0 {
+		// Requeued by the watch on the Addons it depends on.
+		reportWaitingForDependencies(addon, strings.Join(unmet, ", "))
+		return resultStop, nil
+	}
+	return resultNil, nil
+}
+
+func (r *addonDependencyReconciler) Name() string {
+	return ADDON_DEPENDENCY_RECONCILER_NAME
+}
+
+func (r *addonDependencyReconciler) Order() subReconcilerOrder {
+	return AddonDependencyReconcilerOrder
+}
+
+func unmetDependencyReason(dep addonsv1alpha1.AddonDependency, dependency *addonsv1alpha1.Addon) string {
+	if !dependency.IsAvailable() {
+		return fmt.Sprintf("%s is not available", dep.Name)
+	}
+	if len(dep.MinVersion) == 0 {
+		return ""
+	}
+
+	minVersion, err := semver.ParseTolerant(dep.MinVersion)
+	if err != nil {
+		return fmt.Sprintf("minimum version %q of %s is invalid", dep.MinVersion, dep.Name)
+	}
+	version, err := semver.ParseTolerant(dependency.Status.ObservedVersion)
+	if err != nil || version.LT(minVersion) {
+		return fmt.Sprintf("%s has version %q, requires at least %q",
+			dep.Name, dependency.Status.ObservedVersion, dep.MinVersion)
+	}
+	return ""
+}
+
+// Enqueues the Addons depending on the changed Addon,
+// to install them once it becomes available,
+// and the Addons it depends on, to delete them once it is gone.
+func (r *AddonReconciler) enqueueAddonDependencies(ctx context.Context, obj client.Object) []reconcile.Request {
+	addon, ok := obj.(*addonsv1alpha1.Addon)
+	if !ok {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dep := range addon.Spec.DependsOn {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Name: dep.Name},
+		})
+	}
+
+	addonList := &addonsv1alpha1.AddonList{}
+	if err := r.List(ctx, addonList); err != nil {
+		r.Log.Error(err, "listing Addons to enqueue dependents")
+		return requests
+	}
+	for _, dependent := range dependency.Dependents(addon.Name, addonList.Items) {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(dependent),
+		})
+	}
+	return requests
+}
+
+// Returns the names of Addons that depend on the given Addon
+// and have not been deleted yet.
+func (r *AddonReconciler) listDependentAddons(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) ([]string, error) {
+	addonList := &addonsv1alpha1.AddonList{}
+	if err := r.List(ctx, addonList); err != nil {
+		return nil, fmt.Errorf("listing Addons: %w", err)
+	}
+
+	var names []string
+	for _, dependent := range dependency.Dependents(addon.Name, addonList.Items) {
+		names = append(names, dependent.Name)
+	}
+	return names, nil
+}
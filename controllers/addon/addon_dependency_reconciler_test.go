@@ -0,0 +1,128 @@
+package addon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+func newTestDependencyAddon(name, observedVersion string, available bool) addonsv1alpha1.Addon {
+	addon := addonsv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	addon.Status.ObservedVersion = observedVersion
+	if available {
+		meta.SetStatusCondition(&addon.Status.Conditions, metav1.Condition{
+			Type:   addonsv1alpha1.Available,
+			Status: metav1.ConditionTrue,
+		})
+	}
+	return addon
+}
+
+func TestAddonDependencyReconciler(t *testing.T) {
+	for name, tc := range map[string]struct {
+		dependency *addonsv1alpha1.Addon
+		installed  bool
+		waiting    bool
+		message    string
+	}{
+		"available": {
+			dependency: ptr.To(newTestDependencyAddon("monitoring", "1.3.0", true)),
+		},
+		"missing": {
+			waiting: true,
+			message: "monitoring is missing",
+		},
+		"not available": {
+			dependency: ptr.To(newTestDependencyAddon("monitoring", "1.3.0", false)),
+			waiting:    true,
+			message:    "monitoring is not available",
+		},
+		"version too old": {
+			dependency: ptr.To(newTestDependencyAddon("monitoring", "1.1.9", true)),
+			waiting:    true,
+			message:    `monitoring has version "1.1.9", requires at least "1.2"`,
+		},
+		"already installed": {
+			installed: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			r := &addonDependencyReconciler{client: c}
+
+			addon := testutil.NewTestAddonWithCatalogSourceImage()
+			addon.Spec.DependsOn = []addonsv1alpha1.AddonDependency{
+				{Name: "monitoring", MinVersion: "1.2"},
+			}
+			if tc.installed {
+				reportInstalledCondition(addon)
+			}
+
+			getCall := c.On("Get", testutil.IsContext, client.ObjectKey{Name: "monitoring"},
+				mock.IsType(&addonsv1alpha1.Addon{}), mock.Anything)
+			if tc.dependency == nil {
+				getCall.Return(testutil.NewTestErrNotFound())
+			} else {
+				getCall.Run(func(args mock.Arguments) {
+					tc.dependency.DeepCopyInto(args.Get(2).(*addonsv1alpha1.Addon))
+				}).Return(nil)
+			}
+
+			result, err := r.Reconcile(context.Background(), addon)
+			require.NoError(t, err)
+			if tc.installed {
+				c.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if !tc.waiting {
+				assert.Equal(t, resultNil, result)
+				return
+			}
+
+			assert.Equal(t, resultStop, result)
+			assert.Equal(t, addonsv1alpha1.PhasePending, addon.Status.Phase)
+			cond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+			require.NotNil(t, cond)
+			assert.Equal(t, addonsv1alpha1.AddonReasonWaitingForDependencies, cond.Reason)
+			assert.Contains(t, cond.Message, tc.message)
+		})
+	}
+}
+
+func TestEnqueueAddonDependencies(t *testing.T) {
+	c := testutil.NewClient()
+	r := &AddonReconciler{Client: c, Log: testutil.NewLogger(t)}
+
+	c.On("List", testutil.IsContext, mock.IsType(&addonsv1alpha1.AddonList{}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			list := args.Get(1).(*addonsv1alpha1.AddonList)
+			list.Items = []addonsv1alpha1.Addon{{
+				ObjectMeta: metav1.ObjectMeta{Name: "tracing"},
+				Spec: addonsv1alpha1.AddonSpec{
+					DependsOn: []addonsv1alpha1.AddonDependency{{Name: "logging"}},
+				},
+			}}
+		}).
+		Return(nil)
+
+	logging := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+		Spec: addonsv1alpha1.AddonSpec{
+			DependsOn: []addonsv1alpha1.AddonDependency{{Name: "monitoring"}},
+		},
+	}
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: client.ObjectKey{Name: "monitoring"}},
+		{NamespacedName: client.ObjectKey{Name: "tracing"}},
+	}, r.enqueueAddonDependencies(context.Background(), logging))
+}
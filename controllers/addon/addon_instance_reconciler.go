@@ -47,16 +47,26 @@ func (r *addonInstanceReconciler) Order() subReconcilerOrder {
 func (r *addonInstanceReconciler) ensureAddonInstance(
 	ctx context.Context, addon *addonsv1alpha1.Addon) (err error) {
 	log := controllers.LoggerFromContext(ctx)
-	// not capturing "stop" because it won't ever be reached due to the guard rails of CRD Enum-Validation Markers
-	commonConfig, stop := parseAddonInstallConfig(log, addon)
-	if stop {
-		return fmt.Errorf("failed to create addonInstance due to misconfigured install.spec.type")
+	var namespace string
+	switch addon.Spec.Install.Type {
+	case addonsv1alpha1.Helm, addonsv1alpha1.Manifests:
+		namespace = GetAddonInstallNamespace(addon)
+		if len(namespace) == 0 {
+			return fmt.Errorf("failed to create addonInstance due to missing install namespace")
+		}
+	default:
+		// not capturing "stop" because it won't ever be reached due to the guard rails of CRD Enum-Validation Markers
+		commonConfig, stop := parseAddonInstallConfig(log, addon)
+		if stop {
+			return fmt.Errorf("failed to create addonInstance due to misconfigured install.spec.type")
+		}
+		namespace = commonConfig.Namespace
 	}
 
 	desiredAddonInstance := &addonsv1alpha1.AddonInstance{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      addonsv1alpha1.DefaultAddonInstanceName,
-			Namespace: commonConfig.Namespace,
+			Namespace: namespace,
 		},
 		// Can't skip specifying spec because in this case, the zero-value for metav1.Duration will be perceived beforehand i.e. 0s instead of CRD's default value of 10s
 		Spec: addonsv1alpha1.AddonInstanceSpec{
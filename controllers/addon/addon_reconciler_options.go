@@ -3,6 +3,7 @@ package addon
 import (
 	obov1alpha1 "github.com/rhobs/observability-operator/pkg/apis/monitoring/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	pkov1alpha1 "package-operator.run/apis/core/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +31,43 @@ func (w WithMonitoringStackReconciler) ApplyToControllerBuilder(b *builder.Build
 	b.Owns(&obov1alpha1.MonitoringStack{})
 }
 
+// WithPrometheusClient enables the PromQL health gate of canary rollouts.
+type WithPrometheusClient struct {
+	Client prometheusClient
+}
+
+func (w WithPrometheusClient) ApplyToAddonReconciler(config *AddonReconciler) {
+	for _, r := range config.subReconcilers {
+		if olm, ok := r.(*olmReconciler); ok {
+			olm.prometheusClient = w.Client
+		}
+	}
+}
+
+func (w WithPrometheusClient) ApplyToControllerBuilder(_ *builder.Builder) {}
+
+// WithEventRecorder enables Events about the lifecycle of Addons.
+type WithEventRecorder struct {
+	Recorder events.EventRecorder
+}
+
+func (w WithEventRecorder) ApplyToAddonReconciler(config *AddonReconciler) {
+	recorder := newRateLimitedEventRecorder(w.Recorder)
+	config.eventRecorder = recorder
+	for _, r := range config.subReconcilers {
+		switch sub := r.(type) {
+		case *olmReconciler:
+			sub.eventRecorder = recorder
+		case *addonSecretPropagationReconciler:
+			sub.eventRecorder = recorder
+		case *addonDeletionReconciler:
+			sub.eventRecorder = recorder
+		}
+	}
+}
+
+func (w WithEventRecorder) ApplyToControllerBuilder(_ *builder.Builder) {}
+
 type WithPackageOperatorReconciler struct {
 	Client client.Client
 	Scheme *runtime.Scheme
@@ -0,0 +1,167 @@
+package addon
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// Reasons of Events emitted for Addons.
+const (
+	eventReasonInstalled            = "Installed"
+	eventReasonUpgradeStarted       = "UpgradeStarted"
+	eventReasonUpgradeSucceeded     = "UpgradeSucceeded"
+	eventReasonCSVFailed            = "CSVFailed"
+	eventReasonCatalogSourceUnready = "CatalogSourceUnready"
+	eventReasonSecretSourceMissing  = "SecretPropagationSourceMissing"
+	eventReasonDeletionAckReceived  = "DeletionAckReceived"
+	eventReasonDeletionTimedOut     = "DeletionTimedOut"
+	eventReasonPaused               = "Paused"
+	eventReasonUnpaused             = "Unpaused"
+)
+
+const (
+	// Identical Events about the same object are emitted at most once per interval.
+	defaultEventRateLimitInterval = 10 * time.Minute
+	// Bounds the memory used to remember emitted Events.
+	maxRateLimitedEventRecorderEntries = 1000
+	// Events API limit for the note of an Event.
+	maxEventNoteLength = 1024
+)
+
+// Emits an Event regarding the Addon, if Events are enabled.
+func recordAddonEvent(
+	recorder events.EventRecorder, addon *addonsv1alpha1.Addon, related runtime.Object,
+	eventtype, reason, action, note string, args ...interface{},
+) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(addon, related, eventtype, reason, action, note, args...)
+}
+
+// Emits Events for lifecycle transitions visible in the conditions of the Addon,
+// by comparing them to the conditions before the reconcile.
+func (r *AddonReconciler) recordLifecycleEvents(
+	addon *addonsv1alpha1.Addon, previousConditions []metav1.Condition,
+) {
+	if r.eventRecorder == nil {
+		return
+	}
+
+	becameTrue := func(conditionType string) bool {
+		return meta.IsStatusConditionTrue(addon.Status.Conditions, conditionType) &&
+			!meta.IsStatusConditionTrue(previousConditions, conditionType)
+	}
+
+	if becameTrue(addonsv1alpha1.Installed) {
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeNormal,
+			eventReasonInstalled, "Install", "Installed version %q.", addon.Spec.Version)
+	}
+
+	if becameTrue(addonsv1alpha1.UpgradeStarted) {
+		fromVersion := addon.Status.ObservedVersion
+		if last := lastHistoryEntry(addon); last != nil && last.ToVersion == addon.Spec.Version {
+			fromVersion = last.FromVersion
+		}
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeNormal,
+			eventReasonUpgradeStarted, "Upgrade", "Started upgrade from version %q to %q.",
+			fromVersion, addon.Spec.Version)
+	}
+
+	if becameTrue(addonsv1alpha1.UpgradeSucceeded) {
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeNormal,
+			eventReasonUpgradeSucceeded, "Upgrade", "Upgrade to version %q succeeded.", addon.Spec.Version)
+	}
+
+	if becameTrue(addonsv1alpha1.Paused) {
+		note := "Paused reconciliation via .spec.paused."
+		cond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Paused)
+		if cond.Reason == addonsv1alpha1.AddonOperatorReasonPaused {
+			note = "Paused reconciliation of all Addons via the AddonOperator."
+		}
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeNormal,
+			eventReasonPaused, "Pause", note)
+	}
+
+	if meta.IsStatusConditionTrue(previousConditions, addonsv1alpha1.Paused) &&
+		!meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Paused) {
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeNormal,
+			eventReasonUnpaused, "Unpause", "Resumed reconciliation.")
+	}
+}
+
+// Drops Events identical to one emitted for the same object within the interval.
+// Conditions like a missing source Secret are reported on every reconcile,
+// which would otherwise emit an Event each time.
+type rateLimitedEventRecorder struct {
+	recorder events.EventRecorder
+	clock    clock
+	interval time.Duration
+
+	lock    sync.Mutex
+	emitted map[rateLimitedEventKey]time.Time
+}
+
+type rateLimitedEventKey struct {
+	uid               types.UID
+	eventtype, reason string
+	note              string
+}
+
+func newRateLimitedEventRecorder(recorder events.EventRecorder) *rateLimitedEventRecorder {
+	return &rateLimitedEventRecorder{
+		recorder: recorder,
+		clock:    defaultClock{},
+		interval: defaultEventRateLimitInterval,
+		emitted:  map[rateLimitedEventKey]time.Time{},
+	}
+}
+
+func (r *rateLimitedEventRecorder) Eventf(
+	regarding, related runtime.Object, eventtype, reason, action, note string, args ...interface{},
+) {
+	message := fmt.Sprintf(note, args...)
+	if len(message) > maxEventNoteLength {
+		message = message[:maxEventNoteLength]
+	}
+
+	key := rateLimitedEventKey{eventtype: eventtype, reason: reason, note: message}
+	if obj, err := meta.Accessor(regarding); err == nil {
+		key.uid = obj.GetUID()
+	}
+
+	if !r.allow(key) {
+		return
+	}
+	r.recorder.Eventf(regarding, related, eventtype, reason, action, "%s", message)
+}
+
+func (r *rateLimitedEventRecorder) allow(key rateLimitedEventKey) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.clock.Now()
+	if last, ok := r.emitted[key]; ok && now.Sub(last) < r.interval {
+		return false
+	}
+
+	if len(r.emitted) >= maxRateLimitedEventRecorderEntries {
+		for k, last := range r.emitted {
+			if now.Sub(last) >= r.interval {
+				delete(r.emitted, k)
+			}
+		}
+	}
+	r.emitted[key] = now
+	return true
+}
@@ -0,0 +1,82 @@
+package addon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func TestRecordLifecycleEvents(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	r := &AddonReconciler{eventRecorder: recorder}
+	addon := &addonsv1alpha1.Addon{
+		Spec: addonsv1alpha1.AddonSpec{Version: "1.0.0"},
+	}
+
+	previousConditions := addon.Status.Conditions
+	reportInstalledCondition(addon)
+	r.recordLifecycleEvents(addon, previousConditions)
+	// Unchanged conditions do not emit Events again.
+	r.recordLifecycleEvents(addon, addon.Status.Conditions)
+	reportObservedVersion(addon)
+
+	addon.Spec.Version = "2.0.0"
+	previousConditions = addon.Status.Conditions
+	reportAddonUpgradeStarted(addon)
+	r.recordLifecycleEvents(addon, previousConditions)
+
+	previousConditions = addon.Status.Conditions
+	reportAddonUpgradeSucceeded(addon)
+	r.recordLifecycleEvents(addon, previousConditions)
+
+	previousConditions = addon.Status.Conditions
+	reportAddonPauseStatus(addon, addonsv1alpha1.AddonOperatorReasonPaused)
+	r.recordLifecycleEvents(addon, previousConditions)
+
+	previousConditions = addon.Status.Conditions
+	r.removeAddonPauseCondition(addon)
+	r.recordLifecycleEvents(addon, previousConditions)
+
+	close(recorder.Events)
+	var recorded []string
+	for e := range recorder.Events {
+		recorded = append(recorded, e)
+	}
+	assert.Equal(t, []string{
+		`Normal Installed Installed version "1.0.0".`,
+		`Normal UpgradeStarted Started upgrade from version "1.0.0" to "2.0.0".`,
+		`Normal UpgradeSucceeded Upgrade to version "2.0.0" succeeded.`,
+		`Normal Paused Paused reconciliation of all Addons via the AddonOperator.`,
+		`Normal Unpaused Resumed reconciliation.`,
+	}, recorded)
+}
+
+func TestRateLimitedEventRecorder(t *testing.T) {
+	now := time.Now()
+	clock := &testClock{}
+	clock.On("Now").Return(now).Times(4)
+	clock.On("Now").Return(now.Add(defaultEventRateLimitInterval))
+
+	fakeRecorder := events.NewFakeRecorder(10)
+	recorder := newRateLimitedEventRecorder(fakeRecorder)
+	recorder.clock = clock
+
+	addon := &addonsv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{UID: "addon-1"}}
+	otherAddon := &addonsv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{UID: "addon-2"}}
+
+	recorder.Eventf(addon, nil, "Warning", "SecretPropagationSourceMissing", "PropagateSecret", "Secret %s is missing.", "a")
+	recorder.Eventf(addon, nil, "Warning", "SecretPropagationSourceMissing", "PropagateSecret", "Secret %s is missing.", "a")
+	assert.Len(t, fakeRecorder.Events, 1, "identical Event is dropped")
+
+	recorder.Eventf(addon, nil, "Warning", "SecretPropagationSourceMissing", "PropagateSecret", "Secret %s is missing.", "b")
+	recorder.Eventf(otherAddon, nil, "Warning", "SecretPropagationSourceMissing", "PropagateSecret", "Secret %s is missing.", "a")
+	assert.Len(t, fakeRecorder.Events, 3, "different note or object are emitted")
+
+	recorder.Eventf(addon, nil, "Warning", "SecretPropagationSourceMissing", "PropagateSecret", "Secret %s is missing.", "a")
+	assert.Len(t, fakeRecorder.Events, 4, "emitted again after the interval")
+}
@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 )
@@ -78,7 +79,7 @@ func (r *olmReconciler) deleteConfigMapPresent(ctx context.Context, addon *addon
 // handleInstallAck handles installation acknowledgement from
 // the addon instance if specified in the addon CR
 func (r *olmReconciler) handleInstallAck(ctx context.Context, addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
-	installed, err := r.isAddonInstanceInstalled(ctx, addon)
+	installed, err := isAddonInstanceInstalled(ctx, r.client, addon)
 	if err != nil {
 		return resultNil, err
 	}
@@ -92,14 +93,14 @@ func (r *olmReconciler) handleInstallAck(ctx context.Context, addon *addonsv1alp
 }
 
 // isAddonInstanceInstalled returns if the corresponding addon instance has installed=true status condition
-func (r *olmReconciler) isAddonInstanceInstalled(ctx context.Context, addon *addonsv1alpha1.Addon) (bool, error) {
+func isAddonInstanceInstalled(ctx context.Context, c client.Reader, addon *addonsv1alpha1.Addon) (bool, error) {
 	addonInstance := &addonsv1alpha1.AddonInstance{}
 	instanceKey := types.NamespacedName{
 		Name:      addonsv1alpha1.DefaultAddonInstanceName,
-		Namespace: GetCommonInstallOptions(addon).Namespace,
+		Namespace: GetAddonInstallNamespace(addon),
 	}
 
-	if err := r.client.Get(ctx, instanceKey, addonInstance); err != nil {
+	if err := c.Get(ctx, instanceKey, addonInstance); err != nil {
 		return false, err
 	}
 
@@ -0,0 +1,133 @@
+package addon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/helm"
+	"github.com/openshift/addon-operator/internal/oci"
+)
+
+// chartRenderer renders the Helm chart configured on an Addon into objects.
+type chartRenderer interface {
+	Render(
+		ctx context.Context,
+		addon *addonsv1alpha1.Addon,
+		dockerConfigJSON []byte,
+		caps *chartutil.Capabilities,
+	) ([]*unstructured.Unstructured, error)
+	// Forget drops the chart cached for the given Addon.
+	Forget(addonName string)
+}
+
+// ociChartRenderer pulls charts from OCI registries.
+// Charts are cached per Addon, so a chart is only pulled again
+// when the chart reference or version of the Addon changes.
+type ociChartRenderer struct {
+	newOCIClient func(opts ...oci.Option) *oci.Client
+
+	mux    sync.Mutex
+	charts map[string]cachedChart
+}
+
+// The chart archive is cached instead of the loaded chart,
+// because rendering modifies the chart while processing its dependencies.
+type cachedChart struct {
+	ref     string
+	archive []byte
+}
+
+func newOCIChartRenderer() *ociChartRenderer {
+	return &ociChartRenderer{
+		newOCIClient: oci.NewClient,
+		charts:       map[string]cachedChart{},
+	}
+}
+
+func (r *ociChartRenderer) Render(
+	ctx context.Context,
+	addon *addonsv1alpha1.Addon,
+	dockerConfigJSON []byte,
+	caps *chartutil.Capabilities,
+) ([]*unstructured.Unstructured, error) {
+	helmSpec := addon.Spec.Install.Helm
+
+	archive, err := r.pullChart(ctx, addon.Name, helmSpec, dockerConfigJSON)
+	if err != nil {
+		return nil, err
+	}
+	chart, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("loading chart %s: %w", helmSpec.Chart, err)
+	}
+
+	values := map[string]interface{}{}
+	if helmSpec.Values != nil && len(helmSpec.Values.Raw) > 0 {
+		if err := json.Unmarshal(helmSpec.Values.Raw, &values); err != nil {
+			return nil, fmt.Errorf("unmarshal .spec.install.helm.values: %w", err)
+		}
+	}
+
+	installed := meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed)
+	return helm.Render(chart, helm.Options{
+		Release: chartutil.ReleaseOptions{
+			Name:      addon.Name,
+			Namespace: helmSpec.Namespace,
+			Revision:  int(addon.Generation),
+			IsInstall: !installed,
+			IsUpgrade: installed,
+		},
+		Values:       values,
+		Capabilities: caps,
+	})
+}
+
+func (r *ociChartRenderer) Forget(addonName string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	delete(r.charts, addonName)
+}
+
+func (r *ociChartRenderer) pullChart(
+	ctx context.Context,
+	addonName string,
+	helmSpec *addonsv1alpha1.AddonInstallHelm,
+	dockerConfigJSON []byte,
+) ([]byte, error) {
+	// OCI tags can't contain "+", helm push replaces it
+	// in semver build metadata with "_".
+	ref, err := oci.ParseReference(
+		helmSpec.Chart + ":" + strings.ReplaceAll(helmSpec.ChartVersion, "+", "_"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing chart reference: %w", err)
+	}
+
+	r.mux.Lock()
+	cached, ok := r.charts[addonName]
+	r.mux.Unlock()
+	if ok && cached.ref == ref.String() {
+		return cached.archive, nil
+	}
+
+	ociClient := r.newOCIClient(oci.WithDockerConfigJSON(dockerConfigJSON))
+	archive, err := ociClient.PullLayer(ctx, ref, helm.ChartLayerMediaType)
+	if err != nil {
+		return nil, fmt.Errorf("pulling chart: %w", err)
+	}
+
+	r.mux.Lock()
+	r.charts[addonName] = cachedChart{ref: ref.String(), archive: archive}
+	r.mux.Unlock()
+	return archive, nil
+}
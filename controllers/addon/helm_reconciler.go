@@ -0,0 +1,175 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/metrics"
+)
+
+const (
+	HELM_RECONCILER_NAME = "helmReconciler"
+
+	// Field manager used to server-side apply objects rendered from Helm charts.
+	helmFieldManager = "addon-operator-helm"
+
+	// How long the discovered capabilities of the cluster are reused for rendering charts.
+	clusterCapabilitiesTTL = 10 * time.Minute
+)
+
+// clusterCapabilities returns the versions of Kubernetes and its APIs,
+// exposed to chart templates as .Capabilities.
+type clusterCapabilities interface {
+	Get() (*chartutil.Capabilities, error)
+}
+
+// helmReconciler installs Addons of install type Helm,
+// by applying all objects rendered from the chart with the Addon as controller.
+// Objects that disappear from the chart between versions are not pruned,
+// they are garbage collected together with the Addon.
+type helmReconciler struct {
+	client                 client.Client
+	uncachedClient         client.Client
+	scheme                 *runtime.Scheme
+	renderer               chartRenderer
+	capabilities           clusterCapabilities
+	addonOperatorNamespace string
+	recorder               *metrics.Recorder
+}
+
+func (r *helmReconciler) Reconcile(ctx context.Context,
+	addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
+	if addon.Spec.Install.Type != addonsv1alpha1.Helm {
+		// The install type might have changed, the chart is not needed anymore.
+		r.renderer.Forget(addon.Name)
+		addon.Status.HelmObjects = nil
+		return resultNil, nil
+	}
+	log := controllers.LoggerFromContext(ctx)
+	reconErr := metrics.NewReconcileError("addon", r.recorder, true)
+
+	helmSpec := addon.Spec.Install.Helm
+	if helmSpec == nil || len(helmSpec.Namespace) == 0 {
+		reportConfigurationError(addon,
+			".spec.install.helm.namespace is required when .spec.install.type = Helm")
+		return resultStop, nil
+	}
+
+	dockerConfigJSON, result, err := r.getPullSecret(ctx, addon)
+	if err != nil {
+		return resultNil, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	var caps *chartutil.Capabilities
+	if r.capabilities != nil {
+		if caps, err = r.capabilities.Get(); err != nil {
+			return resultNil, fmt.Errorf("discovering cluster capabilities: %w", err)
+		}
+	}
+
+	objs, err := r.renderer.Render(ctx, addon, dockerConfigJSON, caps)
+	if err != nil {
+		// Most likely the registry is unavailable or the chart is broken,
+		// both are not errors of the operator.
+		log.Error(err, "rendering helm chart")
+		reportHelmChartError(addon, err.Error())
+		return resultRequeueAfter(defaultRetryAfterTime), nil
+	}
+
+	if err := validateInstallObjects(objs, helmSpec.Namespace); err != nil {
+		reportHelmChartError(addon, fmt.Sprintf("unsupported objects: %s", err))
+		return resultStop, nil
+	}
+
+	applied := make([]client.Object, 0, len(objs))
+	for _, obj := range objs {
+		if err := applyInstallObject(
+			ctx, r.client, r.scheme, addon, obj, helmSpec.Namespace, helmFieldManager,
+		); err != nil {
+			err = reconErr.Join(err, controllers.ErrApplyHelmChartObjects)
+			return resultNil, err
+		}
+		applied = append(applied, obj)
+	}
+	addon.Status.HelmObjects = make([]addonsv1alpha1.AddonManifestObjectReference, len(applied))
+	for i, obj := range applied {
+		addon.Status.HelmObjects[i] = manifestObjectReference(obj)
+	}
+
+	unready, err := unreadyWorkloads(ctx, r.uncachedClient, applied)
+	if err != nil {
+		err = reconErr.Join(err, controllers.ErrObserveHelmChartWorkloads)
+		return resultNil, err
+	}
+	if len(unready) > 0 {
+		reportUnreadyHelmRelease(addon, unready)
+		// Workloads are owned by the Addon,
+		// their status updates requeue it.
+		return resultStop, nil
+	}
+
+	if addon.Spec.InstallAckRequired {
+		installed, err := isAddonInstanceInstalled(ctx, r.client, addon)
+		if err != nil {
+			return resultNil, err
+		}
+		if !installed {
+			return resultRequeue, nil
+		}
+	}
+
+	reportInstalledCondition(addon)
+	// All workloads of the current chart version rolled out,
+	// so a pending upgrade has concluded.
+	if addonUpgradeStarted(addon) {
+		reportAddonUpgradeSucceeded(addon)
+	}
+	return resultNil, nil
+}
+
+func (r *helmReconciler) Name() string {
+	return HELM_RECONCILER_NAME
+}
+
+func (r *helmReconciler) Order() subReconcilerOrder {
+	return HelmReconcilerOrder
+}
+
+// Looks up the pull secret configured for the chart registry
+// and returns its dockerconfigjson content.
+func (r *helmReconciler) getPullSecret(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) ([]byte, subReconcilerResult, error) {
+	pullSecretName := addon.Spec.Install.Helm.PullSecretName
+	if len(pullSecretName) == 0 {
+		return nil, resultNil, nil
+	}
+
+	secretKey := client.ObjectKey{
+		Name:      pullSecretName,
+		Namespace: r.addonOperatorNamespace,
+	}
+	dockerConfigJSON, err := getDockerConfigJSON(ctx, r.client, r.uncachedClient, secretKey)
+	switch {
+	case apiErrors.IsNotFound(err):
+		reportHelmChartError(addon, fmt.Sprintf("pull secret %s not found", secretKey))
+		return nil, resultRequeueAfter(defaultRetryAfterTime), nil
+	case errors.Is(err, errPullSecretNotDockerConfigJSON):
+		reportConfigurationError(addon, fmt.Sprintf("%s: %s", secretKey, err))
+		return nil, resultStop, nil
+	case err != nil:
+		return nil, resultNil, fmt.Errorf("getting helm chart pull secret: %w", err)
+	}
+	return dockerConfigJSON, resultNil, nil
+}
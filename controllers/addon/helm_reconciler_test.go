@@ -0,0 +1,347 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+type chartRendererMock struct {
+	mock.Mock
+}
+
+func (m *chartRendererMock) Render(
+	ctx context.Context,
+	addon *addonsv1alpha1.Addon,
+	dockerConfigJSON []byte,
+	caps *chartutil.Capabilities,
+) ([]*unstructured.Unstructured, error) {
+	args := m.Called(ctx, addon, dockerConfigJSON, caps)
+	objs, _ := args.Get(0).([]*unstructured.Unstructured)
+	return objs, args.Error(1)
+}
+
+func (m *chartRendererMock) Forget(addonName string) {
+	m.Called(addonName)
+}
+
+type clusterCapabilitiesMock struct {
+	mock.Mock
+}
+
+func (m *clusterCapabilitiesMock) Get() (*chartutil.Capabilities, error) {
+	args := m.Called()
+	caps, _ := args.Get(0).(*chartutil.Capabilities)
+	return caps, args.Error(1)
+}
+
+func newTestHelmAddon() *addonsv1alpha1.Addon {
+	return &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "addon-1",
+			UID:        "addon-1-uid",
+			Generation: 1,
+		},
+		Spec: addonsv1alpha1.AddonSpec{
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Helm,
+				Helm: &addonsv1alpha1.AddonInstallHelm{
+					Namespace:    "addon-1-ns",
+					Chart:        "oci://quay.io/osd-addons/charts/addon-1",
+					ChartVersion: "1.0.0",
+				},
+			},
+		},
+	}
+}
+
+func newTestHelmObjects() []*unstructured.Unstructured {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("config")
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetName("operator")
+	return []*unstructured.Unstructured{configMap, deployment}
+}
+
+func newTestHelmReconciler(c, uncachedC *testutil.Client, renderer chartRenderer) *helmReconciler {
+	return &helmReconciler{
+		client:                 c,
+		uncachedClient:         uncachedC,
+		scheme:                 testutil.NewTestSchemeWithAddonsv1alpha1(),
+		renderer:               renderer,
+		addonOperatorNamespace: "addon-operator",
+	}
+}
+
+func TestHelmReconciler_NotHelm(t *testing.T) {
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(testutil.NewClient(), testutil.NewClient(), renderer)
+
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	renderer.On("Forget", addon.Name).Return()
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	assert.Empty(t, addon.Status.Conditions)
+	renderer.AssertExpectations(t)
+}
+
+func TestHelmReconciler_Capabilities(t *testing.T) {
+	c := testutil.NewClient()
+	renderer := &chartRendererMock{}
+	capabilities := &clusterCapabilitiesMock{}
+	r := newTestHelmReconciler(c, testutil.NewClient(), renderer)
+	r.capabilities = capabilities
+	addon := newTestHelmAddon()
+
+	caps := &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{Version: "v1.32.4", Major: "1", Minor: "32"},
+		APIVersions: chartutil.VersionSet{"v1", "v1/ConfigMap"},
+	}
+	capabilities.On("Get").Return(caps, nil)
+	renderer.On("Render", mock.Anything, addon, []byte(nil), caps).
+		Return(newTestHelmObjects()[:1], nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	renderer.AssertExpectations(t)
+	assert.True(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed))
+}
+
+func TestHelmReconciler_UnsupportedObjects(t *testing.T) {
+	c := testutil.NewClient()
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(c, testutil.NewClient(), renderer)
+	addon := newTestHelmAddon()
+
+	binding := &unstructured.Unstructured{}
+	binding.SetAPIVersion("rbac.authorization.k8s.io/v1")
+	binding.SetKind("ClusterRoleBinding")
+	binding.SetName("cluster-admin-for-everyone")
+	renderer.On("Render", mock.Anything, addon, []byte(nil), (*chartutil.Capabilities)(nil)).
+		Return(append(newTestHelmObjects(), binding), nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultStop, result)
+	// Nothing is applied, when the chart contains unsupported objects.
+	c.AssertNotCalled(t, "Apply", mock.Anything, mock.Anything, mock.Anything)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, addonsv1alpha1.AddonReasonHelmChartError, available.Reason)
+	assert.Contains(t, available.Message, "ClusterRoleBinding")
+}
+
+func TestHelmReconciler_Installed(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(c, uncachedC, renderer)
+	addon := newTestHelmAddon()
+
+	renderer.On("Render", mock.Anything, addon, []byte(nil), (*chartutil.Capabilities)(nil)).
+		Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+	uncachedC.On("Get", testutil.IsContext,
+		client.ObjectKey{Name: "operator", Namespace: "addon-1-ns"},
+		mock.IsType(&appsv1.Deployment{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		deployment := args.Get(2).(*appsv1.Deployment)
+		deployment.Spec.Replicas = ptr.To(int32(1))
+		deployment.Status = appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
+		}
+	}).Return(nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	c.AssertNumberOfCalls(t, "Apply", 2)
+	uncachedC.AssertExpectations(t)
+	assert.True(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed))
+	assert.Equal(t, []addonsv1alpha1.AddonManifestObjectReference{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1-ns", Name: "config"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1-ns", Name: "operator"},
+	}, addon.Status.HelmObjects)
+}
+
+func TestHelmReconciler_UnreadyWorkloads(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(c, uncachedC, renderer)
+	addon := newTestHelmAddon()
+
+	renderer.On("Render", mock.Anything, addon, []byte(nil), (*chartutil.Capabilities)(nil)).
+		Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+	uncachedC.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&appsv1.Deployment{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		deployment := args.Get(2).(*appsv1.Deployment)
+		deployment.Status = appsv1.DeploymentStatus{Replicas: 1}
+	}).Return(nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultStop, result)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, metav1.ConditionFalse, available.Status)
+	assert.Equal(t, addonsv1alpha1.AddonReasonUnreadyHelmRelease, available.Reason)
+	assert.Contains(t, available.Message, "Deployment addon-1-ns/operator")
+	assert.False(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed))
+}
+
+func TestHelmReconciler_RenderError(t *testing.T) {
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(testutil.NewClient(), testutil.NewClient(), renderer)
+	addon := newTestHelmAddon()
+
+	renderer.On("Render", mock.Anything, addon, []byte(nil), (*chartutil.Capabilities)(nil)).
+		Return(nil, errors.New("registry unavailable"))
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultRequeueAfter(defaultRetryAfterTime), result)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, addonsv1alpha1.AddonReasonHelmChartError, available.Reason)
+	assert.Contains(t, available.Message, "registry unavailable")
+}
+
+func TestHelmReconciler_ApplyError(t *testing.T) {
+	c := testutil.NewClient()
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(c, testutil.NewClient(), renderer)
+	addon := newTestHelmAddon()
+
+	renderer.On("Render", mock.Anything, addon, []byte(nil), (*chartutil.Capabilities)(nil)).
+		Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(errors.New("conflict"))
+
+	_, err := r.Reconcile(context.Background(), addon)
+	require.Error(t, err)
+	c.AssertNumberOfCalls(t, "Apply", 1)
+}
+
+func TestHelmReconciler_PullSecret(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	renderer := &chartRendererMock{}
+	r := newTestHelmReconciler(c, uncachedC, renderer)
+	addon := newTestHelmAddon()
+	addon.Spec.Install.Helm.PullSecretName = "pull-secret"
+
+	dockerConfigJSON := []byte(`{"auths":{}}`)
+	secretKey := client.ObjectKey{Name: "pull-secret", Namespace: "addon-operator"}
+	c.On("Get", testutil.IsContext, secretKey, mock.IsType(&corev1.Secret{}), mock.Anything).
+		Return(testutil.NewTestErrNotFound())
+	uncachedC.On("Get", testutil.IsContext, secretKey, mock.IsType(&corev1.Secret{}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			secret := args.Get(2).(*corev1.Secret)
+			secret.Type = corev1.SecretTypeDockerConfigJson
+			secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON}
+		}).Return(nil)
+	renderer.On("Render", mock.Anything, addon, dockerConfigJSON, (*chartutil.Capabilities)(nil)).Return(nil, nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	renderer.AssertExpectations(t)
+	uncachedC.AssertExpectations(t)
+}
+
+func TestHelmReconciler_PullSecretWrongType(t *testing.T) {
+	c := testutil.NewClient()
+	r := newTestHelmReconciler(c, testutil.NewClient(), &chartRendererMock{})
+	addon := newTestHelmAddon()
+	addon.Spec.Install.Helm.PullSecretName = "pull-secret"
+
+	c.On("Get", testutil.IsContext, mock.Anything, mock.IsType(&corev1.Secret{}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			secret := args.Get(2).(*corev1.Secret)
+			secret.Type = corev1.SecretTypeOpaque
+		}).Return(nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultStop, result)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, addonsv1alpha1.AddonReasonConfigError, available.Reason)
+}
+
+func TestDeploymentReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment appsv1.Deployment
+		expected   bool
+	}{
+		{
+			name: "rolled out",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			expected: true,
+		},
+		{
+			name: "generation not observed",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1,
+				},
+			},
+		},
+		{
+			name: "old replicas pending termination",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+		},
+		{
+			name: "updated replicas unavailable",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, deploymentReady(&test.deployment))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package addon
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// Number of transitions kept in .status.history,
+// matches the MaxItems validation of the field.
+const historyLimit = 10
+
+// Returns the most recent transition, nil when the history is empty.
+func lastHistoryEntry(addon *addonsv1alpha1.Addon) *addonsv1alpha1.AddonVersionTransition {
+	if len(addon.Status.History) == 0 {
+		return nil
+	}
+	return &addon.Status.History[len(addon.Status.History)-1]
+}
+
+// Records the start of a transition to .spec.version.
+// A transition to another version still in progress is superseded.
+func startHistoryEntry(addon *addonsv1alpha1.Addon) {
+	last := lastHistoryEntry(addon)
+	if last != nil && last.Outcome == addonsv1alpha1.AddonVersionTransitionInProgress {
+		if last.ToVersion == addon.Spec.Version {
+			return
+		}
+		finishHistoryEntry(last, addonsv1alpha1.AddonVersionTransitionSuperseded)
+	}
+
+	fromVersion := addon.Status.ObservedVersion
+	if fromVersion == addon.Spec.Version {
+		// Canary rollouts start after the observed version moved on already.
+		fromVersion = ""
+		if last != nil && last.ToVersion != addon.Spec.Version {
+			fromVersion = last.ToVersion
+		}
+	}
+
+	appendHistoryEntry(addon, addonsv1alpha1.AddonVersionTransition{
+		FromVersion:     fromVersion,
+		ToVersion:       addon.Spec.Version,
+		UpgradePolicyID: upgradePolicyID(addon),
+		StartTime:       metav1.Now(),
+		Outcome:         addonsv1alpha1.AddonVersionTransitionInProgress,
+	})
+}
+
+// Finishes the transition to .spec.version still in progress, if any.
+func finishCurrentHistoryEntry(addon *addonsv1alpha1.Addon, outcome addonsv1alpha1.AddonVersionTransitionOutcome) {
+	last := lastHistoryEntry(addon)
+	if last == nil || last.Outcome != addonsv1alpha1.AddonVersionTransitionInProgress ||
+		last.ToVersion != addon.Spec.Version {
+		return
+	}
+	finishHistoryEntry(last, outcome)
+}
+
+func finishHistoryEntry(entry *addonsv1alpha1.AddonVersionTransition, outcome addonsv1alpha1.AddonVersionTransitionOutcome) {
+	now := metav1.Now()
+	entry.FinishTime = &now
+	entry.Outcome = outcome
+}
+
+// Records the initial install of the Addon,
+// started when the Addon was created.
+func recordInstallHistory(addon *addonsv1alpha1.Addon) {
+	if len(addon.Status.History) > 0 {
+		return
+	}
+
+	now := metav1.Now()
+	appendHistoryEntry(addon, addonsv1alpha1.AddonVersionTransition{
+		ToVersion:       addon.Spec.Version,
+		CSV:             addon.Status.LastObservedAvailableCSV,
+		UpgradePolicyID: upgradePolicyID(addon),
+		StartTime:       addon.CreationTimestamp,
+		FinishTime:      &now,
+		Outcome:         addonsv1alpha1.AddonVersionTransitionSucceeded,
+	})
+}
+
+// Remembers when the Addon first became Available after a successful transition to .spec.version.
+func reportHistoryAvailable(addon *addonsv1alpha1.Addon) {
+	last := lastHistoryEntry(addon)
+	if last == nil || last.ToVersion != addon.Spec.Version ||
+		last.Outcome != addonsv1alpha1.AddonVersionTransitionSucceeded || last.AvailableTime != nil {
+		return
+	}
+	now := metav1.Now()
+	last.AvailableTime = &now
+}
+
+// Remembers the CSV of the most recent transition to .spec.version.
+func reportHistoryCSV(addon *addonsv1alpha1.Addon, csvName string) {
+	last := lastHistoryEntry(addon)
+	if last == nil || last.ToVersion != addon.Spec.Version {
+		return
+	}
+	if last.Outcome == addonsv1alpha1.AddonVersionTransitionInProgress || len(last.CSV) == 0 {
+		last.CSV = csvName
+	}
+}
+
+func appendHistoryEntry(addon *addonsv1alpha1.Addon, entry addonsv1alpha1.AddonVersionTransition) {
+	addon.Status.History = append(addon.Status.History, entry)
+	if dropped := len(addon.Status.History) - historyLimit; dropped > 0 {
+		addon.Status.History = addon.Status.History[dropped:]
+	}
+}
+
+func upgradePolicyID(addon *addonsv1alpha1.Addon) string {
+	if addon.Spec.UpgradePolicy == nil {
+		return ""
+	}
+	return addon.Spec.UpgradePolicy.ID
+}
+
+// Returns transitions finished in newHistory, that were not yet finished in oldHistory.
+func newlyFinishedTransitions(
+	oldHistory, newHistory []addonsv1alpha1.AddonVersionTransition,
+) []addonsv1alpha1.AddonVersionTransition {
+	return newlySetTransitions(oldHistory, newHistory, func(entry addonsv1alpha1.AddonVersionTransition) bool {
+		return entry.FinishTime != nil
+	})
+}
+
+// Returns transitions that became Available in newHistory, but not yet in oldHistory.
+func newlyAvailableTransitions(
+	oldHistory, newHistory []addonsv1alpha1.AddonVersionTransition,
+) []addonsv1alpha1.AddonVersionTransition {
+	return newlySetTransitions(oldHistory, newHistory, func(entry addonsv1alpha1.AddonVersionTransition) bool {
+		return entry.AvailableTime != nil
+	})
+}
+
+func newlySetTransitions(
+	oldHistory, newHistory []addonsv1alpha1.AddonVersionTransition,
+	isSet func(addonsv1alpha1.AddonVersionTransition) bool,
+) []addonsv1alpha1.AddonVersionTransition {
+	set := map[historyKey]bool{}
+	for _, entry := range oldHistory {
+		if isSet(entry) {
+			set[historyEntryKey(entry)] = true
+		}
+	}
+
+	var transitions []addonsv1alpha1.AddonVersionTransition
+	for _, entry := range newHistory {
+		if isSet(entry) && !set[historyEntryKey(entry)] {
+			transitions = append(transitions, entry)
+		}
+	}
+	return transitions
+}
+
+type historyKey struct {
+	toVersion string
+	startTime int64
+}
+
+func historyEntryKey(entry addonsv1alpha1.AddonVersionTransition) historyKey {
+	return historyKey{toVersion: entry.ToVersion, startTime: entry.StartTime.Unix()}
+}
@@ -0,0 +1,115 @@
+package addon
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func TestHistory_Upgrade(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Spec: addonsv1alpha1.AddonSpec{
+			Version:       "1.0.0",
+			UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicy{ID: "policy-1"},
+		},
+	}
+
+	reportLastObservedAvailableCSV(addon, "ns/addon.v1.0.0")
+	reportInstalledCondition(addon)
+	reportInstalledCondition(addon)
+	require.Len(t, addon.Status.History, 1)
+	install := addon.Status.History[0]
+	assert.Equal(t, addonsv1alpha1.AddonVersionTransitionSucceeded, install.Outcome)
+	assert.Equal(t, addon.CreationTimestamp, install.StartTime)
+	assert.Equal(t, "ns/addon.v1.0.0", install.CSV)
+	assert.Equal(t, "policy-1", install.UpgradePolicyID)
+	reportObservedVersion(addon)
+
+	// Upgrade to 2.0.0 is superseded by 3.0.0.
+	addon.Spec.Version = "2.0.0"
+	reportAddonUpgradeStarted(addon)
+	reportObservedVersion(addon)
+	addon.Spec.Version = "3.0.0"
+	reportAddonUpgradeStarted(addon)
+	reportAddonUpgradeStarted(addon)
+	reportLastObservedAvailableCSV(addon, "ns/addon.v3.0.0")
+	reportAddonUpgradeSucceeded(addon)
+
+	require.Len(t, addon.Status.History, 3)
+	superseded, upgrade := addon.Status.History[1], addon.Status.History[2]
+	assert.Equal(t, "1.0.0", superseded.FromVersion)
+	assert.Equal(t, addonsv1alpha1.AddonVersionTransitionSuperseded, superseded.Outcome)
+	assert.NotNil(t, superseded.FinishTime)
+	assert.Equal(t, "2.0.0", upgrade.FromVersion)
+	assert.Equal(t, "3.0.0", upgrade.ToVersion)
+	assert.Equal(t, "ns/addon.v3.0.0", upgrade.CSV)
+	assert.Equal(t, addonsv1alpha1.AddonVersionTransitionSucceeded, upgrade.Outcome)
+	assert.NotNil(t, upgrade.FinishTime)
+
+	finished := newlyFinishedTransitions(addon.Status.History[:1], addon.Status.History)
+	assert.Len(t, finished, 2)
+}
+
+func TestHistory_RolledBack(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{
+		Spec:   addonsv1alpha1.AddonSpec{Version: "2.0.0"},
+		Status: addonsv1alpha1.AddonStatus{ObservedVersion: "1.0.0"},
+	}
+
+	reportAddonUpgradeStarted(addon)
+	reportAddonUpgradeRolledBack(addon, addonsv1alpha1.AddonReasonCSVFailed, "Rolled back.")
+
+	require.Len(t, addon.Status.History, 1)
+	assert.Equal(t, addonsv1alpha1.AddonVersionTransitionRolledBack, addon.Status.History[0].Outcome)
+	assert.NotNil(t, addon.Status.History[0].FinishTime)
+}
+
+func TestHistory_Limit(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{}
+	for i := range historyLimit + 2 {
+		addon.Spec.Version = fmt.Sprintf("1.0.%d", i)
+		reportAddonUpgradeStarted(addon)
+		reportAddonUpgradeSucceeded(addon)
+		reportObservedVersion(addon)
+	}
+
+	require.Len(t, addon.Status.History, historyLimit)
+	assert.Equal(t, "1.0.2", addon.Status.History[0].ToVersion)
+	assert.Equal(t, "1.0.1", addon.Status.History[0].FromVersion)
+}
+
+func TestHistory_Available(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{
+		Spec: addonsv1alpha1.AddonSpec{Version: "2.0.0"},
+	}
+
+	// Helm and manifest installs are recorded once they are Available.
+	reportReadinessStatus(addon)
+	require.Len(t, addon.Status.History, 1)
+	assert.NotNil(t, addon.Status.History[0].AvailableTime)
+
+	addon.Spec.Version = "3.0.0"
+	reportAddonUpgradeStarted(addon)
+	previousHistory := slices.Clone(addon.Status.History)
+	reportReadinessStatus(addon)
+	assert.Nil(t, addon.Status.History[1].AvailableTime, "not available before the upgrade succeeded")
+
+	reportAddonUpgradeSucceeded(addon)
+	reportReadinessStatus(addon)
+	require.Len(t, addon.Status.History, 2)
+	assert.NotNil(t, addon.Status.History[1].AvailableTime)
+
+	available := newlyAvailableTransitions(previousHistory, addon.Status.History)
+	require.Len(t, available, 1)
+	assert.Equal(t, "3.0.0", available[0].ToVersion)
+}
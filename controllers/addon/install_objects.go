@@ -0,0 +1,114 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+)
+
+var errPullSecretNotDockerConfigJSON = errors.New("pull secret is not of type " + string(corev1.SecretTypeDockerConfigJson))
+
+// Kinds that Helm charts and manifest bundles may contain.
+// The operator only holds permissions to manage these kinds, see deploy/15_clusterrole.yaml,
+// so Addons can't grant cluster wide access, e.g. via ClusterRoleBindings or webhooks.
+// All of them are namespaced, objects are confined to the install namespace.
+var allowedInstallObjectKinds = map[schema.GroupKind]struct{}{
+	{Group: "", Kind: "ConfigMap"}:                            {},
+	{Group: "", Kind: "Secret"}:                               {},
+	{Group: "", Kind: "Service"}:                              {},
+	{Group: "", Kind: "ServiceAccount"}:                       {},
+	{Group: "", Kind: "PersistentVolumeClaim"}:                {},
+	{Group: "apps", Kind: "Deployment"}:                       {},
+	{Group: "apps", Kind: "StatefulSet"}:                      {},
+	{Group: "apps", Kind: "DaemonSet"}:                        {},
+	{Group: "batch", Kind: "Job"}:                             {},
+	{Group: "batch", Kind: "CronJob"}:                         {},
+	{Group: "policy", Kind: "PodDisruptionBudget"}:            {},
+	{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}:   {},
+	{Group: "networking.k8s.io", Kind: "Ingress"}:             {},
+	{Group: "networking.k8s.io", Kind: "NetworkPolicy"}:       {},
+	{Group: "rbac.authorization.k8s.io", Kind: "Role"}:        {},
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}: {},
+	{Group: "monitoring.coreos.com", Kind: "ServiceMonitor"}:  {},
+	{Group: "monitoring.coreos.com", Kind: "PrometheusRule"}:  {},
+}
+
+// validateInstallObjects rejects objects of kinds outside of allowedInstallObjectKinds
+// and objects placed into another namespace than the install namespace.
+func validateInstallObjects(objs []*unstructured.Unstructured, namespace string) error {
+	var errs []error
+	for _, obj := range objs {
+		gk := obj.GroupVersionKind().GroupKind()
+		if _, ok := allowedInstallObjectKinds[gk]; !ok {
+			errs = append(errs, fmt.Errorf("%s %s: kind is not allowed", gk, obj.GetName()))
+			continue
+		}
+		if ns := obj.GetNamespace(); len(ns) > 0 && ns != namespace {
+			errs = append(errs, fmt.Errorf(
+				"%s %s: namespace %s is not the install namespace %s", gk, obj.GetName(), ns, namespace))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// getDockerConfigJSON returns the content of the given
+// kubernetes.io/dockerconfigjson pull secret.
+func getDockerConfigJSON(
+	ctx context.Context, c, uncachedC client.Reader, key client.ObjectKey,
+) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, key, secret)
+	if apiErrors.IsNotFound(err) {
+		// The secret might not be labeled for the cache to pick up.
+		err = uncachedC.Get(ctx, key, secret)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return nil, errPullSecretNotDockerConfigJSON
+	}
+	return secret.Data[corev1.DockerConfigJsonKey], nil
+}
+
+// applyInstallObject server-side applies an object that is part of the Addon installation,
+// placing namespaced objects without namespace into the given namespace.
+func applyInstallObject(
+	ctx context.Context, c client.Client, scheme *runtime.Scheme,
+	addon *addonsv1alpha1.Addon, obj *unstructured.Unstructured,
+	namespace, fieldManager string,
+) error {
+	namespaced, err := c.IsObjectNamespaced(obj)
+	if err != nil {
+		return fmt.Errorf("determining scope of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	if namespaced && len(obj.GetNamespace()) == 0 {
+		obj.SetNamespace(namespace)
+	}
+
+	controllers.AddCommonLabels(obj, addon)
+	controllers.AddCommonAnnotations(obj, addon)
+	if err := controllerutil.SetControllerReference(addon, obj, scheme); err != nil {
+		return fmt.Errorf("setting controller reference: %w", err)
+	}
+
+	if err := c.Apply(
+		ctx, client.ApplyConfigurationFromUnstructured(obj),
+		client.FieldOwner(fieldManager), client.ForceOwnership,
+	); err != nil {
+		return fmt.Errorf("applying %s %s: %w", obj.GetKind(), client.ObjectKeyFromObject(obj), err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package addon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidateInstallObjects(t *testing.T) {
+	newObj := func(apiVersion, kind, namespace string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName("test")
+		obj.SetNamespace(namespace)
+		return obj
+	}
+
+	for name, tc := range map[string]struct {
+		obj   *unstructured.Unstructured
+		valid bool
+	}{
+		"allowed kind":               {obj: newObj("apps/v1", "Deployment", ""), valid: true},
+		"install namespace":          {obj: newObj("v1", "Secret", "addon-1-ns"), valid: true},
+		"other namespace":            {obj: newObj("v1", "Secret", "kube-system")},
+		"cluster scoped":             {obj: newObj("rbac.authorization.k8s.io/v1", "ClusterRoleBinding", "")},
+		"webhook":                    {obj: newObj("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "")},
+		"kind of another group":      {obj: newObj("example.com/v1", "Deployment", "")},
+		"custom resource":            {obj: newObj("example.com/v1", "Widget", "addon-1-ns")},
+		"custom resource definition": {obj: newObj("apiextensions.k8s.io/v1", "CustomResourceDefinition", "")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateInstallObjects([]*unstructured.Unstructured{tc.obj}, "addon-1-ns")
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/maintenance"
+)
+
+// Lower bound of requeues waiting for the next maintenance window,
+// which may be due already when the reconcile took a while.
+const minMaintenanceWindowRequeueAfter = time.Second
+
+// Returns how long to wait for the next maintenance window to open.
+func maintenanceWindowRequeueAfter(nextOpen, now time.Time) time.Duration {
+	return max(nextOpen.Sub(now), minMaintenanceWindowRequeueAfter)
+}
+
+// Returns the maintenance configuration of the Addon,
+// falling back to the default of the AddonOperator.
+func (r *olmReconciler) addonMaintenance(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) (*addonsv1alpha1.AddonMaintenance, error) {
+	if addon.Spec.Maintenance != nil {
+		return addon.Spec.Maintenance, nil
+	}
+	if r.defaultMaintenance == nil {
+		return nil, nil
+	}
+	spec, err := r.defaultMaintenance(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting default maintenance configuration: %w", err)
+	}
+	return spec, nil
+}
+
+// Reports whether a maintenance window is open right now.
+// Otherwise, the change is reported as waiting for the next window,
+// which is returned, if any.
+func (r *olmReconciler) maintenanceWindowOpen(
+	addon *addonsv1alpha1.Addon, spec *addonsv1alpha1.AddonMaintenance, change string,
+) (bool, time.Time, error) {
+	schedule, err := maintenance.NewSchedule(*spec)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid maintenance configuration: %w", err)
+	}
+
+	now := r.clock.Now()
+	if schedule.Open(now) {
+		return true, time.Time{}, nil
+	}
+	nextOpen, _ := schedule.NextOpen(now)
+	reportWaitingForMaintenanceWindow(addon, change, nextOpen)
+	return false, nextOpen, nil
+}
+
+// Keeps the catalog image of the existing CatalogSource,
+// until a maintenance window opens.
+func (r *olmReconciler) deferCatalogSourceImageChange(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	catalogSource *operatorsv1alpha1.CatalogSource,
+) error {
+	spec, err := r.addonMaintenance(ctx, addon)
+	if err != nil || spec == nil {
+		return err
+	}
+
+	current := &operatorsv1alpha1.CatalogSource{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(catalogSource), current); err != nil {
+		// Initial installations are not deferred.
+		return client.IgnoreNotFound(err)
+	}
+	if current.Spec.Image == catalogSource.Spec.Image {
+		return nil
+	}
+
+	open, _, err := r.maintenanceWindowOpen(addon, spec, "catalog image change")
+	if err != nil || open {
+		return err
+	}
+	catalogSource.Spec.Image = current.Spec.Image
+	return nil
+}
+
+// Keeps the channel of the existing Subscription,
+// until a maintenance window opens.
+func (r *olmReconciler) deferSubscriptionChannelChange(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	subscription *operatorsv1alpha1.Subscription,
+) error {
+	spec, err := r.addonMaintenance(ctx, addon)
+	if err != nil || spec == nil {
+		return err
+	}
+
+	current := &operatorsv1alpha1.Subscription{}
+	if err := r.client.Get(ctx, client.ObjectKeyFromObject(subscription), current); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if current.Spec == nil || current.Spec.Channel == subscription.Spec.Channel {
+		return nil
+	}
+
+	open, _, err := r.maintenanceWindowOpen(addon, spec, "channel change")
+	if err != nil || open {
+		return err
+	}
+	subscription.Spec.Channel = current.Spec.Channel
+	return nil
+}
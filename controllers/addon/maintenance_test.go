@@ -0,0 +1,178 @@
+package addon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+// Sundays from 10:00 to 14:00 UTC.
+var testSundayMaintenance = &addonsv1alpha1.AddonMaintenance{
+	Windows: []addonsv1alpha1.AddonMaintenanceWindow{{
+		Schedule: "0 10 * * 0",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}},
+}
+
+func TestDeferCatalogSourceImageChange(t *testing.T) {
+	saturday := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		now           time.Time
+		addonSpec     *addonsv1alpha1.AddonMaintenance
+		defaultSpec   *addonsv1alpha1.AddonMaintenance
+		expectedImage string
+		waiting       bool
+	}{
+		"no maintenance": {
+			now:           saturday,
+			expectedImage: "quay.io/osd-addons/test:new",
+		},
+		"window closed": {
+			now:           saturday,
+			addonSpec:     testSundayMaintenance,
+			expectedImage: "quay.io/osd-addons/test:current",
+			waiting:       true,
+		},
+		"window of AddonOperator closed": {
+			now:           saturday,
+			defaultSpec:   testSundayMaintenance,
+			expectedImage: "quay.io/osd-addons/test:current",
+			waiting:       true,
+		},
+		"window open": {
+			now:           sunday,
+			addonSpec:     testSundayMaintenance,
+			expectedImage: "quay.io/osd-addons/test:new",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			r := newTestCanaryReconciler(c, testutil.NewClient(), tc.now)
+			r.defaultMaintenance = func(context.Context) (*addonsv1alpha1.AddonMaintenance, error) {
+				return tc.defaultSpec, nil
+			}
+			addon := testutil.NewTestAddonWithCatalogSourceImage()
+			addon.Spec.Maintenance = tc.addonSpec
+
+			c.On("Get", testutil.IsContext, mock.Anything,
+				mock.IsType(&operatorsv1alpha1.CatalogSource{}), mock.Anything,
+			).Run(func(args mock.Arguments) {
+				cs := args.Get(2).(*operatorsv1alpha1.CatalogSource)
+				cs.Spec.Image = "quay.io/osd-addons/test:current"
+			}).Return(nil)
+
+			catalogSource := newTestMainCatalogSource(addon)
+			catalogSource.Spec.Image = "quay.io/osd-addons/test:new"
+			require.NoError(t, r.deferCatalogSourceImageChange(context.Background(), addon, catalogSource))
+			assert.Equal(t, tc.expectedImage, catalogSource.Spec.Image)
+
+			cond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.WaitingForMaintenanceWindow)
+			if !tc.waiting {
+				assert.Nil(t, cond)
+				assert.Nil(t, addon.Status.NextMaintenanceWindow)
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, addonsv1alpha1.AddonReasonMaintenanceWindowClosed, cond.Reason)
+			assert.Contains(t, cond.Message, "catalog image change")
+			require.NotNil(t, addon.Status.NextMaintenanceWindow)
+			assert.True(t, time.Date(2024, time.March, 10, 10, 0, 0, 0, time.UTC).Equal(addon.Status.NextMaintenanceWindow.Time))
+		})
+	}
+}
+
+func TestDeferSubscriptionChannelChange(t *testing.T) {
+	c := testutil.NewClient()
+	r := newTestCanaryReconciler(c, testutil.NewClient(), time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC))
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	addon.Spec.Maintenance = testSundayMaintenance
+
+	c.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&operatorsv1alpha1.Subscription{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		sub := args.Get(2).(*operatorsv1alpha1.Subscription)
+		sub.Spec = &operatorsv1alpha1.SubscriptionSpec{Channel: "stable"}
+	}).Return(nil)
+
+	subscription := newTestPendingSubscription("", "")
+	subscription.Spec = &operatorsv1alpha1.SubscriptionSpec{Channel: "fast"}
+	require.NoError(t, r.deferSubscriptionChannelChange(context.Background(), addon, subscription))
+	assert.Equal(t, "stable", subscription.Spec.Channel)
+
+	// Both changes are reported, once.
+	reportWaitingForMaintenanceWindow(addon, "catalog image change", addon.Status.NextMaintenanceWindow.Time)
+	reportWaitingForMaintenanceWindow(addon, "channel change", addon.Status.NextMaintenanceWindow.Time)
+	assert.Equal(t, []string{"channel change", "catalog image change"}, addon.Status.DeferredChanges)
+	cond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.WaitingForMaintenanceWindow)
+	require.NotNil(t, cond)
+	assert.Equal(t, waitingForMaintenanceWindowMessage+"channel change, catalog image change.", cond.Message)
+
+	removeWaitingForMaintenanceWindow(addon)
+	assert.Empty(t, addon.Status.DeferredChanges)
+	assert.Nil(t, meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.WaitingForMaintenanceWindow))
+}
+
+func TestEnsureInstallPlanApproval_Maintenance(t *testing.T) {
+	for name, tc := range map[string]struct {
+		now      time.Time
+		approved bool
+		message  string
+	}{
+		"window closed": {
+			now:     time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC),
+			message: "Waiting for the maintenance window opening at 2024-03-10T10:00:00Z.",
+		},
+		"window open": {
+			now:      time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC),
+			approved: true,
+			message:  "Maintenance window is open.",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			r := newTestCanaryReconciler(c, testutil.NewClient(), tc.now)
+			r.eventRecorder = events.NewFakeRecorder(1)
+			addon := testutil.NewTestAddonWithCatalogSourceImage()
+			addon.Spec.Maintenance = testSundayMaintenance
+			mockPendingInstallPlan(c, "test.v1.1.0")
+
+			err := r.ensureInstallPlanApproval(context.Background(), addon,
+				newTestPendingSubscription("test.v1.0.0", "test.v1.1.0"))
+			require.NoError(t, err)
+
+			if tc.approved {
+				c.AssertCalled(t, "Update", testutil.IsContext,
+					mock.IsType(&operatorsv1alpha1.InstallPlan{}), mock.Anything)
+			} else {
+				c.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			}
+			require.Len(t, addon.Status.InstallPlanApprovals, 1)
+			assert.Equal(t, tc.approved, addon.Status.InstallPlanApprovals[0].Approved)
+			assert.Equal(t, tc.message, addon.Status.InstallPlanApprovals[0].Message)
+			assert.Equal(t, !tc.approved,
+				meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.WaitingForMaintenanceWindow))
+		})
+	}
+}
+
+func TestMaintenanceWindowRequeueAfter(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Hour, maintenanceWindowRequeueAfter(now.Add(time.Hour), now))
+	// Due already, still requeued.
+	assert.Equal(t, time.Second, maintenanceWindowRequeueAfter(now, now))
+	assert.Equal(t, time.Second, maintenanceWindowRequeueAfter(now.Add(-time.Minute), now))
+}
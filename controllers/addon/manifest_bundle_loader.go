@@ -0,0 +1,158 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/manifests"
+	"github.com/openshift/addon-operator/internal/oci"
+)
+
+// manifestBundleLoader loads the manifest bundle configured on an Addon.
+type manifestBundleLoader interface {
+	Load(
+		ctx context.Context,
+		addon *addonsv1alpha1.Addon,
+		dockerConfigJSON []byte,
+	) ([]*unstructured.Unstructured, error)
+	// Forget drops the image cached for the given Addon.
+	Forget(addonName string)
+}
+
+// defaultManifestBundleLoader reads bundles from ConfigMaps
+// in the addon operator namespace or pulls them from images.
+// Images are cached per Addon, so an image is only pulled again
+// when the image reference of the Addon resolves to another digest.
+type defaultManifestBundleLoader struct {
+	client                 client.Reader
+	addonOperatorNamespace string
+	newOCIClient           func(opts ...oci.Option) *oci.Client
+
+	mux    sync.Mutex
+	images map[string]cachedManifestImage
+}
+
+type cachedManifestImage struct {
+	digest string
+	objs   []*unstructured.Unstructured
+}
+
+func newManifestBundleLoader(c client.Reader, addonOperatorNamespace string) *defaultManifestBundleLoader {
+	return &defaultManifestBundleLoader{
+		client:                 c,
+		addonOperatorNamespace: addonOperatorNamespace,
+		newOCIClient:           oci.NewClient,
+		images:                 map[string]cachedManifestImage{},
+	}
+}
+
+func (l *defaultManifestBundleLoader) Load(
+	ctx context.Context,
+	addon *addonsv1alpha1.Addon,
+	dockerConfigJSON []byte,
+) ([]*unstructured.Unstructured, error) {
+	manifestsSpec := addon.Spec.Install.Manifests
+
+	var (
+		objs []*unstructured.Unstructured
+		err  error
+	)
+	if len(manifestsSpec.ConfigMapName) > 0 {
+		objs, err = l.loadConfigMap(ctx, manifestsSpec.ConfigMapName)
+	} else {
+		objs, err = l.loadImage(ctx, addon.Name, manifestsSpec.Image, dockerConfigJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers modify the returned objects while applying them.
+	copied := make([]*unstructured.Unstructured, len(objs))
+	for i := range objs {
+		copied[i] = objs[i].DeepCopy()
+	}
+	manifests.SortByInstallOrder(copied)
+	return copied, nil
+}
+
+func (l *defaultManifestBundleLoader) Forget(addonName string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	delete(l.images, addonName)
+}
+
+func (l *defaultManifestBundleLoader) loadConfigMap(
+	ctx context.Context, name string,
+) ([]*unstructured.Unstructured, error) {
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Name: name, Namespace: l.addonOperatorNamespace}
+	if err := l.client.Get(ctx, key, configMap); err != nil {
+		return nil, fmt.Errorf("getting ConfigMap %s: %w", key, err)
+	}
+
+	keys := make([]string, 0, len(configMap.Data))
+	for k := range configMap.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var objs []*unstructured.Unstructured
+	for _, k := range keys {
+		decoded, err := manifests.Decode([]byte(configMap.Data[k]))
+		if err != nil {
+			return nil, fmt.Errorf("decoding key %s of ConfigMap %s: %w", k, key, err)
+		}
+		objs = append(objs, decoded...)
+	}
+	return objs, nil
+}
+
+func (l *defaultManifestBundleLoader) loadImage(
+	ctx context.Context, addonName, image string, dockerConfigJSON []byte,
+) ([]*unstructured.Unstructured, error) {
+	ref, err := oci.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("parsing image reference: %w", err)
+	}
+
+	ociClient := l.newOCIClient(oci.WithDockerConfigJSON(dockerConfigJSON))
+	// Tags are mutable, the image is pulled by the digest they currently
+	// point to, so a moved tag is noticed and pulled again.
+	if !strings.Contains(ref.Tag, ":") {
+		digest, err := ociClient.Resolve(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		ref.Tag = digest
+	}
+
+	l.mux.Lock()
+	cached, ok := l.images[addonName]
+	l.mux.Unlock()
+	if ok && cached.digest == ref.Tag {
+		return cached.objs, nil
+	}
+
+	layers, err := ociClient.PullLayers(ctx, ref, manifests.ImageLayerMediaTypes...)
+	if err != nil {
+		return nil, fmt.Errorf("pulling image: %w", err)
+	}
+	objs, err := manifests.LoadImageLayers(layers)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifests from %s: %w", ref, err)
+	}
+
+	l.mux.Lock()
+	l.images[addonName] = cachedManifestImage{digest: ref.Tag, objs: objs}
+	l.mux.Unlock()
+	return objs, nil
+}
@@ -0,0 +1,280 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/metrics"
+)
+
+const (
+	MANIFESTS_RECONCILER_NAME = "manifestsReconciler"
+
+	// Field manager used to server-side apply objects from manifest bundles.
+	manifestsFieldManager = "addon-operator-manifests"
+)
+
+// manifestsReconciler installs Addons of install type Manifests,
+// by applying all objects of the bundle with the Addon as controller.
+// Objects removed from the bundle are pruned, as long as they still
+// carry the common labels of the Addon.
+type manifestsReconciler struct {
+	client                 client.Client
+	uncachedClient         client.Client
+	scheme                 *runtime.Scheme
+	loader                 manifestBundleLoader
+	addonOperatorNamespace string
+	recorder               *metrics.Recorder
+}
+
+func (r *manifestsReconciler) Reconcile(ctx context.Context,
+	addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
+	if addon.Spec.Install.Type != addonsv1alpha1.Manifests {
+		// The install type might have changed, the image is not needed anymore.
+		r.loader.Forget(addon.Name)
+		return resultNil, nil
+	}
+	log := controllers.LoggerFromContext(ctx)
+	reconErr := metrics.NewReconcileError("addon", r.recorder, true)
+
+	manifestsSpec := addon.Spec.Install.Manifests
+	if manifestsSpec == nil || len(manifestsSpec.Namespace) == 0 {
+		reportConfigurationError(addon,
+			".spec.install.manifests.namespace is required when .spec.install.type = Manifests")
+		return resultStop, nil
+	}
+	if (len(manifestsSpec.ConfigMapName) == 0) == (len(manifestsSpec.Image) == 0) {
+		reportConfigurationError(addon,
+			"exactly one of .spec.install.manifests.configMapName and .spec.install.manifests.image is required")
+		return resultStop, nil
+	}
+
+	dockerConfigJSON, result, err := r.getPullSecret(ctx, addon)
+	if err != nil {
+		return resultNil, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	objs, err := r.loader.Load(ctx, addon, dockerConfigJSON)
+	if err != nil {
+		// The ConfigMap might not be created yet or the registry is unavailable,
+		// both are not errors of the operator.
+		log.Error(err, "loading manifest bundle")
+		reportManifestBundleError(addon, err.Error())
+		return resultRequeueAfter(defaultRetryAfterTime), nil
+	}
+
+	if err := validateInstallObjects(objs, manifestsSpec.Namespace); err != nil {
+		reportManifestBundleError(addon, fmt.Sprintf("unsupported objects: %s", err))
+		return resultStop, nil
+	}
+
+	applied := make([]client.Object, 0, len(objs))
+	for _, obj := range objs {
+		if err := applyInstallObject(
+			ctx, r.client, r.scheme, addon, obj, manifestsSpec.Namespace, manifestsFieldManager,
+		); err != nil {
+			err = reconErr.Join(err, controllers.ErrApplyManifests)
+			return resultNil, err
+		}
+		applied = append(applied, obj)
+	}
+
+	desired := make([]addonsv1alpha1.AddonManifestObjectReference, len(applied))
+	for i, obj := range applied {
+		desired[i] = manifestObjectReference(obj)
+	}
+	remaining, err := r.prune(ctx, addon, desired)
+	// Objects that failed to be pruned stay tracked, to retry on the next reconcile.
+	addon.Status.ManifestObjects = append(desired, remaining...)
+	if err != nil {
+		err = reconErr.Join(err, controllers.ErrPruneManifests)
+		return resultNil, err
+	}
+
+	unready, err := unreadyWorkloads(ctx, r.uncachedClient, applied)
+	if err != nil {
+		err = reconErr.Join(err, controllers.ErrObserveManifestsWorkloads)
+		return resultNil, err
+	}
+	if len(unready) > 0 {
+		reportUnreadyManifests(addon, unready)
+		// Workloads are owned by the Addon,
+		// their status updates requeue it.
+		return resultStop, nil
+	}
+
+	if addon.Spec.InstallAckRequired {
+		installed, err := isAddonInstanceInstalled(ctx, r.client, addon)
+		if err != nil {
+			return resultNil, err
+		}
+		if !installed {
+			return resultRequeue, nil
+		}
+	}
+
+	reportInstalledCondition(addon)
+	if addonUpgradeStarted(addon) {
+		reportAddonUpgradeSucceeded(addon)
+	}
+	return resultNil, nil
+}
+
+func (r *manifestsReconciler) Name() string {
+	return MANIFESTS_RECONCILER_NAME
+}
+
+func (r *manifestsReconciler) Order() subReconcilerOrder {
+	return ManifestsReconcilerOrder
+}
+
+// Looks up the pull secret configured for the image registry
+// and returns its dockerconfigjson content.
+func (r *manifestsReconciler) getPullSecret(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) ([]byte, subReconcilerResult, error) {
+	manifestsSpec := addon.Spec.Install.Manifests
+	if len(manifestsSpec.Image) == 0 || len(manifestsSpec.PullSecretName) == 0 {
+		return nil, resultNil, nil
+	}
+
+	secretKey := client.ObjectKey{
+		Name:      manifestsSpec.PullSecretName,
+		Namespace: r.addonOperatorNamespace,
+	}
+	dockerConfigJSON, err := getDockerConfigJSON(ctx, r.client, r.uncachedClient, secretKey)
+	switch {
+	case apiErrors.IsNotFound(err):
+		reportManifestBundleError(addon, fmt.Sprintf("pull secret %s not found", secretKey))
+		return nil, resultRequeueAfter(defaultRetryAfterTime), nil
+	case errors.Is(err, errPullSecretNotDockerConfigJSON):
+		reportConfigurationError(addon, fmt.Sprintf("%s: %s", secretKey, err))
+		return nil, resultStop, nil
+	case err != nil:
+		return nil, resultNil, fmt.Errorf("getting manifests image pull secret: %w", err)
+	}
+	return dockerConfigJSON, resultNil, nil
+}
+
+// prune deletes objects that were applied by a previous reconcile,
+// but are no longer part of the bundle.
+// Returns references of objects that could not be deleted.
+func (r *manifestsReconciler) prune(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	desired []addonsv1alpha1.AddonManifestObjectReference,
+) ([]addonsv1alpha1.AddonManifestObjectReference, error) {
+	desiredKeys := map[manifestObjectKey]struct{}{}
+	for _, ref := range desired {
+		desiredKeys[keyForManifestObject(ref)] = struct{}{}
+	}
+
+	var (
+		remaining []addonsv1alpha1.AddonManifestObjectReference
+		errs      []error
+	)
+	for _, ref := range addon.Status.ManifestObjects {
+		if _, ok := desiredKeys[keyForManifestObject(ref)]; ok {
+			continue
+		}
+		if err := r.pruneObject(ctx, addon, ref); err != nil {
+			remaining = append(remaining, ref)
+			errs = append(errs, err)
+		}
+	}
+	return remaining, errors.Join(errs...)
+}
+
+func (r *manifestsReconciler) pruneObject(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	ref addonsv1alpha1.AddonManifestObjectReference,
+) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	key := client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}
+
+	err := r.uncachedClient.Get(ctx, key, obj)
+	if apiErrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting %s %s: %w", ref.Kind, key, err)
+	}
+
+	// The object has been taken over by someone else.
+	if !controllers.CommonLabelsAsLabelSelector(addon).Matches(labels.Set(obj.GetLabels())) {
+		return nil
+	}
+
+	if err := r.client.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("deleting %s %s: %w", ref.Kind, key, err)
+	}
+	return nil
+}
+
+// Enqueues the Addons installing the manifest bundle of the changed ConfigMap,
+// to apply and prune its objects without waiting for the next resync.
+func (r *AddonReconciler) enqueueAddonsForManifestsConfigMap(
+	ctx context.Context, obj client.Object,
+) []reconcile.Request {
+	if obj.GetNamespace() != r.AddonOperatorNamespace {
+		return nil
+	}
+
+	addonList := &addonsv1alpha1.AddonList{}
+	if err := r.List(ctx, addonList); err != nil {
+		r.Log.Error(err, "listing Addons to enqueue for manifests ConfigMap")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, addon := range addonList.Items {
+		manifestsSpec := addon.Spec.Install.Manifests
+		if addon.Spec.Install.Type != addonsv1alpha1.Manifests ||
+			manifestsSpec == nil || manifestsSpec.ConfigMapName != obj.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKey{Name: addon.Name},
+		})
+	}
+	return requests
+}
+
+func manifestObjectReference(obj client.Object) addonsv1alpha1.AddonManifestObjectReference {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return addonsv1alpha1.AddonManifestObjectReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// Objects are identified independent of their API version,
+// so changing the version of an object in the bundle does not prune it.
+type manifestObjectKey struct {
+	schema.GroupKind
+	client.ObjectKey
+}
+
+func keyForManifestObject(ref addonsv1alpha1.AddonManifestObjectReference) manifestObjectKey {
+	return manifestObjectKey{
+		GroupKind: schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind(),
+		ObjectKey: client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace},
+	}
+}
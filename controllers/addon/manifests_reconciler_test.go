@@ -0,0 +1,279 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+type manifestBundleLoaderMock struct {
+	mock.Mock
+}
+
+func (m *manifestBundleLoaderMock) Load(
+	ctx context.Context,
+	addon *addonsv1alpha1.Addon,
+	dockerConfigJSON []byte,
+) ([]*unstructured.Unstructured, error) {
+	args := m.Called(ctx, addon, dockerConfigJSON)
+	objs, _ := args.Get(0).([]*unstructured.Unstructured)
+	return objs, args.Error(1)
+}
+
+func (m *manifestBundleLoaderMock) Forget(addonName string) {
+	m.Called(addonName)
+}
+
+func newTestManifestsAddon() *addonsv1alpha1.Addon {
+	return &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "addon-1",
+			UID:        "addon-1-uid",
+			Generation: 1,
+		},
+		Spec: addonsv1alpha1.AddonSpec{
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{
+					Namespace:     "addon-1-ns",
+					ConfigMapName: "addon-1-manifests",
+				},
+			},
+		},
+	}
+}
+
+func newTestManifestsReconciler(
+	c, uncachedC *testutil.Client, loader manifestBundleLoader,
+) *manifestsReconciler {
+	return &manifestsReconciler{
+		client:                 c,
+		uncachedClient:         uncachedC,
+		scheme:                 testutil.NewTestSchemeWithAddonsv1alpha1(),
+		loader:                 loader,
+		addonOperatorNamespace: "addon-operator",
+	}
+}
+
+func mockRolledOutDeployment(c *testutil.Client) {
+	c.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&appsv1.Deployment{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		deployment := args.Get(2).(*appsv1.Deployment)
+		deployment.Spec.Replicas = ptr.To(int32(1))
+		deployment.Status = appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
+		}
+	}).Return(nil)
+}
+
+func TestManifestsReconciler_NotManifests(t *testing.T) {
+	loader := &manifestBundleLoaderMock{}
+	r := newTestManifestsReconciler(testutil.NewClient(), testutil.NewClient(), loader)
+
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	loader.On("Forget", addon.Name).Return()
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	assert.Empty(t, addon.Status.Conditions)
+	loader.AssertExpectations(t)
+}
+
+func TestManifestsReconciler_ConfigurationError(t *testing.T) {
+	r := newTestManifestsReconciler(testutil.NewClient(), testutil.NewClient(), &manifestBundleLoaderMock{})
+
+	addon := newTestManifestsAddon()
+	addon.Spec.Install.Manifests.Image = "quay.io/osd-addons/addon-1-manifests:v1"
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultStop, result)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, addonsv1alpha1.AddonReasonConfigError, available.Reason)
+}
+
+func TestManifestsReconciler_Installed(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	loader := &manifestBundleLoaderMock{}
+	r := newTestManifestsReconciler(c, uncachedC, loader)
+	addon := newTestManifestsAddon()
+
+	loader.On("Load", mock.Anything, addon, []byte(nil)).Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+	mockRolledOutDeployment(uncachedC)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	c.AssertNumberOfCalls(t, "Apply", 2)
+	assert.True(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed))
+	assert.Equal(t, []addonsv1alpha1.AddonManifestObjectReference{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1-ns", Name: "config"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1-ns", Name: "operator"},
+	}, addon.Status.ManifestObjects)
+}
+
+func TestManifestsReconciler_Prune(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	loader := &manifestBundleLoaderMock{}
+	r := newTestManifestsReconciler(c, uncachedC, loader)
+	addon := newTestManifestsAddon()
+	addon.Status.ManifestObjects = []addonsv1alpha1.AddonManifestObjectReference{
+		// Still part of the bundle, but with a different API version.
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", Namespace: "addon-1-ns", Name: "operator"},
+		// Removed from the bundle.
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1-ns", Name: "removed"},
+		// Removed from the bundle and adopted by someone else.
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1-ns", Name: "adopted"},
+		// Removed from the bundle and already gone.
+		{APIVersion: "v1", Kind: "Secret", Namespace: "addon-1-ns", Name: "gone"},
+	}
+
+	loader.On("Load", mock.Anything, addon, []byte(nil)).Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+	mockRolledOutDeployment(uncachedC)
+	uncachedC.On("Get", testutil.IsContext,
+		client.ObjectKey{Name: "removed", Namespace: "addon-1-ns"},
+		mock.IsType(&unstructured.Unstructured{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		obj := args.Get(2).(*unstructured.Unstructured)
+		obj.SetName("removed")
+		obj.SetNamespace("addon-1-ns")
+		controllers.AddCommonLabels(obj, addon)
+	}).Return(nil)
+	uncachedC.On("Get", testutil.IsContext,
+		client.ObjectKey{Name: "adopted", Namespace: "addon-1-ns"},
+		mock.IsType(&unstructured.Unstructured{}), mock.Anything,
+	).Return(nil)
+	uncachedC.On("Get", testutil.IsContext,
+		client.ObjectKey{Name: "gone", Namespace: "addon-1-ns"},
+		mock.IsType(&unstructured.Unstructured{}), mock.Anything,
+	).Return(testutil.NewTestErrNotFound())
+	c.On("Delete", testutil.IsContext, mock.IsType(&unstructured.Unstructured{}), mock.Anything).Return(nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+
+	c.AssertNumberOfCalls(t, "Delete", 1)
+	c.AssertCalled(t, "Delete", testutil.IsContext, mock.MatchedBy(func(obj *unstructured.Unstructured) bool {
+		return obj.GetName() == "removed"
+	}), mock.Anything)
+	assert.Len(t, addon.Status.ManifestObjects, 2)
+}
+
+func TestManifestsReconciler_PruneError(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	loader := &manifestBundleLoaderMock{}
+	r := newTestManifestsReconciler(c, uncachedC, loader)
+	addon := newTestManifestsAddon()
+	removed := addonsv1alpha1.AddonManifestObjectReference{
+		APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1-ns", Name: "removed",
+	}
+	addon.Status.ManifestObjects = []addonsv1alpha1.AddonManifestObjectReference{removed}
+
+	loader.On("Load", mock.Anything, addon, []byte(nil)).Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+	uncachedC.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&unstructured.Unstructured{}), mock.Anything,
+	).Return(errors.New("timeout"))
+
+	_, err := r.Reconcile(context.Background(), addon)
+	require.Error(t, err)
+	// The object is kept track of, to retry pruning later.
+	assert.Contains(t, addon.Status.ManifestObjects, removed)
+	assert.Len(t, addon.Status.ManifestObjects, 3)
+}
+
+func TestManifestsReconciler_LoadError(t *testing.T) {
+	loader := &manifestBundleLoaderMock{}
+	r := newTestManifestsReconciler(testutil.NewClient(), testutil.NewClient(), loader)
+	addon := newTestManifestsAddon()
+
+	loader.On("Load", mock.Anything, addon, []byte(nil)).Return(nil, errors.New("ConfigMap not found"))
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultRequeueAfter(defaultRetryAfterTime), result)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, addonsv1alpha1.AddonReasonManifestBundleError, available.Reason)
+}
+
+func TestManifestsReconciler_UnreadyWorkloads(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	loader := &manifestBundleLoaderMock{}
+	r := newTestManifestsReconciler(c, uncachedC, loader)
+	addon := newTestManifestsAddon()
+
+	loader.On("Load", mock.Anything, addon, []byte(nil)).Return(newTestHelmObjects(), nil)
+	c.On("Apply", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+	uncachedC.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&appsv1.Deployment{}), mock.Anything,
+	).Return(nil)
+
+	result, err := r.Reconcile(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultStop, result)
+
+	available := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available)
+	require.NotNil(t, available)
+	assert.Equal(t, addonsv1alpha1.AddonReasonUnreadyManifests, available.Reason)
+}
+
+func TestEnqueueAddonsForManifestsConfigMap(t *testing.T) {
+	c := testutil.NewClient()
+	r := &AddonReconciler{
+		Client:                 c,
+		Log:                    testutil.NewLogger(t),
+		AddonOperatorNamespace: "addon-operator",
+	}
+
+	other := newTestManifestsAddon()
+	other.Name = "addon-2"
+	other.Spec.Install.Manifests.ConfigMapName = "addon-2-manifests"
+	olm := &addonsv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: "addon-3"}}
+	c.On("List", testutil.IsContext, mock.IsType(&addonsv1alpha1.AddonList{}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			list := args.Get(1).(*addonsv1alpha1.AddonList)
+			list.Items = []addonsv1alpha1.Addon{*newTestManifestsAddon(), *other, *olm}
+		}).
+		Return(nil)
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "addon-1-manifests",
+		Namespace: "addon-operator",
+	}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: client.ObjectKey{Name: "addon-1"}},
+	}, r.enqueueAddonsForManifestsConfigMap(context.Background(), configMap))
+
+	configMap.Namespace = "addon-1-ns"
+	assert.Empty(t, r.enqueueAddonsForManifestsConfigMap(context.Background(), configMap))
+}
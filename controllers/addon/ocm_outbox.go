@@ -0,0 +1,242 @@
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/outbox"
+)
+
+// Name of the ConfigMap in the AddonOperator namespace persisting the OCM outbox.
+const OCMOutboxConfigMapName = "addon-operator-ocm-outbox"
+
+// Kinds of reports queued in the OCM outbox.
+const (
+	ocmReportAddOnStatus   = "AddOnStatus"
+	ocmReportUpgradePolicy = "UpgradePolicy"
+)
+
+// Defaults of .spec.ocm.reporting in the AddonOperator.
+const (
+	defaultOCMReportingRequestsPerMinute = 120
+	defaultOCMReportingBurst             = 10
+	defaultOCMReportingCoalesceWindow    = 5 * time.Second
+	defaultOCMReportingMaxBulkSize       = 50
+)
+
+var errOCMClientNotInitialized = errors.New("ocm client not initialized")
+
+type ocmOutbox interface {
+	Enqueue(ctx context.Context, kind, addon string, payload interface{}) error
+	SetThrottle(throttle outbox.Throttle)
+}
+
+// OCMOutboxOptions configures an outbox for DeliverOCMReport and DeliverOCMReports,
+// with only the latest status of an Addon being kept.
+func (r *AddonReconciler) OCMOutboxOptions() []outbox.Option {
+	return []outbox.Option{
+		outbox.WithCoalescing(ocmReportAddOnStatus),
+		outbox.WithBatchDelivery(r.DeliverOCMReports, ocmReportAddOnStatus),
+		outbox.WithThrottle(ocmReportingThrottle(nil)),
+		outbox.WithDropHandler(r.OCMReportsDropped),
+	}
+}
+
+// OCMReportsDropped remembers Addon status reports dropped by the outbox,
+// so they are queued again, although the Addon status already records their hash.
+// Addons of reports dropped because the outbox was full are requeued right away.
+// Reports rejected by OCM are queued again with the next reconcile of their Addon,
+// so they are not retried in a loop.
+func (r *AddonReconciler) OCMReportsDropped(reason string, entries []outbox.Entry) {
+	for _, entry := range entries {
+		if entry.Kind != ocmReportAddOnStatus {
+			continue
+		}
+		var req ocm.AddOnStatusPostRequest
+		if err := json.Unmarshal(entry.Payload, &req); err != nil {
+			continue
+		}
+		r.ocmDroppedStatusReports.Store(entry.Addon, HashAddonStatusPayload(req))
+		if reason == outbox.DropReasonFull {
+			r.requeueAddon(entry.Addon)
+		}
+	}
+}
+
+// Reports whether the status report with the given hash was dropped by the outbox.
+func (r *AddonReconciler) isOCMStatusReportDropped(addonName, statusHash string) bool {
+	dropped, ok := r.ocmDroppedStatusReports.Load(addonName)
+	return ok && dropped == statusHash
+}
+
+// InjectOCMOutbox queues all reports to OCM in the given outbox,
+// instead of sending them while reconciling.
+// The outbox must deliver entries via DeliverOCMReport.
+func (r *AddonReconciler) InjectOCMOutbox(o ocmOutbox) {
+	r.ocmOutbox = o
+}
+
+// ConfigureOCMReporting applies the reporting configuration of the AddonOperator
+// to the OCM outbox, unset fields fall back to their defaults.
+func (r *AddonReconciler) ConfigureOCMReporting(reporting *addonsv1alpha1.AddonOperatorOCMReporting) {
+	if r.ocmOutbox == nil {
+		return
+	}
+	r.ocmOutbox.SetThrottle(ocmReportingThrottle(reporting))
+}
+
+func ocmReportingThrottle(reporting *addonsv1alpha1.AddonOperatorOCMReporting) outbox.Throttle {
+	cfg := addonsv1alpha1.AddonOperatorOCMReporting{}
+	if reporting != nil {
+		cfg = *reporting
+	}
+
+	throttle := outbox.Throttle{
+		Rate:           defaultOCMReportingRequestsPerMinute / 60.0,
+		Burst:          defaultOCMReportingBurst,
+		CoalesceWindow: defaultOCMReportingCoalesceWindow,
+		MaxBatchSize:   1,
+	}
+	if cfg.RequestsPerMinute > 0 {
+		throttle.Rate = float64(cfg.RequestsPerMinute) / 60
+	}
+	if cfg.Burst > 0 {
+		throttle.Burst = int(cfg.Burst)
+	}
+	if cfg.CoalesceWindow.Duration > 0 {
+		throttle.CoalesceWindow = cfg.CoalesceWindow.Duration
+	}
+	if cfg.Mode == addonsv1alpha1.AddonOperatorOCMReportingBulk {
+		throttle.MaxBatchSize = defaultOCMReportingMaxBulkSize
+		if cfg.MaxBulkSize > 0 {
+			throttle.MaxBatchSize = int(cfg.MaxBulkSize)
+		}
+	}
+	return throttle
+}
+
+// DeliverOCMReport sends a report queued in the OCM outbox.
+func (r *AddonReconciler) DeliverOCMReport(ctx context.Context, entry outbox.Entry) error {
+	ocmClient := r.getOCMClient()
+	if ocmClient == nil {
+		return errOCMClientNotInitialized
+	}
+
+	var err error
+	switch entry.Kind {
+	case ocmReportAddOnStatus:
+		var req ocm.AddOnStatusPostRequest
+		if err := json.Unmarshal(entry.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %w", outbox.ErrUndeliverable, err)
+		}
+		r.recordAddonServiceRequestDuration(func() {
+			_, err = ocmClient.PostAddOnStatus(ctx, req)
+		})
+
+	case ocmReportUpgradePolicy:
+		var req ocm.UpgradePolicyPatchRequest
+		if err := json.Unmarshal(entry.Payload, &req); err != nil {
+			return fmt.Errorf("%w: %w", outbox.ErrUndeliverable, err)
+		}
+		err = r.handlePatchUpgradePolicy(ctx, ocmClient, req)
+
+	default:
+		return fmt.Errorf("%w: unknown report kind %q", outbox.ErrUndeliverable, entry.Kind)
+	}
+	return ocmReportError(err)
+}
+
+// DeliverOCMReports sends Addon status reports queued in the OCM outbox
+// through the bulk status endpoint.
+// Once OCM answered that it does not serve the endpoint,
+// the reports are posted one by one instead.
+func (r *AddonReconciler) DeliverOCMReports(ctx context.Context, entries []outbox.Entry) error {
+	ocmClient := r.getOCMClient()
+	if ocmClient == nil {
+		return errOCMClientNotInitialized
+	}
+
+	req := ocm.AddOnStatusBulkPostRequest{}
+	for _, entry := range entries {
+		if entry.Kind != ocmReportAddOnStatus {
+			return fmt.Errorf("%w: report kind %q can't be batched", outbox.ErrUndeliverable, entry.Kind)
+		}
+		var item ocm.AddOnStatusPostRequest
+		if err := json.Unmarshal(entry.Payload, &item); err != nil {
+			return fmt.Errorf("%w: %w", outbox.ErrUndeliverable, err)
+		}
+		req.Items = append(req.Items, item)
+	}
+
+	if !r.ocmBulkStatusUnsupported.Load() {
+		var err error
+		r.recordAddonServiceRequestDuration(func() {
+			_, err = ocmClient.PostAddOnStatusBulk(ctx, req)
+		})
+		if !isOCMEndpointUnsupported(err) {
+			return ocmReportError(err)
+		}
+		r.Log.Info("OCM does not serve the bulk status endpoint, reporting Addon statuses individually")
+		r.ocmBulkStatusUnsupported.Store(true)
+	}
+	return r.postAddOnStatuses(ctx, ocmClient, req.Items)
+}
+
+// Posts every status with its own request.
+// Statuses rejected by OCM are skipped, so they don't hold back the others,
+// all other errors fail the whole batch to retry it.
+func (r *AddonReconciler) postAddOnStatuses(
+	ctx context.Context, ocmClient ocmClient, items []ocm.AddOnStatusPostRequest,
+) error {
+	var rejected []error
+	for _, item := range items {
+		var err error
+		r.recordAddonServiceRequestDuration(func() {
+			_, err = ocmClient.PostAddOnStatus(ctx, item)
+		})
+		err = ocmReportError(err)
+		switch {
+		case errors.Is(err, outbox.ErrUndeliverable):
+			rejected = append(rejected, fmt.Errorf("addon %s: %w", item.AddonID, err))
+		case err != nil:
+			return err
+		}
+	}
+	return errors.Join(rejected...)
+}
+
+// Reports whether OCM does not know the requested endpoint at all.
+func isOCMEndpointUnsupported(err error) bool {
+	var ocmErr ocm.OCMError
+	return errors.As(err, &ocmErr) &&
+		(ocmErr.StatusCode == http.StatusNotFound || ocmErr.StatusCode == http.StatusMethodNotAllowed)
+}
+
+// Marks errors of reports rejected by OCM as undeliverable.
+func ocmReportError(err error) error {
+	// OCM rejected the report itself, sending it again won't help.
+	var ocmErr ocm.OCMError
+	if errors.As(err, &ocmErr) &&
+		ocmErr.StatusCode >= 400 && ocmErr.StatusCode < 500 &&
+		ocmErr.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", outbox.ErrUndeliverable, err)
+	}
+	return err
+}
+
+// Sends or queues the UpgradePolicy patch.
+func (r *AddonReconciler) patchUpgradePolicy(
+	ctx context.Context, ocmClient ocmClient,
+	addon *addonsv1alpha1.Addon, req ocm.UpgradePolicyPatchRequest,
+) error {
+	if r.ocmOutbox != nil {
+		return r.ocmOutbox.Enqueue(ctx, ocmReportUpgradePolicy, addon.Name, req)
+	}
+	return r.handlePatchUpgradePolicy(ctx, ocmClient, req)
+}
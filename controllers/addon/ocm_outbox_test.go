@@ -0,0 +1,329 @@
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
+	"github.com/openshift/addon-operator/internal/outbox"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+type testOCMOutbox struct {
+	entries  []outbox.Entry
+	throttle outbox.Throttle
+}
+
+func (o *testOCMOutbox) SetThrottle(throttle outbox.Throttle) {
+	o.throttle = throttle
+}
+
+func (o *testOCMOutbox) Enqueue(_ context.Context, kind, addon string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	o.entries = append(o.entries, outbox.Entry{
+		Sequence: uint64(len(o.entries)),
+		Kind:     kind,
+		Addon:    addon,
+		Payload:  data,
+	})
+	return nil
+}
+
+func TestHandleAddonStatusReporting_Outbox(t *testing.T) {
+	o := &testOCMOutbox{}
+	r := &AddonReconciler{
+		statusReportingEnabled: true,
+		ocmOutbox:              o,
+	}
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+		Spec:       addonsv1alpha1.AddonSpec{Version: "1.0.0"},
+	}
+	log := testutil.NewLogger(t)
+
+	// Every transition is queued, even before the OCM client is initialized.
+	for _, status := range []metav1.ConditionStatus{metav1.ConditionFalse, metav1.ConditionTrue} {
+		addon.Status.Conditions = []metav1.Condition{{
+			Type:   addonsv1alpha1.Available,
+			Status: status,
+			Reason: addonsv1alpha1.AddonReasonFullyReconciled,
+		}}
+		require.NoError(t, r.handleOCMAddOnStatusReporting(context.Background(), log, addon))
+		// Unchanged status is not queued again.
+		require.NoError(t, r.handleOCMAddOnStatusReporting(context.Background(), log, addon))
+	}
+
+	require.Len(t, o.entries, 2)
+	for i, status := range []string{"False", "True"} {
+		var req ocm.AddOnStatusPostRequest
+		require.NoError(t, json.Unmarshal(o.entries[i].Payload, &req))
+		assert.Equal(t, ocmReportAddOnStatus, o.entries[i].Kind)
+		assert.Equal(t, "addon-1", req.AddonID)
+		assert.Equal(t, status, string(req.StatusConditions[0].StatusValue))
+	}
+}
+
+func TestHandleAddonStatusReporting_OutboxDropped(t *testing.T) {
+	for _, reason := range []string{outbox.DropReasonFull, outbox.DropReasonUndeliverable} {
+		t.Run(reason, func(t *testing.T) {
+			o := &testOCMOutbox{}
+			r := &AddonReconciler{
+				statusReportingEnabled: true,
+				ocmOutbox:              o,
+				addonRequeueCh:         make(chan event.GenericEvent),
+			}
+			addon := &addonsv1alpha1.Addon{
+				ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+				Spec:       addonsv1alpha1.AddonSpec{Version: "1.0.0"},
+			}
+			log := testutil.NewLogger(t)
+
+			require.NoError(t, r.handleOCMAddOnStatusReporting(context.Background(), log, addon))
+			require.Len(t, o.entries, 1)
+
+			r.OCMReportsDropped(reason, o.entries)
+			if reason == outbox.DropReasonFull {
+				select {
+				case e := <-r.addonRequeueCh:
+					assert.Equal(t, "addon-1", e.Object.GetName())
+				case <-time.After(5 * time.Second):
+					t.Fatal("Addon not requeued")
+				}
+			}
+
+			// The unchanged status is queued again, once.
+			require.NoError(t, r.handleOCMAddOnStatusReporting(context.Background(), log, addon))
+			require.NoError(t, r.handleOCMAddOnStatusReporting(context.Background(), log, addon))
+			require.Len(t, o.entries, 2)
+			assert.Equal(t, o.entries[0].Payload, o.entries[1].Payload)
+		})
+	}
+}
+
+func TestHandleUpgradePolicyStatusReporting_Outbox(t *testing.T) {
+	ocmClient := ocmtest.NewClient()
+	o := &testOCMOutbox{}
+	r := &AddonReconciler{
+		upgradePolicyStatusEnabled: true,
+		ocmClient:                  ocmClient,
+		ocmOutbox:                  o,
+		clock:                      defaultClock{},
+	}
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+		Spec: addonsv1alpha1.AddonSpec{
+			Version:       "1.0.0",
+			UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicy{ID: "1234"},
+		},
+	}
+
+	ocmClient.
+		On("GetUpgradePolicy", testutil.IsContext, ocm.UpgradePolicyGetRequest{ID: "1234"}).
+		Return(ocm.UpgradePolicyGetResponse{Value: ocm.UpgradePolicyValueScheduled}, nil)
+
+	err := r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+	require.NoError(t, err)
+
+	ocmClient.AssertNotCalled(t, "PatchUpgradePolicy", mock.Anything, mock.Anything)
+	require.Len(t, o.entries, 1)
+	assert.Equal(t, ocmReportUpgradePolicy, o.entries[0].Kind)
+	assert.JSONEq(t,
+		`{"id":"1234","value":"started","description":"Upgrading addon to version \"1.0.0\"."}`,
+		string(o.entries[0].Payload))
+	assert.Equal(t, addonsv1alpha1.AddonUpgradePolicyValueStarted, addon.Status.UpgradePolicy.Value)
+}
+
+func TestDeliverOCMReport(t *testing.T) {
+	statusReq := ocm.AddOnStatusPostRequest{AddonID: "addon-1", AddonVersion: "1.0.0"}
+	statusPayload, err := json.Marshal(statusReq)
+	require.NoError(t, err)
+	policyReq := ocm.UpgradePolicyPatchRequest{ID: "1234", Value: ocm.UpgradePolicyValueCompleted}
+	policyPayload, err := json.Marshal(policyReq)
+	require.NoError(t, err)
+
+	t.Run("waits for the ocm client", func(t *testing.T) {
+		r := &AddonReconciler{}
+		err := r.DeliverOCMReport(context.Background(), outbox.Entry{
+			Kind: ocmReportAddOnStatus, Payload: statusPayload,
+		})
+		assert.ErrorIs(t, err, errOCMClientNotInitialized)
+		assert.NotErrorIs(t, err, outbox.ErrUndeliverable)
+	})
+
+	t.Run("posts addon status", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		ocmClient.On("PostAddOnStatus", testutil.IsContext, statusReq).
+			Return(ocm.AddOnStatusResponse{}, nil)
+		r := &AddonReconciler{ocmClient: ocmClient}
+
+		err := r.DeliverOCMReport(context.Background(), outbox.Entry{
+			Kind: ocmReportAddOnStatus, Payload: statusPayload,
+		})
+		require.NoError(t, err)
+		ocmClient.AssertExpectations(t)
+	})
+
+	for name, tc := range map[string]struct {
+		err           error
+		undeliverable bool
+	}{
+		"rejected": {
+			err:           ocm.OCMError{StatusCode: http.StatusBadRequest},
+			undeliverable: true,
+		},
+		"throttled": {
+			err: ocm.OCMError{StatusCode: http.StatusTooManyRequests},
+		},
+		"unavailable": {
+			err: ocm.ErrCircuitOpen,
+		},
+	} {
+		t.Run("patch UpgradePolicy "+name, func(t *testing.T) {
+			ocmClient := ocmtest.NewClient()
+			ocmClient.On("PatchUpgradePolicy", testutil.IsContext, policyReq).
+				Return(ocm.UpgradePolicyPatchResponse{}, tc.err)
+			r := &AddonReconciler{ocmClient: ocmClient}
+
+			err := r.DeliverOCMReport(context.Background(), outbox.Entry{
+				Kind: ocmReportUpgradePolicy, Payload: policyPayload,
+			})
+			assert.True(t, errors.Is(err, tc.err))
+			assert.Equal(t, tc.undeliverable, errors.Is(err, outbox.ErrUndeliverable))
+		})
+	}
+
+	t.Run("unknown kind", func(t *testing.T) {
+		r := &AddonReconciler{ocmClient: ocmtest.NewClient()}
+		err := r.DeliverOCMReport(context.Background(), outbox.Entry{Kind: "Unknown"})
+		assert.ErrorIs(t, err, outbox.ErrUndeliverable)
+	})
+}
+
+func TestDeliverOCMReports(t *testing.T) {
+	var (
+		entries []outbox.Entry
+		req     ocm.AddOnStatusBulkPostRequest
+	)
+	for _, addon := range []string{"addon-1", "addon-2"} {
+		item := ocm.AddOnStatusPostRequest{AddonID: addon, AddonVersion: "1.0.0"}
+		payload, err := json.Marshal(item)
+		require.NoError(t, err)
+		entries = append(entries, outbox.Entry{Kind: ocmReportAddOnStatus, Addon: addon, Payload: payload})
+		req.Items = append(req.Items, item)
+	}
+
+	t.Run("posts addon statuses in bulk", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		ocmClient.On("PostAddOnStatusBulk", testutil.IsContext, req).
+			Return(ocm.AddOnStatusBulkResponse{}, nil)
+		r := &AddonReconciler{ocmClient: ocmClient}
+
+		require.NoError(t, r.DeliverOCMReports(context.Background(), entries))
+		ocmClient.AssertExpectations(t)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		ocmClient.On("PostAddOnStatusBulk", testutil.IsContext, req).
+			Return(ocm.AddOnStatusBulkResponse{}, ocm.OCMError{StatusCode: http.StatusBadRequest})
+		r := &AddonReconciler{ocmClient: ocmClient}
+
+		err := r.DeliverOCMReports(context.Background(), entries)
+		assert.ErrorIs(t, err, outbox.ErrUndeliverable)
+	})
+
+	t.Run("falls back to individual posts", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		ocmClient.On("PostAddOnStatusBulk", testutil.IsContext, req).
+			Return(ocm.AddOnStatusBulkResponse{}, ocm.OCMError{StatusCode: http.StatusNotFound}).Once()
+		for _, item := range req.Items {
+			ocmClient.On("PostAddOnStatus", testutil.IsContext, item).
+				Return(ocm.AddOnStatusResponse{}, nil).Twice()
+		}
+		r := &AddonReconciler{ocmClient: ocmClient}
+
+		require.NoError(t, r.DeliverOCMReports(context.Background(), entries))
+		// The bulk endpoint is not tried again.
+		require.NoError(t, r.DeliverOCMReports(context.Background(), entries))
+		ocmClient.AssertExpectations(t)
+	})
+
+	t.Run("individual posts skip rejected statuses", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		ocmClient.On("PostAddOnStatus", testutil.IsContext, req.Items[0]).
+			Return(ocm.AddOnStatusResponse{}, ocm.OCMError{StatusCode: http.StatusBadRequest})
+		ocmClient.On("PostAddOnStatus", testutil.IsContext, req.Items[1]).
+			Return(ocm.AddOnStatusResponse{}, nil)
+		r := &AddonReconciler{ocmClient: ocmClient}
+		r.ocmBulkStatusUnsupported.Store(true)
+
+		err := r.DeliverOCMReports(context.Background(), entries)
+		assert.ErrorIs(t, err, outbox.ErrUndeliverable)
+		ocmClient.AssertExpectations(t)
+	})
+
+	t.Run("individual posts retry unavailable OCM", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		ocmClient.On("PostAddOnStatus", testutil.IsContext, req.Items[0]).
+			Return(ocm.AddOnStatusResponse{}, ocm.OCMError{StatusCode: http.StatusServiceUnavailable})
+		r := &AddonReconciler{ocmClient: ocmClient}
+		r.ocmBulkStatusUnsupported.Store(true)
+
+		err := r.DeliverOCMReports(context.Background(), entries)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, outbox.ErrUndeliverable)
+		ocmClient.AssertNotCalled(t, "PostAddOnStatus", testutil.IsContext, req.Items[1])
+	})
+
+	t.Run("only addon statuses", func(t *testing.T) {
+		r := &AddonReconciler{ocmClient: ocmtest.NewClient()}
+		err := r.DeliverOCMReports(context.Background(), []outbox.Entry{
+			entries[0], {Kind: ocmReportUpgradePolicy},
+		})
+		assert.ErrorIs(t, err, outbox.ErrUndeliverable)
+	})
+}
+
+func TestConfigureOCMReporting(t *testing.T) {
+	o := &testOCMOutbox{}
+	r := &AddonReconciler{ocmOutbox: o}
+
+	r.ConfigureOCMReporting(nil)
+	assert.Equal(t, outbox.Throttle{
+		Rate:           2,
+		Burst:          10,
+		CoalesceWindow: 5 * time.Second,
+		MaxBatchSize:   1,
+	}, o.throttle)
+
+	r.ConfigureOCMReporting(&addonsv1alpha1.AddonOperatorOCMReporting{
+		RequestsPerMinute: 30,
+		Burst:             2,
+		CoalesceWindow:    metav1.Duration{Duration: time.Minute},
+		Mode:              addonsv1alpha1.AddonOperatorOCMReportingBulk,
+	})
+	assert.Equal(t, outbox.Throttle{
+		Rate:           0.5,
+		Burst:          2,
+		CoalesceWindow: time.Minute,
+		MaxBatchSize:   50,
+	}, o.throttle)
+
+	// Without outbox, reports are sent right away.
+	(&AddonReconciler{}).ConfigureOCMReporting(nil)
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	"github.com/openshift/addon-operator/controllers"
 	"github.com/openshift/addon-operator/internal/metrics"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/tracing"
 )
 
 const OLM_RECONCILER_NAME = "olmReconciler"
@@ -20,18 +25,35 @@ type olmReconciler struct {
 	uncachedClient          client.Client
 	operatorResourceHandler operatorResourceHandler
 	recorder                *metrics.Recorder
+	clock                   clock
+	// Optional, runs the PromQL health gate of canary rollouts.
+	prometheusClient prometheusClient
+	// Optional, records InstallPlan approval decisions as Events.
+	eventRecorder events.EventRecorder
+	// Looks up the state of an OCM UpgradePolicy by ID.
+	upgradePolicyState func(ctx context.Context, policyID string) (ocm.UpgradePolicyValue, error)
+	// Looks up the maintenance configuration of Addons without their own.
+	defaultMaintenance func(ctx context.Context) (*addonsv1alpha1.AddonMaintenance, error)
 }
 
 func (r *olmReconciler) Reconcile(ctx context.Context,
 	addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
+	if !isOLMInstall(addon) {
+		return resultNil, nil
+	}
 	log := controllers.LoggerFromContext(ctx)
 
-	var err error
 	reconErr := metrics.NewReconcileError("addon", r.recorder, true)
 
+	// Changes waiting for a maintenance window are reported again by the phases below.
+	removeWaitingForMaintenanceWindow(addon)
+
 	// Phase 1.
 	// Ensure OperatorGroup
-	if requeueResult, err := r.ensureOperatorGroup(ctx, addon); err != nil {
+	phaseCtx, span := startOLMPhase(ctx, "EnsureOperatorGroup")
+	requeueResult, err := r.ensureOperatorGroup(phaseCtx, addon)
+	tracing.End(span, err)
+	if err != nil {
 		err = reconErr.Join(err, controllers.ErrEnsureOperatorGroup)
 		return resultNil, err
 	} else if !requeueResult.IsZero() {
@@ -43,7 +65,10 @@ func (r *olmReconciler) Reconcile(ctx context.Context,
 	// Note: This Phase must preempt CatalogSource reconciliation
 	// as the CatalogSources will never report 'ready' if OLM
 	// cannot verify the status of the GRPC connection.
-	if requeueResult, err := r.ensureCatalogSourcesNetworkPolicy(ctx, addon); err != nil {
+	phaseCtx, span = startOLMPhase(ctx, "EnsureCatalogSourcesNetworkPolicy")
+	requeueResult, err = r.ensureCatalogSourcesNetworkPolicy(phaseCtx, addon)
+	tracing.End(span, err)
+	if err != nil {
 		err = reconErr.Join(err, controllers.ErrEnsureNetworkPolicy)
 		return resultNil, err
 	} else if !requeueResult.IsZero() {
@@ -51,32 +76,66 @@ func (r *olmReconciler) Reconcile(ctx context.Context,
 	}
 
 	// Phase 3.
+	// Observe the start of a canary rollout
+	// Note: This Phase must preempt CatalogSource reconciliation
+	// as it compares the new catalog image to the one of the existing CatalogSource.
+	phaseCtx, span = startOLMPhase(ctx, "ObserveCanaryRollout")
+	result, err := r.observeCanaryRollout(phaseCtx, addon)
+	tracing.End(span, err)
+	if err != nil {
+		err = reconErr.Join(err, controllers.ErrCanaryRollout)
+		return resultNil, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	// Phase 4.
 	// Ensure CatalogSource
 	var (
-		catalogSource *operatorsv1alpha1.CatalogSource
-		result        subReconcilerResult
+		catalogSource             *operatorsv1alpha1.CatalogSource
+		subscriptionCatalogSource *operatorsv1alpha1.CatalogSource
 	)
-	if result, catalogSource, err = r.ensureCatalogSource(ctx, addon); err != nil {
+	phaseCtx, span = startOLMPhase(ctx, "EnsureCatalogSource")
+	result, catalogSource, err = r.ensureCatalogSource(phaseCtx, addon)
+	tracing.End(span, err)
+	if err != nil {
 		err = reconErr.Join(err, controllers.ErrEnsureCatalogSource)
 		return resultNil, err
 	} else if !result.IsZero() {
 		return result, nil
 	}
 
-	// Phase 4.
+	// Phase 5.
 	// Ensure Additional CatalogSources
-	if result, err = r.ensureAdditionalCatalogSources(ctx, addon); err != nil {
+	phaseCtx, span = startOLMPhase(ctx, "EnsureAdditionalCatalogSources")
+	result, err = r.ensureAdditionalCatalogSources(phaseCtx, addon)
+	tracing.End(span, err)
+	if err != nil {
 		err = reconErr.Join(err, controllers.ErrEnsureAdditionalCatalogSource)
 		return resultNil, err
 	} else if !result.IsZero() {
 		return result, nil
 	}
 
-	// Phase 5.
+	// Phase 6.
+	// Progress canary rollout, which decides on the CatalogSource of the Subscription.
+	phaseCtx, span = startOLMPhase(ctx, "EnsureCanaryRollout")
+	result, subscriptionCatalogSource, err = r.ensureCanaryRollout(phaseCtx, addon, catalogSource)
+	tracing.End(span, err)
+	if err != nil {
+		err = reconErr.Join(err, controllers.ErrCanaryRollout)
+		return resultNil, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
+	// Phase 7.
 	// Ensure Subscription for this Addon.
+	phaseCtx, span = startOLMPhase(ctx, "EnsureSubscription")
 	result, currentCSVKey, err := r.ensureSubscription(
-		ctx, log.WithName("phase-ensure-subscription"),
-		addon, catalogSource)
+		phaseCtx, log.WithName("phase-ensure-subscription"),
+		addon, subscriptionCatalogSource)
+	tracing.End(span, err)
 	if err != nil {
 		err = reconErr.Join(err, controllers.ErrReconcileSubscription)
 		return resultNil, err
@@ -84,9 +143,12 @@ func (r *olmReconciler) Reconcile(ctx context.Context,
 		return result, nil
 	}
 
-	// Phase 6
+	// Phase 8
 	// Observe operator API
-	if result, err := r.observeOperatorResource(ctx, addon, currentCSVKey); err != nil {
+	phaseCtx, span = startOLMPhase(ctx, "ObserveOperatorResource")
+	result, err = r.observeOperatorResource(phaseCtx, addon, currentCSVKey)
+	tracing.End(span, err)
+	if err != nil {
 		err = reconErr.Join(err, controllers.ErrObserveCSV)
 		return resultNil, err
 	} else if !result.IsZero() {
@@ -96,6 +158,11 @@ func (r *olmReconciler) Reconcile(ctx context.Context,
 	return resultNil, nil
 }
 
+// Starts the span of a single phase of the OLM install.
+func startOLMPhase(ctx context.Context, phase string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "OLM."+phase, attribute.String("olm.phase", phase))
+}
+
 func (r *olmReconciler) Name() string {
 	return OLM_RECONCILER_NAME
 }
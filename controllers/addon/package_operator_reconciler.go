@@ -29,7 +29,8 @@ spec:
   config:
     addonsv1: {{toJson (
 		merge
-			(.config | b64decMap)
+			(hasKey .config "%[14]s" | ternary (dict "%[4]s" (index .config "%[14]s")) (dict))
+			(omit .config "%[14]s" | b64decMap)
 			(hasKey .config "%[4]s" | ternary (dict "%[4]s" (index .config "%[4]s" | b64decMap)) (dict))
 			(hasKey .config "%[13]s" | ternary (dict "%[13]s" (index .config "%[13]s" | b64decMap)) (dict))
 			(dict "%[5]s" "%[6]s" "%[7]s" "%[8]s" "%[9]s" "%[10]s" "%[11]s" "%[12]s")
@@ -46,6 +47,7 @@ const (
 	ParametersConfigKey        = "parameters"
 	TargetNamespaceConfigKey   = "targetNamespace"
 	SendGridConfigKey          = "smtp"
+	SpecParametersConfigKey    = "specParameters"
 )
 
 type OcmClusterInfo struct {
@@ -110,6 +112,7 @@ func (r *PackageOperatorReconciler) reconcileClusterObjectTemplate(ctx context.C
 		OcmClusterNameConfigKey, ocmClusterInfo.Name,
 		TargetNamespaceConfigKey, addonDestNamespace,
 		SendGridConfigKey,
+		SpecParametersConfigKey,
 	)
 
 	clusterObjectTemplate := &pkov1alpha1.ClusterObjectTemplate{
@@ -175,6 +178,23 @@ func (r *PackageOperatorReconciler) reconcileClusterObjectTemplate(ctx context.C
 		},
 	}
 
+	if addon.Spec.Parameters != nil {
+		// Typed parameters are read from the Addon itself,
+		// the Secret only supports string values.
+		clusterObjectTemplate.Spec.Sources = append(clusterObjectTemplate.Spec.Sources,
+			pkov1alpha1.ObjectTemplateSource{
+				APIVersion: addonsv1alpha1.GroupVersion.String(),
+				Kind:       "Addon",
+				Name:       addon.Name,
+				Items: []pkov1alpha1.ObjectTemplateSourceItem{
+					{
+						Key:         ".spec.parameters",
+						Destination: "." + SpecParametersConfigKey,
+					},
+				},
+			})
+	}
+
 	if err := controllerutil.SetControllerReference(addon, clusterObjectTemplate, r.Scheme); err != nil {
 		newErr := reconErr.Join(
 			fmt.Errorf("setting owner reference: %w", err),
@@ -261,6 +281,10 @@ func extractDestinationNamespace(addon *addonsv1alpha1.Addon) string {
 		return specNamespace
 	case addonsv1alpha1.OLMOwnNamespace:
 		return addon.Spec.Install.OLMOwnNamespace.Namespace
+	case addonsv1alpha1.Helm:
+		return addon.Spec.Install.Helm.Namespace
+	case addonsv1alpha1.Manifests:
+		return addon.Spec.Install.Manifests.Namespace
 	default:
 		return ""
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,6 +18,8 @@ import (
 	"github.com/openshift/addon-operator/controllers/addon"
 	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
 	"github.com/openshift/addon-operator/internal/testutil"
+
+	pkov1alpha1 "package-operator.run/apis/core/v1alpha1"
 )
 
 var (
@@ -164,3 +167,38 @@ func TestPackageOperatorReconcilerLogic(t *testing.T) {
 		})
 	}
 }
+
+func TestPackageOperatorReconcilerParametersSource(t *testing.T) {
+	a := addonWithPKO.DeepCopy()
+	a.Spec.Parameters = &runtime.RawExtension{Raw: []byte(`{"replicas": 2}`)}
+
+	c := testutil.NewClient()
+	c.On("Get", testutil.IsContext, mock.Anything, mock.AnythingOfType("*v1alpha1.ClusterObjectTemplate"), mock.Anything).
+		Return(errors.NewNotFound(schema.GroupResource{}, "test"))
+	var created *pkov1alpha1.ClusterObjectTemplate
+	c.On("Create", testutil.IsContext, mock.AnythingOfType("*v1alpha1.ClusterObjectTemplate"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(1).(*pkov1alpha1.ClusterObjectTemplate)
+		}).
+		Return(nil)
+
+	r := &addon.PackageOperatorReconciler{
+		Client: c,
+		Scheme: testutil.NewTestSchemeWithAddonsv1alpha1(),
+		OcmClusterInfo: func() addon.OcmClusterInfo {
+			return addon.OcmClusterInfo{}
+		},
+	}
+	_, err := r.Reconcile(context.Background(), a)
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	require.Contains(t, created.Spec.Sources, pkov1alpha1.ObjectTemplateSource{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "Addon",
+		Name:       a.Name,
+		Items: []pkov1alpha1.ObjectTemplateSourceItem{
+			{Key: ".spec.parameters", Destination: "." + addon.SpecParametersConfigKey},
+		},
+	})
+}
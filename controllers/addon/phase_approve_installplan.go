@@ -0,0 +1,362 @@
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/maintenance"
+	"github.com/openshift/addon-operator/internal/ocm"
+)
+
+const (
+	// Interval to recheck InstallPlans that no approval rule matched yet.
+	installPlanApprovalRecheckInterval = time.Minute
+	// Number of InstallPlan approval decisions kept in the Addon status.
+	maxInstallPlanApprovalDecisions = 10
+)
+
+func installPlanApprovalPolicy(addon *addonsv1alpha1.Addon) *addonsv1alpha1.AddonInstallPlanApprovalPolicy {
+	return GetCommonInstallOptions(addon).InstallPlanApproval
+}
+
+// Returns the InstallPlanApproval of the Subscription.
+// While the Addon has an approval policy or maintenance windows, the addon-operator
+// approves InstallPlans itself and the Subscription is set to Manual approval.
+// The approval from before is recorded in the status and restored once
+// neither is configured anymore, then restored is set.
+// A Subscription pinned after a rollback stays Manual until the pin is released.
+func (r *olmReconciler) subscriptionInstallPlanApproval(
+	ctx context.Context, addon *addonsv1alpha1.Addon, namespace string,
+	approvedByOperator, pinned bool, approval operatorsv1alpha1.Approval,
+) (_ operatorsv1alpha1.Approval, restored bool, err error) {
+	overridden := operatorsv1alpha1.Approval(addon.Status.OverriddenInstallPlanApproval)
+	if !approvedByOperator {
+		if len(overridden) == 0 || pinned {
+			return approval, false, nil
+		}
+		return overridden, true, nil
+	}
+
+	if len(overridden) == 0 {
+		previous, err := r.installPlanApprovalBeforeOverride(ctx, addon, namespace)
+		if err != nil {
+			return "", false, err
+		}
+		addon.Status.OverriddenInstallPlanApproval = string(previous)
+	}
+	return operatorsv1alpha1.ApprovalManual, false, nil
+}
+
+func (r *olmReconciler) installPlanApprovalBeforeOverride(
+	ctx context.Context, addon *addonsv1alpha1.Addon, namespace string,
+) (operatorsv1alpha1.Approval, error) {
+	// A pinned Subscription is Manual already, the rollback recorded the approval before.
+	if rollback := addon.Status.UpgradeRollback; rollback != nil && len(rollback.InstallPlanApproval) > 0 {
+		return operatorsv1alpha1.Approval(rollback.InstallPlanApproval), nil
+	}
+
+	subscription, err := r.GetSubscription(ctx, SubscriptionName(addon), namespace)
+	if k8sApiErrors.IsNotFound(err) {
+		return operatorsv1alpha1.ApprovalAutomatic, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting Subscription: %w", err)
+	}
+	if approval := subscription.GetInstallPlanApproval(); len(approval) > 0 {
+		return approval, nil
+	}
+	return operatorsv1alpha1.ApprovalAutomatic, nil
+}
+
+// Approves the pending InstallPlan of the Subscription, when it is pinned after
+// a rollback or the InstallPlan matches the approval policy of the Addon
+// within its maintenance windows.
+// Runs before the Subscription reports an installed CSV,
+// so InstallPlans of the initial installation are approved, too.
+func (r *olmReconciler) ensureInstallPlanApproval(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	subscription *operatorsv1alpha1.Subscription,
+) error {
+	policy := installPlanApprovalPolicy(addon)
+	maintenanceSpec, err := r.addonMaintenance(ctx, addon)
+	if err != nil {
+		return err
+	}
+	if (policy == nil && maintenanceSpec == nil && addon.Status.UpgradeRollback == nil) ||
+		subscription.Status.InstallPlanRef == nil {
+		return nil
+	}
+
+	installPlan, err := r.GetInstallPlan(ctx,
+		subscription.Status.InstallPlanRef.Name,
+		subscription.Status.InstallPlanRef.Namespace,
+	)
+	if k8sApiErrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting InstallPlan: %w", err)
+	}
+	if installPlan.Spec.Approved ||
+		installPlan.Spec.Approval != operatorsv1alpha1.ApprovalManual {
+		return nil
+	}
+
+	var decision addonsv1alpha1.AddonInstallPlanApprovalDecision
+	if addon.Status.UpgradeRollback != nil {
+		decision = pinnedInstallPlanApproval(addon, installPlan)
+	} else {
+		decision, err = r.decideInstallPlanApproval(ctx, addon, policy, maintenanceSpec, subscription, installPlan)
+		if err != nil {
+			return err
+		}
+	}
+
+	if decision.Approved {
+		installPlan.Spec.Approved = true
+		if err := r.client.Update(ctx, installPlan); err != nil {
+			return fmt.Errorf("approving InstallPlan: %w", err)
+		}
+	}
+	r.recordInstallPlanApproval(addon, installPlan, decision)
+	return nil
+}
+
+// Approves the InstallPlan as soon as one rule of the policy matches,
+// while a maintenance window is open.
+func (r *olmReconciler) decideInstallPlanApproval(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	policy *addonsv1alpha1.AddonInstallPlanApprovalPolicy,
+	maintenanceSpec *addonsv1alpha1.AddonMaintenance,
+	subscription *operatorsv1alpha1.Subscription,
+	installPlan *operatorsv1alpha1.InstallPlan,
+) (addonsv1alpha1.AddonInstallPlanApprovalDecision, error) {
+	if len(subscription.Status.InstalledCSV) == 0 {
+		return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+			Approved: true,
+			Message:  "Initial installation.",
+		}, nil
+	}
+
+	if maintenanceSpec != nil {
+		open, nextOpen, err := r.maintenanceWindowOpen(addon, maintenanceSpec, "InstallPlan approval")
+		if err != nil {
+			return addonsv1alpha1.AddonInstallPlanApprovalDecision{}, err
+		}
+		switch {
+		case !open && nextOpen.IsZero():
+			return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+				Message: "No maintenance window opens anymore.",
+			}, nil
+		case !open:
+			return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+				Message: fmt.Sprintf("Waiting for the maintenance window opening at %s.", nextOpen.UTC().Format(time.RFC3339)),
+			}, nil
+		case policy == nil:
+			return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+				Approved: true,
+				Message:  "Maintenance window is open.",
+			}, nil
+		}
+	}
+
+	unmatched := make([]string, 0, len(policy.Rules))
+	for _, rule := range policy.Rules {
+		var (
+			matched bool
+			message string
+			err     error
+		)
+		switch rule.Type {
+		case addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion:
+			matched, message, err = r.isPatchVersionUpgrade(ctx, subscription, installPlan)
+		case addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow:
+			matched, message = r.isMaintenanceWindowOpen(rule.MaintenanceWindows)
+		case addonsv1alpha1.AddonInstallPlanApprovalRuleUpgradePolicyScheduled:
+			matched, message, err = r.isUpgradePolicyScheduled(ctx, addon)
+		default:
+			message = fmt.Sprintf("unknown rule type %q.", rule.Type)
+		}
+		if err != nil {
+			return addonsv1alpha1.AddonInstallPlanApprovalDecision{}, err
+		}
+		if matched {
+			return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+				Approved: true,
+				Rule:     rule.Type,
+				Message:  message,
+			}, nil
+		}
+		unmatched = append(unmatched, fmt.Sprintf("%s: %s", rule.Type, message))
+	}
+
+	return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+		Message: "No approval rule matched. " + strings.Join(unmatched, " "),
+	}, nil
+}
+
+func (r *olmReconciler) isPatchVersionUpgrade(
+	ctx context.Context,
+	subscription *operatorsv1alpha1.Subscription,
+	installPlan *operatorsv1alpha1.InstallPlan,
+) (bool, string, error) {
+	installed, err := r.installedCSVVersion(ctx, subscription)
+	if err != nil {
+		return false, "", err
+	}
+	upgrade, ok := installPlanCSVVersion(installPlan, subscription.Status.CurrentCSV)
+	if installed == nil || !ok {
+		return false, "version of the installed or new ClusterServiceVersion is unknown.", nil
+	}
+
+	if upgrade.Major != installed.Major ||
+		upgrade.Minor != installed.Minor ||
+		!upgrade.GT(*installed) {
+		return false, fmt.Sprintf("%s to %s is no patch version upgrade.", installed, upgrade), nil
+	}
+	return true, fmt.Sprintf("%s to %s is a patch version upgrade.", installed, upgrade), nil
+}
+
+func (r *olmReconciler) installedCSVVersion(
+	ctx context.Context, subscription *operatorsv1alpha1.Subscription,
+) (*semver.Version, error) {
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	err := r.uncachedClient.Get(ctx, client.ObjectKey{
+		Name:      subscription.Status.InstalledCSV,
+		Namespace: subscription.Namespace,
+	}, csv)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("getting ClusterServiceVersion: %w", err)
+	}
+	if err == nil && !csv.Spec.Version.Equals(semver.Version{}) {
+		return &csv.Spec.Version.Version, nil
+	}
+	if v, ok := csvVersionFromName(subscription.Status.InstalledCSV); ok {
+		return &v, nil
+	}
+	return nil, nil
+}
+
+// Reads the version of a CSV from its manifest in the InstallPlan.
+// Bundles unpacked by OLM only reference their manifests,
+// so this falls back to the version in the name of the CSV.
+func installPlanCSVVersion(installPlan *operatorsv1alpha1.InstallPlan, csvName string) (semver.Version, bool) {
+	for _, step := range installPlan.Status.Plan {
+		if step == nil ||
+			step.Resource.Kind != operatorsv1alpha1.ClusterServiceVersionKind ||
+			step.Resource.Name != csvName {
+			continue
+		}
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		if err := json.Unmarshal([]byte(step.Resource.Manifest), csv); err == nil &&
+			!csv.Spec.Version.Equals(semver.Version{}) {
+			return csv.Spec.Version.Version, true
+		}
+	}
+	return csvVersionFromName(csvName)
+}
+
+// CSV names follow the <package>.v<version> convention.
+// The package name may contain ".v" itself, e.g. "foo.vendor.v1.2.3".
+func csvVersionFromName(name string) (semver.Version, bool) {
+	i := strings.LastIndex(name, ".v")
+	if i == -1 {
+		return semver.Version{}, false
+	}
+	v, err := semver.ParseTolerant(name[i+len(".v"):])
+	if err != nil {
+		return semver.Version{}, false
+	}
+	return v, true
+}
+
+func (r *olmReconciler) isMaintenanceWindowOpen(windows []addonsv1alpha1.AddonMaintenanceWindow) (bool, string) {
+	now := r.clock.Now()
+
+	var nextOpen time.Time
+	for _, spec := range windows {
+		window, err := maintenance.NewWindow(spec)
+		if err != nil {
+			return false, fmt.Sprintf("invalid maintenance window: %s.", err)
+		}
+		if window.Open(now) {
+			return true, fmt.Sprintf("maintenance window %q is open.", spec.Schedule)
+		}
+		if next := window.NextOpen(now); nextOpen.IsZero() || next.Before(nextOpen) {
+			nextOpen = next
+		}
+	}
+	if nextOpen.IsZero() {
+		return false, "no maintenance window configured."
+	}
+	return false, fmt.Sprintf("next maintenance window opens at %s.", nextOpen.UTC().Format(time.RFC3339))
+}
+
+func (r *olmReconciler) isUpgradePolicyScheduled(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) (bool, string, error) {
+	if addon.Spec.UpgradePolicy == nil {
+		return false, "Addon has no UpgradePolicy.", nil
+	}
+	if r.upgradePolicyState == nil {
+		return false, "UpgradePolicy state is unknown.", nil
+	}
+
+	state, err := r.upgradePolicyState(ctx, addon.Spec.UpgradePolicy.ID)
+	if err != nil {
+		// OCM being unavailable just delays the approval.
+		controllers.LoggerFromContext(ctx).Error(err, "getting UpgradePolicy state")
+		return false, "UpgradePolicy state is unknown.", nil
+	}
+	if state != ocm.UpgradePolicyValueScheduled {
+		return false, fmt.Sprintf("UpgradePolicy is %q.", state), nil
+	}
+	return true, "UpgradePolicy is scheduled.", nil
+}
+
+// Records a decision in the Addon status and as Event,
+// unless it repeats the last decision.
+func (r *olmReconciler) recordInstallPlanApproval(
+	addon *addonsv1alpha1.Addon, installPlan *operatorsv1alpha1.InstallPlan,
+	decision addonsv1alpha1.AddonInstallPlanApprovalDecision,
+) {
+	decisions := addon.Status.InstallPlanApprovals
+	if n := len(decisions); n > 0 &&
+		decisions[n-1].InstallPlan == installPlan.Name &&
+		decisions[n-1].Approved == decision.Approved &&
+		decisions[n-1].Message == decision.Message {
+		return
+	}
+
+	decision.InstallPlan = installPlan.Name
+	decision.ClusterServiceVersions = installPlan.Spec.ClusterServiceVersionNames
+	decision.Time = metav1.NewTime(r.clock.Now())
+	decisions = append(decisions, decision)
+	if len(decisions) > maxInstallPlanApprovalDecisions {
+		decisions = decisions[len(decisions)-maxInstallPlanApprovalDecisions:]
+	}
+	addon.Status.InstallPlanApprovals = decisions
+
+	if r.eventRecorder == nil {
+		return
+	}
+	if decision.Approved {
+		r.eventRecorder.Eventf(addon, installPlan, corev1.EventTypeNormal,
+			"InstallPlanApproved", "Approve", "Approved InstallPlan %s: %s", installPlan.Name, decision.Message)
+		return
+	}
+	r.eventRecorder.Eventf(addon, installPlan, corev1.EventTypeNormal,
+		"InstallPlanApprovalDeferred", "Defer", "Deferred approval of InstallPlan %s: %s", installPlan.Name, decision.Message)
+}
@@ -0,0 +1,326 @@
+package addon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+func newTestApprovalPolicyAddon(rules ...addonsv1alpha1.AddonInstallPlanApprovalRule) *addonsv1alpha1.Addon {
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	addon.Spec.Install.OLMOwnNamespace.InstallPlanApproval = &addonsv1alpha1.AddonInstallPlanApprovalPolicy{
+		Rules: rules,
+	}
+	return addon
+}
+
+func newTestPendingSubscription(installedCSV, currentCSV string) *operatorsv1alpha1.Subscription {
+	return &operatorsv1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-addon-1", Namespace: "addon-1"},
+		Status: operatorsv1alpha1.SubscriptionStatus{
+			InstalledCSV:   installedCSV,
+			CurrentCSV:     currentCSV,
+			InstallPlanRef: &corev1.ObjectReference{Name: "install-abcde", Namespace: "addon-1"},
+		},
+	}
+}
+
+func mockPendingInstallPlan(c *testutil.Client, csvNames ...string) {
+	c.On("Get", testutil.IsContext,
+		client.ObjectKey{Name: "install-abcde", Namespace: "addon-1"},
+		mock.IsType(&operatorsv1alpha1.InstallPlan{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		ip := args.Get(2).(*operatorsv1alpha1.InstallPlan)
+		ip.Name = "install-abcde"
+		ip.Namespace = "addon-1"
+		ip.Spec.ClusterServiceVersionNames = csvNames
+		ip.Spec.Approval = operatorsv1alpha1.ApprovalManual
+	}).Return(nil)
+	c.On("Update", testutil.IsContext,
+		mock.IsType(&operatorsv1alpha1.InstallPlan{}), mock.Anything,
+	).Return(nil)
+}
+
+func TestEnsureInstallPlanApproval_NoPolicy(t *testing.T) {
+	c := testutil.NewClient()
+	r := newTestCanaryReconciler(c, testutil.NewClient(), time.Now())
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+
+	err := r.ensureInstallPlanApproval(context.Background(), addon,
+		newTestPendingSubscription("test.v1.0.0", "test.v1.1.0"))
+	require.NoError(t, err)
+	c.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, addon.Status.InstallPlanApprovals)
+}
+
+func TestEnsureInstallPlanApproval(t *testing.T) {
+	// Saturday noon.
+	now := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+	saturdays := []addonsv1alpha1.AddonMaintenanceWindow{{
+		Schedule: "0 10 * * 6",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}}
+	sundays := []addonsv1alpha1.AddonMaintenanceWindow{{
+		Schedule: "0 10 * * 0",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}}
+
+	for name, tc := range map[string]struct {
+		rules             []addonsv1alpha1.AddonInstallPlanApprovalRule
+		installedCSV      string
+		currentCSV        string
+		upgradePolicy     ocm.UpgradePolicyValue
+		approved          bool
+		rule              addonsv1alpha1.AddonInstallPlanApprovalRuleType
+		messageContaining string
+	}{
+		"initial installation": {
+			currentCSV: "test.v1.1.0",
+			approved:   true,
+		},
+		"patch version": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion},
+			},
+			installedCSV: "test.v1.0.0",
+			currentCSV:   "test.v1.0.1",
+			approved:     true,
+			rule:         addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion,
+		},
+		"minor version": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion},
+			},
+			installedCSV:      "test.v1.0.0",
+			currentCSV:        "test.v1.1.0",
+			messageContaining: "1.0.0 to 1.1.0 is no patch version upgrade.",
+		},
+		"maintenance window open": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion},
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow, MaintenanceWindows: saturdays},
+			},
+			installedCSV: "test.v1.0.0",
+			currentCSV:   "test.v1.1.0",
+			approved:     true,
+			rule:         addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow,
+		},
+		"maintenance window closed": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow, MaintenanceWindows: sundays},
+			},
+			installedCSV:      "test.v1.0.0",
+			currentCSV:        "test.v1.1.0",
+			messageContaining: "next maintenance window opens at 2024-03-10T10:00:00Z.",
+		},
+		"upgrade policy scheduled": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRuleUpgradePolicyScheduled},
+			},
+			installedCSV:  "test.v1.0.0",
+			currentCSV:    "test.v2.0.0",
+			upgradePolicy: ocm.UpgradePolicyValueScheduled,
+			approved:      true,
+			rule:          addonsv1alpha1.AddonInstallPlanApprovalRuleUpgradePolicyScheduled,
+		},
+		"upgrade policy completed": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRuleUpgradePolicyScheduled},
+			},
+			installedCSV:      "test.v1.0.0",
+			currentCSV:        "test.v2.0.0",
+			upgradePolicy:     ocm.UpgradePolicyValueCompleted,
+			messageContaining: `UpgradePolicy is "completed".`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			uncachedC := testutil.NewClient()
+			r := newTestCanaryReconciler(c, uncachedC, now)
+			recorder := events.NewFakeRecorder(1)
+			r.eventRecorder = recorder
+			r.upgradePolicyState = func(_ context.Context, id string) (ocm.UpgradePolicyValue, error) {
+				assert.Equal(t, "1234", id)
+				return tc.upgradePolicy, nil
+			}
+
+			addon := newTestApprovalPolicyAddon(tc.rules...)
+			addon.Spec.UpgradePolicy = &addonsv1alpha1.AddonUpgradePolicy{ID: "1234"}
+			mockPendingInstallPlan(c, tc.currentCSV)
+			uncachedC.On("Get", testutil.IsContext, mock.Anything,
+				mock.IsType(&operatorsv1alpha1.ClusterServiceVersion{}), mock.Anything,
+			).Return(testutil.NewTestErrNotFound())
+
+			err := r.ensureInstallPlanApproval(context.Background(), addon,
+				newTestPendingSubscription(tc.installedCSV, tc.currentCSV))
+			require.NoError(t, err)
+
+			if tc.approved {
+				c.AssertCalled(t, "Update", testutil.IsContext,
+					mock.MatchedBy(func(ip *operatorsv1alpha1.InstallPlan) bool {
+						return ip.Spec.Approved
+					}), mock.Anything)
+			} else {
+				c.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			require.Len(t, addon.Status.InstallPlanApprovals, 1)
+			decision := addon.Status.InstallPlanApprovals[0]
+			assert.Equal(t, "install-abcde", decision.InstallPlan)
+			assert.Equal(t, []string{tc.currentCSV}, decision.ClusterServiceVersions)
+			assert.Equal(t, tc.approved, decision.Approved)
+			assert.Equal(t, tc.rule, decision.Rule)
+			assert.Contains(t, decision.Message, tc.messageContaining)
+			assert.True(t, now.Equal(decision.Time.Time))
+
+			event := <-recorder.Events
+			if tc.approved {
+				assert.Contains(t, event, "Normal InstallPlanApproved")
+			} else {
+				assert.Contains(t, event, "Normal InstallPlanApprovalDeferred")
+			}
+		})
+	}
+}
+
+func TestRecordInstallPlanApproval(t *testing.T) {
+	start := time.Now()
+	r := newTestCanaryReconciler(testutil.NewClient(), testutil.NewClient(), start)
+	recorder := events.NewFakeRecorder(maxInstallPlanApprovalDecisions + 2)
+	r.eventRecorder = recorder
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	installPlan := &operatorsv1alpha1.InstallPlan{ObjectMeta: metav1.ObjectMeta{Name: "install-abcde"}}
+
+	deferred := addonsv1alpha1.AddonInstallPlanApprovalDecision{Message: "No approval rule matched."}
+	r.recordInstallPlanApproval(addon, installPlan, deferred)
+	// Repeated decisions are not recorded again.
+	r.recordInstallPlanApproval(addon, installPlan, deferred)
+	assert.Len(t, addon.Status.InstallPlanApprovals, 1)
+	assert.Len(t, recorder.Events, 1)
+
+	for i := 0; i < maxInstallPlanApprovalDecisions; i++ {
+		installPlan := &operatorsv1alpha1.InstallPlan{ObjectMeta: metav1.ObjectMeta{Name: "install-" + string(rune('a'+i))}}
+		r.recordInstallPlanApproval(addon, installPlan, addonsv1alpha1.AddonInstallPlanApprovalDecision{
+			Approved: true,
+			Message:  "Initial installation.",
+		})
+	}
+	require.Len(t, addon.Status.InstallPlanApprovals, maxInstallPlanApprovalDecisions)
+	assert.Equal(t, "install-a", addon.Status.InstallPlanApprovals[0].InstallPlan)
+	assert.Equal(t, "install-j", addon.Status.InstallPlanApprovals[maxInstallPlanApprovalDecisions-1].InstallPlan)
+}
+
+func TestInstallPlanCSVVersion(t *testing.T) {
+	installPlan := &operatorsv1alpha1.InstallPlan{
+		Status: operatorsv1alpha1.InstallPlanStatus{
+			Plan: []*operatorsv1alpha1.Step{
+				{
+					Resource: operatorsv1alpha1.StepResource{
+						Kind:     operatorsv1alpha1.ClusterServiceVersionKind,
+						Name:     "test-operator",
+						Manifest: `{"kind":"ClusterServiceVersion","spec":{"version":"1.2.3"}}`,
+					},
+				},
+			},
+		},
+	}
+
+	v, ok := installPlanCSVVersion(installPlan, "test-operator")
+	require.True(t, ok)
+	assert.Equal(t, semver.MustParse("1.2.3"), v)
+
+	v, ok = installPlanCSVVersion(installPlan, "test.v2.0.0")
+	require.True(t, ok)
+	assert.Equal(t, semver.MustParse("2.0.0"), v)
+
+	v, ok = installPlanCSVVersion(installPlan, "foo.vendor.v1.2.3")
+	require.True(t, ok)
+	assert.Equal(t, semver.MustParse("1.2.3"), v)
+
+	_, ok = installPlanCSVVersion(installPlan, "test")
+	assert.False(t, ok)
+}
+
+func TestSubscriptionInstallPlanApproval(t *testing.T) {
+	mockSubscription := func(c *testutil.Client, approval operatorsv1alpha1.Approval) {
+		c.On("Get", testutil.IsContext,
+			client.ObjectKey{Name: "addon-addon-1", Namespace: "addon-1"},
+			mock.IsType(&operatorsv1alpha1.Subscription{}), mock.Anything,
+		).Run(func(args mock.Arguments) {
+			sub := args.Get(2).(*operatorsv1alpha1.Subscription)
+			sub.Spec = &operatorsv1alpha1.SubscriptionSpec{InstallPlanApproval: approval}
+		}).Return(nil)
+	}
+
+	t.Run("records the approval before the override", func(t *testing.T) {
+		c := testutil.NewClient()
+		mockSubscription(c, operatorsv1alpha1.ApprovalAutomatic)
+		r := newTestCanaryReconciler(c, testutil.NewClient(), time.Now())
+		addon := newTestApprovalPolicyAddon()
+
+		approval, restored, err := r.subscriptionInstallPlanApproval(
+			context.Background(), addon, "addon-1", true, false, "")
+		require.NoError(t, err)
+		assert.Equal(t, operatorsv1alpha1.ApprovalManual, approval)
+		assert.False(t, restored)
+		assert.Equal(t, string(operatorsv1alpha1.ApprovalAutomatic), addon.Status.OverriddenInstallPlanApproval)
+
+		// The recorded approval is kept while the override lasts.
+		_, _, err = r.subscriptionInstallPlanApproval(context.Background(), addon, "addon-1", true, false, "")
+		require.NoError(t, err)
+		c.AssertNumberOfCalls(t, "Get", 1)
+	})
+
+	t.Run("restores the approval without policy", func(t *testing.T) {
+		c := testutil.NewClient()
+		r := newTestCanaryReconciler(c, testutil.NewClient(), time.Now())
+		addon := testutil.NewTestAddonWithCatalogSourceImage()
+		addon.Status.OverriddenInstallPlanApproval = string(operatorsv1alpha1.ApprovalAutomatic)
+
+		approval, restored, err := r.subscriptionInstallPlanApproval(
+			context.Background(), addon, "addon-1", false, false, "")
+		require.NoError(t, err)
+		assert.Equal(t, operatorsv1alpha1.ApprovalAutomatic, approval)
+		assert.True(t, restored)
+	})
+
+	t.Run("keeps a pinned subscription manual", func(t *testing.T) {
+		c := testutil.NewClient()
+		r := newTestCanaryReconciler(c, testutil.NewClient(), time.Now())
+		addon := testutil.NewTestAddonWithCatalogSourceImage()
+		addon.Status.OverriddenInstallPlanApproval = string(operatorsv1alpha1.ApprovalAutomatic)
+
+		approval, restored, err := r.subscriptionInstallPlanApproval(
+			context.Background(), addon, "addon-1", false, true, operatorsv1alpha1.ApprovalManual)
+		require.NoError(t, err)
+		assert.Equal(t, operatorsv1alpha1.ApprovalManual, approval)
+		assert.False(t, restored)
+	})
+
+	t.Run("leaves the approval unmanaged without override", func(t *testing.T) {
+		c := testutil.NewClient()
+		r := newTestCanaryReconciler(c, testutil.NewClient(), time.Now())
+		addon := testutil.NewTestAddonWithCatalogSourceImage()
+
+		approval, restored, err := r.subscriptionInstallPlanApproval(
+			context.Background(), addon, "addon-1", false, false, "")
+		require.NoError(t, err)
+		assert.Empty(t, approval)
+		assert.False(t, restored)
+		c.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
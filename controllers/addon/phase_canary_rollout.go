@@ -0,0 +1,457 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+)
+
+const (
+	defaultCanaryBakeTime         = 10 * time.Minute
+	defaultCanaryProgressDeadline = 30 * time.Minute
+)
+
+// prometheusClient evaluates the PromQL health gate of canary rollouts.
+type prometheusClient interface {
+	Healthy(ctx context.Context, query string) (bool, error)
+}
+
+func CanaryCatalogSourceName(addon *addonsv1alpha1.Addon) string {
+	return CatalogSourceName(addon) + "-canary"
+}
+
+func isCanaryUpgrade(addon *addonsv1alpha1.Addon) bool {
+	return addon.Spec.UpgradeStrategy != nil &&
+		addon.Spec.UpgradeStrategy.Type == addonsv1alpha1.AddonUpgradeStrategyCanary
+}
+
+// Returns the image of the main CatalogSource of the Addon.
+// While a canary rollout is in progress, the main CatalogSource
+// keeps serving the last good catalog image.
+func stableCatalogSourceImage(addon *addonsv1alpha1.Addon, commonConfig *addonsv1alpha1.AddonInstallOLMCommon) string {
+	if isCanaryUpgrade(addon) && addon.Status.CanaryRollout != nil {
+		return addon.Status.CanaryRollout.StableCatalogSourceImage
+	}
+	return commonConfig.CatalogSourceImage
+}
+
+// Starts a canary rollout, when the catalog image of the Addon changed
+// compared to the image of the existing CatalogSource.
+// Must run before the main CatalogSource is reconciled,
+// which would otherwise be updated to the new image right away.
+func (r *olmReconciler) observeCanaryRollout(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) (subReconcilerResult, error) {
+	if !isCanaryUpgrade(addon) {
+		return resultNil, nil
+	}
+	log := controllers.LoggerFromContext(ctx)
+
+	commonConfig, stop := parseAddonInstallConfig(log, addon)
+	if stop {
+		return resultStop, nil
+	}
+	image := commonConfig.CatalogSourceImage
+
+	if rollout := addon.Status.CanaryRollout; rollout != nil {
+		switch image {
+		case rollout.CatalogSourceImage:
+			// Rollout in progress or concluded by a rollback.
+		case rollout.StableCatalogSourceImage:
+			// The catalog image was reverted, nothing to roll out anymore.
+			addon.Status.CanaryRollout = nil
+		default:
+			// The catalog image changed again before the rollout concluded,
+			// start over from the last good image.
+			r.startCanaryRollout(addon, rollout.StableCatalogSourceImage, rollout.StableCSV, image)
+		}
+		return resultNil, nil
+	}
+
+	currentCatalogSource := &operatorsv1alpha1.CatalogSource{}
+	err := r.client.Get(ctx, client.ObjectKey{
+		Name:      CatalogSourceName(addon),
+		Namespace: commonConfig.Namespace,
+	}, currentCatalogSource)
+	if k8sApiErrors.IsNotFound(err) {
+		// Initial installation, nothing to roll out.
+		return resultNil, nil
+	}
+	if err != nil {
+		return resultNil, fmt.Errorf("getting CatalogSource: %w", err)
+	}
+
+	if len(currentCatalogSource.Spec.Image) == 0 || currentCatalogSource.Spec.Image == image {
+		return resultNil, nil
+	}
+
+	// The main CatalogSource keeps the current image,
+	// until the rollout can start in a maintenance window.
+	maintenanceSpec, err := r.addonMaintenance(ctx, addon)
+	if err != nil {
+		return resultNil, err
+	}
+	if maintenanceSpec != nil {
+		if open, _, err := r.maintenanceWindowOpen(addon, maintenanceSpec, "catalog image change"); err != nil || !open {
+			return resultNil, err
+		}
+	}
+	r.startCanaryRollout(addon, currentCatalogSource.Spec.Image, addon.Status.LastObservedAvailableCSV, image)
+	return resultNil, nil
+}
+
+func (r *olmReconciler) startCanaryRollout(
+	addon *addonsv1alpha1.Addon, stableImage, stableCSV, image string,
+) {
+	addon.Status.CanaryRollout = &addonsv1alpha1.AddonCanaryRolloutStatus{
+		CatalogSourceImage:       image,
+		StableCatalogSourceImage: stableImage,
+		StableCSV:                stableCSV,
+	}
+	meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.RolledBack)
+	r.setCanaryRolloutPhase(addon, addonsv1alpha1.AddonCanaryRolloutPhaseBaking,
+		fmt.Sprintf("Baking catalog image %s.", image))
+}
+
+// Drives a canary rollout through its phases and
+// returns the CatalogSource the Subscription has to point to.
+// Waiting for the bake time or health gates does not block reconciliation
+// of the Addon, the rollout is polled while in progress instead.
+func (r *olmReconciler) ensureCanaryRollout(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	catalogSource *operatorsv1alpha1.CatalogSource,
+) (subReconcilerResult, *operatorsv1alpha1.CatalogSource, error) {
+	if !isCanaryUpgrade(addon) {
+		addon.Status.CanaryRollout = nil
+		return resultNil, catalogSource, nil
+	}
+
+	rollout := addon.Status.CanaryRollout
+	if rollout == nil ||
+		rollout.Phase == addonsv1alpha1.AddonCanaryRolloutPhaseRolledBack {
+		// Clean up after a concluded rollout.
+		if err := r.deleteCanaryCatalogSource(ctx, addon, catalogSource.Namespace); err != nil {
+			return resultNil, nil, err
+		}
+		return resultNil, catalogSource, nil
+	}
+
+	strategy := addon.Spec.UpgradeStrategy.Canary
+	if strategy == nil {
+		strategy = &addonsv1alpha1.AddonCanaryUpgradeStrategy{}
+	}
+
+	canaryCatalogSource, ready, err := r.ensureCanaryCatalogSource(ctx, addon, catalogSource)
+	if err != nil {
+		return resultNil, nil, err
+	}
+
+	if rollout.Phase == addonsv1alpha1.AddonCanaryRolloutPhaseBaking {
+		if !ready {
+			rollout.Message = fmt.Sprintf("Baking catalog image %s: CatalogSource is not ready.",
+				rollout.CatalogSourceImage)
+			return resultNil, catalogSource, nil
+		}
+
+		bakedAt := rollout.LastTransitionTime.Add(canaryBakeTime(strategy))
+		if r.clock.Now().Before(bakedAt) {
+			rollout.Message = fmt.Sprintf("Baking catalog image %s until %s.",
+				rollout.CatalogSourceImage, bakedAt.UTC().Format(time.RFC3339))
+			return resultNil, catalogSource, nil
+		}
+
+		// Don't upgrade an Addon that is unhealthy already,
+		// it would be impossible to tell whether the new version is to blame.
+		gates, err := r.checkCanaryHealthGates(ctx, addon, strategy, false)
+		if err != nil {
+			return resultNil, nil, err
+		}
+		if !gates.passed() {
+			rollout.Message = fmt.Sprintf("Baked catalog image %s, waiting for the Addon to be healthy: %s",
+				rollout.CatalogSourceImage, gates.message)
+			return resultNil, catalogSource, nil
+		}
+
+		r.setCanaryRolloutPhase(addon, addonsv1alpha1.AddonCanaryRolloutPhaseVerifying,
+			fmt.Sprintf("Moved Subscription to catalog image %s.", rollout.CatalogSourceImage))
+		reportAddonUpgradeStarted(addon)
+		return resultNil, canaryCatalogSource, nil
+	}
+
+	// Verifying
+	gates, err := r.checkCanaryHealthGates(ctx, addon, strategy, true)
+	if err != nil {
+		return resultNil, nil, err
+	}
+	switch {
+	case ready && gates.passed():
+		// The main CatalogSource picks up the new image on the next reconcile
+		// and the Subscription is moved back to it.
+		addon.Status.CanaryRollout = nil
+		reportAddonUpgradeSucceeded(addon)
+		return resultRequeue, canaryCatalogSource, nil
+
+	case gates.failed:
+		return r.rollbackCanary(ctx, addon, catalogSource, addonsv1alpha1.AddonReasonCSVFailed, gates.message)
+
+	case !r.clock.Now().Before(rollout.LastTransitionTime.Add(canaryProgressDeadline(strategy))):
+		return r.rollbackCanary(ctx, addon, catalogSource, addonsv1alpha1.AddonReasonProgressDeadlineExceeded,
+			fmt.Sprintf("progress deadline exceeded, %s", gates.message))
+	}
+
+	rollout.Message = fmt.Sprintf("Verifying catalog image %s: %s", rollout.CatalogSourceImage, gates.message)
+	if !ready {
+		rollout.Message = fmt.Sprintf("Verifying catalog image %s: CatalogSource is not ready.",
+			rollout.CatalogSourceImage)
+	}
+	return resultNil, canaryCatalogSource, nil
+}
+
+// Reports whether bake time or health gates of a canary rollout have to be polled.
+func canaryRolloutInProgress(addon *addonsv1alpha1.Addon) bool {
+	return isCanaryUpgrade(addon) && addon.Status.CanaryRollout != nil &&
+		addon.Status.CanaryRollout.Phase != addonsv1alpha1.AddonCanaryRolloutPhaseRolledBack
+}
+
+// Moves the Subscription back to the main CatalogSource, which still serves the last good image.
+// OLM does not downgrade operators, so the new CSV and the Subscription are deleted
+// and the Subscription is recreated starting at the CSV that was available before the rollout.
+func (r *olmReconciler) rollbackCanary(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	catalogSource *operatorsv1alpha1.CatalogSource, reason, cause string,
+) (subReconcilerResult, *operatorsv1alpha1.CatalogSource, error) {
+	log := controllers.LoggerFromContext(ctx)
+	rollout := addon.Status.CanaryRollout
+
+	subscription, err := r.GetSubscription(ctx, SubscriptionName(addon), catalogSource.Namespace)
+	if client.IgnoreNotFound(err) != nil {
+		return resultNil, nil, fmt.Errorf("getting Subscription: %w", err)
+	}
+	if err == nil {
+		installedCSV := subscription.Status.InstalledCSV
+		if len(installedCSV) > 0 && installedCSV != canaryStableCSVName(addon) {
+			csv := &operatorsv1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      installedCSV,
+					Namespace: subscription.Namespace,
+				},
+			}
+			if err := r.client.Delete(ctx, csv); client.IgnoreNotFound(err) != nil {
+				return resultNil, nil, fmt.Errorf("deleting ClusterServiceVersion: %w", err)
+			}
+		}
+		if err := r.client.Delete(ctx, subscription); client.IgnoreNotFound(err) != nil {
+			return resultNil, nil, fmt.Errorf("deleting Subscription: %w", err)
+		}
+	}
+
+	message := fmt.Sprintf("Rolled back catalog image %s to %s: %s",
+		rollout.CatalogSourceImage, rollout.StableCatalogSourceImage, cause)
+	log.Info("rolled back canary", "reason", reason, "cause", cause)
+	r.setCanaryRolloutPhase(addon, addonsv1alpha1.AddonCanaryRolloutPhaseRolledBack, message)
+	reportAddonUpgradeRolledBack(addon, reason, message)
+	return resultRequeue, catalogSource, nil
+}
+
+func (r *olmReconciler) setCanaryRolloutPhase(
+	addon *addonsv1alpha1.Addon, phase addonsv1alpha1.AddonCanaryRolloutPhase, message string,
+) {
+	rollout := addon.Status.CanaryRollout
+	rollout.Phase = phase
+	rollout.Message = message
+	rollout.LastTransitionTime = metav1.NewTime(r.clock.Now())
+}
+
+// Ensures the CatalogSource of the catalog image being rolled out,
+// next to the main CatalogSource of the Addon.
+func (r *olmReconciler) ensureCanaryCatalogSource(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	catalogSource *operatorsv1alpha1.CatalogSource,
+) (*operatorsv1alpha1.CatalogSource, bool, error) {
+	canaryCatalogSource := &operatorsv1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CanaryCatalogSourceName(addon),
+			Namespace: catalogSource.Namespace,
+		},
+		Spec: *catalogSource.Spec.DeepCopy(),
+	}
+	canaryCatalogSource.Spec.Image = addon.Status.CanaryRollout.CatalogSourceImage
+
+	controllers.AddCommonLabels(canaryCatalogSource, addon)
+	controllers.AddCommonAnnotations(canaryCatalogSource, addon)
+	if err := controllerutil.SetControllerReference(addon, canaryCatalogSource, r.scheme); err != nil {
+		return nil, false, err
+	}
+
+	observed, err := reconcileCatalogSource(ctx, r.client, canaryCatalogSource)
+	if err != nil {
+		return nil, false, fmt.Errorf("reconciling canary CatalogSource: %w", err)
+	}
+	ready := observed.Status.GRPCConnectionState != nil &&
+		observed.Status.GRPCConnectionState.LastObservedState == "READY"
+	return observed, ready, nil
+}
+
+func (r *olmReconciler) deleteCanaryCatalogSource(
+	ctx context.Context, addon *addonsv1alpha1.Addon, namespace string,
+) error {
+	canaryCatalogSource := &operatorsv1alpha1.CatalogSource{}
+	err := r.client.Get(ctx, client.ObjectKey{
+		Name:      CanaryCatalogSourceName(addon),
+		Namespace: namespace,
+	}, canaryCatalogSource)
+	if k8sApiErrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting canary CatalogSource: %w", err)
+	}
+	if !metav1.IsControlledBy(canaryCatalogSource, addon) {
+		return nil
+	}
+	if err := r.client.Delete(ctx, canaryCatalogSource); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("deleting canary CatalogSource: %w", err)
+	}
+	return nil
+}
+
+type canaryHealthGates struct {
+	// All gates passed.
+	healthy bool
+	// A gate failed permanently, there is no point in waiting any longer.
+	failed bool
+	// Describes the first gate that did not pass.
+	message string
+}
+
+func (g canaryHealthGates) passed() bool {
+	return g.healthy && !g.failed
+}
+
+// Checks the CSV, AddonInstance and PromQL health gates.
+// When newCSV is set, the CSV installed by the Subscription has to differ from
+// the one that was available before the rollout.
+func (r *olmReconciler) checkCanaryHealthGates(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	strategy *addonsv1alpha1.AddonCanaryUpgradeStrategy, newCSV bool,
+) (canaryHealthGates, error) {
+	commonInstallOptions := GetCommonInstallOptions(addon)
+
+	subscription, err := r.GetSubscription(ctx, SubscriptionName(addon), commonInstallOptions.Namespace)
+	if k8sApiErrors.IsNotFound(err) {
+		return canaryHealthGates{message: "Subscription not found."}, nil
+	}
+	if err != nil {
+		return canaryHealthGates{}, fmt.Errorf("getting Subscription: %w", err)
+	}
+	installedCSV := subscription.Status.InstalledCSV
+	if len(installedCSV) == 0 ||
+		(newCSV && installedCSV == canaryStableCSVName(addon)) {
+		return canaryHealthGates{message: "waiting for the new ClusterServiceVersion to be installed."}, nil
+	}
+
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	if err := r.uncachedClient.Get(ctx, client.ObjectKey{
+		Name:      installedCSV,
+		Namespace: subscription.Namespace,
+	}, csv); k8sApiErrors.IsNotFound(err) {
+		return canaryHealthGates{message: fmt.Sprintf("ClusterServiceVersion %s not found.", installedCSV)}, nil
+	} else if err != nil {
+		return canaryHealthGates{}, fmt.Errorf("getting ClusterServiceVersion: %w", err)
+	}
+	switch csv.Status.Phase {
+	case operatorsv1alpha1.CSVPhaseSucceeded:
+	case operatorsv1alpha1.CSVPhaseFailed:
+		return canaryHealthGates{
+			failed:  true,
+			message: fmt.Sprintf("ClusterServiceVersion %s failed: %s", installedCSV, csv.Status.Message),
+		}, nil
+	default:
+		return canaryHealthGates{
+			message: fmt.Sprintf("ClusterServiceVersion %s is in phase %q.", installedCSV, csv.Status.Phase),
+		}, nil
+	}
+
+	if !strategy.SkipAddonInstanceHealthCheck {
+		addonInstance := &addonsv1alpha1.AddonInstance{}
+		if err := r.client.Get(ctx, client.ObjectKey{
+			Name:      addonsv1alpha1.DefaultAddonInstanceName,
+			Namespace: commonInstallOptions.Namespace,
+		}, addonInstance); client.IgnoreNotFound(err) != nil {
+			return canaryHealthGates{}, fmt.Errorf("getting AddonInstance: %w", err)
+		}
+		if !meta.IsStatusConditionTrue(
+			addonInstance.Status.Conditions, addonsv1alpha1.AddonInstanceConditionHealthy.String()) {
+			return canaryHealthGates{message: "AddonInstance is not reporting Healthy."}, nil
+		}
+	}
+
+	if len(strategy.PrometheusQuery) > 0 {
+		if r.prometheusClient == nil {
+			return canaryHealthGates{message: "no Prometheus API configured to run the health query against."}, nil
+		}
+		healthy, err := r.prometheusClient.Healthy(ctx, strategy.PrometheusQuery)
+		if err != nil {
+			// Monitoring being unavailable is no reason to fail the reconcile,
+			// the gate just does not pass.
+			return canaryHealthGates{message: fmt.Sprintf("running Prometheus health query: %s", err)}, nil
+		}
+		if !healthy {
+			return canaryHealthGates{message: "Prometheus health query returned no non-zero sample."}, nil
+		}
+	}
+
+	return canaryHealthGates{healthy: true, message: "all health gates passed."}, nil
+}
+
+// Name of the CSV that was available before the rollout started.
+func canaryStableCSVName(addon *addonsv1alpha1.Addon) string {
+	if addon.Status.CanaryRollout == nil {
+		return ""
+	}
+	return csvNameFromKey(addon.Status.CanaryRollout.StableCSV)
+}
+
+// CSVs are recorded in the Addon status as namespaced name.
+func csvNameFromKey(key string) string {
+	_, name, found := strings.Cut(key, string(types.Separator))
+	if !found {
+		return key
+	}
+	return name
+}
+
+// CSV the Subscription has to start at after a rollback.
+func canaryRollbackStartingCSV(addon *addonsv1alpha1.Addon) string {
+	if !isCanaryUpgrade(addon) || addon.Status.CanaryRollout == nil ||
+		addon.Status.CanaryRollout.Phase != addonsv1alpha1.AddonCanaryRolloutPhaseRolledBack {
+		return ""
+	}
+	return canaryStableCSVName(addon)
+}
+
+func canaryBakeTime(strategy *addonsv1alpha1.AddonCanaryUpgradeStrategy) time.Duration {
+	if strategy.BakeTime.Duration > 0 {
+		return strategy.BakeTime.Duration
+	}
+	return defaultCanaryBakeTime
+}
+
+func canaryProgressDeadline(strategy *addonsv1alpha1.AddonCanaryUpgradeStrategy) time.Duration {
+	if strategy.ProgressDeadline.Duration > 0 {
+		return strategy.ProgressDeadline.Duration
+	}
+	return defaultCanaryProgressDeadline
+}
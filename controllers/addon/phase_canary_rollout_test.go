@@ -0,0 +1,357 @@
+package addon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+const (
+	testStableCatalogImage = "quay.io/osd-addons/test:v1"
+	testCanaryCatalogImage = "quay.io/osd-addons/test:v2"
+)
+
+type prometheusClientMock struct {
+	mock.Mock
+}
+
+func (m *prometheusClientMock) Healthy(ctx context.Context, query string) (bool, error) {
+	args := m.Called(ctx, query)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestCanaryAddon(phase addonsv1alpha1.AddonCanaryRolloutPhase, since time.Time) *addonsv1alpha1.Addon {
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	addon.Spec.Install.OLMOwnNamespace.CatalogSourceImage = testCanaryCatalogImage
+	addon.Spec.UpgradeStrategy = &addonsv1alpha1.AddonUpgradeStrategy{
+		Type: addonsv1alpha1.AddonUpgradeStrategyCanary,
+		Canary: &addonsv1alpha1.AddonCanaryUpgradeStrategy{
+			BakeTime:         metav1.Duration{Duration: 10 * time.Minute},
+			ProgressDeadline: metav1.Duration{Duration: 30 * time.Minute},
+		},
+	}
+	if len(phase) > 0 {
+		addon.Status.CanaryRollout = &addonsv1alpha1.AddonCanaryRolloutStatus{
+			Phase:                    phase,
+			CatalogSourceImage:       testCanaryCatalogImage,
+			StableCatalogSourceImage: testStableCatalogImage,
+			StableCSV:                "addon-1/test.v1",
+			LastTransitionTime:       metav1.NewTime(since),
+		}
+	}
+	return addon
+}
+
+func newTestCanaryReconciler(c, uncachedC *testutil.Client, now time.Time) *olmReconciler {
+	clock := &testClock{}
+	clock.On("Now").Return(now)
+	return &olmReconciler{
+		client:         c,
+		uncachedClient: uncachedC,
+		scheme:         testutil.NewTestSchemeWithAddonsv1alpha1(),
+		clock:          clock,
+	}
+}
+
+func newTestMainCatalogSource(addon *addonsv1alpha1.Addon) *operatorsv1alpha1.CatalogSource {
+	return &operatorsv1alpha1.CatalogSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CatalogSourceName(addon),
+			Namespace: "addon-1",
+		},
+		Spec: operatorsv1alpha1.CatalogSourceSpec{
+			SourceType: operatorsv1alpha1.SourceTypeGrpc,
+			Image:      testStableCatalogImage,
+		},
+	}
+}
+
+// Mocks the canary CatalogSource, which reconcileCatalogSource updates to the desired spec.
+func mockCanaryCatalogSource(c *testutil.Client, addon *addonsv1alpha1.Addon, state string) {
+	c.On("Get", testutil.IsContext,
+		client.ObjectKey{Name: CanaryCatalogSourceName(addon), Namespace: "addon-1"},
+		mock.IsType(&operatorsv1alpha1.CatalogSource{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		cs := args.Get(2).(*operatorsv1alpha1.CatalogSource)
+		cs.Name = CanaryCatalogSourceName(addon)
+		cs.Namespace = "addon-1"
+		cs.Status.GRPCConnectionState = &operatorsv1alpha1.GRPCConnectionState{LastObservedState: state}
+	}).Return(nil)
+	c.On("Update", testutil.IsContext,
+		mock.IsType(&operatorsv1alpha1.CatalogSource{}), mock.Anything,
+	).Return(nil)
+}
+
+// Mocks all health gates of the installed CSV.
+func mockCanaryHealthGates(
+	c, uncachedC *testutil.Client, installedCSV string,
+	csvPhase operatorsv1alpha1.ClusterServiceVersionPhase, addonInstanceHealthy bool,
+) {
+	c.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&operatorsv1alpha1.Subscription{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		sub := args.Get(2).(*operatorsv1alpha1.Subscription)
+		sub.Name = "addon-addon-1"
+		sub.Namespace = "addon-1"
+		sub.Status.InstalledCSV = installedCSV
+	}).Return(nil)
+	uncachedC.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&operatorsv1alpha1.ClusterServiceVersion{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		csv := args.Get(2).(*operatorsv1alpha1.ClusterServiceVersion)
+		csv.Status.Phase = csvPhase
+	}).Return(nil)
+	c.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&addonsv1alpha1.AddonInstance{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		if !addonInstanceHealthy {
+			return
+		}
+		instance := args.Get(2).(*addonsv1alpha1.AddonInstance)
+		meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:   addonsv1alpha1.AddonInstanceConditionHealthy.String(),
+			Status: metav1.ConditionTrue,
+		})
+	}).Return(nil)
+}
+
+func TestObserveCanaryRollout_Start(t *testing.T) {
+	c := testutil.NewClient()
+	now := time.Now()
+	r := newTestCanaryReconciler(c, testutil.NewClient(), now)
+	addon := newTestCanaryAddon("", now)
+	addon.Status.LastObservedAvailableCSV = "addon-1/test.v1"
+
+	c.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&operatorsv1alpha1.CatalogSource{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		newTestMainCatalogSource(addon).DeepCopyInto(args.Get(2).(*operatorsv1alpha1.CatalogSource))
+	}).Return(nil)
+
+	result, err := r.observeCanaryRollout(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+
+	rollout := addon.Status.CanaryRollout
+	require.NotNil(t, rollout)
+	assert.Equal(t, addonsv1alpha1.AddonCanaryRolloutPhaseBaking, rollout.Phase)
+	assert.Equal(t, testStableCatalogImage, rollout.StableCatalogSourceImage)
+	assert.Equal(t, testCanaryCatalogImage, rollout.CatalogSourceImage)
+	assert.Equal(t, "addon-1/test.v1", rollout.StableCSV)
+
+	// The main CatalogSource keeps the last good image.
+	commonConfig := GetCommonInstallOptions(addon)
+	assert.Equal(t, testStableCatalogImage, stableCatalogSourceImage(addon, &commonConfig))
+}
+
+func TestObserveCanaryRollout_Reverted(t *testing.T) {
+	now := time.Now()
+	r := newTestCanaryReconciler(testutil.NewClient(), testutil.NewClient(), now)
+	addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseBaking, now)
+	addon.Spec.Install.OLMOwnNamespace.CatalogSourceImage = testStableCatalogImage
+
+	_, err := r.observeCanaryRollout(context.Background(), addon)
+	require.NoError(t, err)
+	assert.Nil(t, addon.Status.CanaryRollout)
+}
+
+func TestEnsureCanaryRollout_Baking(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	start := time.Now()
+	r := newTestCanaryReconciler(c, uncachedC, start.Add(5*time.Minute))
+	addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseBaking, start)
+	mockCanaryCatalogSource(c, addon, "READY")
+
+	mainCatalogSource := newTestMainCatalogSource(addon)
+	result, subscriptionCatalogSource, err := r.ensureCanaryRollout(context.Background(), addon, mainCatalogSource)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	assert.Equal(t, mainCatalogSource, subscriptionCatalogSource)
+	assert.Equal(t, addonsv1alpha1.AddonCanaryRolloutPhaseBaking, addon.Status.CanaryRollout.Phase)
+	assert.True(t, canaryRolloutInProgress(addon))
+
+	c.AssertCalled(t, "Update", testutil.IsContext,
+		mock.MatchedBy(func(cs *operatorsv1alpha1.CatalogSource) bool {
+			return cs.Name == CanaryCatalogSourceName(addon) && cs.Spec.Image == testCanaryCatalogImage
+		}), mock.Anything)
+}
+
+func TestEnsureCanaryRollout_Baked(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	start := time.Now()
+	r := newTestCanaryReconciler(c, uncachedC, start.Add(10*time.Minute))
+	addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseBaking, start)
+	mockCanaryCatalogSource(c, addon, "READY")
+	mockCanaryHealthGates(c, uncachedC, "test.v1", operatorsv1alpha1.CSVPhaseSucceeded, true)
+
+	result, subscriptionCatalogSource, err := r.ensureCanaryRollout(
+		context.Background(), addon, newTestMainCatalogSource(addon))
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	assert.Equal(t, CanaryCatalogSourceName(addon), subscriptionCatalogSource.Name)
+	assert.Equal(t, addonsv1alpha1.AddonCanaryRolloutPhaseVerifying, addon.Status.CanaryRollout.Phase)
+	assert.True(t, addonUpgradeStarted(addon))
+}
+
+func TestEnsureCanaryRollout_BakedUnhealthy(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	start := time.Now()
+	r := newTestCanaryReconciler(c, uncachedC, start.Add(time.Hour))
+	addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseBaking, start)
+	mockCanaryCatalogSource(c, addon, "READY")
+	mockCanaryHealthGates(c, uncachedC, "test.v1", operatorsv1alpha1.CSVPhaseSucceeded, false)
+
+	_, subscriptionCatalogSource, err := r.ensureCanaryRollout(
+		context.Background(), addon, newTestMainCatalogSource(addon))
+	require.NoError(t, err)
+	assert.Equal(t, CatalogSourceName(addon), subscriptionCatalogSource.Name)
+	assert.Equal(t, addonsv1alpha1.AddonCanaryRolloutPhaseBaking, addon.Status.CanaryRollout.Phase)
+	assert.Contains(t, addon.Status.CanaryRollout.Message, "AddonInstance")
+}
+
+func TestEnsureCanaryRollout_Verified(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	start := time.Now()
+	r := newTestCanaryReconciler(c, uncachedC, start.Add(time.Minute))
+	promClient := &prometheusClientMock{}
+	r.prometheusClient = promClient
+	addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseVerifying, start)
+	addon.Spec.UpgradeStrategy.Canary.PrometheusQuery = `up{job="test"} == 1`
+	reportAddonUpgradeStarted(addon)
+	mockCanaryCatalogSource(c, addon, "READY")
+	mockCanaryHealthGates(c, uncachedC, "test.v2", operatorsv1alpha1.CSVPhaseSucceeded, true)
+	promClient.On("Healthy", mock.Anything, `up{job="test"} == 1`).Return(true, nil)
+
+	result, _, err := r.ensureCanaryRollout(context.Background(), addon, newTestMainCatalogSource(addon))
+	require.NoError(t, err)
+	assert.Equal(t, resultRequeue, result)
+	assert.Nil(t, addon.Status.CanaryRollout)
+	assert.True(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.UpgradeSucceeded))
+	promClient.AssertExpectations(t)
+}
+
+func TestEnsureCanaryRollout_VerifyingPending(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	start := time.Now()
+	r := newTestCanaryReconciler(c, uncachedC, start.Add(time.Minute))
+	addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseVerifying, start)
+	addon.Spec.UpgradeStrategy.Canary.PrometheusQuery = "up == 1"
+	mockCanaryCatalogSource(c, addon, "READY")
+	mockCanaryHealthGates(c, uncachedC, "test.v2", operatorsv1alpha1.CSVPhaseSucceeded, true)
+
+	// Without Prometheus API, the query gate can't pass.
+	result, subscriptionCatalogSource, err := r.ensureCanaryRollout(
+		context.Background(), addon, newTestMainCatalogSource(addon))
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	assert.Equal(t, CanaryCatalogSourceName(addon), subscriptionCatalogSource.Name)
+	assert.Equal(t, addonsv1alpha1.AddonCanaryRolloutPhaseVerifying, addon.Status.CanaryRollout.Phase)
+	assert.Contains(t, addon.Status.CanaryRollout.Message, "Prometheus")
+}
+
+func TestEnsureCanaryRollout_Rollback(t *testing.T) {
+	for name, tc := range map[string]struct {
+		csvPhase operatorsv1alpha1.ClusterServiceVersionPhase
+		elapsed  time.Duration
+		reason   string
+	}{
+		"csv failed": {
+			csvPhase: operatorsv1alpha1.CSVPhaseFailed,
+			elapsed:  time.Minute,
+			reason:   addonsv1alpha1.AddonReasonCSVFailed,
+		},
+		"progress deadline exceeded": {
+			csvPhase: operatorsv1alpha1.CSVPhaseInstalling,
+			elapsed:  30 * time.Minute,
+			reason:   addonsv1alpha1.AddonReasonProgressDeadlineExceeded,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			uncachedC := testutil.NewClient()
+			start := time.Now()
+			r := newTestCanaryReconciler(c, uncachedC, start.Add(tc.elapsed))
+			addon := newTestCanaryAddon(addonsv1alpha1.AddonCanaryRolloutPhaseVerifying, start)
+			reportAddonUpgradeStarted(addon)
+			mockCanaryCatalogSource(c, addon, "READY")
+			mockCanaryHealthGates(c, uncachedC, "test.v2", tc.csvPhase, true)
+			c.On("Delete", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+
+			mainCatalogSource := newTestMainCatalogSource(addon)
+			result, subscriptionCatalogSource, err := r.ensureCanaryRollout(
+				context.Background(), addon, mainCatalogSource)
+			require.NoError(t, err)
+			assert.Equal(t, resultRequeue, result)
+			assert.Equal(t, mainCatalogSource, subscriptionCatalogSource)
+
+			c.AssertCalled(t, "Delete", testutil.IsContext,
+				mock.MatchedBy(func(csv *operatorsv1alpha1.ClusterServiceVersion) bool {
+					return csv.Name == "test.v2" && csv.Namespace == "addon-1"
+				}), mock.Anything)
+			c.AssertCalled(t, "Delete", testutil.IsContext,
+				mock.IsType(&operatorsv1alpha1.Subscription{}), mock.Anything)
+
+			assert.Equal(t, addonsv1alpha1.AddonCanaryRolloutPhaseRolledBack, addon.Status.CanaryRollout.Phase)
+			assert.False(t, canaryRolloutInProgress(addon))
+			assert.False(t, addonUpgradeStarted(addon))
+			rolledBack := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.RolledBack)
+			require.NotNil(t, rolledBack)
+			assert.Equal(t, tc.reason, rolledBack.Reason)
+
+			// The recreated Subscription starts at the last good CSV.
+			assert.Equal(t, "test.v1", canaryRollbackStartingCSV(addon))
+			commonConfig := GetCommonInstallOptions(addon)
+			assert.Equal(t, testStableCatalogImage, stableCatalogSourceImage(addon, &commonConfig))
+		})
+	}
+}
+
+func TestEnsureCanaryRollout_Concluded(t *testing.T) {
+	c := testutil.NewClient()
+	now := time.Now()
+	r := newTestCanaryReconciler(c, testutil.NewClient(), now)
+	addon := newTestCanaryAddon("", now)
+
+	c.On("Get", testutil.IsContext, mock.Anything,
+		mock.IsType(&operatorsv1alpha1.CatalogSource{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		cs := args.Get(2).(*operatorsv1alpha1.CatalogSource)
+		cs.Name = CanaryCatalogSourceName(addon)
+		cs.Namespace = "addon-1"
+		cs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: addonsv1alpha1.GroupVersion.String(),
+			Kind:       "Addon",
+			Name:       addon.Name,
+			UID:        addon.UID,
+			Controller: ptr.To(true),
+		}}
+	}).Return(nil)
+	c.On("Delete", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+
+	mainCatalogSource := newTestMainCatalogSource(addon)
+	result, subscriptionCatalogSource, err := r.ensureCanaryRollout(context.Background(), addon, mainCatalogSource)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	assert.Equal(t, mainCatalogSource, subscriptionCatalogSource)
+	c.AssertCalled(t, "Delete", testutil.IsContext,
+		mock.MatchedBy(func(cs *operatorsv1alpha1.CatalogSource) bool {
+			return cs.Name == CanaryCatalogSourceName(addon)
+		}), mock.Anything)
+}
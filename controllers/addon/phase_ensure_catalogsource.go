@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,7 +41,7 @@ func (r *olmReconciler) ensureCatalogSource(
 			SourceType:    operatorsv1alpha1.SourceTypeGrpc,
 			Publisher:     catalogSourcePublisher,
 			DisplayName:   addon.Spec.DisplayName,
-			Image:         commonConfig.CatalogSourceImage,
+			Image:         stableCatalogSourceImage(addon, commonConfig),
 			GrpcPodConfig: &operatorsv1alpha1.GrpcPodConfig{SecurityContextConfig: operatorsv1alpha1.Restricted},
 		},
 	}
@@ -49,6 +50,7 @@ func (r *olmReconciler) ensureCatalogSource(
 			commonConfig.PullSecretName,
 		}
 	}
+	applyCatalogSourceConfig(&catalogSource.Spec, commonConfig.CatalogSourceConfig)
 
 	controllers.AddCommonLabels(catalogSource, addon)
 	controllers.AddCommonAnnotations(catalogSource, addon)
@@ -57,6 +59,10 @@ func (r *olmReconciler) ensureCatalogSource(
 		return resultNil, nil, err
 	}
 
+	if err := r.deferCatalogSourceImageChange(ctx, addon, catalogSource); err != nil {
+		return resultNil, nil, err
+	}
+
 	var observedCatalogSource *operatorsv1alpha1.CatalogSource
 	{
 		var err error
@@ -71,6 +77,11 @@ func (r *olmReconciler) ensureCatalogSource(
 		return resultRetry, nil, nil
 	}
 	if observedCatalogSource.Status.GRPCConnectionState.LastObservedState != "READY" {
+		// A missing connection state is expected while the CatalogSource starts up,
+		// only states actually observed by OLM are worth an Event.
+		recordAddonEvent(r.eventRecorder, addon, observedCatalogSource, corev1.EventTypeWarning,
+			eventReasonCatalogSourceUnready, "ReconcileCatalogSource", "CatalogSource %s is not ready: %s.",
+			observedCatalogSource.Name, observedCatalogSource.Status.GRPCConnectionState.LastObservedState)
 		reportCatalogSourceUnreadinessStatus(
 			addon,
 			fmt.Sprintf(
@@ -97,6 +108,10 @@ func (r *olmReconciler) ensureAdditionalCatalogSources(
 	if stop {
 		return resultStop, nil
 	}
+	commonConfig, err := addon.GetInstallOLMCommon()
+	if err != nil {
+		return resultNil, err
+	}
 	for _, additionalCatalogSrc := range additionalCatalogSrcs {
 		currentCatalogSrc := &operatorsv1alpha1.CatalogSource{
 			ObjectMeta: metav1.ObjectMeta{
@@ -117,6 +132,11 @@ func (r *olmReconciler) ensureAdditionalCatalogSources(
 				pullSecret,
 			}
 		}
+		if additionalCatalogSrc.Config != nil {
+			applyCatalogSourceConfig(&currentCatalogSrc.Spec, additionalCatalogSrc.Config)
+		} else {
+			applyCatalogSourceConfig(&currentCatalogSrc.Spec, commonConfig.CatalogSourceConfig)
+		}
 
 		controllers.AddCommonLabels(currentCatalogSrc, addon)
 		controllers.AddCommonAnnotations(currentCatalogSrc, addon)
@@ -124,9 +144,7 @@ func (r *olmReconciler) ensureAdditionalCatalogSources(
 		if err := controllerutil.SetControllerReference(addon, currentCatalogSrc, r.scheme); err != nil {
 			return resultNil, err
 		}
-		var observedCatalogSource *operatorsv1alpha1.CatalogSource
-		var err error
-		observedCatalogSource, err = reconcileCatalogSource(ctx, r.client, currentCatalogSrc)
+		observedCatalogSource, err := reconcileCatalogSource(ctx, r.client, currentCatalogSrc)
 		if err != nil {
 			return resultNil, err
 		}
@@ -149,6 +167,35 @@ func (r *olmReconciler) ensureAdditionalCatalogSources(
 	return resultNil, nil
 }
 
+// Applies the Addon settings of a CatalogSource to its spec.
+func applyCatalogSourceConfig(spec *operatorsv1alpha1.CatalogSourceSpec, config *addonsv1alpha1.CatalogSourceConfig) {
+	if config == nil {
+		return
+	}
+	spec.Priority = config.Priority
+
+	if poll := config.UpdateStrategy; poll != nil && poll.RegistryPoll != nil {
+		interval := poll.RegistryPoll.Interval.Duration
+		spec.UpdateStrategy = &operatorsv1alpha1.UpdateStrategy{
+			RegistryPoll: &operatorsv1alpha1.RegistryPoll{
+				RawInterval: interval.String(),
+				// Set like OLM does when decoding, so the specs compare equal.
+				Interval: &metav1.Duration{Duration: interval},
+			},
+		}
+	}
+
+	if podConfig := config.GrpcPodConfig; podConfig != nil {
+		podConfig = podConfig.DeepCopy()
+		spec.GrpcPodConfig.NodeSelector = podConfig.NodeSelector
+		spec.GrpcPodConfig.Tolerations = podConfig.Tolerations
+		spec.GrpcPodConfig.MemoryTarget = podConfig.MemoryTarget
+		if len(podConfig.PriorityClassName) > 0 {
+			spec.GrpcPodConfig.PriorityClassName = &podConfig.PriorityClassName
+		}
+	}
+}
+
 // reconciles a CatalogSource and returns a new CatalogSource object with updated state.
 // Warning: Will adopt existing CatalogSource
 func reconcileCatalogSource(ctx context.Context, c client.Client, catalogSource *operatorsv1alpha1.CatalogSource) (
@@ -2,16 +2,23 @@ package addon
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	"github.com/openshift/addon-operator/controllers"
 	"github.com/openshift/addon-operator/internal/testutil"
 )
@@ -258,3 +265,92 @@ func TestEnsureCatalogSource_Update(t *testing.T) {
 	c.AssertNumberOfCalls(t, "Get", 1)
 	c.AssertNumberOfCalls(t, "Update", 1)
 }
+
+func TestEnsureAdditionalCatalogSource_Config(t *testing.T) {
+	addon := testutil.NewTestAddonWithAdditionalCatalogSources()
+	common := &addon.Spec.Install.OLMOwnNamespace.AddonInstallOLMCommon
+	common.CatalogSourceConfig = &addonsv1alpha1.CatalogSourceConfig{
+		Priority: 10,
+		GrpcPodConfig: &addonsv1alpha1.CatalogSourceGrpcPodConfig{
+			NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		},
+	}
+	common.AdditionalCatalogSources[1].Config = &addonsv1alpha1.CatalogSourceConfig{Priority: -10}
+
+	c := testutil.NewClient()
+	c.On("Get",
+		mock.Anything,
+		testutil.IsObjectKey,
+		testutil.IsOperatorsV1Alpha1CatalogSourcePtr,
+		mock.Anything,
+	).Return(testutil.NewTestErrNotFound())
+	created := map[string]*operatorsv1alpha1.CatalogSource{}
+	c.On("Create",
+		mock.Anything,
+		testutil.IsOperatorsV1Alpha1CatalogSourcePtr,
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		catalogSource := args.Get(1).(*operatorsv1alpha1.CatalogSource)
+		catalogSource.Status.GRPCConnectionState = &operatorsv1alpha1.GRPCConnectionState{
+			LastObservedState: "READY",
+		}
+		created[catalogSource.Name] = catalogSource
+	}).Return(nil)
+	r := &olmReconciler{
+		client: c,
+		scheme: testutil.NewTestSchemeWithAddonsv1alpha1(),
+	}
+
+	ctx := controllers.ContextWithLogger(context.Background(), testutil.NewLogger(t))
+	requeueResult, err := r.ensureAdditionalCatalogSources(ctx, addon)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, requeueResult)
+
+	require.Contains(t, created, "test-1")
+	assert.Equal(t, 10, created["test-1"].Spec.Priority)
+	assert.Equal(t, map[string]string{"node-role.kubernetes.io/infra": ""},
+		created["test-1"].Spec.GrpcPodConfig.NodeSelector)
+
+	require.Contains(t, created, "test-2")
+	assert.Equal(t, -10, created["test-2"].Spec.Priority)
+	assert.Empty(t, created["test-2"].Spec.GrpcPodConfig.NodeSelector)
+	assert.Equal(t, operatorsv1alpha1.Restricted, created["test-2"].Spec.GrpcPodConfig.SecurityContextConfig)
+}
+
+func TestApplyCatalogSourceConfig(t *testing.T) {
+	spec := operatorsv1alpha1.CatalogSourceSpec{
+		GrpcPodConfig: &operatorsv1alpha1.GrpcPodConfig{SecurityContextConfig: operatorsv1alpha1.Restricted},
+	}
+	memoryTarget := resource.MustParse("64Mi")
+	applyCatalogSourceConfig(&spec, &addonsv1alpha1.CatalogSourceConfig{
+		UpdateStrategy: &addonsv1alpha1.CatalogSourceUpdateStrategy{
+			RegistryPoll: &addonsv1alpha1.CatalogSourceRegistryPoll{
+				Interval: metav1.Duration{Duration: 45 * time.Minute},
+			},
+		},
+		Priority: 5,
+		GrpcPodConfig: &addonsv1alpha1.CatalogSourceGrpcPodConfig{
+			Tolerations: []corev1.Toleration{{
+				Key:      "node-role.kubernetes.io/infra",
+				Operator: corev1.TolerationOpExists,
+			}},
+			PriorityClassName: "system-cluster-critical",
+			MemoryTarget:      &memoryTarget,
+		},
+	})
+
+	assert.Equal(t, 5, spec.Priority)
+	assert.Equal(t, "system-cluster-critical", *spec.GrpcPodConfig.PriorityClassName)
+	assert.Equal(t, &memoryTarget, spec.GrpcPodConfig.MemoryTarget)
+	assert.Len(t, spec.GrpcPodConfig.Tolerations, 1)
+	assert.Equal(t, operatorsv1alpha1.Restricted, spec.GrpcPodConfig.SecurityContextConfig)
+
+	// The spec has to survive a round trip through the API unchanged,
+	// otherwise the CatalogSource would be updated on every reconciliation.
+	data, err := json.Marshal(spec)
+	require.NoError(t, err)
+	decoded := operatorsv1alpha1.CatalogSourceSpec{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, equality.Semantic.DeepEqual(spec, decoded))
+	assert.Equal(t, "45m0s", decoded.UpdateStrategy.RawInterval)
+}
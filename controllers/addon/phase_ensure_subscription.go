@@ -1,8 +1,11 @@
 package addon
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 
 	"github.com/go-logr/logr"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -11,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -20,6 +24,9 @@ import (
 	"github.com/openshift/addon-operator/controllers"
 )
 
+// Environment variable of the Addon operator containing the Addon parameters.
+const ParametersEnvVarName = "ADDON_PARAMETERS"
+
 func (r *olmReconciler) ensureSubscription(
 	ctx context.Context,
 	log logr.Logger,
@@ -35,7 +42,28 @@ func (r *olmReconciler) ensureSubscription(
 		return resultNil, client.ObjectKey{}, err
 	}
 
-	subscriptionConfigObject := createSubscriptionConfigObject(commonInstallOptions)
+	startingCSV := canaryRollbackStartingCSV(addon)
+	pinnedCSV, installPlanApproval, rollbackReleased := upgradeRollbackPin(addon, commonInstallOptions)
+	if len(pinnedCSV) > 0 {
+		startingCSV = pinnedCSV
+	}
+	maintenanceSpec, err := r.addonMaintenance(ctx, addon)
+	if err != nil {
+		return resultNil, client.ObjectKey{}, err
+	}
+	// InstallPlans are approved by the addon-operator, see ensureInstallPlanApproval.
+	approvedByOperator := installPlanApprovalPolicy(addon) != nil || maintenanceSpec != nil
+	installPlanApproval, approvalRestored, err := r.subscriptionInstallPlanApproval(ctx, addon,
+		commonInstallOptions.Namespace, approvedByOperator, len(pinnedCSV) > 0, installPlanApproval)
+	if err != nil {
+		return resultNil, client.ObjectKey{}, err
+	}
+
+	subscriptionConfigObject, err := addParametersEnv(
+		createSubscriptionConfigObject(commonInstallOptions), addon.Spec.Parameters)
+	if err != nil {
+		return resultNil, client.ObjectKey{}, err
+	}
 	desiredSubscription := &operatorsv1alpha1.Subscription{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      SubscriptionName(addon),
@@ -46,8 +74,11 @@ func (r *olmReconciler) ensureSubscription(
 			CatalogSourceNamespace: catalogSource.Namespace,
 			Channel:                commonInstallOptions.Channel,
 			Package:                commonInstallOptions.PackageName,
+			StartingCSV:            startingCSV,
 			Config:                 subscriptionConfigObject,
-			// InstallPlanApproval is deliberately unmanaged
+			// InstallPlanApproval is deliberately unmanaged,
+			// unless the Addon has an InstallPlan approval policy or maintenance windows
+			// or is pinned after a rollback of a failed upgrade
 			// API default is `Automatic`
 			// Legacy behavior of existing managed-tenants tooling is:
 			// All addons initially have to be installed with `Automatic`
@@ -56,6 +87,7 @@ func (r *olmReconciler) ensureSubscription(
 			// change the Subscription.Spec.InstallPlanApproval value to `Manual`
 			// ATTENTION: When reconciling the subscription, we need to
 			// make sure to keep the current value of this field
+			InstallPlanApproval: installPlanApproval,
 		},
 	}
 	controllers.AddCommonLabels(desiredSubscription, addon)
@@ -63,11 +95,23 @@ func (r *olmReconciler) ensureSubscription(
 	if err := controllerutil.SetControllerReference(addon, desiredSubscription, r.scheme); err != nil {
 		return resultNil, client.ObjectKey{}, fmt.Errorf("setting controller reference: %w", err)
 	}
+	if err := r.deferSubscriptionChannelChange(ctx, addon, desiredSubscription); err != nil {
+		return resultNil, client.ObjectKey{}, err
+	}
 
 	observedSubscription, err := r.reconcileSubscription(ctx, desiredSubscription)
 	if err != nil {
 		return resultNil, client.ObjectKey{}, fmt.Errorf("reconciling Subscription: %w", err)
 	}
+	if rollbackReleased {
+		releaseUpgradeRollback(addon)
+	}
+	if approvalRestored {
+		addon.Status.OverriddenInstallPlanApproval = ""
+	}
+	if err := r.ensureInstallPlanApproval(ctx, addon, observedSubscription); err != nil {
+		return resultNil, client.ObjectKey{}, err
+	}
 
 	if len(observedSubscription.Status.InstalledCSV) == 0 ||
 		len(observedSubscription.Status.CurrentCSV) == 0 {
@@ -116,8 +160,10 @@ func (r *olmReconciler) reconcileSubscription(
 		return nil, err
 	}
 
-	// keep installPlanApproval value of existing object
-	subscription.Spec.InstallPlanApproval = currentSubscription.Spec.InstallPlanApproval
+	// keep installPlanApproval value of existing object, unless explicitly set
+	if len(subscription.Spec.InstallPlanApproval) == 0 {
+		subscription.Spec.InstallPlanApproval = currentSubscription.Spec.InstallPlanApproval
+	}
 
 	// Only update when spec, controllerRef, or labels have changed
 	specChanged := !equality.Semantic.DeepEqual(subscription.Spec, currentSubscription.Spec)
@@ -136,13 +182,48 @@ func (r *olmReconciler) reconcileSubscription(
 
 // Returns the subscription config object to be created from the passed AddonInstallOLMCommon object
 func createSubscriptionConfigObject(commonInstallOptions addonsv1alpha1.AddonInstallOLMCommon) *operatorsv1alpha1.SubscriptionConfig {
-	if commonInstallOptions.Config != nil {
-		subscriptionConfig := &operatorsv1alpha1.SubscriptionConfig{
-			Env: getSubscriptionEnvObjects(commonInstallOptions.Config.EnvironmentVariables),
-		}
-		return subscriptionConfig
+	config := commonInstallOptions.Config
+	if config == nil {
+		return nil
+	}
+	config = config.DeepCopy()
+	return &operatorsv1alpha1.SubscriptionConfig{
+		Env:          getSubscriptionEnvObjects(config.EnvironmentVariables),
+		EnvFrom:      config.EnvFrom,
+		Resources:    config.Resources,
+		NodeSelector: config.NodeSelector,
+		Tolerations:  config.Tolerations,
+		Affinity:     config.Affinity,
+		Volumes:      config.Volumes,
+		VolumeMounts: config.VolumeMounts,
+		Annotations:  config.Annotations,
+	}
+}
+
+// Passes the Addon parameters as compact JSON to the operator of the Addon.
+// Environment variables with the same name are overridden.
+func addParametersEnv(
+	config *operatorsv1alpha1.SubscriptionConfig, parameters *runtime.RawExtension,
+) (*operatorsv1alpha1.SubscriptionConfig, error) {
+	if parameters == nil || len(parameters.Raw) == 0 {
+		return config, nil
+	}
+
+	value := &bytes.Buffer{}
+	if err := json.Compact(value, parameters.Raw); err != nil {
+		return nil, fmt.Errorf("encoding parameters: %w", err)
+	}
+	if config == nil {
+		config = &operatorsv1alpha1.SubscriptionConfig{}
 	}
-	return nil
+	config.Env = slices.DeleteFunc(config.Env, func(env corev1.EnvVar) bool {
+		return env.Name == ParametersEnvVarName
+	})
+	config.Env = append(config.Env, corev1.EnvVar{
+		Name:  ParametersEnvVarName,
+		Value: value.String(),
+	})
+	return config, nil
 }
 
 // Converts addonsv1alpha1.EnvObjects to corev1.EnvVar's
@@ -150,8 +231,9 @@ func getSubscriptionEnvObjects(envObjects []addonsv1alpha1.EnvObject) []corev1.E
 	subscriptionEnvObjects := []corev1.EnvVar{}
 	for _, envObject := range envObjects {
 		currentEnvObj := corev1.EnvVar{
-			Name:  envObject.Name,
-			Value: envObject.Value,
+			Name:      envObject.Name,
+			Value:     envObject.Value,
+			ValueFrom: envObject.ValueFrom,
 		}
 		subscriptionEnvObjects = append(subscriptionEnvObjects, currentEnvObj)
 	}
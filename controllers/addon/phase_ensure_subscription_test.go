@@ -9,7 +9,10 @@ import (
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	"github.com/openshift/addon-operator/internal/testutil"
@@ -50,6 +53,87 @@ func TestEnsureSubscription_Adoption(t *testing.T) {
 
 }
 
+var (
+	testTokenEnvSource = &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+			Key:                  "token",
+		},
+	}
+	testInfraResources = &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+	testInfraTolerations = []corev1.Toleration{{
+		Key:      "node-role.kubernetes.io/infra",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}}
+)
+
+func TestReconcileSubscription_ConfigDrift(t *testing.T) {
+	for name, tc := range map[string]struct {
+		observed *operatorsv1alpha1.SubscriptionConfig
+		updated  bool
+	}{
+		"in sync": {
+			observed: &operatorsv1alpha1.SubscriptionConfig{
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("262144Ki")},
+				},
+				Tolerations: testInfraTolerations,
+			},
+		},
+		"tolerations removed": {
+			observed: &operatorsv1alpha1.SubscriptionConfig{
+				Resources: testInfraResources,
+			},
+			updated: true,
+		},
+		"config removed": {
+			updated: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			subscription := testutil.NewTestSubscription()
+			subscription.Spec.Config = &operatorsv1alpha1.SubscriptionConfig{
+				Resources:   testInfraResources,
+				Tolerations: testInfraTolerations,
+			}
+
+			c := testutil.NewClient()
+			c.On("Get",
+				testutil.IsContext,
+				testutil.IsObjectKey,
+				testutil.IsOperatorsV1Alpha1SubscriptionPtr,
+				mock.Anything,
+			).Run(func(args mock.Arguments) {
+				observed := testutil.NewTestSubscription()
+				observed.Spec.Config = tc.observed
+				observed.DeepCopyInto(args.Get(2).(*operatorsv1alpha1.Subscription))
+			}).Return(nil)
+			c.On("Update",
+				testutil.IsContext,
+				testutil.IsOperatorsV1Alpha1SubscriptionPtr,
+				mock.Anything,
+			).Return(nil)
+
+			rec := olmReconciler{
+				client: c,
+				scheme: testutil.NewTestSchemeWithAddonsv1alpha1(),
+			}
+			reconciled, err := rec.reconcileSubscription(context.Background(), subscription.DeepCopy())
+			require.NoError(t, err)
+
+			if !tc.updated {
+				c.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			c.AssertCalled(t, "Update", testutil.IsContext, testutil.IsOperatorsV1Alpha1SubscriptionPtr, mock.Anything)
+			assert.Equal(t, subscription.Spec.Config, reconciled.Spec.Config)
+		})
+	}
+}
+
 func TestCreateSubscriptionConfigObject(t *testing.T) {
 	testCases := []struct {
 		commonOLMInstallOptions    addonsv1alpha1.AddonInstallOLMCommon
@@ -109,6 +193,32 @@ func TestCreateSubscriptionConfigObject(t *testing.T) {
 				},
 			},
 		},
+		{
+			commonOLMInstallOptions: addonsv1alpha1.AddonInstallOLMCommon{
+				Config: &addonsv1alpha1.SubscriptionConfig{
+					EnvironmentVariables: []addonsv1alpha1.EnvObject{
+						{
+							Name:      "TOKEN",
+							ValueFrom: testTokenEnvSource,
+						},
+					},
+					Resources:    testInfraResources,
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+					Tolerations:  testInfraTolerations,
+				},
+			},
+			expectedSubscriptionConfig: &operatorsv1alpha1.SubscriptionConfig{
+				Env: []corev1.EnvVar{
+					{
+						Name:      "TOKEN",
+						ValueFrom: testTokenEnvSource,
+					},
+				},
+				Resources:    testInfraResources,
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations:  testInfraTolerations,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -119,6 +229,35 @@ func TestCreateSubscriptionConfigObject(t *testing.T) {
 	}
 }
 
+func TestAddParametersEnv(t *testing.T) {
+	parameters := &runtime.RawExtension{Raw: []byte(`{
+		"replicas": 2,
+		"logLevel": "info"
+	}`)}
+
+	config, err := addParametersEnv(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	config, err = addParametersEnv(nil, parameters)
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: ParametersEnvVarName, Value: `{"replicas":2,"logLevel":"info"}`},
+	}, config.Env)
+
+	config, err = addParametersEnv(&operatorsv1alpha1.SubscriptionConfig{
+		Env: []corev1.EnvVar{
+			{Name: "test", Value: "test"},
+			{Name: ParametersEnvVarName, Value: "{}"},
+		},
+	}, parameters)
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "test", Value: "test"},
+		{Name: ParametersEnvVarName, Value: `{"replicas":2,"logLevel":"info"}`},
+	}, config.Env)
+}
+
 func TestGetSubscriptionEnvObjects(t *testing.T) {
 	testCases := []struct {
 		envObjects   []addonsv1alpha1.EnvObject
@@ -6,6 +6,7 @@ import (
 
 	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -44,8 +45,18 @@ func (r *olmReconciler) observeOperatorResource(
 			return resultNil, err
 		}
 
-		if currentIp.Status.Phase == operatorsv1alpha1.InstallPlanPhaseRequiresApproval {
+		// While pinned after a rollback, InstallPlans of newer versions are never approved.
+		if currentIp.Status.Phase == operatorsv1alpha1.InstallPlanPhaseRequiresApproval &&
+			!currentIp.Spec.Approved &&
+			addon.Status.UpgradeRollback == nil {
 			reportInstallPlanPending(addon)
+			if next := addon.Status.NextMaintenanceWindow; next != nil {
+				return resultRequeueAfter(maintenanceWindowRequeueAfter(next.Time, r.clock.Now())), nil
+			}
+			if installPlanApprovalPolicy(addon) != nil {
+				// Wait for a rule of the approval policy to match.
+				return resultRequeueAfter(installPlanApprovalRecheckInterval), nil
+			}
 			// CSV will not be available at this stage
 			return resultStop, nil
 		}
@@ -53,7 +64,7 @@ func (r *olmReconciler) observeOperatorResource(
 
 	operatorKey := client.ObjectKey{
 		Namespace: "",
-		Name:      generateOperatorResourceName(addon),
+		Name:      OperatorResourceName(addon),
 	}
 
 	// add mapping
@@ -88,17 +99,25 @@ func (r *olmReconciler) observeOperatorResource(
 	phase := getCSVPhase(addonCSVRef)
 
 	// If the addon was being upgraded, we mark the upgrade as
-	// concluded.
-	if addonUpgradeConcluded(addon, csvKey, phase) {
+	// concluded. Canary rollouts conclude once all health gates passed.
+	if addon.Status.CanaryRollout == nil && addonUpgradeConcluded(addon, csvKey, phase) {
 		reportAddonUpgradeSucceeded(addon)
 	}
 
+	if result, err := r.rollbackFailedUpgrade(ctx, addon, csvKey, phase); err != nil {
+		return resultRetry, err
+	} else if !result.IsZero() {
+		return result, nil
+	}
+
 	var message string
 	switch phase {
 	case operatorsv1alpha1.CSVPhaseSucceeded:
 		// do nothing here
 	case operatorsv1alpha1.CSVPhaseFailed:
 		message = "failed"
+		recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeWarning,
+			eventReasonCSVFailed, "ObserveCSV", "ClusterServiceVersion %s failed.", csvKey.Name)
 	default:
 		message = "unknown/pending"
 	}
@@ -128,7 +147,8 @@ func getCSVPhase(csvReference *operatorsv1.RichReference) operatorsv1alpha1.Clus
 	return ""
 }
 
-func generateOperatorResourceName(addon *addonsv1alpha1.Addon) string {
+// OperatorResourceName returns the name of the cluster scoped OLM Operator resource of the Addon.
+func OperatorResourceName(addon *addonsv1alpha1.Addon) string {
 	commonInstallOptions := GetCommonInstallOptions(addon)
 	return fmt.Sprintf("%s.%s", commonInstallOptions.PackageName, commonInstallOptions.Namespace)
 }
@@ -0,0 +1,144 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sApiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+)
+
+const defaultUpgradeRollbackProgressDeadline = 30 * time.Minute
+
+// Canary rollouts roll back on their own and are not covered here.
+func isUpgradeRollbackEnabled(addon *addonsv1alpha1.Addon) bool {
+	return addon.Spec.UpgradeStrategy != nil &&
+		addon.Spec.UpgradeStrategy.Rollback != nil &&
+		!isCanaryUpgrade(addon)
+}
+
+// Rolls back an upgrade, when the CSV of the new version failed
+// or did not succeed within the progress deadline.
+// OLM does not downgrade operators, so the new CSV and the Subscription are deleted.
+// The Subscription is recreated pinned to the last available CSV, see upgradeRollbackPin.
+func (r *olmReconciler) rollbackFailedUpgrade(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+	csvKey client.ObjectKey, phase operatorsv1alpha1.ClusterServiceVersionPhase,
+) (subReconcilerResult, error) {
+	lastAvailableCSV := addon.Status.LastObservedAvailableCSV
+	if !isUpgradeRollbackEnabled(addon) ||
+		addon.Status.UpgradeRollback != nil ||
+		phase == operatorsv1alpha1.CSVPhaseSucceeded ||
+		len(lastAvailableCSV) == 0 ||
+		lastAvailableCSV == csvKey.String() {
+		return resultNil, nil
+	}
+	log := controllers.LoggerFromContext(ctx)
+
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	if err := r.uncachedClient.Get(ctx, csvKey, csv); k8sApiErrors.IsNotFound(err) {
+		return resultNil, nil
+	} else if err != nil {
+		return resultNil, fmt.Errorf("getting ClusterServiceVersion: %w", err)
+	}
+
+	var reason, message string
+	switch {
+	case phase == operatorsv1alpha1.CSVPhaseFailed ||
+		csv.Status.Phase == operatorsv1alpha1.CSVPhaseFailed:
+		reason = addonsv1alpha1.AddonReasonCSVFailed
+		message = fmt.Sprintf("ClusterServiceVersion %s failed: %s", csvKey.Name, csv.Status.Message)
+
+	case !r.clock.Now().Before(csv.CreationTimestamp.Add(upgradeRollbackProgressDeadline(addon))):
+		reason = addonsv1alpha1.AddonReasonProgressDeadlineExceeded
+		message = fmt.Sprintf("ClusterServiceVersion %s did not succeed within the progress deadline, phase %q.",
+			csvKey.Name, csv.Status.Phase)
+
+	default:
+		return resultNil, nil
+	}
+
+	subscription, err := r.GetSubscription(ctx, SubscriptionName(addon), csvKey.Namespace)
+	if err != nil {
+		return resultNil, fmt.Errorf("getting Subscription: %w", err)
+	}
+	installPlanApproval := subscription.GetInstallPlanApproval()
+
+	if err := r.client.Delete(ctx, csv); client.IgnoreNotFound(err) != nil {
+		return resultNil, fmt.Errorf("deleting ClusterServiceVersion: %w", err)
+	}
+	if err := r.client.Delete(ctx, subscription); client.IgnoreNotFound(err) != nil {
+		return resultNil, fmt.Errorf("deleting Subscription: %w", err)
+	}
+
+	addon.Status.UpgradeRollback = &addonsv1alpha1.AddonUpgradeRollbackStatus{
+		PinnedCSV:           lastAvailableCSV,
+		FailedCSV:           csvKey.String(),
+		Version:             addon.Spec.Version,
+		CatalogSourceImage:  GetCommonInstallOptions(addon).CatalogSourceImage,
+		InstallPlanApproval: string(installPlanApproval),
+	}
+	log.Info("rolled back upgrade", "reason", reason, "failedCSV", csvKey.String(), "pinnedCSV", lastAvailableCSV)
+	recordAddonEvent(r.eventRecorder, addon, csv, corev1.EventTypeWarning,
+		reason, "RollbackUpgrade", "%s Rolled back to %s.", strings.TrimSuffix(message, ".")+".", csvNameFromKey(lastAvailableCSV))
+	reportAddonUpgradeRolledBack(addon, reason,
+		fmt.Sprintf("Rolled back to %s: %s", csvNameFromKey(lastAvailableCSV), message))
+	return resultRequeue, nil
+}
+
+// Returns the startingCSV and InstallPlanApproval of the Subscription after a rollback.
+// The Subscription stays pinned to the last available CSV with manual approval,
+// so OLM does not retry the upgrade, until a new version or catalog image is rolled out.
+// Then released is set and the InstallPlanApproval from before the rollback is restored.
+func upgradeRollbackPin(
+	addon *addonsv1alpha1.Addon, commonInstallOptions addonsv1alpha1.AddonInstallOLMCommon,
+) (startingCSV string, approval operatorsv1alpha1.Approval, released bool) {
+	rollback := addon.Status.UpgradeRollback
+	if rollback == nil {
+		return "", "", false
+	}
+	if !isUpgradeRollbackEnabled(addon) ||
+		rollback.Version != addon.Spec.Version ||
+		rollback.CatalogSourceImage != commonInstallOptions.CatalogSourceImage {
+		return "", operatorsv1alpha1.Approval(rollback.InstallPlanApproval), true
+	}
+	return csvNameFromKey(rollback.PinnedCSV), operatorsv1alpha1.ApprovalManual, false
+}
+
+func releaseUpgradeRollback(addon *addonsv1alpha1.Addon) {
+	addon.Status.UpgradeRollback = nil
+	meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.RolledBack)
+}
+
+// A pinned Subscription only gets InstallPlans approved,
+// that install nothing but the CSV it is pinned to.
+func pinnedInstallPlanApproval(
+	addon *addonsv1alpha1.Addon, installPlan *operatorsv1alpha1.InstallPlan,
+) addonsv1alpha1.AddonInstallPlanApprovalDecision {
+	pinnedCSV := csvNameFromKey(addon.Status.UpgradeRollback.PinnedCSV)
+	csvNames := installPlan.Spec.ClusterServiceVersionNames
+	if len(csvNames) != 1 || csvNames[0] != pinnedCSV {
+		return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+			Message: fmt.Sprintf("Subscription is pinned to %s after a rollback.", pinnedCSV),
+		}
+	}
+	return addonsv1alpha1.AddonInstallPlanApprovalDecision{
+		Approved: true,
+		Message:  fmt.Sprintf("Reinstalling %s after a rollback.", pinnedCSV),
+	}
+}
+
+func upgradeRollbackProgressDeadline(addon *addonsv1alpha1.Addon) time.Duration {
+	if d := addon.Spec.UpgradeStrategy.Rollback.ProgressDeadline.Duration; d > 0 {
+		return d
+	}
+	return defaultUpgradeRollbackProgressDeadline
+}
@@ -0,0 +1,237 @@
+package addon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+func newTestRollbackAddon() *addonsv1alpha1.Addon {
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	addon.Spec.Version = "2.0.0"
+	addon.Spec.UpgradeStrategy = &addonsv1alpha1.AddonUpgradeStrategy{
+		Type: addonsv1alpha1.AddonUpgradeStrategyImmediate,
+		Rollback: &addonsv1alpha1.AddonUpgradeRollback{
+			ProgressDeadline: metav1.Duration{Duration: 30 * time.Minute},
+		},
+	}
+	addon.Status.LastObservedAvailableCSV = "addon-1/test.v1"
+	return addon
+}
+
+func TestRollbackFailedUpgrade_Disabled(t *testing.T) {
+	c := testutil.NewClient()
+	uncachedC := testutil.NewClient()
+	r := newTestCanaryReconciler(c, uncachedC, time.Now())
+
+	addon := newTestRollbackAddon()
+	addon.Spec.UpgradeStrategy = nil
+
+	result, err := r.rollbackFailedUpgrade(context.Background(), addon,
+		client.ObjectKey{Name: "test.v2", Namespace: "addon-1"}, operatorsv1alpha1.CSVPhaseFailed)
+	require.NoError(t, err)
+	assert.Equal(t, resultNil, result)
+	uncachedC.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Nil(t, addon.Status.UpgradeRollback)
+}
+
+func TestRollbackFailedUpgrade(t *testing.T) {
+	now := time.Now()
+	for name, tc := range map[string]struct {
+		phase      operatorsv1alpha1.ClusterServiceVersionPhase
+		age        time.Duration
+		rolledBack bool
+		reason     string
+	}{
+		"csv failed": {
+			phase:      operatorsv1alpha1.CSVPhaseFailed,
+			age:        time.Minute,
+			rolledBack: true,
+			reason:     addonsv1alpha1.AddonReasonCSVFailed,
+		},
+		"progress deadline exceeded": {
+			phase:      operatorsv1alpha1.CSVPhaseInstalling,
+			age:        31 * time.Minute,
+			rolledBack: true,
+			reason:     addonsv1alpha1.AddonReasonProgressDeadlineExceeded,
+		},
+		"pending": {
+			phase: operatorsv1alpha1.CSVPhaseInstalling,
+			age:   time.Minute,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			uncachedC := testutil.NewClient()
+			r := newTestCanaryReconciler(c, uncachedC, now)
+			addon := newTestRollbackAddon()
+			csvKey := client.ObjectKey{Name: "test.v2", Namespace: "addon-1"}
+
+			uncachedC.On("Get", testutil.IsContext, csvKey,
+				mock.IsType(&operatorsv1alpha1.ClusterServiceVersion{}), mock.Anything,
+			).Run(func(args mock.Arguments) {
+				csv := args.Get(2).(*operatorsv1alpha1.ClusterServiceVersion)
+				csv.Name = csvKey.Name
+				csv.Namespace = csvKey.Namespace
+				csv.CreationTimestamp = metav1.NewTime(now.Add(-tc.age))
+				csv.Status.Phase = tc.phase
+			}).Return(nil)
+			c.On("Get", testutil.IsContext, mock.Anything,
+				mock.IsType(&operatorsv1alpha1.Subscription{}), mock.Anything,
+			).Run(func(args mock.Arguments) {
+				sub := args.Get(2).(*operatorsv1alpha1.Subscription)
+				sub.Name = SubscriptionName(addon)
+				sub.Namespace = "addon-1"
+				sub.Spec = &operatorsv1alpha1.SubscriptionSpec{}
+			}).Return(nil)
+			c.On("Delete", testutil.IsContext, mock.Anything, mock.Anything).Return(nil)
+
+			result, err := r.rollbackFailedUpgrade(context.Background(), addon, csvKey, tc.phase)
+			require.NoError(t, err)
+
+			if !tc.rolledBack {
+				assert.Equal(t, resultNil, result)
+				c.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+				assert.Nil(t, addon.Status.UpgradeRollback)
+				return
+			}
+
+			assert.Equal(t, resultRequeue, result)
+			c.AssertCalled(t, "Delete", testutil.IsContext,
+				mock.MatchedBy(func(csv *operatorsv1alpha1.ClusterServiceVersion) bool {
+					return csv.Name == "test.v2"
+				}), mock.Anything)
+			c.AssertCalled(t, "Delete", testutil.IsContext,
+				mock.IsType(&operatorsv1alpha1.Subscription{}), mock.Anything)
+
+			assert.Equal(t, &addonsv1alpha1.AddonUpgradeRollbackStatus{
+				PinnedCSV:           "addon-1/test.v1",
+				FailedCSV:           "addon-1/test.v2",
+				Version:             "2.0.0",
+				CatalogSourceImage:  addon.Spec.Install.OLMOwnNamespace.CatalogSourceImage,
+				InstallPlanApproval: string(operatorsv1alpha1.ApprovalAutomatic),
+			}, addon.Status.UpgradeRollback)
+
+			rolledBack := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.RolledBack)
+			require.NotNil(t, rolledBack)
+			assert.Equal(t, tc.reason, rolledBack.Reason)
+		})
+	}
+}
+
+func TestUpgradeRollbackPin(t *testing.T) {
+	for name, tc := range map[string]struct {
+		modify      func(addon *addonsv1alpha1.Addon)
+		startingCSV string
+		approval    operatorsv1alpha1.Approval
+		released    bool
+	}{
+		"pinned": {
+			modify:      func(*addonsv1alpha1.Addon) {},
+			startingCSV: "test.v1",
+			approval:    operatorsv1alpha1.ApprovalManual,
+		},
+		"new version": {
+			modify: func(addon *addonsv1alpha1.Addon) {
+				addon.Spec.Version = "2.0.1"
+			},
+			approval: operatorsv1alpha1.ApprovalAutomatic,
+			released: true,
+		},
+		"new catalog image": {
+			modify: func(addon *addonsv1alpha1.Addon) {
+				addon.Spec.Install.OLMOwnNamespace.CatalogSourceImage = "quay.io/osd-addons/test:v3"
+			},
+			approval: operatorsv1alpha1.ApprovalAutomatic,
+			released: true,
+		},
+		"rollback disabled": {
+			modify: func(addon *addonsv1alpha1.Addon) {
+				addon.Spec.UpgradeStrategy = nil
+			},
+			approval: operatorsv1alpha1.ApprovalAutomatic,
+			released: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addon := newTestRollbackAddon()
+			addon.Status.UpgradeRollback = &addonsv1alpha1.AddonUpgradeRollbackStatus{
+				PinnedCSV:           "addon-1/test.v1",
+				FailedCSV:           "addon-1/test.v2",
+				Version:             addon.Spec.Version,
+				CatalogSourceImage:  addon.Spec.Install.OLMOwnNamespace.CatalogSourceImage,
+				InstallPlanApproval: string(operatorsv1alpha1.ApprovalAutomatic),
+			}
+			tc.modify(addon)
+
+			startingCSV, approval, released := upgradeRollbackPin(addon, GetCommonInstallOptions(addon))
+			assert.Equal(t, tc.startingCSV, startingCSV)
+			assert.Equal(t, tc.approval, approval)
+			assert.Equal(t, tc.released, released)
+		})
+	}
+}
+
+func TestEnsureInstallPlanApproval_Pinned(t *testing.T) {
+	for name, tc := range map[string]struct {
+		csvNames []string
+		approved bool
+	}{
+		"pinned csv": {
+			csvNames: []string{"test.v1"},
+			approved: true,
+		},
+		"failed csv": {
+			csvNames: []string{"test.v2"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := testutil.NewClient()
+			r := newTestCanaryReconciler(c, testutil.NewClient(), time.Now())
+			addon := newTestRollbackAddon()
+			addon.Status.UpgradeRollback = &addonsv1alpha1.AddonUpgradeRollbackStatus{
+				PinnedCSV: "addon-1/test.v1",
+				FailedCSV: "addon-1/test.v2",
+			}
+			subscription := &operatorsv1alpha1.Subscription{
+				Status: operatorsv1alpha1.SubscriptionStatus{
+					InstallPlanRef: &corev1.ObjectReference{Name: "install-abcde", Namespace: "addon-1"},
+				},
+			}
+
+			c.On("Get", testutil.IsContext,
+				client.ObjectKey{Name: "install-abcde", Namespace: "addon-1"},
+				mock.IsType(&operatorsv1alpha1.InstallPlan{}), mock.Anything,
+			).Run(func(args mock.Arguments) {
+				ip := args.Get(2).(*operatorsv1alpha1.InstallPlan)
+				ip.Spec.ClusterServiceVersionNames = tc.csvNames
+				ip.Spec.Approval = operatorsv1alpha1.ApprovalManual
+			}).Return(nil)
+			c.On("Update", testutil.IsContext,
+				mock.IsType(&operatorsv1alpha1.InstallPlan{}), mock.Anything,
+			).Return(nil)
+
+			require.NoError(t, r.ensureInstallPlanApproval(context.Background(), addon, subscription))
+			if tc.approved {
+				c.AssertCalled(t, "Update", testutil.IsContext,
+					mock.MatchedBy(func(ip *operatorsv1alpha1.InstallPlan) bool {
+						return ip.Spec.Approved
+					}), mock.Anything)
+			} else {
+				c.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -24,6 +25,8 @@ type addonSecretPropagationReconciler struct {
 	scheme                       *runtime.Scheme
 	addonOperatorNamespace       string
 	recorder                     *metrics.Recorder
+	// Optional, emits Events when a source Secret is missing.
+	eventRecorder events.EventRecorder
 }
 
 func (r *addonSecretPropagationReconciler) Reconcile(ctx context.Context, addon *addonsv1alpha1.Addon) (subReconcilerResult, error) {
@@ -123,6 +126,9 @@ func (r *addonSecretPropagationReconciler) getReferencedSecret(
 		// fallback to a uncached read to discover.
 		if err := r.uncachedClient.Get(ctx, secretKey, referencedSecret); apiErrors.IsNotFound(err) {
 			// Secret does not exist for sure, break and keep retrying later.
+			recordAddonEvent(r.eventRecorder, addon, nil, corev1.EventTypeWarning,
+				eventReasonSecretSourceMissing, "PropagateSecret",
+				"Source Secret %s for propagation is missing.", secretKey)
 			reportPendingStatus(addon, addonsv1alpha1.AddonReasonMissingSecretForPropagation, err.Error())
 			return nil, resultRequeueAfter(defaultRetryAfterTime), nil
 		} else if err != nil {
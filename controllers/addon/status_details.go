@@ -0,0 +1,384 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+)
+
+// Heartbeats older than this multiple of the heartbeat update period are stale,
+// matching the default threshold of the AddonInstance controller.
+const heartbeatFreshnessMultiplier = 3
+
+// statusDetailsCache keeps the parts of the status details per Addon,
+// that are looked up with uncached requests.
+// They are only looked up again, when the resource version
+// of their watched input in the cache changed.
+type statusDetailsCache struct {
+	mux     sync.Mutex
+	entries map[string]cachedStatusDetails
+}
+
+type cachedStatusDetails struct {
+	// Resource version of the OLM Operator,
+	// which changes with the status of the installed CSV.
+	operatorResourceVersion string
+	installedCSV            *ocm.AddOnInstalledCSV
+	workloads               map[addonsv1alpha1.AddonManifestObjectReference]cachedWorkloadReadiness
+}
+
+type cachedWorkloadReadiness struct {
+	resourceVersion string
+	ready           bool
+}
+
+func (c *statusDetailsCache) get(addonName string) cachedStatusDetails {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.entries[addonName]
+}
+
+func (c *statusDetailsCache) set(addonName string, entry cachedStatusDetails) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]cachedStatusDetails{}
+	}
+	c.entries[addonName] = entry
+}
+
+func (c *statusDetailsCache) forget(addonName string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.entries, addonName)
+}
+
+// Collects the health details and workload inventory of the Addon reported to OCM.
+func (r *AddonReconciler) addonStatusDetails(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) (details ocm.AddOnStatusDetails, err error) {
+	if details.Resources, err = r.namespaceResourceStatuses(ctx, addon); err != nil {
+		return details, err
+	}
+
+	namespace := GetAddonInstallNamespace(addon)
+	if len(namespace) == 0 {
+		// Nothing was installed yet.
+		return details, nil
+	}
+
+	if isOLMInstall(addon) {
+		var olmResources []ocm.AddOnResourceStatus
+		details.InstalledCSV, olmResources, err = r.olmStatusDetails(ctx, addon, namespace)
+		if err != nil {
+			return details, err
+		}
+		details.Resources = append(details.Resources, olmResources...)
+	}
+
+	installedResources, err := r.installedObjectStatuses(ctx, addon)
+	if err != nil {
+		return details, err
+	}
+	details.Resources = append(details.Resources, installedResources...)
+
+	if details.AddonInstance, err = r.addonInstanceHealth(ctx, namespace); err != nil {
+		return details, err
+	}
+
+	// Pods are not watched, like for the addon health metric
+	// they are only looked at while the Addon is not Available.
+	if !meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Available) {
+		unschedulablePods, err := r.listUnschedulableAddonPods(ctx, addon)
+		if err != nil {
+			return details, err
+		}
+		details.UnschedulablePods = len(unschedulablePods.Items)
+	}
+	return details, nil
+}
+
+func (r *AddonReconciler) namespaceResourceStatuses(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) ([]ocm.AddOnResourceStatus, error) {
+	var resources []ocm.AddOnResourceStatus
+	for _, addonNamespace := range addon.Spec.Namespaces {
+		namespace := &corev1.Namespace{}
+		err := r.Client.Get(ctx, client.ObjectKey{Name: addonNamespace.Name}, namespace)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("getting Namespace %s: %w", addonNamespace.Name, err)
+		}
+		resources = append(resources, ocm.AddOnResourceStatus{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       addonNamespace.Name,
+			Ready:      err == nil && namespace.Status.Phase == corev1.NamespaceActive,
+		})
+	}
+	return resources, nil
+}
+
+// Reports the CSV installed by the Subscription
+// and the readiness of the CatalogSource and Subscription.
+func (r *AddonReconciler) olmStatusDetails(
+	ctx context.Context, addon *addonsv1alpha1.Addon, namespace string,
+) (*ocm.AddOnInstalledCSV, []ocm.AddOnResourceStatus, error) {
+	catalogSource := &operatorsv1alpha1.CatalogSource{}
+	catalogSourceKey := client.ObjectKey{Name: CatalogSourceName(addon), Namespace: namespace}
+	err := r.Client.Get(ctx, catalogSourceKey, catalogSource)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("getting CatalogSource: %w", err)
+	}
+	catalogSourceReady := err == nil &&
+		catalogSource.Status.GRPCConnectionState != nil &&
+		catalogSource.Status.GRPCConnectionState.LastObservedState == "READY"
+
+	subscription := &operatorsv1alpha1.Subscription{}
+	subscriptionKey := client.ObjectKey{Name: SubscriptionName(addon), Namespace: namespace}
+	err = r.Client.Get(ctx, subscriptionKey, subscription)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("getting Subscription: %w", err)
+	}
+	installedCSVName := subscription.Status.InstalledCSV
+	subscriptionReady := err == nil &&
+		len(installedCSVName) > 0 && installedCSVName == subscription.Status.CurrentCSV
+
+	resources := []ocm.AddOnResourceStatus{
+		{
+			APIVersion: operatorsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       operatorsv1alpha1.CatalogSourceKind,
+			Namespace:  namespace,
+			Name:       catalogSourceKey.Name,
+			Ready:      catalogSourceReady,
+		},
+		{
+			APIVersion: operatorsv1alpha1.SchemeGroupVersion.String(),
+			Kind:       operatorsv1alpha1.SubscriptionKind,
+			Namespace:  namespace,
+			Name:       subscriptionKey.Name,
+			Ready:      subscriptionReady,
+		},
+	}
+	if len(installedCSVName) == 0 {
+		return nil, resources, nil
+	}
+
+	installedCSV, err := r.installedCSVStatus(ctx, addon, client.ObjectKey{
+		Name: installedCSVName, Namespace: namespace,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return installedCSV, resources, nil
+}
+
+// CSVs are not watched, they are looked up again
+// only when the OLM Operator of the Addon changed.
+func (r *AddonReconciler) installedCSVStatus(
+	ctx context.Context, addon *addonsv1alpha1.Addon, csvKey client.ObjectKey,
+) (*ocm.AddOnInstalledCSV, error) {
+	operatorResourceVersion, err := r.cachedResourceVersion(ctx,
+		operatorsv1.GroupVersion.WithKind("Operator"), client.ObjectKey{Name: OperatorResourceName(addon)})
+	if err != nil {
+		return nil, err
+	}
+
+	cached := r.statusDetails.get(addon.Name)
+	if len(operatorResourceVersion) > 0 && cached.installedCSV != nil &&
+		cached.installedCSV.Name == csvKey.Name &&
+		cached.operatorResourceVersion == operatorResourceVersion {
+		installedCSV := *cached.installedCSV
+		return &installedCSV, nil
+	}
+
+	installedCSV := &ocm.AddOnInstalledCSV{Name: csvKey.Name}
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	err = r.UncachedClient.Get(ctx, csvKey, csv)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, fmt.Errorf("getting ClusterServiceVersion: %w", err)
+	default:
+		installedCSV.Version = csv.Spec.Version.String()
+		installedCSV.Phase = string(csv.Status.Phase)
+	}
+
+	cached.operatorResourceVersion = operatorResourceVersion
+	cached.installedCSV = installedCSV
+	r.statusDetails.set(addon.Name, cached)
+
+	result := *installedCSV
+	return &result, nil
+}
+
+// Reports the readiness of the objects applied from a manifest bundle or Helm chart.
+// Only workloads have a readiness, other objects are ready when they exist.
+// Workloads are watched by their metadata only,
+// so their status is looked up again when their resource version changed.
+func (r *AddonReconciler) installedObjectStatuses(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) ([]ocm.AddOnResourceStatus, error) {
+	cached := r.statusDetails.get(addon.Name)
+	workloads := map[addonsv1alpha1.AddonManifestObjectReference]cachedWorkloadReadiness{}
+
+	var resources []ocm.AddOnResourceStatus
+	installed := slices.Concat(addon.Status.ManifestObjects, addon.Status.HelmObjects)
+	for _, ref := range installed {
+		ready := true
+		gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+		if slices.Contains(workloadGroupKinds, gvk.GroupKind()) {
+			key := client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}
+			resourceVersion, err := r.cachedResourceVersion(ctx, gvk, key)
+			if err != nil {
+				return nil, err
+			}
+
+			readiness, ok := cached.workloads[ref]
+			if !ok || readiness.resourceVersion != resourceVersion {
+				readiness, err = r.lookupWorkloadReadiness(ctx, gvk, key, resourceVersion)
+				if err != nil {
+					return nil, err
+				}
+			}
+			workloads[ref] = readiness
+			ready = readiness.ready
+		}
+
+		resources = append(resources, ocm.AddOnResourceStatus{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Namespace:  ref.Namespace,
+			Name:       ref.Name,
+			Ready:      ready,
+		})
+	}
+
+	cached.workloads = workloads
+	r.statusDetails.set(addon.Name, cached)
+	return resources, nil
+}
+
+// Workloads missing from the cache are not ready and not looked up.
+func (r *AddonReconciler) lookupWorkloadReadiness(
+	ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey, resourceVersion string,
+) (cachedWorkloadReadiness, error) {
+	if len(resourceVersion) == 0 {
+		return cachedWorkloadReadiness{}, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(key.Namespace)
+	obj.SetName(key.Name)
+	ready, err := workloadReady(ctx, r.UncachedClient, obj)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return cachedWorkloadReadiness{}, err
+	}
+	return cachedWorkloadReadiness{resourceVersion: resourceVersion, ready: ready}, nil
+}
+
+// Returns the resource version of the object from the metadata cache,
+// empty when the object does not exist.
+func (r *AddonReconciler) cachedResourceVersion(
+	ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey,
+) (string, error) {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	err := r.Client.Get(ctx, key, obj)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting %s %s: %w", gvk.Kind, key, err)
+	}
+	return obj.ResourceVersion, nil
+}
+
+func (r *AddonReconciler) addonInstanceHealth(
+	ctx context.Context, namespace string,
+) (*ocm.AddOnInstanceHealth, error) {
+	instance := &addonsv1alpha1.AddonInstance{}
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Name:      addonsv1alpha1.DefaultAddonInstanceName,
+		Namespace: namespace,
+	}, instance)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting AddonInstance: %w", err)
+	}
+
+	health := &ocm.AddOnInstanceHealth{}
+	if staleAt, ok := heartbeatStaleAt(instance); ok {
+		lastHeartbeat := instance.Status.LastHeartbeatTime
+		health.LastHeartbeatTime = &lastHeartbeat
+		health.HeartbeatFresh = r.clock.Now().Before(staleAt)
+	}
+	if degraded := meta.FindStatusCondition(
+		instance.Status.Conditions, addonsv1alpha1.AddonInstanceConditionDegraded.String(),
+	); degraded != nil {
+		condition := mapToAddonStatusConditions([]metav1.Condition{*degraded})[0]
+		health.Degraded = &condition
+	}
+	return health, nil
+}
+
+// Returns when the last heartbeat of the AddonInstance turns stale,
+// false when there was no heartbeat yet.
+func heartbeatStaleAt(instance *addonsv1alpha1.AddonInstance) (time.Time, bool) {
+	lastHeartbeat := instance.Status.LastHeartbeatTime
+	if lastHeartbeat.IsZero() {
+		return time.Time{}, false
+	}
+	period := instance.Spec.HeartbeatUpdatePeriod.Duration
+	if period <= 0 {
+		period = addonsv1alpha1.DefaultAddonInstanceHeartbeatUpdatePeriod
+	}
+	return lastHeartbeat.Add(time.Duration(heartbeatFreshnessMultiplier) * period), true
+}
+
+// Returns when the Addon needs to be reconciled again to report
+// the heartbeat of its AddonInstance as stale, which no event announces.
+// 0 if the heartbeat is stale already or not reported.
+func (r *AddonReconciler) heartbeatStaleRequeueAfter(ctx context.Context, addon *addonsv1alpha1.Addon) time.Duration {
+	namespace := GetAddonInstallNamespace(addon)
+	if !r.statusReportingEnabled || len(namespace) == 0 {
+		return 0
+	}
+
+	instance := &addonsv1alpha1.AddonInstance{}
+	if err := r.Client.Get(ctx, client.ObjectKey{
+		Name:      addonsv1alpha1.DefaultAddonInstanceName,
+		Namespace: namespace,
+	}, instance); err != nil {
+		return 0
+	}
+	staleAt, ok := heartbeatStaleAt(instance)
+	if !ok {
+		return 0
+	}
+	if requeueAfter := staleAt.Sub(r.clock.Now()); requeueAfter > 0 {
+		// Past the threshold, to find the heartbeat stale.
+		return requeueAfter + time.Second
+	}
+	return 0
+}
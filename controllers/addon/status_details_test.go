@@ -0,0 +1,355 @@
+package addon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/lib/version"
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+func newStatusDetailsTestClient(t *testing.T, objs ...runtime.Object) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, operatorsv1.AddToScheme(scheme))
+	require.NoError(t, operatorsv1alpha1.AddToScheme(scheme))
+	require.NoError(t, addonsv1alpha1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+}
+
+func newStatusDetailsTestReconciler(t *testing.T, now time.Time, objs ...runtime.Object) *AddonReconciler {
+	t.Helper()
+
+	c := newStatusDetailsTestClient(t, objs...)
+
+	clock := &testClock{}
+	clock.On("Now").Return(now)
+	return &AddonReconciler{
+		Client:         c,
+		UncachedClient: c,
+		clock:          clock,
+	}
+}
+
+func TestAddonStatusDetails_OLM(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	addon.Spec.Namespaces = []addonsv1alpha1.AddonNamespace{{Name: "addon-1"}}
+
+	r := newStatusDetailsTestReconciler(t, now,
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&operatorsv1alpha1.CatalogSource{
+			ObjectMeta: metav1.ObjectMeta{Name: CatalogSourceName(addon), Namespace: "addon-1"},
+			Status: operatorsv1alpha1.CatalogSourceStatus{
+				GRPCConnectionState: &operatorsv1alpha1.GRPCConnectionState{LastObservedState: "READY"},
+			},
+		},
+		&operatorsv1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: SubscriptionName(addon), Namespace: "addon-1"},
+			Status: operatorsv1alpha1.SubscriptionStatus{
+				CurrentCSV:   "addon-1.v1.2.0",
+				InstalledCSV: "addon-1.v1.1.0",
+			},
+		},
+		&operatorsv1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "addon-1.v1.1.0", Namespace: "addon-1"},
+			Spec: operatorsv1alpha1.ClusterServiceVersionSpec{
+				Version: version.OperatorVersion{Version: semver.MustParse("1.1.0")},
+			},
+			Status: operatorsv1alpha1.ClusterServiceVersionStatus{
+				Phase: operatorsv1alpha1.CSVPhaseSucceeded,
+			},
+		},
+		&addonsv1alpha1.AddonInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: addonsv1alpha1.DefaultAddonInstanceName, Namespace: "addon-1"},
+			Spec: addonsv1alpha1.AddonInstanceSpec{
+				HeartbeatUpdatePeriod: metav1.Duration{Duration: 10 * time.Second},
+			},
+			Status: addonsv1alpha1.AddonInstanceStatus{
+				LastHeartbeatTime: metav1.NewTime(now.Add(-20 * time.Second)),
+				Conditions: []metav1.Condition{
+					{
+						Type:    addonsv1alpha1.AddonInstanceConditionDegraded.String(),
+						Status:  metav1.ConditionTrue,
+						Reason:  "CacheUnavailable",
+						Message: "cache is unavailable",
+					},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "addon-1"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{
+						Type:   corev1.PodScheduled,
+						Status: corev1.ConditionFalse,
+						Reason: corev1.PodReasonUnschedulable,
+					},
+				},
+			},
+		},
+	)
+
+	details, err := r.addonStatusDetails(context.Background(), addon)
+	require.NoError(t, err)
+
+	assert.Equal(t, &ocm.AddOnInstalledCSV{
+		Name:    "addon-1.v1.1.0",
+		Version: "1.1.0",
+		Phase:   string(operatorsv1alpha1.CSVPhaseSucceeded),
+	}, details.InstalledCSV)
+	assert.Equal(t, 1, details.UnschedulablePods)
+	assert.Equal(t, []ocm.AddOnResourceStatus{
+		{APIVersion: "v1", Kind: "Namespace", Name: "addon-1", Ready: true},
+		{
+			APIVersion: "operators.coreos.com/v1alpha1", Kind: "CatalogSource",
+			Namespace: "addon-1", Name: CatalogSourceName(addon), Ready: true,
+		},
+		{
+			// Still upgrading to the current csv.
+			APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription",
+			Namespace: "addon-1", Name: SubscriptionName(addon), Ready: false,
+		},
+	}, details.Resources)
+
+	require.NotNil(t, details.AddonInstance)
+	assert.True(t, details.AddonInstance.HeartbeatFresh)
+	assert.True(t, now.Add(-20*time.Second).Equal(details.AddonInstance.LastHeartbeatTime.Time))
+	assert.Equal(t, &addonsv1alpha1.AddOnStatusCondition{
+		StatusType:  addonsv1alpha1.AddonInstanceConditionDegraded.String(),
+		StatusValue: metav1.ConditionTrue,
+		Reason:      "CacheUnavailable",
+		Message:     "cache is unavailable",
+	}, details.AddonInstance.Degraded)
+}
+
+func TestAddonStatusDetails_StaleHeartbeat(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+
+	r := newStatusDetailsTestReconciler(t, now,
+		&addonsv1alpha1.AddonInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: addonsv1alpha1.DefaultAddonInstanceName, Namespace: "addon-1"},
+			Status: addonsv1alpha1.AddonInstanceStatus{
+				// Default update period of 10s.
+				LastHeartbeatTime: metav1.NewTime(now.Add(-time.Minute)),
+			},
+		},
+	)
+
+	details, err := r.addonStatusDetails(context.Background(), addon)
+	require.NoError(t, err)
+
+	assert.Nil(t, details.InstalledCSV)
+	require.NotNil(t, details.AddonInstance)
+	assert.False(t, details.AddonInstance.HeartbeatFresh)
+	assert.Nil(t, details.AddonInstance.Degraded)
+}
+
+func TestAddonStatusDetails_Manifests(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+		Spec: addonsv1alpha1.AddonSpec{
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type:      addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{Namespace: "addon-1"},
+			},
+		},
+		Status: addonsv1alpha1.AddonStatus{
+			ManifestObjects: []addonsv1alpha1.AddonManifestObjectReference{
+				{APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1", Name: "config"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "ready"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "missing"},
+			},
+		},
+	}
+
+	r := newStatusDetailsTestReconciler(t, time.Now(),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "addon-1"},
+			Status: appsv1.DeploymentStatus{
+				Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1,
+			},
+		},
+	)
+
+	details, err := r.addonStatusDetails(context.Background(), addon)
+	require.NoError(t, err)
+
+	assert.Nil(t, details.AddonInstance)
+	assert.Equal(t, []ocm.AddOnResourceStatus{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "addon-1", Name: "config", Ready: true},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "ready", Ready: true},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "missing", Ready: false},
+	}, details.Resources)
+}
+
+func TestAddonStatusDetails_Helm(t *testing.T) {
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+		Spec: addonsv1alpha1.AddonSpec{
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Helm,
+				Helm: &addonsv1alpha1.AddonInstallHelm{Namespace: "addon-1"},
+			},
+		},
+		Status: addonsv1alpha1.AddonStatus{
+			HelmObjects: []addonsv1alpha1.AddonManifestObjectReference{
+				{APIVersion: "v1", Kind: "Service", Namespace: "addon-1", Name: "service"},
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "ready"},
+			},
+		},
+	}
+
+	r := newStatusDetailsTestReconciler(t, time.Now(),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "addon-1"},
+			Status: appsv1.DeploymentStatus{
+				Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1,
+			},
+		},
+	)
+
+	details, err := r.addonStatusDetails(context.Background(), addon)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ocm.AddOnResourceStatus{
+		{APIVersion: "v1", Kind: "Service", Namespace: "addon-1", Name: "service", Ready: true},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "ready", Ready: true},
+	}, details.Resources)
+}
+
+func TestAddonStatusDetails_LooksUpOnChange(t *testing.T) {
+	ctx := context.Background()
+	addon := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "addon-1"},
+		Spec: addonsv1alpha1.AddonSpec{
+			Install: addonsv1alpha1.AddonInstallSpec{
+				Type:      addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{Namespace: "addon-1"},
+			},
+		},
+		Status: addonsv1alpha1.AddonStatus{
+			Conditions: []metav1.Condition{
+				{Type: addonsv1alpha1.Available, Status: metav1.ConditionTrue},
+			},
+			ManifestObjects: []addonsv1alpha1.AddonManifestObjectReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "addon-1", Name: "deployment"},
+			},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "addon-1"},
+		Status: appsv1.DeploymentStatus{
+			Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1,
+		},
+	}
+
+	// Separate clients to tell cached from uncached lookups apart.
+	cached := newStatusDetailsTestClient(t, deployment.DeepCopy())
+	uncached := newStatusDetailsTestClient(t, deployment.DeepCopy())
+	r := &AddonReconciler{Client: cached, UncachedClient: uncached}
+
+	details, err := r.addonStatusDetails(ctx, addon)
+	require.NoError(t, err)
+	require.Len(t, details.Resources, 1)
+	assert.True(t, details.Resources[0].Ready)
+
+	unready := &appsv1.Deployment{}
+	require.NoError(t, uncached.Get(ctx, client.ObjectKeyFromObject(deployment), unready))
+	unready.Status.AvailableReplicas = 0
+	require.NoError(t, uncached.Status().Update(ctx, unready))
+
+	// Unchanged in the cache, so not looked up again.
+	details, err = r.addonStatusDetails(ctx, addon)
+	require.NoError(t, err)
+	assert.True(t, details.Resources[0].Ready)
+
+	changed := &appsv1.Deployment{}
+	require.NoError(t, cached.Get(ctx, client.ObjectKeyFromObject(deployment), changed))
+	changed.Status.AvailableReplicas = 0
+	require.NoError(t, cached.Status().Update(ctx, changed))
+
+	details, err = r.addonStatusDetails(ctx, addon)
+	require.NoError(t, err)
+	assert.False(t, details.Resources[0].Ready)
+}
+
+func TestHeartbeatStaleRequeueAfter(t *testing.T) {
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+
+	r := newStatusDetailsTestReconciler(t, now,
+		&addonsv1alpha1.AddonInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: addonsv1alpha1.DefaultAddonInstanceName, Namespace: "addon-1"},
+			Status: addonsv1alpha1.AddonInstanceStatus{
+				// Stale after 30s with the default update period of 10s.
+				LastHeartbeatTime: metav1.NewTime(now.Add(-20 * time.Second)),
+			},
+		},
+	)
+	assert.Zero(t, r.heartbeatStaleRequeueAfter(context.Background(), addon))
+
+	r.statusReportingEnabled = true
+	assert.Equal(t, 11*time.Second, r.heartbeatStaleRequeueAfter(context.Background(), addon))
+}
+
+func TestHashAddonStatusPayload(t *testing.T) {
+	addon := testutil.NewTestAddonWithCatalogSourceImage()
+	payload := newAddonStatusPayload(addon)
+
+	t.Run("payloads without details hash like the addon status", func(t *testing.T) {
+		assert.Equal(t, HashCurrentAddonStatus(addon), HashAddonStatusPayload(payload))
+	})
+
+	withDetails := payload
+	withDetails.AddonInstance = &ocm.AddOnInstanceHealth{
+		LastHeartbeatTime: &metav1.Time{Time: time.Unix(100, 0)},
+		HeartbeatFresh:    true,
+	}
+
+	t.Run("details are hashed", func(t *testing.T) {
+		assert.NotEqual(t, HashAddonStatusPayload(payload), HashAddonStatusPayload(withDetails))
+	})
+
+	t.Run("heartbeat times are not hashed", func(t *testing.T) {
+		nextHeartbeat := withDetails
+		nextHeartbeat.AddonInstance = &ocm.AddOnInstanceHealth{
+			LastHeartbeatTime: &metav1.Time{Time: time.Unix(110, 0)},
+			HeartbeatFresh:    true,
+		}
+		assert.Equal(t, HashAddonStatusPayload(withDetails), HashAddonStatusPayload(nextHeartbeat))
+		// The hashed payload is left untouched.
+		assert.Equal(t, time.Unix(110, 0), nextHeartbeat.AddonInstance.LastHeartbeatTime.Time)
+	})
+
+	t.Run("heartbeat freshness is hashed", func(t *testing.T) {
+		stale := withDetails
+		stale.AddonInstance = &ocm.AddOnInstanceHealth{
+			LastHeartbeatTime: withDetails.AddonInstance.LastHeartbeatTime,
+		}
+		assert.NotEqual(t, HashAddonStatusPayload(withDetails), HashAddonStatusPayload(stale))
+	})
+}
@@ -2,6 +2,7 @@ package addon
 
 import (
 	"context"
+	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -17,12 +18,13 @@ func (r *AddonReconciler) handleOCMAddOnStatusReporting(
 	log logr.Logger,
 	addon *addonsv1alpha1.Addon,
 ) (err error) {
-	if !r.statusReportingRequired(addon) {
+	if !r.statusReportingEnabled {
 		log.Info("skipping status reporting")
 		return nil
 	}
 
-	if r.ocmClient == nil {
+	ocmClient := r.getOCMClient()
+	if r.ocmOutbox == nil && ocmClient == nil {
 		// OCM Client is not initialized.
 		// Either the AddonOperatorReconciler did not yet create and inject the client or
 		// the AddonOperator CR is not configured for OCM status reporting.
@@ -33,33 +35,69 @@ func (r *AddonReconciler) handleOCMAddOnStatusReporting(
 		return nil
 	}
 
+	statusPayload, err := r.newAddonStatusPayloadWithDetails(ctx, addon)
+	if err != nil {
+		return fmt.Errorf("collecting addon status details: %w", err)
+	}
+	statusHash := HashAddonStatusPayload(statusPayload)
+	if !isCurrentStatusDifferentFromPrevious(addon, statusHash) &&
+		!r.isOCMStatusReportDropped(addon.Name, statusHash) {
+		log.Info("skipping status reporting")
+		return nil
+	}
+
+	if r.ocmOutbox != nil {
+		// Queued reports are sent as soon as the OCM client is initialized.
+		log.Info("queueing addon status")
+		if err := r.ocmOutbox.Enqueue(
+			ctx, ocmReportAddOnStatus, addon.Name, statusPayload); err != nil {
+			return fmt.Errorf("queueing addon status: %w", err)
+		}
+		r.ocmDroppedStatusReports.Delete(addon.Name)
+		setLastReportedStatus(addon, statusHash)
+		return nil
+	}
+
 	log.Info("upserting addon status")
-	err = r.postAddonStatus(ctx, addon)
+	err = r.postAddonStatus(ctx, ocmClient, statusPayload)
 	if err != nil {
 		return err
 	}
 
 	// Before returning we store the current reported status
 	// in the addon's status block.
-	setLastReportedStatus(addon)
+	setLastReportedStatus(addon, statusHash)
 	return nil
 }
 
-func (r *AddonReconciler) postAddonStatus(ctx context.Context, addon *addonsv1alpha1.Addon) (err error) {
-	statusPayload := ocm.AddOnStatusPostRequest{
+func (r *AddonReconciler) postAddonStatus(
+	ctx context.Context, ocmClient ocmClient, statusPayload ocm.AddOnStatusPostRequest,
+) (err error) {
+	r.recordAddonServiceRequestDuration(func() {
+		_, err = ocmClient.PostAddOnStatus(ctx, statusPayload)
+	})
+	return
+}
+
+func newAddonStatusPayload(addon *addonsv1alpha1.Addon) ocm.AddOnStatusPostRequest {
+	return ocm.AddOnStatusPostRequest{
 		AddonID:          addon.Name,
 		CorrelationID:    addon.Spec.CorrelationID,
 		AddonVersion:     addon.Spec.Version,
 		StatusConditions: mapToAddonStatusConditions(addon.Status.Conditions),
 	}
-	r.recordAddonServiceRequestDuration(func() {
-		_, err = r.ocmClient.PostAddOnStatus(ctx, statusPayload)
-	})
-	return
 }
 
-func (r *AddonReconciler) statusReportingRequired(addon *addonsv1alpha1.Addon) bool {
-	return r.statusReportingEnabled && isCurrentStatusDifferentFromPrevious(addon)
+func (r *AddonReconciler) newAddonStatusPayloadWithDetails(
+	ctx context.Context, addon *addonsv1alpha1.Addon,
+) (ocm.AddOnStatusPostRequest, error) {
+	statusPayload := newAddonStatusPayload(addon)
+	details, err := r.addonStatusDetails(ctx, addon)
+	if err != nil {
+		return ocm.AddOnStatusPostRequest{}, err
+	}
+	statusPayload.AddOnStatusDetails = details
+	return statusPayload, nil
 }
 
 func (r *AddonReconciler) recordAddonServiceRequestDuration(reqFunc func()) {
@@ -86,17 +124,17 @@ func mapToAddonStatusConditions(in []metav1.Condition) []addonsv1alpha1.AddOnSta
 	return res
 }
 
-func isCurrentStatusDifferentFromPrevious(addon *addonsv1alpha1.Addon) bool {
+func isCurrentStatusDifferentFromPrevious(addon *addonsv1alpha1.Addon, statusHash string) bool {
 	if addon.Status.OCMReportedStatusHash != nil {
-		return addon.Status.OCMReportedStatusHash.StatusHash != HashCurrentAddonStatus(addon)
+		return addon.Status.OCMReportedStatusHash.StatusHash != statusHash
 	}
 	// If reported status is nil.
 	return true
 }
 
-func setLastReportedStatus(addon *addonsv1alpha1.Addon) {
+func setLastReportedStatus(addon *addonsv1alpha1.Addon, statusHash string) {
 	addon.Status.OCMReportedStatusHash = &addonsv1alpha1.OCMAddOnStatusHash{
-		StatusHash:         HashCurrentAddonStatus(addon),
+		StatusHash:         statusHash,
 		ObservedGeneration: addon.Generation,
 	}
 }
@@ -3,11 +3,15 @@ package addon
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/openshift/addon-operator/internal/ocm"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 )
@@ -23,10 +27,9 @@ func (r *AddonReconciler) handleUpgradePolicyStatusReporting(
 		return nil
 	}
 
-	r.ocmClientMux.RLock()
-	defer r.ocmClientMux.RUnlock()
-
-	if r.ocmClient == nil {
+	// The client is copied, so the lock isn't held during OCM requests.
+	ocmClient := r.getOCMClient()
+	if ocmClient == nil {
 		// OCM Client is not initialized.
 		// Either the AddonOperatorReconciler did not yet create and inject the client or
 		// the AddonOperator CR is not configured for OCM status reporting.
@@ -37,7 +40,7 @@ func (r *AddonReconciler) handleUpgradePolicyStatusReporting(
 		return nil
 	}
 
-	stateVal, err := r.getPreviousUpgradePolicyStateValue(ctx, addon.Spec.UpgradePolicy.ID)
+	stateVal, err := r.getPreviousUpgradePolicyStateValue(ctx, ocmClient, addon.Spec.UpgradePolicy.ID)
 	if err != nil {
 		return fmt.Errorf("getting previous UpgradePolicy state value: %w", err)
 	}
@@ -51,7 +54,7 @@ func (r *AddonReconciler) handleUpgradePolicyStatusReporting(
 	if addon.Status.UpgradePolicy == nil {
 		log.Info("UpgradePolicy status unknown; reporting upgrade as started")
 
-		return r.reportUpgradeStarted(ctx, addon)
+		return r.reportUpgradeStarted(ctx, ocmClient, addon)
 	}
 	if addon.Status.UpgradePolicy.Version == "" {
 		log.Info("previous upgrade version unknown")
@@ -79,37 +82,135 @@ func (r *AddonReconciler) handleUpgradePolicyStatusReporting(
 			),
 		)
 
-		return r.reportUpgradeStarted(ctx, addon)
+		return r.reportUpgradeStarted(ctx, ocmClient, addon)
+	}
+	if cond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.RolledBack); cond != nil &&
+		cond.Status == metav1.ConditionTrue {
+		if addon.Status.UpgradePolicy.Value == addonsv1alpha1.AddonUpgradePolicyValueFailed {
+			log.Info("rolled back upgrade already reported as failed")
+
+			return nil
+		}
+		log.Info("upgrade was rolled back; reporting upgrade as failed")
+
+		return r.reportUpgradeFailed(ctx, ocmClient, addon, cond.Message)
+	}
+	if meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.WaitingForMaintenanceWindow) {
+		log.Info("upgrade is waiting for a maintenance window")
+
+		return nil
 	}
 	if addon.IsAvailable() {
 		if stateVal == ocm.UpgradePolicyValueScheduled {
 			log.Info("UpgradePolicy in scheduled state; reporting upgrade as started before completed")
 
-			if err := r.reportUpgradeStarted(ctx, addon); err != nil {
+			if err := r.reportUpgradeStarted(ctx, ocmClient, addon); err != nil {
 				return fmt.Errorf("reporting upgrade as started: %w", err)
 			}
 		}
 
+		// Also after the upgrade was reported as failed or delayed,
+		// because it exceeded its deadline.
 		log.Info("reporting upgrade as completed")
 
-		return r.reportUpgradeCompleted(ctx, addon)
+		return r.reportUpgradeCompleted(ctx, ocmClient, addon)
+	}
+	if addon.Status.UpgradePolicy.Value == addonsv1alpha1.AddonUpgradePolicyValueFailed {
+		log.Info("upgrade already reported as failed")
+
+		return nil
+	}
+	if addon.Status.UpgradePolicy.Value == addonsv1alpha1.AddonUpgradePolicyValueDelayed {
+		log.Info("upgrade already reported as delayed")
+
+		return nil
+	}
+	if addon.Spec.UpgradePolicy.Deadline.Duration > 0 && addon.Status.UpgradePolicy.StartedTime == nil {
+		// Upgrades started before the start time was tracked
+		// get their deadline counted from now on.
+		now := metav1.NewTime(r.clock.Now())
+		addon.Status.UpgradePolicy.StartedTime = &now
+
+		return nil
+	}
+	if deadline, ok := upgradePolicyDeadline(addon); ok && !r.clock.Now().Before(deadline) {
+		value := upgradePolicyDeadlineExceededValue(addon)
+		log.Info(fmt.Sprintf("upgrade deadline exceeded; reporting upgrade as %s", value))
+
+		return r.reportUpgradeDeadlineExceeded(ctx, ocmClient, addon, value)
 	}
 
 	return nil
 }
 
+// Returns the time the Addon has to become Available by,
+// false when the UpgradePolicy has no deadline or the upgrade was not started.
+func upgradePolicyDeadline(addon *addonsv1alpha1.Addon) (time.Time, bool) {
+	if addon.Spec.UpgradePolicy == nil || addon.Spec.UpgradePolicy.Deadline.Duration <= 0 ||
+		addon.Status.UpgradePolicy == nil || addon.Status.UpgradePolicy.StartedTime == nil {
+		return time.Time{}, false
+	}
+	return addon.Status.UpgradePolicy.StartedTime.Add(addon.Spec.UpgradePolicy.Deadline.Duration), true
+}
+
+func upgradePolicyDeadlineExceededValue(addon *addonsv1alpha1.Addon) addonsv1alpha1.AddonUpgradePolicyValue {
+	if addon.Spec.UpgradePolicy.DeadlineExceededValue == addonsv1alpha1.AddonUpgradePolicyValueDelayed {
+		return addonsv1alpha1.AddonUpgradePolicyValueDelayed
+	}
+	return addonsv1alpha1.AddonUpgradePolicyValueFailed
+}
+
+// Returns when the Addon needs to be reconciled again to report an exceeded upgrade deadline,
+// 0 if no deadline is pending.
+func (r *AddonReconciler) upgradePolicyDeadlineRequeueAfter(addon *addonsv1alpha1.Addon) time.Duration {
+	if !requiresReporting(addon) || !r.upgradePolicyStatusEnabled ||
+		addon.Status.UpgradePolicy.Value != addonsv1alpha1.AddonUpgradePolicyValueStarted ||
+		addon.Status.UpgradePolicy.Version != addon.Spec.Version {
+		return 0
+	}
+	deadline, ok := upgradePolicyDeadline(addon)
+	if !ok {
+		return 0
+	}
+	return max(deadline.Sub(r.clock.Now()), time.Second)
+}
+
+// Describes why the Addon is not Available from its conditions.
+func unreadyConditionsMessage(addon *addonsv1alpha1.Addon) string {
+	var unready []string
+	for _, condType := range []string{addonsv1alpha1.Available, addonsv1alpha1.Installed} {
+		cond := meta.FindStatusCondition(addon.Status.Conditions, condType)
+		switch {
+		case cond == nil:
+			unready = append(unready, fmt.Sprintf("%s: condition missing", condType))
+		case cond.Status != metav1.ConditionTrue:
+			msg := fmt.Sprintf("%s: %s", condType, cond.Reason)
+			if len(cond.Message) > 0 {
+				msg += ": " + cond.Message
+			}
+			unready = append(unready, msg)
+		}
+	}
+	if len(unready) == 0 {
+		return "Addon is not Available."
+	}
+	return strings.Join(unready, "; ")
+}
+
 func requiresReporting(addon *addonsv1alpha1.Addon) bool {
 	return addon.Spec.Version != "" &&
 		addon.Spec.UpgradePolicy != nil &&
 		!addon.UpgradeCompleteForCurrentVersion()
 }
 
-func (r *AddonReconciler) getPreviousUpgradePolicyStateValue(ctx context.Context, policyID string) (ocm.UpgradePolicyValue, error) {
+func (r *AddonReconciler) getPreviousUpgradePolicyStateValue(
+	ctx context.Context, ocmClient ocmClient, policyID string,
+) (ocm.UpgradePolicyValue, error) {
 	req := ocm.UpgradePolicyGetRequest{
 		ID: policyID,
 	}
 
-	res, err := r.handleGetUpgradePolicyState(ctx, req)
+	res, err := r.handleGetUpgradePolicyState(ctx, ocmClient, req)
 	if err != nil {
 		return ocm.UpgradePolicyValueNone, fmt.Errorf(
 			"getting UpgradePolicy %q state: %w", policyID, err,
@@ -119,7 +220,19 @@ func (r *AddonReconciler) getPreviousUpgradePolicyStateValue(ctx context.Context
 	return res.Value, nil
 }
 
-func (r *AddonReconciler) reportUpgradeStarted(ctx context.Context, addon *addonsv1alpha1.Addon) error {
+// Looks up the state of an UpgradePolicy outside of status reporting.
+// Returns UpgradePolicyValueNone until the OCM client is initialized.
+func (r *AddonReconciler) lookupUpgradePolicyState(ctx context.Context, policyID string) (ocm.UpgradePolicyValue, error) {
+	ocmClient := r.getOCMClient()
+	if ocmClient == nil {
+		return ocm.UpgradePolicyValueNone, nil
+	}
+	return r.getPreviousUpgradePolicyStateValue(ctx, ocmClient, policyID)
+}
+
+func (r *AddonReconciler) reportUpgradeStarted(
+	ctx context.Context, ocmClient ocmClient, addon *addonsv1alpha1.Addon,
+) error {
 	var (
 		policyID = addon.Spec.UpgradePolicy.ID
 		version  = addon.Spec.Version
@@ -131,18 +244,24 @@ func (r *AddonReconciler) reportUpgradeStarted(ctx context.Context, addon *addon
 		Description: fmt.Sprintf("Upgrading addon to version %q.", version),
 	}
 
-	if err := r.handlePatchUpgradePolicy(ctx, req); err != nil {
+	if err := r.patchUpgradePolicy(ctx, ocmClient, addon, req); err != nil {
 		return fmt.Errorf(
 			"patching UpgradePolicy %q at version %q as 'Started': %w", policyID, version, err,
 		)
 	}
 
 	addon.SetUpgradePolicyStatus(addonsv1alpha1.AddonUpgradePolicyValueStarted)
+	if addon.Status.UpgradePolicy.StartedTime == nil {
+		now := metav1.NewTime(r.clock.Now())
+		addon.Status.UpgradePolicy.StartedTime = &now
+	}
 
 	return nil
 }
 
-func (r *AddonReconciler) reportUpgradeCompleted(ctx context.Context, addon *addonsv1alpha1.Addon) error {
+func (r *AddonReconciler) reportUpgradeCompleted(
+	ctx context.Context, ocmClient ocmClient, addon *addonsv1alpha1.Addon,
+) error {
 	var (
 		policyID = addon.Spec.UpgradePolicy.ID
 		version  = addon.Spec.Version
@@ -154,7 +273,7 @@ func (r *AddonReconciler) reportUpgradeCompleted(ctx context.Context, addon *add
 		Description: fmt.Sprintf("Addon was healthy at least once at version %q.", version),
 	}
 
-	if err := r.handlePatchUpgradePolicy(ctx, req); err != nil {
+	if err := r.patchUpgradePolicy(ctx, ocmClient, addon, req); err != nil {
 		return fmt.Errorf(
 			"patching UpgradePolicy %q at version %q to 'Completed': %w", policyID, version, err,
 		)
@@ -165,19 +284,76 @@ func (r *AddonReconciler) reportUpgradeCompleted(ctx context.Context, addon *add
 	return nil
 }
 
+func (r *AddonReconciler) reportUpgradeFailed(
+	ctx context.Context, ocmClient ocmClient, addon *addonsv1alpha1.Addon, message string,
+) error {
+	var (
+		policyID = addon.Spec.UpgradePolicy.ID
+		version  = addon.Spec.Version
+	)
+
+	req := ocm.UpgradePolicyPatchRequest{
+		ID:          policyID,
+		Value:       ocm.UpgradePolicyValueFailed,
+		Description: fmt.Sprintf("Upgrading addon to version %q failed: %s", version, message),
+	}
+
+	if err := r.patchUpgradePolicy(ctx, ocmClient, addon, req); err != nil {
+		return fmt.Errorf(
+			"patching UpgradePolicy %q at version %q to 'Failed': %w", policyID, version, err,
+		)
+	}
+
+	addon.SetUpgradePolicyStatus(addonsv1alpha1.AddonUpgradePolicyValueFailed)
+
+	return nil
+}
+
+func (r *AddonReconciler) reportUpgradeDeadlineExceeded(
+	ctx context.Context, ocmClient ocmClient,
+	addon *addonsv1alpha1.Addon, value addonsv1alpha1.AddonUpgradePolicyValue,
+) error {
+	var (
+		policyID = addon.Spec.UpgradePolicy.ID
+		version  = addon.Spec.Version
+		message  = unreadyConditionsMessage(addon)
+	)
+
+	req := ocm.UpgradePolicyPatchRequest{
+		ID:    policyID,
+		Value: ocm.UpgradePolicyValue(value),
+		Description: fmt.Sprintf(
+			"Addon did not become available at version %q within %s: %s",
+			version, addon.Spec.UpgradePolicy.Deadline.Duration, message),
+	}
+
+	if err := r.patchUpgradePolicy(ctx, ocmClient, addon, req); err != nil {
+		return fmt.Errorf(
+			"patching UpgradePolicy %q at version %q to '%s': %w", policyID, version, value, err,
+		)
+	}
+
+	addon.SetUpgradePolicyStatus(value)
+	reportAddonUpgradeFailed(addon, value, fmt.Sprintf(
+		"Upgrade to version %q exceeded its deadline of %s: %s",
+		version, addon.Spec.UpgradePolicy.Deadline.Duration, message))
+
+	return nil
+}
+
 func (r *AddonReconciler) handlePatchUpgradePolicy(ctx context.Context,
-	req ocm.UpgradePolicyPatchRequest) (err error) {
+	ocmClient ocmClient, req ocm.UpgradePolicyPatchRequest) (err error) {
 	r.recordOCMRequestDuration(func() {
-		_, err = r.ocmClient.PatchUpgradePolicy(ctx, req)
+		_, err = ocmClient.PatchUpgradePolicy(ctx, req)
 	})
 
 	return
 }
 
 func (r *AddonReconciler) handleGetUpgradePolicyState(ctx context.Context,
-	req ocm.UpgradePolicyGetRequest) (res ocm.UpgradePolicyGetResponse, err error) {
+	ocmClient ocmClient, req ocm.UpgradePolicyGetRequest) (res ocm.UpgradePolicyGetResponse, err error) {
 	r.recordOCMRequestDuration(func() {
-		res, err = r.ocmClient.GetUpgradePolicy(ctx, req)
+		res, err = ocmClient.GetUpgradePolicy(ctx, req)
 	})
 
 	return
@@ -2,13 +2,16 @@ package addon
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/openshift/addon-operator/internal/metrics"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
@@ -135,6 +138,7 @@ func TestAddonReconciler_handleUpgradePolicyStatusReporting(t *testing.T) {
 			Client:    client,
 			ocmClient: ocmClient,
 			Recorder:  recorder,
+			clock:     defaultClock{},
 		}
 		r.upgradePolicyStatusEnabled = true
 		var Version = "1.0.0"
@@ -192,6 +196,7 @@ func TestAddonReconciler_handleUpgradePolicyStatusReporting(t *testing.T) {
 			assert.Equal(t,
 				addon.Generation,
 				addon.Status.UpgradePolicy.ObservedGeneration)
+			assert.NotNil(t, addon.Status.UpgradePolicy.StartedTime)
 		}
 	})
 
@@ -319,4 +324,320 @@ func TestAddonReconciler_handleUpgradePolicyStatusReporting(t *testing.T) {
 				addon.Status.UpgradePolicy.ObservedGeneration)
 		}
 	})
+
+	t.Run("post `failed` after `started` when rolled back", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		recorder := metrics.NewRecorder(false, "asdfew143")
+
+		mockSummary := testutil.NewSummaryMock()
+		recorder.InjectOCMAPIRequestDuration(mockSummary)
+
+		var Version = "1.0.0"
+
+		r := &AddonReconciler{
+			ocmClient: ocmClient,
+			Recorder:  recorder,
+		}
+		r.upgradePolicyStatusEnabled = true
+		log := testutil.NewLogger(t)
+		addon := &addonsv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{
+				Generation: 100,
+			},
+			Spec: addonsv1alpha1.AddonSpec{
+				Version: Version,
+				UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicy{
+					ID: "1234",
+				},
+			},
+			Status: addonsv1alpha1.AddonStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   addonsv1alpha1.Available,
+						Status: metav1.ConditionTrue,
+					},
+					{
+						Type:    addonsv1alpha1.RolledBack,
+						Status:  metav1.ConditionTrue,
+						Reason:  addonsv1alpha1.AddonReasonCSVFailed,
+						Message: "Rolled back to addon-1.v0.9.0.",
+					},
+				},
+				UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicyStatus{
+					ID:      "1234",
+					Version: Version,
+					Value:   addonsv1alpha1.AddonUpgradePolicyValueStarted,
+				},
+			},
+		}
+
+		ocmClient.
+			On("GetUpgradePolicy", mock.Anything, ocm.UpgradePolicyGetRequest{
+				ID: "1234",
+			}).
+			Return(
+				ocm.UpgradePolicyGetResponse{
+					Value: ocm.UpgradePolicyValueStarted,
+				}, nil,
+			)
+		ocmClient.
+			On("PatchUpgradePolicy", mock.Anything, ocm.UpgradePolicyPatchRequest{
+				ID:          "1234",
+				Value:       ocm.UpgradePolicyValueFailed,
+				Description: `Upgrading addon to version "1.0.0" failed: Rolled back to addon-1.v0.9.0.`,
+			}).
+			Return(
+				ocm.UpgradePolicyPatchResponse{},
+				nil,
+			).
+			Once()
+		mockSummary.On(
+			"Observe", mock.IsType(float64(0)))
+
+		err := r.handleUpgradePolicyStatusReporting(
+			context.Background(), log, addon)
+		require.NoError(t, err)
+
+		if assert.NotNil(t, addon.Status.UpgradePolicy) {
+			assert.Equal(t,
+				addonsv1alpha1.AddonUpgradePolicyValueFailed,
+				addon.Status.UpgradePolicy.Value)
+		}
+
+		// Failures are reported once.
+		err = r.handleUpgradePolicyStatusReporting(
+			context.Background(), log, addon)
+		require.NoError(t, err)
+
+		ocmClient.AssertNumberOfCalls(t, "PatchUpgradePolicy", 1)
+	})
+}
+
+func TestAddonReconciler_handleUpgradePolicyStatusReporting_Deadline(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newAddon := func(value addonsv1alpha1.AddonUpgradePolicyValue, startedTime time.Time) *addonsv1alpha1.Addon {
+		started := metav1.NewTime(startedTime)
+		return &addonsv1alpha1.Addon{
+			Spec: addonsv1alpha1.AddonSpec{
+				Version: "1.0.0",
+				UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicy{
+					ID:       "1234",
+					Deadline: metav1.Duration{Duration: time.Hour},
+				},
+			},
+			Status: addonsv1alpha1.AddonStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:    addonsv1alpha1.Available,
+						Status:  metav1.ConditionFalse,
+						Reason:  addonsv1alpha1.AddonReasonUnreadyCSV,
+						Message: "ClusterServiceVersion is not ready: InstallCheckFailed",
+					},
+					{
+						Type:   addonsv1alpha1.UpgradeStarted,
+						Status: metav1.ConditionTrue,
+						Reason: addonsv1alpha1.AddonReasonUpgradeStarted,
+					},
+				},
+				UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicyStatus{
+					ID:          "1234",
+					Version:     "1.0.0",
+					Value:       value,
+					StartedTime: &started,
+				},
+			},
+		}
+	}
+
+	newReconciler := func(ocmClient *ocmtest.Client) *AddonReconciler {
+		clock := &testClock{}
+		clock.On("Now").Return(now)
+		ocmClient.
+			On("GetUpgradePolicy", mock.Anything, ocm.UpgradePolicyGetRequest{ID: "1234"}).
+			Return(ocm.UpgradePolicyGetResponse{Value: ocm.UpgradePolicyValueStarted}, nil)
+		return &AddonReconciler{
+			ocmClient:                  ocmClient,
+			clock:                      clock,
+			upgradePolicyStatusEnabled: true,
+		}
+	}
+
+	const description = `Addon did not become available at version "1.0.0" within 1h0m0s: ` +
+		`Available: UnreadyCSV: ClusterServiceVersion is not ready: InstallCheckFailed; Installed: condition missing`
+
+	t.Run("noop within deadline", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		r := newReconciler(ocmClient)
+		addon := newAddon(addonsv1alpha1.AddonUpgradePolicyValueStarted, now.Add(-30*time.Minute))
+
+		err := r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+
+		ocmClient.AssertNotCalled(t, "PatchUpgradePolicy", mock.Anything, mock.Anything)
+		assert.Equal(t, 30*time.Minute, r.upgradePolicyDeadlineRequeueAfter(addon))
+	})
+
+	t.Run("post `failed` when deadline exceeded", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		r := newReconciler(ocmClient)
+		addon := newAddon(addonsv1alpha1.AddonUpgradePolicyValueStarted, now.Add(-time.Hour))
+
+		ocmClient.
+			On("PatchUpgradePolicy", mock.Anything, ocm.UpgradePolicyPatchRequest{
+				ID:          "1234",
+				Value:       ocm.UpgradePolicyValueFailed,
+				Description: description,
+			}).
+			Return(ocm.UpgradePolicyPatchResponse{}, nil).
+			Once()
+
+		err := r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+
+		assert.Equal(t, addonsv1alpha1.AddonUpgradePolicyValueFailed, addon.Status.UpgradePolicy.Value)
+		assert.Equal(t, now.Add(-time.Hour), addon.Status.UpgradePolicy.StartedTime.Time)
+		cond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.UpgradeFailed)
+		require.NotNil(t, cond)
+		assert.Equal(t, addonsv1alpha1.AddonReasonUpgradeFailed, cond.Reason)
+		assert.Nil(t, meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.UpgradeStarted))
+		assert.Zero(t, r.upgradePolicyDeadlineRequeueAfter(addon))
+
+		// Failures are reported once.
+		err = r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+		ocmClient.AssertNumberOfCalls(t, "PatchUpgradePolicy", 1)
+	})
+
+	t.Run("post `delayed` and `completed` once Available", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		r := newReconciler(ocmClient)
+		addon := newAddon(addonsv1alpha1.AddonUpgradePolicyValueStarted, now.Add(-2*time.Hour))
+		addon.Spec.UpgradePolicy.DeadlineExceededValue = addonsv1alpha1.AddonUpgradePolicyValueDelayed
+
+		ocmClient.
+			On("PatchUpgradePolicy", mock.Anything, ocm.UpgradePolicyPatchRequest{
+				ID:          "1234",
+				Value:       ocm.UpgradePolicyValueDelayed,
+				Description: description,
+			}).
+			Return(ocm.UpgradePolicyPatchResponse{}, nil).
+			Once()
+
+		err := r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+
+		assert.Equal(t, addonsv1alpha1.AddonUpgradePolicyValueDelayed, addon.Status.UpgradePolicy.Value)
+		assert.True(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.UpgradeFailed))
+		assert.True(t, meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.UpgradeStarted))
+
+		// Delays are reported once.
+		err = r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+		ocmClient.AssertNumberOfCalls(t, "PatchUpgradePolicy", 1)
+
+		ocmClient.
+			On("PatchUpgradePolicy", mock.Anything, ocm.UpgradePolicyPatchRequest{
+				ID:          "1234",
+				Value:       ocm.UpgradePolicyValueCompleted,
+				Description: `Addon was healthy at least once at version "1.0.0".`,
+			}).
+			Return(ocm.UpgradePolicyPatchResponse{}, nil)
+		meta.SetStatusCondition(&addon.Status.Conditions, metav1.Condition{
+			Type:   addonsv1alpha1.Available,
+			Status: metav1.ConditionTrue,
+			Reason: addonsv1alpha1.AddonReasonFullyReconciled,
+		})
+
+		err = r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+		assert.Equal(t, addonsv1alpha1.AddonUpgradePolicyValueCompleted, addon.Status.UpgradePolicy.Value)
+
+		reportAddonUpgradeSucceeded(addon)
+		assert.Nil(t, meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.UpgradeFailed))
+	})
+
+	t.Run("post `completed` after `failed` once Available", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		r := newReconciler(ocmClient)
+		addon := newAddon(addonsv1alpha1.AddonUpgradePolicyValueFailed, now.Add(-2*time.Hour))
+		meta.SetStatusCondition(&addon.Status.Conditions, metav1.Condition{
+			Type:   addonsv1alpha1.Available,
+			Status: metav1.ConditionTrue,
+			Reason: addonsv1alpha1.AddonReasonFullyReconciled,
+		})
+
+		ocmClient.
+			On("PatchUpgradePolicy", mock.Anything, ocm.UpgradePolicyPatchRequest{
+				ID:          "1234",
+				Value:       ocm.UpgradePolicyValueCompleted,
+				Description: `Addon was healthy at least once at version "1.0.0".`,
+			}).
+			Return(ocm.UpgradePolicyPatchResponse{}, nil).
+			Once()
+
+		err := r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+
+		ocmClient.AssertExpectations(t)
+		assert.Equal(t, addonsv1alpha1.AddonUpgradePolicyValueCompleted, addon.Status.UpgradePolicy.Value)
+	})
+
+	t.Run("noop without deadline", func(t *testing.T) {
+		ocmClient := ocmtest.NewClient()
+		r := newReconciler(ocmClient)
+		addon := newAddon(addonsv1alpha1.AddonUpgradePolicyValueStarted, now.Add(-24*time.Hour))
+		addon.Spec.UpgradePolicy.Deadline = metav1.Duration{}
+
+		err := r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+		require.NoError(t, err)
+
+		ocmClient.AssertNotCalled(t, "PatchUpgradePolicy", mock.Anything, mock.Anything)
+		assert.Zero(t, r.upgradePolicyDeadlineRequeueAfter(addon))
+	})
+}
+
+func TestAddonReconciler_handleUpgradePolicyStatusReporting_DoesNotBlockOCMClientInjection(t *testing.T) {
+	ocmClient := ocmtest.NewClient()
+	r := &AddonReconciler{
+		ocmClient:                  ocmClient,
+		upgradePolicyStatusEnabled: true,
+	}
+	addon := &addonsv1alpha1.Addon{
+		Spec: addonsv1alpha1.AddonSpec{
+			Version:       "1.0.0",
+			UpgradePolicy: &addonsv1alpha1.AddonUpgradePolicy{ID: "1234"},
+		},
+	}
+
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	ocmClient.
+		On("GetUpgradePolicy", mock.Anything, ocm.UpgradePolicyGetRequest{ID: "1234"}).
+		Run(func(mock.Arguments) {
+			close(requested)
+			<-release
+		}).
+		Return(ocm.UpgradePolicyGetResponse{}, errors.New("timeout"))
+
+	done := make(chan error)
+	go func() {
+		done <- r.handleUpgradePolicyStatusReporting(context.Background(), testutil.NewLogger(t), addon)
+	}()
+	<-requested
+
+	// A new client is injected while the OCM request is still running.
+	injected := make(chan error)
+	go func() {
+		injected <- r.InjectOCMClient(context.Background(), &ocm.Client{})
+	}()
+	select {
+	case err := <-injected:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("OCM client injection blocked by running OCM request")
+	}
+
+	close(release)
+	require.Error(t, <-done)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
 )
 
 type subReconcilerResult struct {
@@ -52,11 +54,24 @@ func (r *AddonReconciler) handleAddonCRDeletion(
 		return res, nil
 	}
 
+	// Addons are deleted after the Addons depending on them,
+	// which requeue this Addon when they are gone.
+	dependents, err := r.listDependentAddons(ctx, addon)
+	if err != nil {
+		return res, err
+	}
+
 	// Report that Addon is deleting.
 	reportTerminationStatus(addon)
+	if len(dependents) > 0 {
+		reportWaitingForDependents(addon, dependents)
+	}
 	if err := r.Status().Update(ctx, addon); err != nil {
 		return res, fmt.Errorf("updating Addon status: %w", err)
 	}
+	if len(dependents) > 0 {
+		return res, nil
+	}
 
 	res, err = r.ensureClusterPackageDeletion(ctx, addon)
 	if err != nil {
@@ -95,6 +110,11 @@ func (r *AddonReconciler) ensureClusterPackageDeletion(
 
 // Report Addon status to communicate that everything is alright
 func reportReadinessStatus(addon *addonsv1alpha1.Addon) {
+	// Records the install of Addons not deployed through OLM, once they are Available for the first time.
+	if meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Available) == nil {
+		recordInstallHistory(addon)
+	}
+	reportHistoryAvailable(addon)
 	meta.SetStatusCondition(&addon.Status.Conditions, metav1.Condition{
 		Type:               addonsv1alpha1.Available,
 		Status:             metav1.ConditionTrue,
@@ -194,6 +214,7 @@ func (r *AddonReconciler) removeAddonPauseCondition(addon *addonsv1alpha1.Addon)
 
 func reportLastObservedAvailableCSV(addon *addonsv1alpha1.Addon, csvName string) {
 	addon.Status.LastObservedAvailableCSV = csvName
+	reportHistoryCSV(addon, csvName)
 }
 
 func reportAddonUpgradeSucceeded(addon *addonsv1alpha1.Addon) {
@@ -202,6 +223,8 @@ func reportAddonUpgradeSucceeded(addon *addonsv1alpha1.Addon) {
 	if upgradeStartedCond != nil {
 		// Remove the upgrade started condition
 		meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.UpgradeStarted)
+		meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.UpgradeFailed)
+		finishCurrentHistoryEntry(addon, addonsv1alpha1.AddonVersionTransitionSucceeded)
 		meta.SetStatusCondition(&addon.Status.Conditions,
 			metav1.Condition{
 				Type:               addonsv1alpha1.UpgradeSucceeded,
@@ -215,11 +238,14 @@ func reportAddonUpgradeSucceeded(addon *addonsv1alpha1.Addon) {
 }
 
 func reportAddonUpgradeStarted(addon *addonsv1alpha1.Addon) {
-	// If upgrade succeeded status was previously set, remove it.
+	// If upgrade succeeded or rolled back status was previously set, remove it.
 	upgradeSucceededCond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.UpgradeSucceeded)
 	if upgradeSucceededCond != nil {
 		meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.UpgradeSucceeded)
 	}
+	meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.RolledBack)
+	meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.UpgradeFailed)
+	startHistoryEntry(addon)
 	meta.SetStatusCondition(&addon.Status.Conditions,
 		metav1.Condition{
 			Type:               addonsv1alpha1.UpgradeStarted,
@@ -231,6 +257,69 @@ func reportAddonUpgradeStarted(addon *addonsv1alpha1.Addon) {
 	addon.Status.ObservedGeneration = addon.Generation
 }
 
+func reportAddonUpgradeRolledBack(addon *addonsv1alpha1.Addon, reason, message string) {
+	meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.UpgradeStarted)
+	finishCurrentHistoryEntry(addon, addonsv1alpha1.AddonVersionTransitionRolledBack)
+	meta.SetStatusCondition(&addon.Status.Conditions,
+		metav1.Condition{
+			Type:               addonsv1alpha1.RolledBack,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: addon.Generation,
+		})
+	addon.Status.ObservedGeneration = addon.Generation
+}
+
+// Reports that the upgrade did not finish within the deadline of the UpgradePolicy.
+// Delayed upgrades may still succeed, so the UpgradeStarted condition is only removed for failed ones.
+func reportAddonUpgradeFailed(addon *addonsv1alpha1.Addon, value addonsv1alpha1.AddonUpgradePolicyValue, message string) {
+	if value == addonsv1alpha1.AddonUpgradePolicyValueFailed {
+		meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.UpgradeStarted)
+		finishCurrentHistoryEntry(addon, addonsv1alpha1.AddonVersionTransitionFailed)
+	}
+	meta.SetStatusCondition(&addon.Status.Conditions,
+		metav1.Condition{
+			Type:               addonsv1alpha1.UpgradeFailed,
+			Status:             metav1.ConditionTrue,
+			Reason:             addonsv1alpha1.AddonReasonUpgradeFailed,
+			Message:            message,
+			ObservedGeneration: addon.Generation,
+		})
+	addon.Status.ObservedGeneration = addon.Generation
+}
+
+const waitingForMaintenanceWindowMessage = "Deferred until the next maintenance window opens: "
+
+// Adds the deferred change to the WaitingForMaintenanceWindow condition.
+func reportWaitingForMaintenanceWindow(addon *addonsv1alpha1.Addon, change string, nextOpen time.Time) {
+	if slices.Contains(addon.Status.DeferredChanges, change) {
+		return
+	}
+	addon.Status.DeferredChanges = append(addon.Status.DeferredChanges, change)
+
+	meta.SetStatusCondition(&addon.Status.Conditions,
+		metav1.Condition{
+			Type:               addonsv1alpha1.WaitingForMaintenanceWindow,
+			Status:             metav1.ConditionTrue,
+			Reason:             addonsv1alpha1.AddonReasonMaintenanceWindowClosed,
+			Message:            waitingForMaintenanceWindowMessage + strings.Join(addon.Status.DeferredChanges, ", ") + ".",
+			ObservedGeneration: addon.Generation,
+		})
+	addon.Status.NextMaintenanceWindow = nil
+	if !nextOpen.IsZero() {
+		next := metav1.NewTime(nextOpen)
+		addon.Status.NextMaintenanceWindow = &next
+	}
+	addon.Status.ObservedGeneration = addon.Generation
+}
+
+func removeWaitingForMaintenanceWindow(addon *addonsv1alpha1.Addon) {
+	meta.RemoveStatusCondition(&addon.Status.Conditions, addonsv1alpha1.WaitingForMaintenanceWindow)
+	addon.Status.NextMaintenanceWindow = nil
+	addon.Status.DeferredChanges = nil
+}
+
 func reportUninstalledCondition(addon *addonsv1alpha1.Addon) {
 	installedCond := meta.FindStatusCondition(addon.Status.Conditions, addonsv1alpha1.Installed)
 	if installedCond != nil {
@@ -248,6 +337,9 @@ func reportUninstalledCondition(addon *addonsv1alpha1.Addon) {
 }
 
 func reportInstalledCondition(addon *addonsv1alpha1.Addon) {
+	if !meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Installed) {
+		recordInstallHistory(addon)
+	}
 	meta.SetStatusCondition(&addon.Status.Conditions,
 		metav1.Condition{
 			Type:               addonsv1alpha1.Installed,
@@ -319,6 +411,22 @@ func reportMissingCSV(addon *addonsv1alpha1.Addon) {
 	reportPendingStatus(addon, addonsv1alpha1.AddonReasonMissingCSV, "ClusterServiceVersion is missing.")
 }
 
+func reportWaitingForDependencies(addon *addonsv1alpha1.Addon, message string) {
+	reportPendingStatus(addon, addonsv1alpha1.AddonReasonWaitingForDependencies,
+		fmt.Sprintf("Waiting for Addons it depends on: %s.", message))
+}
+
+// Keeps reporting the Addon as terminating.
+func reportWaitingForDependents(addon *addonsv1alpha1.Addon, dependents []string) {
+	meta.SetStatusCondition(&addon.Status.Conditions, metav1.Condition{
+		Type:               addonsv1alpha1.Available,
+		Status:             metav1.ConditionFalse,
+		Reason:             addonsv1alpha1.AddonReasonWaitingForDependents,
+		Message:            fmt.Sprintf("Waiting for Addons depending on it to be deleted: %s.", strings.Join(dependents, ", ")),
+		ObservedGeneration: addon.Generation,
+	})
+}
+
 func reportInstallPlanPending(addon *addonsv1alpha1.Addon) {
 	reportPendingStatus(addon, addonsv1alpha1.AddonReasonInstallPlanPending, "InstallPlan is waiting for approval.")
 }
@@ -342,6 +450,26 @@ func reportUnreadyClusterObjectTemplate(addon *addonsv1alpha1.Addon) {
 		"PackageOperator ClusterPackageTemplate is not ready")
 }
 
+func reportUnreadyHelmRelease(addon *addonsv1alpha1.Addon, unreadyWorkloads []string) {
+	reportPendingStatus(addon, addonsv1alpha1.AddonReasonUnreadyHelmRelease,
+		fmt.Sprintf("Workloads not yet rolled out: %s", strings.Join(unreadyWorkloads, ", ")))
+}
+
+func reportHelmChartError(addon *addonsv1alpha1.Addon, message string) {
+	reportPendingStatus(addon, addonsv1alpha1.AddonReasonHelmChartError,
+		fmt.Sprintf("Helm chart could not be rendered: %s", message))
+}
+
+func reportUnreadyManifests(addon *addonsv1alpha1.Addon, unreadyWorkloads []string) {
+	reportPendingStatus(addon, addonsv1alpha1.AddonReasonUnreadyManifests,
+		fmt.Sprintf("Workloads not yet rolled out: %s", strings.Join(unreadyWorkloads, ", ")))
+}
+
+func reportManifestBundleError(addon *addonsv1alpha1.Addon, message string) {
+	reportPendingStatus(addon, addonsv1alpha1.AddonReasonManifestBundleError,
+		fmt.Sprintf("Manifest bundle could not be loaded: %s", message))
+}
+
 func reportPendingStatus(addon *addonsv1alpha1.Addon, reason, msg string) {
 	meta.SetStatusCondition(&addon.Status.Conditions,
 		metav1.Condition{
@@ -557,6 +685,35 @@ func GetCommonInstallOptions(addon *addonsv1alpha1.Addon) (commonInstallOptions
 	return
 }
 
+// GetAddonInstallNamespace returns the namespace the Addon is installed into,
+// independent of the install type.
+func GetAddonInstallNamespace(addon *addonsv1alpha1.Addon) string {
+	switch addon.Spec.Install.Type {
+	case addonsv1alpha1.Helm:
+		if addon.Spec.Install.Helm == nil {
+			return ""
+		}
+		return addon.Spec.Install.Helm.Namespace
+	case addonsv1alpha1.Manifests:
+		if addon.Spec.Install.Manifests == nil {
+			return ""
+		}
+		return addon.Spec.Install.Manifests.Namespace
+	default:
+		return GetCommonInstallOptions(addon).Namespace
+	}
+}
+
+// isOLMInstall returns true if the Addon is installed via OLM.
+func isOLMInstall(addon *addonsv1alpha1.Addon) bool {
+	switch addon.Spec.Install.Type {
+	case addonsv1alpha1.OLMAllNamespaces, addonsv1alpha1.OLMOwnNamespace:
+		return true
+	default:
+		return false
+	}
+}
+
 func corev1ProtocolPtr(proto corev1.Protocol) *corev1.Protocol   { return &proto }
 func intOrStringPtr(iors intstr.IntOrString) *intstr.IntOrString { return &iors }
 
@@ -570,7 +727,37 @@ func HashCurrentAddonStatus(addon *addonsv1alpha1.Addon) string {
 	return hashOCMAddonStatus(ocmAddonStatus)
 }
 
+// HashAddonStatusPayload hashes the reported status to skip reporting it again while unchanged.
+// Heartbeat times are left out, as they change with every heartbeat.
+// Payloads without details hash like HashCurrentAddonStatus.
+func HashAddonStatusPayload(payload ocm.AddOnStatusPostRequest) string {
+	ocmAddonStatus := addonsv1alpha1.OCMAddOnStatus{
+		AddonID:          payload.AddonID,
+		CorrelationID:    payload.CorrelationID,
+		AddonVersion:     payload.AddonVersion,
+		StatusConditions: payload.StatusConditions,
+	}
+	details := payload.AddOnStatusDetails
+	if details.InstalledCSV == nil && details.AddonInstance == nil &&
+		details.UnschedulablePods == 0 && len(details.Resources) == 0 {
+		return hashOCMAddonStatus(ocmAddonStatus)
+	}
+	if details.AddonInstance != nil {
+		addonInstance := *details.AddonInstance
+		addonInstance.LastHeartbeatTime = nil
+		details.AddonInstance = &addonInstance
+	}
+	return hashObject(struct {
+		Status  addonsv1alpha1.OCMAddOnStatus
+		Details ocm.AddOnStatusDetails
+	}{ocmAddonStatus, details})
+}
+
 func hashOCMAddonStatus(ocmAddonStatus addonsv1alpha1.OCMAddOnStatus) string {
+	return hashObject(ocmAddonStatus)
+}
+
+func hashObject(obj interface{}) string {
 	hasher := fnv.New32a()
 	hasher.Reset()
 	printer := spew.ConfigState{
@@ -579,6 +766,6 @@ func hashOCMAddonStatus(ocmAddonStatus addonsv1alpha1.OCMAddOnStatus) string {
 		DisableMethods: true,
 		SpewKeys:       true,
 	}
-	printer.Fprintf(hasher, "%#v", ocmAddonStatus)
+	printer.Fprintf(hasher, "%#v", obj)
 	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
@@ -50,6 +50,9 @@ func TestHandleAddonDeletion(t *testing.T) {
 		c.
 			On("Delete", mock.Anything, mock.AnythingOfType("*v1alpha1.ClusterObjectTemplate"), mock.Anything).
 			Return(errors.NewNotFound(schema.GroupResource{}, ""))
+		c.
+			On("List", mock.Anything, mock.IsType(&addonsv1alpha1.AddonList{}), mock.Anything).
+			Return(nil)
 		operatorResourceHandlerMock.
 			On("Free", addonToDelete)
 
@@ -73,6 +76,51 @@ func TestHandleAddonDeletion(t *testing.T) {
 		}
 	})
 
+	t.Run("waits for dependents", func(t *testing.T) {
+		addonToDelete := &addonsv1alpha1.Addon{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "monitoring",
+				Finalizers: []string{cacheFinalizer},
+			},
+		}
+
+		c := testutil.NewClient()
+		r := &AddonReconciler{
+			Client: c,
+			Log:    testutil.NewLogger(t),
+			Scheme: testutil.NewTestSchemeWithAddonsv1alpha1(),
+		}
+
+		c.StatusMock.
+			On("Update", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil)
+		c.
+			On("List", mock.Anything, mock.IsType(&addonsv1alpha1.AddonList{}), mock.Anything).
+			Run(func(args mock.Arguments) {
+				list := args.Get(1).(*addonsv1alpha1.AddonList)
+				list.Items = []addonsv1alpha1.Addon{{
+					ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+					Spec: addonsv1alpha1.AddonSpec{
+						DependsOn: []addonsv1alpha1.AddonDependency{{Name: "monitoring"}},
+					},
+				}}
+			}).
+			Return(nil)
+
+		res, err := r.handleAddonCRDeletion(context.Background(), addonToDelete)
+		require.NoError(t, err)
+		require.True(t, res.IsZero())
+
+		assert.Equal(t, []string{cacheFinalizer}, addonToDelete.Finalizers)
+		c.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+		assert.Equal(t, addonsv1alpha1.PhaseTerminating, addonToDelete.Status.Phase)
+		availableCond := meta.FindStatusCondition(addonToDelete.Status.Conditions, addonsv1alpha1.Available)
+		if assert.NotNil(t, availableCond) {
+			assert.Equal(t, addonsv1alpha1.AddonReasonWaitingForDependents, availableCond.Reason)
+			assert.Contains(t, availableCond.Message, "logging")
+		}
+	})
+
 	t.Run("noop if finalizer already gone", func(t *testing.T) {
 		addonToDelete := &addonsv1alpha1.Addon{}
 
@@ -0,0 +1,126 @@
+package addon
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kinds of which workloadReady checks the rollout.
+var workloadGroupKinds = []schema.GroupKind{
+	{Group: appsv1.GroupName, Kind: "Deployment"},
+	{Group: appsv1.GroupName, Kind: "StatefulSet"},
+	{Group: appsv1.GroupName, Kind: "DaemonSet"},
+	{Group: batchv1.GroupName, Kind: "Job"},
+}
+
+// unreadyWorkloads fetches all Deployments, StatefulSets, DaemonSets and Jobs
+// among the given objects and returns a description of every one
+// that has not finished rolling out. Other kinds are considered ready.
+func unreadyWorkloads(ctx context.Context, c client.Reader, objs []client.Object) ([]string, error) {
+	var unready []string
+	for _, obj := range objs {
+		ready, err := workloadReady(ctx, c, obj)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			gvk := obj.GetObjectKind().GroupVersionKind()
+			unready = append(unready, fmt.Sprintf("%s %s", gvk.Kind, client.ObjectKeyFromObject(obj)))
+		}
+	}
+	return unready, nil
+}
+
+func workloadReady(ctx context.Context, c client.Reader, obj client.Object) (bool, error) {
+	key := client.ObjectKeyFromObject(obj)
+
+	switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "Deployment"}:
+		deployment := &appsv1.Deployment{}
+		if err := c.Get(ctx, key, deployment); err != nil {
+			return false, fmt.Errorf("getting Deployment %s: %w", key, err)
+		}
+		return deploymentReady(deployment), nil
+
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "StatefulSet"}:
+		statefulSet := &appsv1.StatefulSet{}
+		if err := c.Get(ctx, key, statefulSet); err != nil {
+			return false, fmt.Errorf("getting StatefulSet %s: %w", key, err)
+		}
+		return statefulSetReady(statefulSet), nil
+
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "DaemonSet"}:
+		daemonSet := &appsv1.DaemonSet{}
+		if err := c.Get(ctx, key, daemonSet); err != nil {
+			return false, fmt.Errorf("getting DaemonSet %s: %w", key, err)
+		}
+		return daemonSetReady(daemonSet), nil
+
+	case schema.GroupKind{Group: batchv1.GroupName, Kind: "Job"}:
+		job := &batchv1.Job{}
+		if err := c.Get(ctx, key, job); err != nil {
+			return false, fmt.Errorf("getting Job %s: %w", key, err)
+		}
+		return jobComplete(job), nil
+
+	default:
+		return true, nil
+	}
+}
+
+// Follows the checks of `kubectl rollout status`.
+func deploymentReady(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	status := deployment.Status
+	return status.UpdatedReplicas >= replicas &&
+		status.Replicas <= status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas
+}
+
+func statefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		return false
+	}
+	replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+	status := statefulSet.Status
+	if status.ReadyReplicas < replicas {
+		return false
+	}
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return true
+	}
+
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil &&
+		rollingUpdate.Partition != nil {
+		return status.UpdatedReplicas >= replicas-*rollingUpdate.Partition
+	}
+	return status.UpdateRevision == status.CurrentRevision
+}
+
+func daemonSetReady(daemonSet *appsv1.DaemonSet) bool {
+	if daemonSet.Status.ObservedGeneration < daemonSet.Generation {
+		return false
+	}
+	status := daemonSet.Status
+	return status.UpdatedNumberScheduled >= status.DesiredNumberScheduled &&
+		status.NumberAvailable >= status.DesiredNumberScheduled
+}
+
+func jobComplete(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
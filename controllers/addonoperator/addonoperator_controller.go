@@ -17,14 +17,17 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	"github.com/openshift/addon-operator/controllers"
 	"github.com/openshift/addon-operator/internal/ocm"
 
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -33,6 +36,9 @@ import (
 
 const (
 	defaultAddonOperatorRequeueTime = time.Minute
+
+	ocmCircuitBreakerFailureThreshold = 5
+	ocmCircuitBreakerCooldown         = time.Minute
 )
 
 type AddonOperatorReconciler struct {
@@ -45,14 +51,34 @@ type AddonOperatorReconciler struct {
 	Recorder            *metrics.Recorder
 	ClusterExternalID   string
 	FeatureTogglesState []string // no need to guard this with a mutex considering the fact that no two goroutines would ever try to update it as this is only initialized at startup
+
+	// Optional, watches the Secret referenced in .spec.ocm.secret,
+	// to pick up rotated credentials right away.
+	OCMSecretWatch *OCMSecretWatch
+
+	// Shared by all OCM clients, so the state survives re-creating the client.
+	ocmCircuitBreaker *ocm.CircuitBreaker
+	// Client in use and the configuration it was created for.
+	ocmClient              *ocm.Client
+	ocmClientConfig        addonsv1alpha1.AddonOperatorOCM
+	ocmClientSecretVersion string
 }
 
 func (r *AddonOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&addonsv1alpha1.AddonOperator{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		WatchesRawSource(source.Func(enqueueAddonOperator)).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&addonsv1alpha1.AddonOperator{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WatchesRawSource(source.Func(enqueueAddonOperator))
+	if r.OCMSecretWatch != nil {
+		b = b.WatchesRawSource(r.OCMSecretWatch.Source())
+	}
+	return b.Complete(r)
+}
+
+func enqueueAddonOperatorForSecret(_ context.Context, _ *corev1.Secret) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name: addonsv1alpha1.DefaultAddonOperatorName,
+	}}}
 }
 
 func enqueueAddonOperator(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
@@ -107,9 +133,30 @@ func (r *AddonOperatorReconciler) Reconcile(
 		return ctrl.Result{}, fmt.Errorf("handling global pause: %w", err)
 	}
 
-	if err := r.handleOCMClient(ctx, log, addonOperator); err != nil {
+	// OCM errors are surfaced in the OCMConnected condition,
+	// so the status has to be updated before returning them.
+	ocmErr := r.handleOCMClient(ctx, log, addonOperator)
+	if ocmErr != nil {
 		reconErr.Report(controllers.ErrCreateOCMClient, addonOperator.Name)
-		return ctrl.Result{}, fmt.Errorf("handling OCM client: %w", err)
+	}
+
+	// Reports are paced with the defaults without OCM configuration.
+	var ocmReporting *addonsv1alpha1.AddonOperatorOCMReporting
+	if addonOperator.Spec.OCM != nil {
+		ocmReporting = addonOperator.Spec.OCM.Reporting
+	}
+	r.OCMClientManager.ConfigureOCMReporting(ocmReporting)
+
+	requeueAfter := defaultAddonOperatorRequeueTime
+	var pullErr error
+	if ocmErr == nil {
+		var nextPull time.Duration
+		nextPull, pullErr = r.handleOCMPull(ctx, log, addonOperator, r.ocmClient)
+		if pullErr != nil {
+			reconErr.Report(controllers.ErrSyncOCMAddons, addonOperator.Name)
+		} else if nextPull > 0 {
+			requeueAfter = min(requeueAfter, nextPull)
+		}
 	}
 
 	// TODO: This is where all the checking / validation happens
@@ -120,7 +167,13 @@ func (r *AddonOperatorReconciler) Reconcile(
 		reconErr.Report(controllers.ErrReportAddonOperatorStatus, addonOperator.Name)
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{RequeueAfter: defaultAddonOperatorRequeueTime}, nil
+	if ocmErr != nil {
+		return ctrl.Result{}, fmt.Errorf("handling OCM client: %w", ocmErr)
+	}
+	if pullErr != nil {
+		return ctrl.Result{}, fmt.Errorf("syncing Addons from OCM: %w", pullErr)
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func areSlicesEquivalent(sliceA []string, sliceB []string) bool {
@@ -145,48 +198,133 @@ func areSlicesEquivalent(sliceA []string, sliceB []string) bool {
 	return true
 }
 
-// Creates an OCM API client and injects it into the OCM Client Manager for distribution.
+// Connects to OCM and hands the client to the AddonReconciler.
+// The client is kept across reconciles, rotated access tokens are swapped in place,
+// other rotated credentials create a new client.
 func (r *AddonOperatorReconciler) handleOCMClient(
 	ctx context.Context, log logr.Logger, addonOperator *addonsv1alpha1.AddonOperator) error {
+	r.watchOCMSecret(log, addonOperator.Spec.OCM)
 	if addonOperator.Spec.OCM == nil {
+		meta.RemoveStatusCondition(&addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMConnected)
+		return nil
+	}
+	ocmSpec := addonOperator.Spec.OCM
+
+	c, secretVersion, err := r.ensureOCMClient(ctx, ocmSpec)
+	if err != nil {
+		setOCMConnectedCondition(addonOperator, metav1.ConditionFalse,
+			addonsv1alpha1.AddonOperatorReasonOCMCredentialsError, err.Error())
+		return err
+	}
+
+	if c == r.ocmClient && secretVersion == r.ocmClientSecretVersion {
+		// Connected before with the same configuration and credentials.
+		setOCMConnectedCondition(addonOperator, metav1.ConditionTrue,
+			addonsv1alpha1.AddonOperatorReasonOCMConnected, "Connected to the OCM API")
 		return nil
 	}
+	if err := c.Connect(ctx); err != nil {
+		//ocm client not connected, usually because the OCM API is not yet
+		//available or because the ClusterID from the ClusterVersion doesn't
+		//properly translate into an internal_id
+		log.Info("delaying ocm client initialization until the OCM API is available", "error", err.Error())
+		setOCMConnectedCondition(addonOperator, metav1.ConditionFalse,
+			addonsv1alpha1.AddonOperatorReasonOCMConnectionFailed, err.Error())
+		return fmt.Errorf("connecting to OCM: %w", err)
+	}
+	setOCMConnectedCondition(addonOperator, metav1.ConditionTrue,
+		addonsv1alpha1.AddonOperatorReasonOCMConnected, "Connected to the OCM API")
+
+	if c != r.ocmClient {
+		if err := r.OCMClientManager.InjectOCMClient(ctx, c); err != nil {
+			return fmt.Errorf("injecting ocm client: %w", err)
+		}
+		r.ocmClient = c
+		r.ocmClientConfig = *ocmSpec.DeepCopy()
+	}
+	r.ocmClientSecretVersion = secretVersion
+	return nil
+}
 
-	secret := &corev1.Secret{}
-	// Use an uncached client to get this secret,
-	// so we don't setup a cluster-wide cache for Secrets.
-	// Saving memory and required RBAC privileges.
-	if err := r.UncachedClient.Get(ctx, client.ObjectKey{
-		Name:      addonOperator.Spec.OCM.Secret.Name,
-		Namespace: addonOperator.Spec.OCM.Secret.Namespace,
-	}, secret); err != nil {
-		return fmt.Errorf("getting ocm secret: %w", err)
+// Moves the watch along with the referenced Secret.
+// Failing to watch is not fatal, the Secret is still read with the periodic resync.
+func (r *AddonOperatorReconciler) watchOCMSecret(log logr.Logger, ocmSpec *addonsv1alpha1.AddonOperatorOCM) {
+	if r.OCMSecretWatch == nil {
+		return
+	}
+	if ocmSpec == nil {
+		r.OCMSecretWatch.Stop()
+		return
+	}
+	if err := r.OCMSecretWatch.Ensure(ocmSpec.Secret); err != nil {
+		log.Error(err, "watching OCM secret")
 	}
+}
 
-	accessToken, err := accessTokenFromDockerConfig(secret.Data[corev1.DockerConfigJsonKey])
+// Returns the client in use with up-to-date credentials,
+// or a new client when the configuration or credentials changed,
+// along with the version of the secret holding the credentials.
+func (r *AddonOperatorReconciler) ensureOCMClient(
+	ctx context.Context, ocmSpec *addonsv1alpha1.AddonOperatorOCM) (*ocm.Client, string, error) {
+	secret, err := r.getOCMSecret(ctx, ocmSpec.Secret)
 	if err != nil {
-		return fmt.Errorf("extracting access token from .dockerconfigjson: %w", err)
+		return nil, "", err
 	}
 
-	c, _ := ocm.NewClient(
-		ctx,
-		ocm.WithEndpoint(addonOperator.Spec.OCM.Endpoint),
-		ocm.WithAccessToken(accessToken),
-		ocm.WithClusterExternalID(r.ClusterExternalID),
-	)
+	unchanged := r.ocmClient != nil && equality.Semantic.DeepEqual(r.ocmClientConfig, *ocmSpec)
+	switch {
+	case unchanged && ocmAuthType(ocmSpec) == addonsv1alpha1.AddonOperatorOCMAuthAccessToken:
+		accessToken, err := accessTokenFromSecret(secret)
+		if err != nil {
+			return nil, "", err
+		}
+		r.ocmClient.SetAccessToken(accessToken)
+		return r.ocmClient, secret.ResourceVersion, nil
 
-	//ocm client not initialized, usually because the OCM API is not yet
-	//available or because the ClusterID from the ClusterVersion doesn't
-	//properly translate into an internal_id
-	if c == nil {
-		log.Info("delaying ocm client initialization until the OCM API is available")
-		return nil
+	case unchanged && r.ocmClientSecretVersion == secret.ResourceVersion:
+		return r.ocmClient, secret.ResourceVersion, nil
 	}
 
-	if err := r.OCMClientManager.InjectOCMClient(ctx, c); err != nil {
-		return fmt.Errorf("injecting ocm client: %w", err)
+	authOpts, err := r.ocmAuthOptions(ocmSpec, secret)
+	if err != nil {
+		return nil, "", err
 	}
-	return nil
+	opts := append([]ocm.Option{
+		ocm.WithEndpoint(ocmSpec.Endpoint),
+		ocm.WithClusterExternalID(r.ClusterExternalID),
+		ocm.WithCircuitBreaker(r.getOCMCircuitBreaker()),
+	}, authOpts...)
+	if r.Recorder != nil {
+		opts = append(opts, ocm.WithMetrics(r.Recorder))
+	}
+	return ocm.New(opts...), secret.ResourceVersion, nil
+}
+
+func setOCMConnectedCondition(
+	addonOperator *addonsv1alpha1.AddonOperator,
+	status metav1.ConditionStatus, reason, message string,
+) {
+	meta.SetStatusCondition(&addonOperator.Status.Conditions, metav1.Condition{
+		Type:               addonsv1alpha1.AddonOperatorOCMConnected,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: addonOperator.Generation,
+	})
+}
+
+// Reconcile calls are never concurrent,
+// as the controller runs a single worker.
+func (r *AddonOperatorReconciler) getOCMCircuitBreaker() *ocm.CircuitBreaker {
+	if r.ocmCircuitBreaker == nil {
+		var m ocm.Metrics
+		if r.Recorder != nil {
+			m = r.Recorder
+		}
+		r.ocmCircuitBreaker = ocm.NewCircuitBreaker(
+			ocmCircuitBreakerFailureThreshold, ocmCircuitBreakerCooldown, m)
+	}
+	return r.ocmCircuitBreaker
 }
 
 func (r *AddonOperatorReconciler) handleGlobalPause(
@@ -0,0 +1,128 @@
+package addonoperator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+)
+
+var errOCMTokenURLRequired = errors.New("auth type requires a token URL")
+
+func ocmAuthType(ocmSpec *addonsv1alpha1.AddonOperatorOCM) addonsv1alpha1.AddonOperatorOCMAuthType {
+	if ocmSpec.Auth == nil || len(ocmSpec.Auth.Type) == 0 {
+		return addonsv1alpha1.AddonOperatorOCMAuthAccessToken
+	}
+	return ocmSpec.Auth.Type
+}
+
+// Builds the client options authenticating with the credentials in the secret.
+func (r *AddonOperatorReconciler) ocmAuthOptions(
+	ocmSpec *addonsv1alpha1.AddonOperatorOCM, secret *corev1.Secret) ([]ocm.Option, error) {
+	authType := ocmAuthType(ocmSpec)
+	var auth addonsv1alpha1.AddonOperatorOCMAuth
+	if ocmSpec.Auth != nil {
+		auth = *ocmSpec.Auth
+	}
+
+	switch authType {
+	case addonsv1alpha1.AddonOperatorOCMAuthAccessToken:
+		accessToken, err := accessTokenFromSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		secretRef := ocmSpec.Secret
+		return []ocm.Option{
+			ocm.WithAccessToken(accessToken),
+			ocm.WithAccessTokenRefresher(func(ctx context.Context) (string, error) {
+				secret, err := r.getOCMSecret(ctx, secretRef)
+				if err != nil {
+					return "", err
+				}
+				return accessTokenFromSecret(secret)
+			}),
+		}, nil
+
+	case addonsv1alpha1.AddonOperatorOCMAuthOfflineToken:
+		if len(auth.TokenURL) == 0 {
+			return nil, fmt.Errorf("%s: %w", authType, errOCMTokenURLRequired)
+		}
+		offlineToken, err := secretValue(secret, addonsv1alpha1.OCMSecretOfflineTokenKey)
+		if err != nil {
+			return nil, err
+		}
+		clientID := string(secret.Data[addonsv1alpha1.OCMSecretClientIDKey])
+		if len(clientID) == 0 {
+			clientID = ocm.DefaultOfflineTokenClientID
+		}
+		return []ocm.Option{ocm.WithTokenSource(
+			ocm.NewOfflineTokenSource(auth.TokenURL, clientID, offlineToken, auth.Scopes),
+		)}, nil
+
+	case addonsv1alpha1.AddonOperatorOCMAuthClientCredentials:
+		if len(auth.TokenURL) == 0 {
+			return nil, fmt.Errorf("%s: %w", authType, errOCMTokenURLRequired)
+		}
+		clientID, err := secretValue(secret, addonsv1alpha1.OCMSecretClientIDKey)
+		if err != nil {
+			return nil, err
+		}
+		clientSecret, err := secretValue(secret, addonsv1alpha1.OCMSecretClientSecretKey)
+		if err != nil {
+			return nil, err
+		}
+		return []ocm.Option{ocm.WithTokenSource(
+			ocm.NewClientCredentialsTokenSource(auth.TokenURL, clientID, clientSecret, auth.Scopes),
+		)}, nil
+
+	case addonsv1alpha1.AddonOperatorOCMAuthClientCertificate:
+		tlsConfig, err := ocm.NewClientCertificateTLSConfig(
+			secret.Data[corev1.TLSCertKey],
+			secret.Data[corev1.TLSPrivateKeyKey],
+			secret.Data[addonsv1alpha1.OCMSecretCAKey],
+		)
+		if err != nil {
+			return nil, err
+		}
+		return []ocm.Option{ocm.WithTLSConfig(tlsConfig)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q", authType)
+	}
+}
+
+func (r *AddonOperatorReconciler) getOCMSecret(
+	ctx context.Context, secretRef addonsv1alpha1.ClusterSecretReference) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	// Use an uncached client to get this secret,
+	// so we don't setup a cluster-wide cache for Secrets.
+	// Saving memory and required RBAC privileges.
+	if err := r.UncachedClient.Get(ctx, client.ObjectKey{
+		Name:      secretRef.Name,
+		Namespace: secretRef.Namespace,
+	}, secret); err != nil {
+		return nil, fmt.Errorf("getting ocm secret: %w", err)
+	}
+	return secret, nil
+}
+
+func accessTokenFromSecret(secret *corev1.Secret) (string, error) {
+	accessToken, err := accessTokenFromDockerConfig(secret.Data[corev1.DockerConfigJsonKey])
+	if err != nil {
+		return "", fmt.Errorf("extracting access token from .dockerconfigjson: %w", err)
+	}
+	return accessToken, nil
+}
+
+func secretValue(secret *corev1.Secret, key string) (string, error) {
+	value := secret.Data[key]
+	if len(value) == 0 {
+		return "", fmt.Errorf("missing %q key in ocm secret", key)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,85 @@
+package addonoperator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func TestOCMAuthOptions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		auth          *addonsv1alpha1.AddonOperatorOCMAuth
+		data          map[string][]byte
+		expectedError string
+	}{
+		"default access token": {
+			data: map[string][]byte{
+				corev1.DockerConfigJsonKey: []byte(`{"auths":{"cloud.openshift.com":{"auth":"token"}}}`),
+			},
+		},
+		"access token missing": {
+			auth:          &addonsv1alpha1.AddonOperatorOCMAuth{Type: addonsv1alpha1.AddonOperatorOCMAuthAccessToken},
+			data:          map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{}`)},
+			expectedError: "missing token for cloud.openshift.com",
+		},
+		"offline token": {
+			auth: &addonsv1alpha1.AddonOperatorOCMAuth{
+				Type:     addonsv1alpha1.AddonOperatorOCMAuthOfflineToken,
+				TokenURL: "https://sso.example.com/token",
+			},
+			data: map[string][]byte{addonsv1alpha1.OCMSecretOfflineTokenKey: []byte("offline")},
+		},
+		"offline token without token URL": {
+			auth: &addonsv1alpha1.AddonOperatorOCMAuth{
+				Type: addonsv1alpha1.AddonOperatorOCMAuthOfflineToken,
+			},
+			data:          map[string][]byte{addonsv1alpha1.OCMSecretOfflineTokenKey: []byte("offline")},
+			expectedError: "OfflineToken: auth type requires a token URL",
+		},
+		"client credentials": {
+			auth: &addonsv1alpha1.AddonOperatorOCMAuth{
+				Type:     addonsv1alpha1.AddonOperatorOCMAuthClientCredentials,
+				TokenURL: "https://sso.example.com/token",
+			},
+			data: map[string][]byte{
+				addonsv1alpha1.OCMSecretClientIDKey:     []byte("client"),
+				addonsv1alpha1.OCMSecretClientSecretKey: []byte("secret"),
+			},
+		},
+		"client credentials without secret": {
+			auth: &addonsv1alpha1.AddonOperatorOCMAuth{
+				Type:     addonsv1alpha1.AddonOperatorOCMAuthClientCredentials,
+				TokenURL: "https://sso.example.com/token",
+			},
+			data:          map[string][]byte{addonsv1alpha1.OCMSecretClientIDKey: []byte("client")},
+			expectedError: `missing "clientSecret" key in ocm secret`,
+		},
+		"client certificate without certificate": {
+			auth: &addonsv1alpha1.AddonOperatorOCMAuth{
+				Type: addonsv1alpha1.AddonOperatorOCMAuthClientCertificate,
+			},
+			expectedError: "loading client certificate",
+		},
+		"unsupported": {
+			auth:          &addonsv1alpha1.AddonOperatorOCMAuth{Type: "Magic"},
+			expectedError: `unsupported auth type "Magic"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := &AddonOperatorReconciler{}
+			ocmSpec := &addonsv1alpha1.AddonOperatorOCM{Auth: tc.auth}
+
+			opts, err := r.ocmAuthOptions(ocmSpec, &corev1.Secret{Data: tc.data})
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, opts)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package addonoperator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+type ocmClientManagerMock struct {
+	mock.Mock
+}
+
+func (m *ocmClientManagerMock) InjectOCMClient(ctx context.Context, c *ocm.Client) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *ocmClientManagerMock) ConfigureOCMReporting(reporting *addonsv1alpha1.AddonOperatorOCMReporting) {
+	m.Called(reporting)
+}
+
+// Serves the cluster lookup, accepting only the current token.
+type ocmAPIMock struct {
+	lock         sync.Mutex
+	token        string
+	clusterFound bool
+	requests     int
+}
+
+func (m *ocmAPIMock) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.requests++
+	if r.Header.Get("Authorization") != "AccessToken 1ou:"+m.token &&
+		r.Header.Get("Authorization") != "AccessToken :"+m.token {
+		rw.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintln(rw, `{"code":"unauthorized","reason":"invalid token"}`)
+		return
+	}
+	if !m.clusterFound {
+		fmt.Fprintln(rw, `{"items": []}`)
+		return
+	}
+	fmt.Fprintln(rw, `{"items": [{"kind": "Cluster","id": "1ou","external_id": "123"}]}`)
+}
+
+func (m *ocmAPIMock) requestCount() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.requests
+}
+
+func (m *ocmAPIMock) rotate(token string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.token = token
+}
+
+func mockOCMSecret(c *testutil.Client, token *string) {
+	// Rotated tokens are also looked up within the trace span of OCM requests.
+	for _, ctxMatcher := range []interface{}{testutil.IsContext, testutil.IsTracedContext} {
+		c.On("Get", ctxMatcher, testutil.IsObjectKey, mock.IsType(&corev1.Secret{}), mock.Anything).
+			Run(func(args mock.Arguments) {
+				secret := args.Get(2).(*corev1.Secret)
+				secret.ResourceVersion = *token
+				secret.Data = map[string][]byte{
+					corev1.DockerConfigJsonKey: []byte(
+						fmt.Sprintf(`{"auths":{"cloud.openshift.com":{"auth":%q}}}`, *token)),
+				}
+			}).
+			Return(nil)
+	}
+}
+
+func TestHandleOCMClient(t *testing.T) {
+	api := &ocmAPIMock{token: "token-1", clusterFound: true}
+	s := httptest.NewServer(api)
+	defer s.Close()
+
+	token := "token-1"
+	uncachedClient := testutil.NewClient()
+	mockOCMSecret(uncachedClient, &token)
+	ocmClientManager := &ocmClientManagerMock{}
+	ocmClientManager.On("InjectOCMClient", testutil.IsContext, mock.Anything).Return(nil)
+
+	r := &AddonOperatorReconciler{
+		UncachedClient:    uncachedClient,
+		OCMClientManager:  ocmClientManager,
+		ClusterExternalID: "123",
+	}
+	addonOperator := &addonsv1alpha1.AddonOperator{
+		Spec: addonsv1alpha1.AddonOperatorSpec{
+			OCM: &addonsv1alpha1.AddonOperatorOCM{
+				Endpoint: s.URL,
+				Secret:   addonsv1alpha1.ClusterSecretReference{Name: "pull-secret", Namespace: "openshift-config"},
+			},
+		},
+	}
+	ctx := context.Background()
+	log := testutil.NewLogger(t)
+
+	require.NoError(t, r.handleOCMClient(ctx, log, addonOperator))
+	assert.True(t, meta.IsStatusConditionTrue(
+		addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMConnected))
+	c := r.ocmClient
+	require.NotNil(t, c)
+
+	// Not connected again, while neither configuration nor credentials change.
+	requests := api.requestCount()
+	require.NoError(t, r.handleOCMClient(ctx, log, addonOperator))
+	assert.Equal(t, requests, api.requestCount())
+	assert.True(t, meta.IsStatusConditionTrue(
+		addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMConnected))
+
+	// Rotated credentials are swapped into the injected client.
+	token = "token-2"
+	api.rotate("token-2")
+	require.NoError(t, r.handleOCMClient(ctx, log, addonOperator))
+	assert.Same(t, c, r.ocmClient)
+	ocmClientManager.AssertNumberOfCalls(t, "InjectOCMClient", 1)
+
+	// Rotation between reconciles is picked up after a 401.
+	token = "token-3"
+	api.rotate("token-3")
+	_, err := c.GetCluster(ctx, ocm.ClusterGetRequest{})
+	require.NoError(t, err)
+
+	// A changed configuration creates a new client.
+	addonOperator.Spec.OCM.Endpoint = s.URL + "/"
+	require.NoError(t, r.handleOCMClient(ctx, log, addonOperator))
+	assert.NotSame(t, c, r.ocmClient)
+	ocmClientManager.AssertNumberOfCalls(t, "InjectOCMClient", 2)
+
+	// Removing the configuration removes the condition.
+	addonOperator.Spec.OCM = nil
+	require.NoError(t, r.handleOCMClient(ctx, log, addonOperator))
+	assert.Nil(t, meta.FindStatusCondition(
+		addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMConnected))
+}
+
+func TestHandleOCMClient_Errors(t *testing.T) {
+	api := &ocmAPIMock{token: "token"}
+	s := httptest.NewServer(api)
+	defer s.Close()
+
+	newAddonOperator := func() *addonsv1alpha1.AddonOperator {
+		return &addonsv1alpha1.AddonOperator{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec: addonsv1alpha1.AddonOperatorSpec{
+				OCM: &addonsv1alpha1.AddonOperatorOCM{
+					Endpoint: s.URL,
+					Secret:   addonsv1alpha1.ClusterSecretReference{Name: "pull-secret", Namespace: "openshift-config"},
+				},
+			},
+		}
+	}
+
+	t.Run("missing secret", func(t *testing.T) {
+		uncachedClient := testutil.NewClient()
+		uncachedClient.
+			On("Get", testutil.IsContext, testutil.IsObjectKey, mock.IsType(&corev1.Secret{}), mock.Anything).
+			Return(testutil.NewTestErrNotFound())
+		r := &AddonOperatorReconciler{UncachedClient: uncachedClient}
+		addonOperator := newAddonOperator()
+
+		err := r.handleOCMClient(context.Background(), testutil.NewLogger(t), addonOperator)
+		require.Error(t, err)
+
+		cond := meta.FindStatusCondition(addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMConnected)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, addonsv1alpha1.AddonOperatorReasonOCMCredentialsError, cond.Reason)
+		assert.Equal(t, err.Error(), cond.Message)
+		assert.Equal(t, int64(2), cond.ObservedGeneration)
+	})
+
+	t.Run("cluster not found", func(t *testing.T) {
+		token := "token"
+		uncachedClient := testutil.NewClient()
+		mockOCMSecret(uncachedClient, &token)
+		ocmClientManager := &ocmClientManagerMock{}
+		r := &AddonOperatorReconciler{
+			UncachedClient:    uncachedClient,
+			OCMClientManager:  ocmClientManager,
+			ClusterExternalID: "123",
+		}
+		addonOperator := newAddonOperator()
+
+		err := r.handleOCMClient(context.Background(), testutil.NewLogger(t), addonOperator)
+		require.ErrorContains(t, err, "cluster 123 not found")
+
+		cond := meta.FindStatusCondition(addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMConnected)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, addonsv1alpha1.AddonOperatorReasonOCMConnectionFailed, cond.Reason)
+		assert.Contains(t, cond.Message, "cluster 123 not found")
+		ocmClientManager.AssertNotCalled(t, "InjectOCMClient", mock.Anything, mock.Anything)
+		assert.Nil(t, r.ocmClient)
+	})
+}
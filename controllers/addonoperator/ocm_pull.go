@@ -0,0 +1,319 @@
+package addonoperator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/ocm"
+)
+
+const (
+	defaultOCMPullInterval = 5 * time.Minute
+
+	// Hash of the Addon spec last applied from OCM.
+	// Comparing hashes instead of specs ignores fields defaulted by the API server.
+	ocmAddonSpecHashAnnotation = "addons.managed.openshift.io/ocm-spec-hash"
+	// Hash of the Addon spec as stored by the API server after it was last applied from OCM,
+	// including defaulted fields. Differing from the live spec means it was changed on the cluster.
+	ocmAddonAppliedSpecHashAnnotation = "addons.managed.openshift.io/ocm-applied-spec-hash"
+
+	// Addons missing from OCM are not deleted, when more than this share
+	// of the pull managed Addons is missing at once.
+	maxOCMPullDeletionRatio = 0.5
+)
+
+type ocmAddonInstallationLister interface {
+	ListAddonInstallations(ctx context.Context) ([]ocm.AddonInstallation, error)
+}
+
+type ocmPullResult struct {
+	drift []addonsv1alpha1.AddonOperatorOCMDrift
+	// Pull managed Addons missing from OCM, that were kept because too many are missing at once.
+	deletionRefused []string
+}
+
+// Syncs Addons from OCM when pull mode is enabled and the last sync is older than the interval.
+// Returns the time until the next sync is due.
+func (r *AddonOperatorReconciler) handleOCMPull(
+	ctx context.Context, log logr.Logger,
+	addonOperator *addonsv1alpha1.AddonOperator, lister ocmAddonInstallationLister,
+) (time.Duration, error) {
+	if addonOperator.Spec.OCM == nil || addonOperator.Spec.OCM.PullMode == nil {
+		addonOperator.Status.OCMPull = nil
+		meta.RemoveStatusCondition(&addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced)
+		return 0, nil
+	}
+
+	interval := addonOperator.Spec.OCM.PullMode.Interval.Duration
+	if interval <= 0 {
+		interval = defaultOCMPullInterval
+	}
+	if last := addonOperator.Status.OCMPull; last != nil {
+		if wait := time.Until(last.LastSyncTime.Add(interval)); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	log.Info("syncing Addons from OCM")
+	result, err := r.syncAddonsFromOCM(ctx, log, lister)
+	if err != nil {
+		setOCMAddonsSyncedCondition(addonOperator, metav1.ConditionFalse,
+			addonsv1alpha1.AddonOperatorReasonOCMAddonsSyncFailed, err.Error())
+		return 0, err
+	}
+
+	addonOperator.Status.OCMPull = &addonsv1alpha1.AddonOperatorOCMPullStatus{
+		LastSyncTime: metav1.Now(),
+		Drift:        result.drift,
+	}
+	switch {
+	case len(result.deletionRefused) > 0:
+		setOCMAddonsSyncedCondition(addonOperator, metav1.ConditionFalse,
+			addonsv1alpha1.AddonOperatorReasonOCMAddonsDeletionRefused,
+			fmt.Sprintf("Not deleting Addons, too many are missing from OCM at once, "+
+				"delete them manually if intended: %s", strings.Join(result.deletionRefused, ", ")))
+
+	case len(result.drift) > 0:
+		addons := make([]string, len(result.drift))
+		for i := range result.drift {
+			addons[i] = result.drift[i].Addon
+		}
+		setOCMAddonsSyncedCondition(addonOperator, metav1.ConditionFalse,
+			addonsv1alpha1.AddonOperatorReasonOCMAddonsDrifted,
+			fmt.Sprintf("Addons differ from OCM: %s", strings.Join(addons, ", ")))
+
+	default:
+		setOCMAddonsSyncedCondition(addonOperator, metav1.ConditionTrue,
+			addonsv1alpha1.AddonOperatorReasonOCMAddonsSynced, "Addons match the addon installations in OCM")
+	}
+	return interval, nil
+}
+
+// Creates, updates and deletes Addons managed by pull mode to match the addon installations in OCM.
+// Addons created by others are left alone, their differences to OCM are returned as drift.
+// A failure to sync one Addon does not stop the others from being synced.
+func (r *AddonOperatorReconciler) syncAddonsFromOCM(
+	ctx context.Context, log logr.Logger, lister ocmAddonInstallationLister,
+) (ocmPullResult, error) {
+	installations, err := lister.ListAddonInstallations(ctx)
+	if err != nil {
+		return ocmPullResult{}, fmt.Errorf("listing addon installations: %w", err)
+	}
+
+	addonList := &addonsv1alpha1.AddonList{}
+	if err := r.List(ctx, addonList); err != nil {
+		return ocmPullResult{}, fmt.Errorf("listing Addons: %w", err)
+	}
+	existing := map[string]*addonsv1alpha1.Addon{}
+	for i := range addonList.Items {
+		existing[addonList.Items[i].Name] = &addonList.Items[i]
+	}
+
+	var (
+		result    ocmPullResult
+		errs      []error
+		installed = map[string]struct{}{}
+		deleting  = map[string]struct{}{}
+	)
+	for _, installation := range installations {
+		if installation.State == ocm.AddonInstallationStateDeleting {
+			deleting[installation.ID] = struct{}{}
+			continue
+		}
+		installed[installation.ID] = struct{}{}
+
+		addon, ok := existing[installation.ID]
+		switch {
+		case !ok:
+			if err := r.createOCMPulledAddon(ctx, newOCMPulledAddon(installation)); err != nil {
+				errs = append(errs, fmt.Errorf("creating Addon %s: %w", installation.ID, err))
+			}
+
+		case !isManagedByOCMPull(addon):
+			if addon.Spec.Version != installation.AddonSpec.Version {
+				result.drift = append(result.drift, addonsv1alpha1.AddonOperatorOCMDrift{
+					Addon: addon.Name,
+					Type:  addonsv1alpha1.AddonOperatorOCMDriftVersionMismatch,
+					Message: fmt.Sprintf("version %q on the cluster, %q in OCM",
+						addon.Spec.Version, installation.AddonSpec.Version),
+				})
+			}
+
+		case markedForDeletion(addon):
+			// Recreated from OCM once the deletion completed.
+
+		case addon.Annotations[ocmAddonSpecHashAnnotation] != hashAddonSpec(installation.AddonSpec):
+			if err := r.updateOCMPulledAddon(ctx, addon, installation); err != nil {
+				errs = append(errs, fmt.Errorf("updating Addon %s: %w", addon.Name, err))
+			}
+
+		case addon.Annotations[ocmAddonAppliedSpecHashAnnotation] != hashAddonSpec(addon.Spec):
+			log.Info("reverting changes made on the cluster to Addon managed by OCM pull mode", "addon", addon.Name)
+			if err := r.updateOCMPulledAddon(ctx, addon, installation); err != nil {
+				errs = append(errs, fmt.Errorf("reverting Addon %s: %w", addon.Name, err))
+			}
+		}
+	}
+
+	var (
+		managed  int
+		toDelete []*addonsv1alpha1.Addon
+		missing  []*addonsv1alpha1.Addon
+	)
+	for i := range addonList.Items {
+		addon := &addonList.Items[i]
+		if isManagedByOCMPull(addon) {
+			managed++
+		}
+		if _, ok := installed[addon.Name]; ok {
+			continue
+		}
+		if !isManagedByOCMPull(addon) {
+			result.drift = append(result.drift, addonsv1alpha1.AddonOperatorOCMDrift{
+				Addon:   addon.Name,
+				Type:    addonsv1alpha1.AddonOperatorOCMDriftNotInOCM,
+				Message: "Addon is not installed in OCM",
+			})
+			continue
+		}
+		if _, ok := deleting[addon.Name]; ok {
+			toDelete = append(toDelete, addon)
+		} else {
+			missing = append(missing, addon)
+		}
+	}
+
+	if len(missing) > 0 && !ocmPullDeletionAllowed(len(installations), len(missing), managed) {
+		for _, addon := range missing {
+			result.deletionRefused = append(result.deletionRefused, addon.Name)
+		}
+		slices.Sort(result.deletionRefused)
+	} else {
+		toDelete = append(toDelete, missing...)
+	}
+	for _, addon := range toDelete {
+		if err := r.deleteOCMPulledAddon(ctx, addon); err != nil {
+			errs = append(errs, fmt.Errorf("deleting Addon %s: %w", addon.Name, err))
+		}
+	}
+
+	slices.SortFunc(result.drift, func(a, b addonsv1alpha1.AddonOperatorOCMDrift) int {
+		return strings.Compare(a.Addon, b.Addon)
+	})
+	return result, errors.Join(errs...)
+}
+
+// An empty or sharply shrunk list of addon installations more likely comes from
+// an incomplete OCM response than from most addons being uninstalled at once.
+func ocmPullDeletionAllowed(installations, missing, managed int) bool {
+	if installations == 0 {
+		return false
+	}
+	return missing <= max(1, int(float64(managed)*maxOCMPullDeletionRatio))
+}
+
+func (r *AddonOperatorReconciler) createOCMPulledAddon(ctx context.Context, addon *addonsv1alpha1.Addon) error {
+	if err := r.Create(ctx, addon); err != nil {
+		return err
+	}
+	return r.recordAppliedSpecHash(ctx, addon)
+}
+
+func (r *AddonOperatorReconciler) updateOCMPulledAddon(
+	ctx context.Context, addon *addonsv1alpha1.Addon, installation ocm.AddonInstallation,
+) error {
+	desired := newOCMPulledAddon(installation)
+	addon.Spec = desired.Spec
+	addon.Annotations[ocmAddonSpecHashAnnotation] = desired.Annotations[ocmAddonSpecHashAnnotation]
+	if err := r.Update(ctx, addon); err != nil {
+		return err
+	}
+	return r.recordAppliedSpecHash(ctx, addon)
+}
+
+// Records the hash of the spec returned by the API server, to detect later changes on the cluster.
+func (r *AddonOperatorReconciler) recordAppliedSpecHash(ctx context.Context, addon *addonsv1alpha1.Addon) error {
+	patch := client.MergeFrom(addon.DeepCopy())
+	addon.Annotations[ocmAddonAppliedSpecHashAnnotation] = hashAddonSpec(addon.Spec)
+	return r.Patch(ctx, addon, patch)
+}
+
+// Deletes an Addon the same way OCM does in push mode:
+// the delete annotation lets the addon acknowledge the deletion,
+// the Addon is removed once it reports ReadyToBeDeleted or the acknowledgement timed out.
+// Progress is picked up by the next sync.
+func (r *AddonOperatorReconciler) deleteOCMPulledAddon(ctx context.Context, addon *addonsv1alpha1.Addon) error {
+	if !addon.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	if !markedForDeletion(addon) {
+		addon.Annotations[addonsv1alpha1.DeleteAnnotationFlag] = "true"
+		return r.Update(ctx, addon)
+	}
+	if !meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.ReadyToBeDeleted) &&
+		!meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.DeleteTimeout) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, addon))
+}
+
+func markedForDeletion(addon *addonsv1alpha1.Addon) bool {
+	_, ok := addon.Annotations[addonsv1alpha1.DeleteAnnotationFlag]
+	return ok
+}
+
+func newOCMPulledAddon(installation ocm.AddonInstallation) *addonsv1alpha1.Addon {
+	return &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: installation.ID,
+			Labels: map[string]string{
+				controllers.CommonManagedByLabel: controllers.CommonManagedByValue,
+			},
+			Annotations: map[string]string{
+				ocmAddonSpecHashAnnotation: hashAddonSpec(installation.AddonSpec),
+			},
+		},
+		Spec: *installation.AddonSpec.DeepCopy(),
+	}
+}
+
+// Addons created by pull mode are labeled as managed by the addon operator.
+func isManagedByOCMPull(addon *addonsv1alpha1.Addon) bool {
+	return addon.Labels[controllers.CommonManagedByLabel] == controllers.CommonManagedByValue &&
+		len(addon.Annotations[ocmAddonSpecHashAnnotation]) > 0
+}
+
+func hashAddonSpec(spec addonsv1alpha1.AddonSpec) string {
+	// AddonSpec only holds JSON compatible types.
+	data, _ := json.Marshal(spec)
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(data)
+	return strconv.FormatUint(hasher.Sum64(), 16)
+}
+
+func setOCMAddonsSyncedCondition(
+	addonOperator *addonsv1alpha1.AddonOperator,
+	status metav1.ConditionStatus, reason, message string,
+) {
+	meta.SetStatusCondition(&addonOperator.Status.Conditions, metav1.Condition{
+		Type:               addonsv1alpha1.AddonOperatorOCMAddonsSynced,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: addonOperator.Generation,
+	})
+}
@@ -0,0 +1,352 @@
+package addonoperator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/controllers"
+	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+func newPullModeAddonOperator(lastSync *metav1.Time) *addonsv1alpha1.AddonOperator {
+	addonOperator := &addonsv1alpha1.AddonOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: addonsv1alpha1.DefaultAddonOperatorName},
+		Spec: addonsv1alpha1.AddonOperatorSpec{
+			OCM: &addonsv1alpha1.AddonOperatorOCM{
+				PullMode: &addonsv1alpha1.AddonOperatorOCMPullMode{
+					Interval: metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+		},
+	}
+	if lastSync != nil {
+		addonOperator.Status.OCMPull = &addonsv1alpha1.AddonOperatorOCMPullStatus{LastSyncTime: *lastSync}
+	}
+	return addonOperator
+}
+
+func newPulledAddon(name, version string) *addonsv1alpha1.Addon {
+	addon := newOCMPulledAddon(ocm.AddonInstallation{
+		ID:        name,
+		AddonSpec: addonsv1alpha1.AddonSpec{Version: version},
+	})
+	addon.Annotations[ocmAddonAppliedSpecHashAnnotation] = hashAddonSpec(addon.Spec)
+	return addon
+}
+
+func newPullTestClient(objs ...client.Object) client.WithWatch {
+	return fake.NewClientBuilder().
+		WithScheme(testutil.NewTestSchemeWithAddonsv1alpha1()).
+		WithObjects(objs...).
+		Build()
+}
+
+func pullInstallations(versions map[string]string) []ocm.AddonInstallation {
+	var installations []ocm.AddonInstallation
+	for name, version := range versions {
+		installations = append(installations, ocm.AddonInstallation{
+			ID: name, AddonSpec: addonsv1alpha1.AddonSpec{Version: version},
+		})
+	}
+	return installations
+}
+
+func TestHandleOCMPull_Sync(t *testing.T) {
+	unmanaged := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "hive-addon"},
+		Spec:       addonsv1alpha1.AddonSpec{Version: "1.0.0"},
+	}
+	orphan := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "orphan"},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testutil.NewTestSchemeWithAddonsv1alpha1()).
+		WithObjects(
+			unmanaged, orphan,
+			newPulledAddon("outdated", "1.0.0"),
+			newPulledAddon("uninstalled", "1.0.0"),
+			newPulledAddon("deleting", "1.0.0"),
+		).
+		Build()
+	r := &AddonOperatorReconciler{Client: c}
+
+	ocmClient := ocmtest.NewClient()
+	ocmClient.On("ListAddonInstallations", mock.Anything).Return([]ocm.AddonInstallation{
+		{ID: "new", AddonSpec: addonsv1alpha1.AddonSpec{Version: "1.0.0"}},
+		{ID: "outdated", AddonSpec: addonsv1alpha1.AddonSpec{Version: "2.0.0"}},
+		{ID: "hive-addon", AddonSpec: addonsv1alpha1.AddonSpec{Version: "2.0.0"}},
+		{ID: "deleting", State: ocm.AddonInstallationStateDeleting},
+	}, nil)
+
+	addonOperator := newPullModeAddonOperator(nil)
+	ctx := context.Background()
+	requeueAfter, err := r.handleOCMPull(ctx, testutil.NewLogger(t), addonOperator, ocmClient)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, requeueAfter)
+
+	created := &addonsv1alpha1.Addon{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "new"}, created))
+	assert.Equal(t, "1.0.0", created.Spec.Version)
+	assert.Equal(t, controllers.CommonManagedByValue, created.Labels[controllers.CommonManagedByLabel])
+
+	updated := &addonsv1alpha1.Addon{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "outdated"}, updated))
+	assert.Equal(t, "2.0.0", updated.Spec.Version)
+
+	// Removed Addons are asked to acknowledge the deletion first.
+	for _, name := range []string{"uninstalled", "deleting"} {
+		addon := &addonsv1alpha1.Addon{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Name: name}, addon))
+		assert.Contains(t, addon.Annotations, addonsv1alpha1.DeleteAnnotationFlag)
+	}
+
+	// Addons not created from OCM are never changed.
+	for _, obj := range []*addonsv1alpha1.Addon{unmanaged, orphan} {
+		current := &addonsv1alpha1.Addon{}
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(obj), current))
+		assert.Equal(t, obj.Spec, current.Spec)
+	}
+
+	require.NotNil(t, addonOperator.Status.OCMPull)
+	assert.Equal(t, []addonsv1alpha1.AddonOperatorOCMDrift{
+		{
+			Addon:   "hive-addon",
+			Type:    addonsv1alpha1.AddonOperatorOCMDriftVersionMismatch,
+			Message: `version "1.0.0" on the cluster, "2.0.0" in OCM`,
+		},
+		{
+			Addon:   "orphan",
+			Type:    addonsv1alpha1.AddonOperatorOCMDriftNotInOCM,
+			Message: "Addon is not installed in OCM",
+		},
+	}, addonOperator.Status.OCMPull.Drift)
+
+	cond := meta.FindStatusCondition(addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, addonsv1alpha1.AddonOperatorReasonOCMAddonsDrifted, cond.Reason)
+	assert.Equal(t, "Addons differ from OCM: hive-addon, orphan", cond.Message)
+}
+
+func TestHandleOCMPull_InSync(t *testing.T) {
+	c := fake.NewClientBuilder().
+		WithScheme(testutil.NewTestSchemeWithAddonsv1alpha1()).
+		WithObjects(newPulledAddon("addon-1", "1.0.0")).
+		Build()
+	r := &AddonOperatorReconciler{Client: c}
+
+	ocmClient := ocmtest.NewClient()
+	ocmClient.On("ListAddonInstallations", mock.Anything).Return([]ocm.AddonInstallation{
+		{ID: "addon-1", AddonSpec: addonsv1alpha1.AddonSpec{Version: "1.0.0"}},
+	}, nil)
+
+	addonOperator := newPullModeAddonOperator(nil)
+	_, err := r.handleOCMPull(context.Background(), testutil.NewLogger(t), addonOperator, ocmClient)
+	require.NoError(t, err)
+
+	assert.Empty(t, addonOperator.Status.OCMPull.Drift)
+	assert.True(t, meta.IsStatusConditionTrue(
+		addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced))
+}
+
+func TestHandleOCMPull_Interval(t *testing.T) {
+	ocmClient := ocmtest.NewClient()
+	r := &AddonOperatorReconciler{}
+
+	lastSync := metav1.NewTime(time.Now().Add(-4 * time.Minute))
+	addonOperator := newPullModeAddonOperator(&lastSync)
+	requeueAfter, err := r.handleOCMPull(context.Background(), testutil.NewLogger(t), addonOperator, ocmClient)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 6*time.Minute, requeueAfter, float64(time.Second))
+	ocmClient.AssertNotCalled(t, "ListAddonInstallations", mock.Anything)
+}
+
+func TestHandleOCMPull_Error(t *testing.T) {
+	ocmClient := ocmtest.NewClient()
+	ocmClient.On("ListAddonInstallations", mock.Anything).
+		Return([]ocm.AddonInstallation(nil), errors.New("ocm unavailable"))
+	r := &AddonOperatorReconciler{}
+
+	lastSync := metav1.NewTime(time.Now().Add(-time.Hour))
+	addonOperator := newPullModeAddonOperator(&lastSync)
+	_, err := r.handleOCMPull(context.Background(), testutil.NewLogger(t), addonOperator, ocmClient)
+	require.Error(t, err)
+
+	// The last successful sync is kept.
+	assert.Equal(t, lastSync, addonOperator.Status.OCMPull.LastSyncTime)
+	cond := meta.FindStatusCondition(addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced)
+	require.NotNil(t, cond)
+	assert.Equal(t, addonsv1alpha1.AddonOperatorReasonOCMAddonsSyncFailed, cond.Reason)
+}
+
+func TestHandleOCMPull_Disabled(t *testing.T) {
+	r := &AddonOperatorReconciler{}
+	addonOperator := newPullModeAddonOperator(&metav1.Time{})
+	addonOperator.Spec.OCM.PullMode = nil
+	setOCMAddonsSyncedCondition(addonOperator, metav1.ConditionTrue,
+		addonsv1alpha1.AddonOperatorReasonOCMAddonsSynced, "")
+
+	requeueAfter, err := r.handleOCMPull(context.Background(), testutil.NewLogger(t), addonOperator, nil)
+	require.NoError(t, err)
+
+	assert.Zero(t, requeueAfter)
+	assert.Nil(t, addonOperator.Status.OCMPull)
+	assert.Nil(t, meta.FindStatusCondition(
+		addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced))
+}
+
+func TestHandleOCMPull_DeleteAfterAck(t *testing.T) {
+	acked := newPulledAddon("acked", "1.0.0")
+	acked.Annotations[addonsv1alpha1.DeleteAnnotationFlag] = "true"
+	acked.Status.Conditions = []metav1.Condition{{
+		Type: addonsv1alpha1.ReadyToBeDeleted, Status: metav1.ConditionTrue,
+	}}
+	timedOut := newPulledAddon("timed-out", "1.0.0")
+	timedOut.Annotations[addonsv1alpha1.DeleteAnnotationFlag] = "true"
+	timedOut.Status.Conditions = []metav1.Condition{
+		{Type: addonsv1alpha1.ReadyToBeDeleted, Status: metav1.ConditionFalse},
+		{Type: addonsv1alpha1.DeleteTimeout, Status: metav1.ConditionTrue},
+	}
+	waiting := newPulledAddon("waiting", "1.0.0")
+	waiting.Annotations[addonsv1alpha1.DeleteAnnotationFlag] = "true"
+	waiting.Status.Conditions = []metav1.Condition{{
+		Type: addonsv1alpha1.ReadyToBeDeleted, Status: metav1.ConditionFalse,
+	}}
+	c := newPullTestClient(acked, timedOut, waiting)
+	r := &AddonOperatorReconciler{Client: c}
+
+	ocmClient := ocmtest.NewClient()
+	ocmClient.On("ListAddonInstallations", mock.Anything).Return([]ocm.AddonInstallation{
+		{ID: "acked", State: ocm.AddonInstallationStateDeleting},
+		{ID: "timed-out", State: ocm.AddonInstallationStateDeleting},
+		{ID: "waiting", State: ocm.AddonInstallationStateDeleting},
+	}, nil)
+
+	ctx := context.Background()
+	_, err := r.handleOCMPull(ctx, testutil.NewLogger(t), newPullModeAddonOperator(nil), ocmClient)
+	require.NoError(t, err)
+
+	for _, name := range []string{"acked", "timed-out"} {
+		err := c.Get(ctx, client.ObjectKey{Name: name}, &addonsv1alpha1.Addon{})
+		assert.True(t, client.IgnoreNotFound(err) == nil && err != nil, "%s should be deleted", name)
+	}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "waiting"}, &addonsv1alpha1.Addon{}))
+}
+
+func TestHandleOCMPull_DeletionRefused(t *testing.T) {
+	for name, tc := range map[string]struct {
+		installations []ocm.AddonInstallation
+		refused       []string
+	}{
+		"empty list": {
+			refused: []string{"addon-1", "addon-2", "addon-3", "addon-4"},
+		},
+		"sharp shrink": {
+			installations: pullInstallations(map[string]string{"addon-1": "1.0.0"}),
+			refused:       []string{"addon-2", "addon-3", "addon-4"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := newPullTestClient(
+				newPulledAddon("addon-1", "1.0.0"), newPulledAddon("addon-2", "1.0.0"),
+				newPulledAddon("addon-3", "1.0.0"), newPulledAddon("addon-4", "1.0.0"),
+			)
+			r := &AddonOperatorReconciler{Client: c}
+
+			ocmClient := ocmtest.NewClient()
+			ocmClient.On("ListAddonInstallations", mock.Anything).Return(tc.installations, nil)
+
+			addonOperator := newPullModeAddonOperator(nil)
+			ctx := context.Background()
+			_, err := r.handleOCMPull(ctx, testutil.NewLogger(t), addonOperator, ocmClient)
+			require.NoError(t, err)
+
+			for _, name := range tc.refused {
+				addon := &addonsv1alpha1.Addon{}
+				require.NoError(t, c.Get(ctx, client.ObjectKey{Name: name}, addon))
+				assert.NotContains(t, addon.Annotations, addonsv1alpha1.DeleteAnnotationFlag)
+			}
+
+			cond := meta.FindStatusCondition(addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced)
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, addonsv1alpha1.AddonOperatorReasonOCMAddonsDeletionRefused, cond.Reason)
+			assert.Contains(t, cond.Message, strings.Join(tc.refused, ", "))
+		})
+	}
+}
+
+func TestHandleOCMPull_PartialFailure(t *testing.T) {
+	c := fake.NewClientBuilder().
+		WithScheme(testutil.NewTestSchemeWithAddonsv1alpha1()).
+		WithObjects(newPulledAddon("outdated", "1.0.0")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == "broken" {
+					return errors.New("admission denied")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	r := &AddonOperatorReconciler{Client: c}
+
+	ocmClient := ocmtest.NewClient()
+	ocmClient.On("ListAddonInstallations", mock.Anything).Return(pullInstallations(map[string]string{
+		"broken": "1.0.0", "new": "1.0.0", "outdated": "2.0.0",
+	}), nil)
+
+	addonOperator := newPullModeAddonOperator(nil)
+	ctx := context.Background()
+	_, err := r.handleOCMPull(ctx, testutil.NewLogger(t), addonOperator, ocmClient)
+	require.ErrorContains(t, err, "creating Addon broken: admission denied")
+
+	// The other Addons are still synced.
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "new"}, &addonsv1alpha1.Addon{}))
+	updated := &addonsv1alpha1.Addon{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "outdated"}, updated))
+	assert.Equal(t, "2.0.0", updated.Spec.Version)
+
+	cond := meta.FindStatusCondition(addonOperator.Status.Conditions, addonsv1alpha1.AddonOperatorOCMAddonsSynced)
+	require.NotNil(t, cond)
+	assert.Equal(t, addonsv1alpha1.AddonOperatorReasonOCMAddonsSyncFailed, cond.Reason)
+}
+
+func TestHandleOCMPull_RevertsLocalChanges(t *testing.T) {
+	c := newPullTestClient(newPulledAddon("addon-1", "1.0.0"))
+	r := &AddonOperatorReconciler{Client: c}
+	ctx := context.Background()
+
+	modified := &addonsv1alpha1.Addon{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "addon-1"}, modified))
+	modified.Spec.Paused = true
+	require.NoError(t, c.Update(ctx, modified))
+
+	ocmClient := ocmtest.NewClient()
+	ocmClient.On("ListAddonInstallations", mock.Anything).
+		Return(pullInstallations(map[string]string{"addon-1": "1.0.0"}), nil)
+
+	_, err := r.handleOCMPull(ctx, testutil.NewLogger(t), newPullModeAddonOperator(nil), ocmClient)
+	require.NoError(t, err)
+
+	reverted := &addonsv1alpha1.Addon{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "addon-1"}, reverted))
+	assert.False(t, reverted.Spec.Paused)
+	assert.Equal(t, hashAddonSpec(reverted.Spec), reverted.Annotations[ocmAddonAppliedSpecHashAnnotation])
+}
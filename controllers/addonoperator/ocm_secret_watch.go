@@ -0,0 +1,158 @@
+package addonoperator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// OCMSecretWatch enqueues the AddonOperator when the Secret referenced in .spec.ocm.secret changes,
+// to pick up rotated credentials right away.
+// Only the referenced Secret is cached, the cache is replaced when the reference changes.
+// Events of all caches go through the single Source registered with the AddonOperator controller
+// and are filtered on the currently referenced Secret.
+// Implements manager.Runnable, caches are stopped together with the manager.
+type OCMSecretWatch struct {
+	log      logr.Logger
+	newCache func(ref addonsv1alpha1.ClusterSecretReference) (cache.Cache, error)
+
+	mux sync.Mutex
+	ctx context.Context
+	// Queue of the AddonOperator controller, set once it starts the Source.
+	queue   workqueue.TypedRateLimitingInterface[reconcile.Request]
+	ref     addonsv1alpha1.ClusterSecretReference
+	started bool
+	stop    context.CancelFunc
+}
+
+// Creates an OCMSecretWatch, caching the referenced Secret with the given config and options.
+func NewOCMSecretWatch(log logr.Logger, config *rest.Config, opts cache.Options) *OCMSecretWatch {
+	return &OCMSecretWatch{
+		log: log,
+		newCache: func(ref addonsv1alpha1.ClusterSecretReference) (cache.Cache, error) {
+			opts := opts
+			opts.DefaultNamespaces = map[string]cache.Config{ref.Namespace: {}}
+			opts.ByObject = map[client.Object]cache.ByObject{
+				&corev1.Secret{}: {
+					Field: fields.OneTermEqualSelector("metadata.name", ref.Name),
+				},
+			}
+			return cache.New(config, opts)
+		},
+	}
+}
+
+// Start keeps the watch running until the context is canceled.
+func (w *OCMSecretWatch) Start(ctx context.Context) error {
+	w.mux.Lock()
+	w.ctx = ctx
+	if err := w.restart(); err != nil {
+		w.log.Error(err, "watching OCM secret")
+	}
+	w.mux.Unlock()
+
+	<-ctx.Done()
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.stopCache()
+	return nil
+}
+
+// Source returns the event source to register once with the AddonOperator controller.
+func (w *OCMSecretWatch) Source() source.Source {
+	return source.Func(func(_ context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		w.mux.Lock()
+		defer w.mux.Unlock()
+
+		w.queue = queue
+		if err := w.restart(); err != nil {
+			w.log.Error(err, "watching OCM secret")
+		}
+		return nil
+	})
+}
+
+// Ensure moves the watch to the given Secret, unless it is watched already.
+func (w *OCMSecretWatch) Ensure(ref addonsv1alpha1.ClusterSecretReference) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.ref == ref && w.started {
+		return nil
+	}
+	w.ref = ref
+	return w.restart()
+}
+
+// Stop stops watching, when .spec.ocm was removed.
+func (w *OCMSecretWatch) Stop() {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.ref = addonsv1alpha1.ClusterSecretReference{}
+	w.stopCache()
+}
+
+// Must be called with the lock held.
+func (w *OCMSecretWatch) restart() error {
+	w.stopCache()
+	// Started by Start and Source once the manager runs.
+	if w.ctx == nil || w.queue == nil || len(w.ref.Name) == 0 {
+		return nil
+	}
+
+	c, err := w.newCache(w.ref)
+	if err != nil {
+		return fmt.Errorf("creating OCM secret cache: %w", err)
+	}
+	ctx, cancel := context.WithCancel(w.ctx)
+	// Feeds the queue of the controller until the cache is replaced.
+	if err := source.Kind(
+		c, &corev1.Secret{},
+		handler.TypedEnqueueRequestsFromMapFunc(enqueueAddonOperatorForSecret),
+		predicate.NewTypedPredicateFuncs(w.isReferenced),
+	).Start(ctx, w.queue); err != nil {
+		cancel()
+		return fmt.Errorf("watching OCM secret: %w", err)
+	}
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			w.log.Error(err, "running OCM secret cache")
+		}
+	}()
+	w.started = true
+	w.stop = cancel
+	return nil
+}
+
+// Drops events of replaced caches, that are still shutting down.
+func (w *OCMSecretWatch) isReferenced(secret *corev1.Secret) bool {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return secret.Name == w.ref.Name && secret.Namespace == w.ref.Namespace
+}
+
+// Must be called with the lock held.
+func (w *OCMSecretWatch) stopCache() {
+	if w.stop != nil {
+		w.stop()
+	}
+	w.stop = nil
+	w.started = false
+}
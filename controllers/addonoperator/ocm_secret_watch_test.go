@@ -0,0 +1,128 @@
+package addonoperator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/testutil"
+)
+
+func TestOCMSecretWatch(t *testing.T) {
+	var (
+		cached    []addonsv1alpha1.ClusterSecretReference
+		informers []*testSecretInformer
+	)
+	w := &OCMSecretWatch{
+		log: testutil.NewLogger(t),
+		newCache: func(ref addonsv1alpha1.ClusterSecretReference) (cache.Cache, error) {
+			cached = append(cached, ref)
+			informer := &testSecretInformer{
+				FakeInformer: &controllertest.FakeInformer{Synced: true},
+				registered:   make(chan struct{}),
+			}
+			informers = append(informers, informer)
+			return &informertest.FakeInformers{
+				Scheme: scheme.Scheme,
+				InformersByGVK: map[schema.GroupVersionKind]toolscache.SharedIndexInformer{
+					corev1.SchemeGroupVersion.WithKind("Secret"): informer,
+				},
+			}, nil
+		},
+	}
+	secret := addonsv1alpha1.ClusterSecretReference{Name: "pull-secret", Namespace: "openshift-config"}
+
+	// Picked up once the manager starts the watch and the controller its source.
+	require.NoError(t, w.Ensure(secret))
+	assert.Empty(t, cached)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+	require.Eventually(t, func() bool {
+		w.mux.Lock()
+		defer w.mux.Unlock()
+		return w.ctx != nil
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, cached)
+
+	queue := workqueue.NewTypedRateLimitingQueue(
+		workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	require.NoError(t, w.Source().Start(ctx, queue))
+	require.Len(t, cached, 1)
+	requireEnqueued(t, queue, informers[0], secret)
+
+	// Unchanged references keep the watch.
+	require.NoError(t, w.Ensure(secret))
+	assert.Len(t, cached, 1)
+
+	// Changed references move it, events of the old cache are dropped.
+	renamed := addonsv1alpha1.ClusterSecretReference{Name: "ocm-secret", Namespace: "addon-operator"}
+	require.NoError(t, w.Ensure(renamed))
+	assert.Equal(t, []addonsv1alpha1.ClusterSecretReference{secret, renamed}, cached)
+	requireEnqueued(t, queue, informers[1], renamed)
+
+	informers[0].Add(newTestSecret(secret))
+	assert.Equal(t, 0, queue.Len())
+
+	w.Stop()
+	assert.False(t, w.started)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+// The fake informer is not safe for concurrent use,
+// so events are only added once the watch registered its event handler.
+type testSecretInformer struct {
+	*controllertest.FakeInformer
+	registered chan struct{}
+}
+
+func (i *testSecretInformer) AddEventHandlerWithOptions(
+	h toolscache.ResourceEventHandler, opts toolscache.HandlerOptions,
+) (toolscache.ResourceEventHandlerRegistration, error) {
+	reg, err := i.FakeInformer.AddEventHandlerWithOptions(h, opts)
+	close(i.registered)
+	return reg, err
+}
+
+// Adds the Secret to the cache and expects the AddonOperator to be enqueued for it.
+func requireEnqueued(
+	t *testing.T, queue workqueue.TypedRateLimitingInterface[reconcile.Request],
+	informer *testSecretInformer, ref addonsv1alpha1.ClusterSecretReference,
+) {
+	t.Helper()
+
+	select {
+	case <-informer.registered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event handler not registered")
+	}
+	informer.Add(newTestSecret(ref))
+	require.Equal(t, 1, queue.Len())
+
+	req, _ := queue.Get()
+	assert.Equal(t, addonsv1alpha1.DefaultAddonOperatorName, req.Name)
+	queue.Forget(req)
+	queue.Done(req)
+}
+
+func newTestSecret(ref addonsv1alpha1.ClusterSecretReference) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace}}
+}
@@ -21,6 +21,7 @@ type globalPauseManager interface {
 
 type ocmClientManager interface {
 	InjectOCMClient(ctx context.Context, c *ocm.Client) error
+	ConfigureOCMReporting(reporting *addonsv1alpha1.AddonOperatorOCMReporting)
 }
 
 func (r *AddonOperatorReconciler) handleAddonOperatorCreation(
@@ -51,6 +51,8 @@ var (
 	ErrEnsureNetworkPolicy = newControllerReconcileError("err_ensure_networkpolicy")
 	// Failed to ensure existence of catalogsource
 	ErrEnsureCatalogSource = newControllerReconcileError("err_ensure_catalogsource")
+	// Failed to roll out a new catalog image
+	ErrCanaryRollout = newControllerReconcileError("err_canary_rollout")
 	// Failed to ensure existence of additional catalogsource
 	ErrEnsureAdditionalCatalogSource = newControllerReconcileError("err_ensure_additional_catalogsource")
 	// An error happened while reconciling a subscription
@@ -61,6 +63,16 @@ var (
 	ErrEnsureDeleteClusterObjectTemplate = newControllerReconcileError("err_ensure_delete_of_clusterobjecttemplate")
 	// An error happened while reconcileing clusterobjecttemplate
 	ErrReconcileClusterObjectTemplate = newControllerReconcileError("err_reconcile_cluster_object_template")
+	// Failed to apply the objects rendered from a helm chart
+	ErrApplyHelmChartObjects = newControllerReconcileError("err_apply_helm_chart_objects")
+	// Failed to observe the workloads rendered from a helm chart
+	ErrObserveHelmChartWorkloads = newControllerReconcileError("err_observe_helm_chart_workloads")
+	// Failed to apply the objects of a manifest bundle
+	ErrApplyManifests = newControllerReconcileError("err_apply_manifests")
+	// Failed to prune objects removed from a manifest bundle
+	ErrPruneManifests = newControllerReconcileError("err_prune_manifests")
+	// Failed to observe the workloads of a manifest bundle
+	ErrObserveManifestsWorkloads = newControllerReconcileError("err_observe_manifests_workloads")
 	// Failed to cleanup unknown secrets
 	ErrCleanupUnknownSecrets = newControllerReconcileError("err_cleanup_unknown_secrets")
 	// Failed to get target/destination secrets that didn't have namespace
@@ -81,6 +93,8 @@ var (
 	ErrAddonOperatorHandleGlobalPause = newControllerReconcileError("err_addon_operator_handle_global_pause")
 	// Failed to create OCM client
 	ErrCreateOCMClient = newControllerReconcileError("err_create_ocm_client")
+	// Failed to sync Addons from OCM in pull mode
+	ErrSyncOCMAddons = newControllerReconcileError("err_sync_ocm_addons")
 	// Failed to report addon-operator readiness status
 	ErrReportAddonOperatorStatus = newControllerReconcileError("err_report_addonoperator_status")
 )
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
+)
+
+// SetAPIMockScenario replaces the faults injected by the api-mock.
+func SetAPIMockScenario(ctx context.Context, scenario ocmtest.Scenario) error {
+	body, err := json.Marshal(scenario)
+	if err != nil {
+		return fmt.Errorf("marshaling scenario: %w", err)
+	}
+	return doAPIMockRequest(ctx, http.MethodPut, "/scenario", body, nil)
+}
+
+// ResetAPIMock removes all injected faults and recorded requests from the api-mock.
+func ResetAPIMock(ctx context.Context) error {
+	if err := doAPIMockRequest(ctx, http.MethodDelete, "/scenario", nil, nil); err != nil {
+		return err
+	}
+	return doAPIMockRequest(ctx, http.MethodDelete, "/requests", nil, nil)
+}
+
+// APIMockRequests lists the OCM API requests recorded by the api-mock,
+// filtered by method and a regular expression matching the path, if not empty.
+func APIMockRequests(ctx context.Context, method, path string) ([]ocmtest.RecordedRequest, error) {
+	query := url.Values{}
+	query.Set("method", method)
+	query.Set("path", path)
+
+	var requests []ocmtest.RecordedRequest
+	if err := doAPIMockRequest(ctx, http.MethodGet, "/requests?"+query.Encode(), nil, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func doAPIMockRequest(ctx context.Context, method, path string, body []byte, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, apiMockProxyEndpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating api-mock request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending api-mock request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		data, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("api-mock %s %s: HTTP %d: %s", method, path, res.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("decoding api-mock response: %w", err)
+	}
+	return nil
+}
@@ -43,6 +43,8 @@ import (
 
 const (
 	OCMAPIEndpoint = "http://api-mock.api-mock.svc.cluster.local"
+	// The api-mock as reached through the API server proxy started by RunAPIServerProxy.
+	apiMockProxyEndpoint = "http://127.0.0.1:8001/api/v1/namespaces/api-mock/services/api-mock:80/proxy"
 )
 
 var (
@@ -123,7 +125,7 @@ func InitOCMClient() error {
 	// Create a client to talk with the OCM mock API for testing
 	ocmClient, err := ocm.NewClient(
 		context.Background(),
-		ocm.WithEndpoint("http://127.0.0.1:8001/api/v1/namespaces/api-mock/services/api-mock:80/proxy"),
+		ocm.WithEndpoint(apiMockProxyEndpoint),
 		ocm.WithAccessToken("accessToken"), // TODO: Needs to be supplied from the outside, does not matter for mock.
 		ocm.WithClusterExternalID(string(Cv.Spec.ClusterID)),
 	)
@@ -2,6 +2,8 @@ package integration_test
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -12,6 +14,7 @@ import (
 	addonutils "github.com/openshift/addon-operator/controllers/addon"
 	"github.com/openshift/addon-operator/integration"
 	"github.com/openshift/addon-operator/internal/ocm"
+	"github.com/openshift/addon-operator/internal/ocm/ocmtest"
 	"github.com/openshift/addon-operator/internal/testutil"
 )
 
@@ -141,7 +144,74 @@ func (s *integrationTestSuite) TestAddonStatusReporting() {
 		s.Require().NoError(err)
 
 		s.Require().NotNil(addon.Status.OCMReportedStatusHash)
-		s.Require().Equal(addon.Status.OCMReportedStatusHash.StatusHash, addonutils.HashCurrentAddonStatus(addon))
+		s.Require().Equal(addon.Status.OCMReportedStatusHash.StatusHash, addonutils.HashAddonStatusPayload(
+			ocm.AddOnStatusPostRequest{
+				AddonID:            res.AddonID,
+				CorrelationID:      res.CorrelationID,
+				AddonVersion:       res.AddonVersion,
+				StatusConditions:   res.StatusConditions,
+				AddOnStatusDetails: res.AddOnStatusDetails,
+			}))
 	})
 
 }
+
+func (s *integrationTestSuite) TestOCMClientFaults() {
+	if !testutil.IsApiMockEnabled() {
+		s.T().Skip("skipping OCM tests since api mock execution is disabled")
+	}
+
+	ctx := context.Background()
+	s.Require().NoError(integration.ResetAPIMock(ctx))
+	s.T().Cleanup(func() {
+		s.Assert().NoError(integration.ResetAPIMock(ctx))
+	})
+
+	// Only requests about this addon are affected,
+	// so the Addon Operator keeps talking to the api-mock undisturbed.
+	const statusPath = "/status/fault-injection$"
+
+	s.Run("retries through an outage", func() {
+		s.Require().NoError(integration.SetAPIMockScenario(ctx, ocmtest.Scenario{
+			Rules: []ocmtest.FaultRule{
+				{Name: "outage", Path: statusPath, Times: 2, StatusCode: http.StatusServiceUnavailable},
+			},
+		}))
+
+		// The status does not exist, the api-mock answers once the outage is over.
+		_, err := integration.OCMClient.GetAddOnStatus(ctx, "fault-injection")
+		var ocmErr ocm.OCMError
+		s.Require().ErrorAs(err, &ocmErr)
+		s.Assert().Equal(http.StatusNotFound, ocmErr.StatusCode)
+
+		requests, err := integration.APIMockRequests(ctx, http.MethodGet, statusPath)
+		s.Require().NoError(err)
+		s.Assert().Len(requests, 3)
+	})
+
+	s.Run("rejects invalid tokens", func() {
+		s.Require().NoError(integration.SetAPIMockScenario(ctx, ocmtest.Scenario{
+			Rules: []ocmtest.FaultRule{
+				{Name: "auth", Path: statusPath, Tokens: []string{"rotated"}},
+			},
+		}))
+
+		_, err := integration.OCMClient.GetAddOnStatus(ctx, "fault-injection")
+		var ocmErr ocm.OCMError
+		s.Require().ErrorAs(err, &ocmErr)
+		s.Assert().Equal(http.StatusUnauthorized, ocmErr.StatusCode)
+	})
+
+	s.Run("times out slow responses", func() {
+		s.Require().NoError(integration.SetAPIMockScenario(ctx, ocmtest.Scenario{
+			Rules: []ocmtest.FaultRule{
+				{Name: "slow", Path: statusPath, Latency: metav1.Duration{Duration: time.Minute}},
+			},
+		}))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_, err := integration.OCMClient.GetAddOnStatus(timeoutCtx, "fault-injection")
+		s.Assert().ErrorIs(err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,57 @@
+package dependency
+
+import (
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// Dependents returns the Addons that depend on the Addon with the given name.
+func Dependents(name string, addons []addonsv1alpha1.Addon) []*addonsv1alpha1.Addon {
+	var dependents []*addonsv1alpha1.Addon
+	for i := range addons {
+		for _, dep := range addons[i].Spec.DependsOn {
+			if dep.Name == name {
+				dependents = append(dependents, &addons[i])
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// FindCycle returns the names of the Addons forming a cycle
+// through the given Addon, starting and ending with it.
+// Existing Addons are taken from the list, except for the given Addon,
+// which may be a new version of an Addon in the list.
+// Returns nil, if the Addon is not part of a cycle.
+func FindCycle(addon *addonsv1alpha1.Addon, addons []addonsv1alpha1.Addon) []string {
+	graph := map[string][]string{}
+	for _, a := range addons {
+		for _, dep := range a.Spec.DependsOn {
+			graph[a.Name] = append(graph[a.Name], dep.Name)
+		}
+	}
+	graph[addon.Name] = nil
+	for _, dep := range addon.Spec.DependsOn {
+		graph[addon.Name] = append(graph[addon.Name], dep.Name)
+	}
+
+	visited := map[string]bool{}
+	var visit func(name string, path []string) []string
+	visit = func(name string, path []string) []string {
+		path = append(path, name)
+		if name == addon.Name && len(path) > 1 {
+			return path
+		}
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+		for _, next := range graph[name] {
+			if cycle := visit(next, path); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit(addon.Name, nil)
+}
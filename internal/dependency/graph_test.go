@@ -0,0 +1,69 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func newAddon(name string, dependsOn ...string) addonsv1alpha1.Addon {
+	addon := addonsv1alpha1.Addon{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, dep := range dependsOn {
+		addon.Spec.DependsOn = append(addon.Spec.DependsOn, addonsv1alpha1.AddonDependency{Name: dep})
+	}
+	return addon
+}
+
+func TestDependents(t *testing.T) {
+	addons := []addonsv1alpha1.Addon{
+		newAddon("logging", "monitoring"),
+		newAddon("tracing", "monitoring", "logging"),
+		newAddon("monitoring"),
+	}
+
+	var names []string
+	for _, a := range Dependents("monitoring", addons) {
+		names = append(names, a.Name)
+	}
+	assert.Equal(t, []string{"logging", "tracing"}, names)
+	assert.Empty(t, Dependents("tracing", addons))
+}
+
+func TestFindCycle(t *testing.T) {
+	addons := []addonsv1alpha1.Addon{
+		newAddon("logging", "monitoring"),
+		newAddon("tracing", "logging"),
+		newAddon("monitoring"),
+	}
+
+	for name, tc := range map[string]struct {
+		addon addonsv1alpha1.Addon
+		cycle []string
+	}{
+		"new addon": {
+			addon: newAddon("storage", "monitoring", "logging"),
+		},
+		"no cycle": {
+			addon: newAddon("monitoring", "storage"),
+		},
+		"self": {
+			addon: newAddon("monitoring", "monitoring"),
+			cycle: []string{"monitoring", "monitoring"},
+		},
+		"indirect": {
+			addon: newAddon("monitoring", "tracing"),
+			cycle: []string{"monitoring", "tracing", "logging", "monitoring"},
+		},
+		"dependency removed": {
+			// The stored version of logging depends on monitoring.
+			addon: newAddon("logging"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.cycle, FindCycle(&tc.addon, addons))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package helm
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/client-go/discovery"
+)
+
+// CapabilitiesCache looks up the capabilities of the cluster,
+// exposed to templates as .Capabilities, via the discovery API.
+// Results are kept for the configured TTL, so API groups
+// installed later on are eventually visible to charts.
+type CapabilitiesCache struct {
+	discovery discovery.DiscoveryInterface
+	ttl       time.Duration
+	now       func() time.Time
+
+	mux       sync.Mutex
+	caps      *chartutil.Capabilities
+	fetchedAt time.Time
+}
+
+func NewCapabilitiesCache(dc discovery.DiscoveryInterface, ttl time.Duration) *CapabilitiesCache {
+	return &CapabilitiesCache{
+		discovery: dc,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// Get returns the cached capabilities,
+// refreshing them when they are older than the TTL.
+func (c *CapabilitiesCache) Get() (*chartutil.Capabilities, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.caps != nil && c.now().Sub(c.fetchedAt) < c.ttl {
+		return c.caps, nil
+	}
+
+	serverVersion, err := c.discovery.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("getting server version: %w", err)
+	}
+	apiVersions, err := versionSet(c.discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	c.caps = &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{
+			Version: serverVersion.GitVersion,
+			Major:   serverVersion.Major,
+			Minor:   serverVersion.Minor,
+		},
+		APIVersions: apiVersions,
+		HelmVersion: chartutil.DefaultCapabilities.HelmVersion,
+	}
+	c.fetchedAt = c.now()
+	return c.caps, nil
+}
+
+// versionSet lists all group/versions and group/version/kinds served by the cluster,
+// following `helm install`.
+func versionSet(dc discovery.ServerResourcesInterface) (chartutil.VersionSet, error) {
+	groups, resources, err := dc.ServerGroupsAndResources()
+	// Unavailable aggregated APIs must not block rendering,
+	// the result still contains all other groups.
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("getting server resources: %w", err)
+	}
+
+	seen := map[string]struct{}{}
+	var versions chartutil.VersionSet
+	add := func(v string) {
+		if _, ok := seen[v]; ok {
+			return
+		}
+		seen[v] = struct{}{}
+		versions = append(versions, v)
+	}
+	for _, group := range groups {
+		for _, gv := range group.Versions {
+			add(gv.GroupVersion)
+		}
+	}
+	for _, list := range resources {
+		for _, resource := range list.APIResources {
+			add(path.Join(list.GroupVersion, resource.Kind))
+		}
+	}
+	return versions, nil
+}
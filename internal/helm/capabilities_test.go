@@ -0,0 +1,57 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCapabilitiesCache(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{
+		Fake: &clienttesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap"}},
+				},
+				{
+					GroupVersion: "policy/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"},
+						{Name: "poddisruptionbudgets/status", Kind: "PodDisruptionBudget"},
+					},
+				},
+			},
+		},
+		FakedServerVersion: &version.Info{GitVersion: "v1.32.4", Major: "1", Minor: "32"},
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCapabilitiesCache(dc, time.Minute)
+	c.now = func() time.Time { return now }
+
+	caps, err := c.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.32.4", caps.KubeVersion.Version)
+	assert.Equal(t, "32", caps.KubeVersion.Minor)
+	assert.ElementsMatch(t, []string{
+		"v1", "v1/ConfigMap", "policy/v1", "policy/v1/PodDisruptionBudget",
+	}, []string(caps.APIVersions))
+
+	// Cached within the TTL.
+	dc.FakedServerVersion = &version.Info{GitVersion: "v1.33.0", Major: "1", Minor: "33"}
+	caps, err = c.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.32.4", caps.KubeVersion.Version)
+
+	now = now.Add(time.Minute)
+	caps, err = c.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.33.0", caps.KubeVersion.Version)
+}
@@ -0,0 +1,104 @@
+package helm
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/addon-operator/internal/manifests"
+)
+
+// Media type of the chart layer in Helm OCI artifacts.
+const ChartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+const (
+	notesFileSuffix = "NOTES.txt"
+	hookAnnotation  = "helm.sh/hook"
+)
+
+// Options influence how a chart is rendered.
+type Options struct {
+	Release chartutil.ReleaseOptions
+	// Values merged over the chart defaults.
+	Values map[string]interface{}
+	// Capabilities of the target cluster,
+	// chartutil.DefaultCapabilities are used when nil.
+	Capabilities *chartutil.Capabilities
+}
+
+// Render renders all templates of the given chart and its dependencies
+// using the Helm template engine and returns the resulting objects,
+// including the CRDs of the chart, in install order.
+// The chart is modified while processing its dependencies,
+// so it must be loaded anew for every call.
+func Render(ch *chart.Chart, opts Options) ([]*unstructured.Unstructured, error) {
+	values := opts.Values
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	if err := chartutil.ProcessDependenciesWithMerge(ch, values); err != nil {
+		return nil, fmt.Errorf("processing chart dependencies: %w", err)
+	}
+
+	renderValues, err := chartutil.ToRenderValues(ch, values, opts.Release, opts.Capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("preparing values: %w", err)
+	}
+	files, err := engine.Render(ch, renderValues)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []*unstructured.Unstructured
+	// CRDs are not templated, Helm installs them as they are.
+	for _, crd := range ch.CRDObjects() {
+		decoded, err := manifests.Decode(crd.File.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", crd.Filename, err)
+		}
+		objs = append(objs, decoded...)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if strings.HasSuffix(name, notesFileSuffix) || strings.HasPrefix(path.Base(name), "_") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		decoded, err := manifests.Decode([]byte(files[name]))
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", name, err)
+		}
+		for _, obj := range decoded {
+			if isTestHook(obj) {
+				continue
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	manifests.SortByInstallOrder(objs)
+	return objs, nil
+}
+
+// Test hooks are only run on demand by `helm test`.
+// Other hooks are applied together with all other objects.
+func isTestHook(obj *unstructured.Unstructured) bool {
+	for _, hook := range strings.Split(obj.GetAnnotations()[hookAnnotation], ",") {
+		switch strings.TrimSpace(hook) {
+		case "test", "test-success":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestRender(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "test", Version: "1.0.0"},
+		Values: map[string]interface{}{
+			"replicas": 1,
+			"image": map[string]interface{}{
+				"repository": "quay.io/test/test",
+				"tag":        "v1",
+			},
+			"extraLabels": map[string]interface{}{"removed": "true"},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(
+				`{{- define "test.fullname" -}}{{ .Release.Name }}-{{ .Chart.Name }}{{- end -}}`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`Installed {{ .Release.Name }}`)},
+			{Name: "templates/deployment.yaml", Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "test.fullname" . }}
+  labels:
+    {{- with .Values.extraLabels }}
+    {{- toYaml . | nindent 4 }}
+    {{- end }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+      - name: test
+        image: {{ printf "%s:%s" .Values.image.repository .Values.image.tag | quote }}
+`)},
+			{Name: "templates/config.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "test.fullname" . }}
+  namespace: {{ .Release.Namespace }}
+data:
+  install: {{ .Release.IsInstall | quote }}
+---
+# Empty documents are skipped.
+---
+{{- if .Values.missing }}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: never
+{{- end }}
+`)},
+		},
+	}
+
+	objs, err := Render(ch, Options{
+		Release: chartutil.ReleaseOptions{Name: "rel", Namespace: "ns", IsInstall: true},
+		Values: map[string]interface{}{
+			"replicas":    3,
+			"image":       map[string]interface{}{"tag": "v2"},
+			"extraLabels": nil,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+
+	// ConfigMaps are installed before Deployments.
+	configMap := objs[0]
+	assert.Equal(t, "ConfigMap", configMap.GetKind())
+	assert.Equal(t, "rel-test", configMap.GetName())
+	assert.Equal(t, "ns", configMap.GetNamespace())
+	assert.Equal(t, map[string]interface{}{"install": "true"}, configMap.Object["data"])
+
+	deployment := objs[1]
+	assert.Equal(t, "Deployment", deployment.GetKind())
+	assert.Equal(t, "rel-test", deployment.GetName())
+	assert.Empty(t, deployment.GetLabels())
+	assert.Equal(t, map[string]interface{}{
+		"replicas": int64(3),
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "test", "image": "quay.io/test/test:v2"},
+				},
+			},
+		},
+	}, deployment.Object["spec"])
+
+	// Chart defaults must not be modified by rendering.
+	assert.Equal(t, 1, ch.Values["replicas"])
+	assert.Contains(t, ch.Values, "extraLabels")
+}
+
+func TestRender_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "parse error",
+			template: `{{ .Values.a `,
+			expected: "test/templates/a.yaml",
+		},
+		{
+			name:     "required value",
+			template: `{{ required "a is required" .Values.a }}`,
+			expected: "a is required",
+		},
+		{
+			name:     "fail",
+			template: `{{ fail "unsupported" }}`,
+			expected: "unsupported",
+		},
+		{
+			name:     "missing kind",
+			template: "apiVersion: v1\nmetadata:\n  name: a\n",
+			expected: "missing apiVersion or kind",
+		},
+		{
+			name:     "recursive include",
+			template: `{{ define "loop" }}{{ include "loop" . }}{{ end }}{{ include "loop" . }}`,
+			expected: "nested reference name: loop",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ch := &chart.Chart{
+				Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "test"},
+				Templates: []*chart.File{
+					{Name: "templates/a.yaml", Data: []byte(test.template)},
+				},
+			}
+			_, err := Render(ch, Options{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestRender_Tpl(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "test"},
+		Values: map[string]interface{}{
+			"name":     "{{ .Release.Name }}-cm",
+			"settings": map[string]interface{}{"b": "2", "a": "1"},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/cm.yaml", Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ tpl .Values.name . }}
+data:
+  keys: {{ keys .Values.settings | sortAlpha | join "," }}
+  merged: {{ merge (dict "c" "3") .Values.settings | toJson | quote }}
+  default: {{ .Values.unset | default "fallback" }}
+`)},
+		},
+	}
+
+	objs, err := Render(ch, Options{Release: chartutil.ReleaseOptions{Name: "rel"}})
+	require.NoError(t, err)
+	require.Len(t, objs, 1)
+	assert.Equal(t, "rel-cm", objs[0].GetName())
+	assert.Equal(t, map[string]interface{}{
+		"keys":    "a,b",
+		"merged":  `{"a":"1","b":"2","c":"3"}`,
+		"default": "fallback",
+	}, objs[0].Object["data"])
+}
+
+func TestRender_CapabilitiesCRDsAndHooks(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "test", KubeVersion: ">= 1.30.0-0"},
+		Files: []*chart.File{
+			{Name: "crds/crd.yaml", Data: []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: tests.example.com
+`)},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/pdb.yaml", Data: []byte(`{{- if .Capabilities.APIVersions.Has "policy/v1/PodDisruptionBudget" }}
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    kube-version: {{ .Capabilities.KubeVersion.Minor | quote }}
+{{- end }}
+`)},
+			{Name: "templates/tests/test.yaml", Data: []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}-test
+  annotations:
+    helm.sh/hook: test
+`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`Installed {{ .Release.Name }}`)},
+		},
+	}
+
+	objs, err := Render(ch, Options{
+		Release: chartutil.ReleaseOptions{Name: "rel"},
+		Capabilities: &chartutil.Capabilities{
+			KubeVersion: chartutil.KubeVersion{Version: "v1.32.4", Major: "1", Minor: "32"},
+			APIVersions: chartutil.VersionSet{"v1", "policy/v1", "policy/v1/PodDisruptionBudget"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	assert.Equal(t, "PodDisruptionBudget", objs[0].GetKind())
+	assert.Equal(t, map[string]string{"kube-version": "32"}, objs[0].GetLabels())
+	assert.Equal(t, "CustomResourceDefinition", objs[1].GetKind())
+}
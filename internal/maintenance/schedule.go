@@ -0,0 +1,82 @@
+package maintenance
+
+import (
+	"fmt"
+	"time"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+const (
+	blackoutDateLayout = "2006-01-02"
+	// Limits the search for the next window,
+	// when blackout dates cover many consecutive openings.
+	maxNextOpenAttempts = 1000
+)
+
+// Schedule combines recurring maintenance windows with blackout dates,
+// on which no window opens.
+type Schedule struct {
+	windows       []*Window
+	blackoutDates map[string]struct{}
+}
+
+// Creates a Schedule from its API representation.
+func NewSchedule(spec addonsv1alpha1.AddonMaintenance) (*Schedule, error) {
+	s := &Schedule{blackoutDates: map[string]struct{}{}}
+	for i, windowSpec := range spec.Windows {
+		w, err := NewWindow(windowSpec)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i, err)
+		}
+		s.windows = append(s.windows, w)
+	}
+	for _, date := range spec.BlackoutDates {
+		if _, err := time.Parse(blackoutDateLayout, date); err != nil {
+			return nil, fmt.Errorf("parsing blackout date %q: %w", date, err)
+		}
+		s.blackoutDates[date] = struct{}{}
+	}
+	return s, nil
+}
+
+// Open reports whether any window is open at the given time,
+// that did not open on a blackout date.
+func (s *Schedule) Open(t time.Time) bool {
+	for _, w := range s.windows {
+		// Openings of the same window may overlap,
+		// when the schedule fires more often than the duration.
+		for opened := w.schedule.Next(t.In(w.location).Add(-w.duration)); !opened.IsZero() && !opened.After(t); opened = w.schedule.Next(opened) {
+			if !s.blackout(opened) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextOpen returns the next time a window opens after the given time,
+// skipping openings on blackout dates.
+// Returns false, if no window opens anymore.
+func (s *Schedule) NextOpen(t time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, w := range s.windows {
+		opens := w.NextOpen(t)
+		for i := 0; i < maxNextOpenAttempts && !opens.IsZero() && s.blackout(opens); i++ {
+			opens = w.NextOpen(opens)
+		}
+		if opens.IsZero() || s.blackout(opens) {
+			continue
+		}
+		if next.IsZero() || opens.Before(next) {
+			next = opens
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Expects the time in the location of the window.
+func (s *Schedule) blackout(t time.Time) bool {
+	_, ok := s.blackoutDates[t.Format(blackoutDateLayout)]
+	return ok
+}
@@ -0,0 +1,95 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func TestSchedule(t *testing.T) {
+	// Weekdays from 22:00 to 02:00 in New York, except on Christmas Eve.
+	s, err := NewSchedule(addonsv1alpha1.AddonMaintenance{
+		Windows: []addonsv1alpha1.AddonMaintenanceWindow{{
+			Schedule: "0 22 * * 1-5",
+			Duration: metav1.Duration{Duration: 4 * time.Hour},
+			TimeZone: "America/New_York",
+		}},
+		BlackoutDates: []string{"2024-12-24"},
+	})
+	require.NoError(t, err)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		t        time.Time
+		open     bool
+		nextOpen time.Time
+	}{
+		"business hours": {
+			t:        time.Date(2024, time.December, 23, 12, 0, 0, 0, newYork),
+			nextOpen: time.Date(2024, time.December, 23, 22, 0, 0, 0, newYork),
+		},
+		"open past midnight": {
+			t:        time.Date(2024, time.December, 24, 1, 0, 0, 0, newYork),
+			open:     true,
+			nextOpen: time.Date(2024, time.December, 25, 22, 0, 0, 0, newYork),
+		},
+		"blackout date": {
+			t:        time.Date(2024, time.December, 24, 23, 0, 0, 0, newYork),
+			nextOpen: time.Date(2024, time.December, 25, 22, 0, 0, 0, newYork),
+		},
+		"blackout date in UTC": {
+			// Already December 25th in UTC.
+			t:        time.Date(2024, time.December, 25, 4, 0, 0, 0, time.UTC),
+			nextOpen: time.Date(2024, time.December, 25, 22, 0, 0, 0, newYork),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.open, s.Open(tc.t))
+			nextOpen, ok := s.NextOpen(tc.t)
+			require.True(t, ok)
+			assert.True(t, tc.nextOpen.Equal(nextOpen), "next open %s", nextOpen)
+		})
+	}
+}
+
+func TestSchedule_NeverOpens(t *testing.T) {
+	s, err := NewSchedule(addonsv1alpha1.AddonMaintenance{
+		Windows: []addonsv1alpha1.AddonMaintenanceWindow{{
+			Schedule: "0 10 30 2 *",
+			Duration: metav1.Duration{Duration: time.Hour},
+		}},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2024, time.December, 24, 10, 30, 0, 0, time.UTC)
+	assert.False(t, s.Open(now))
+	_, ok := s.NextOpen(now)
+	assert.False(t, ok)
+}
+
+func TestNewSchedule_Invalid(t *testing.T) {
+	for name, spec := range map[string]addonsv1alpha1.AddonMaintenance{
+		"window": {
+			Windows: []addonsv1alpha1.AddonMaintenanceWindow{{Schedule: "0 2 * * 6"}},
+		},
+		"blackout date": {
+			Windows: []addonsv1alpha1.AddonMaintenanceWindow{{
+				Schedule: "0 2 * * 6",
+				Duration: metav1.Duration{Duration: time.Hour},
+			}},
+			BlackoutDates: []string{"2024-02-30"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSchedule(spec)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	// Time zones have to be resolvable in minimal container images.
+	_ "time/tzdata"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// Window is a recurring time window, opening on a cron schedule
+// and staying open for a fixed duration.
+type Window struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// Creates a Window from its API representation.
+func NewWindow(spec addonsv1alpha1.AddonMaintenanceWindow) (*Window, error) {
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schedule %q: %w", spec.Schedule, err)
+	}
+	if spec.Duration.Duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+
+	location := time.UTC
+	if len(spec.TimeZone) > 0 {
+		location, err = time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("loading time zone: %w", err)
+		}
+	}
+
+	return &Window{
+		schedule: schedule,
+		duration: spec.Duration.Duration,
+		location: location,
+	}, nil
+}
+
+// Open reports whether the window is open at the given time.
+func (w *Window) Open(t time.Time) bool {
+	// Next returns the first activation after the given time, so
+	// the window is open if it was activated within the last duration.
+	opened := w.schedule.Next(t.In(w.location).Add(-w.duration))
+	return !opened.After(t)
+}
+
+// NextOpen returns the next time the window opens after the given time.
+func (w *Window) NextOpen(t time.Time) time.Time {
+	return w.schedule.Next(t.In(w.location))
+}
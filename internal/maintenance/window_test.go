@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+func TestWindow(t *testing.T) {
+	// Saturdays from 02:00 to 06:00 in Berlin.
+	w, err := NewWindow(addonsv1alpha1.AddonMaintenanceWindow{
+		Schedule: "0 2 * * 6",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Berlin",
+	})
+	require.NoError(t, err)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		t        time.Time
+		open     bool
+		nextOpen time.Time
+	}{
+		"before": {
+			t:        time.Date(2024, time.March, 9, 1, 59, 0, 0, berlin),
+			nextOpen: time.Date(2024, time.March, 9, 2, 0, 0, 0, berlin),
+		},
+		"opening": {
+			t:        time.Date(2024, time.March, 9, 2, 0, 0, 0, berlin),
+			open:     true,
+			nextOpen: time.Date(2024, time.March, 16, 2, 0, 0, 0, berlin),
+		},
+		"open in UTC": {
+			t:        time.Date(2024, time.March, 9, 4, 0, 0, 0, time.UTC),
+			open:     true,
+			nextOpen: time.Date(2024, time.March, 16, 2, 0, 0, 0, berlin),
+		},
+		"closed": {
+			t:        time.Date(2024, time.March, 9, 6, 0, 0, 0, berlin),
+			nextOpen: time.Date(2024, time.March, 16, 2, 0, 0, 0, berlin),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.open, w.Open(tc.t))
+			assert.True(t, tc.nextOpen.Equal(w.NextOpen(tc.t)), "next open %s", w.NextOpen(tc.t))
+		})
+	}
+}
+
+func TestNewWindow_Invalid(t *testing.T) {
+	for name, spec := range map[string]addonsv1alpha1.AddonMaintenanceWindow{
+		"schedule": {Schedule: "every saturday", Duration: metav1.Duration{Duration: time.Hour}},
+		"duration": {Schedule: "0 2 * * 6"},
+		"timezone": {Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewWindow(spec)
+			require.Error(t, err)
+		})
+	}
+}
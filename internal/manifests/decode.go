@@ -0,0 +1,100 @@
+package manifests
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// Decode decodes a stream of YAML or JSON documents separated by "---" into objects.
+// Documents that only contain whitespace or comments are skipped.
+func Decode(data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for i, doc := range documentSeparator.Split(string(data), -1) {
+		jsonDoc, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		// Decodes whole numbers into int64, as expected within unstructured objects.
+		content := map[string]interface{}{}
+		if err := utiljson.Unmarshal(jsonDoc, &content); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if len(obj.GetAPIVersion()) == 0 || len(obj.GetKind()) == 0 {
+			return nil, fmt.Errorf("document %d: object is missing apiVersion or kind", i)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Kinds that other objects commonly depend on are installed first,
+// mirroring the install order of the Helm CLI.
+var installOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"SecretList",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleList",
+	"ClusterRoleBinding",
+	"ClusterRoleBindingList",
+	"Role",
+	"RoleList",
+	"RoleBinding",
+	"RoleBindingList",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
+// SortByInstallOrder stable sorts objects by kind,
+// so dependencies like Namespaces and CRDs are applied first.
+func SortByInstallOrder(objs []*unstructured.Unstructured) {
+	rank := make(map[string]int, len(installOrder))
+	for i, kind := range installOrder {
+		rank[kind] = i
+	}
+	rankOf := func(obj *unstructured.Unstructured) int {
+		if r, ok := rank[obj.GetKind()]; ok {
+			return r
+		}
+		// Unknown kinds, e.g. custom resources, go last.
+		return len(installOrder)
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		return rankOf(objs[i]) < rankOf(objs[j])
+	})
+}
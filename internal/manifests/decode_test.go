@@ -0,0 +1,68 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecode(t *testing.T) {
+	objs, err := Decode([]byte(`# leading comment
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  replicas: "1"
+---
+---
+{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "b"}, "spec": {"replicas": 2}}
+`))
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+
+	assert.Equal(t, "ConfigMap", objs[0].GetKind())
+	assert.Equal(t, "a", objs[0].GetName())
+
+	assert.Equal(t, "Deployment", objs[1].GetKind())
+	replicas, found, err := unstructured.NestedInt64(objs[1].Object, "spec", "replicas")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(2), replicas)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing kind":       "apiVersion: v1\nmetadata:\n  name: a\n",
+		"missing apiVersion": "kind: ConfigMap\nmetadata:\n  name: a\n",
+		"invalid yaml":       "kind: [",
+		"not an object":      "- a\n- b\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSortByInstallOrder(t *testing.T) {
+	var objs []*unstructured.Unstructured
+	for _, kind := range []string{"Deployment", "MyCustomResource", "ConfigMap", "Namespace", "CustomResourceDefinition"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		objs = append(objs, obj)
+	}
+
+	SortByInstallOrder(objs)
+
+	kinds := make([]string, len(objs))
+	for i := range objs {
+		kinds[i] = objs[i].GetKind()
+	}
+	assert.Equal(t, []string{
+		"Namespace", "ConfigMap", "CustomResourceDefinition", "Deployment", "MyCustomResource",
+	}, kinds)
+}
@@ -0,0 +1,154 @@
+package manifests
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Media types of image layers that manifests are read from.
+const (
+	MediaTypeImageLayer      = "application/vnd.oci.image.layer.v1.tar"
+	MediaTypeImageLayerGzip  = "application/vnd.oci.image.layer.v1.tar+gzip"
+	MediaTypeDockerLayerGzip = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+const (
+	// Files removed by upper layers are marked with whiteout files.
+	whiteoutPrefix          = ".wh."
+	whiteoutOpaqueDirectory = ".wh..wh..opq"
+
+	// Upper bound of the total size of all manifest files in an image.
+	maxImageManifestsSize = 16 << 20
+)
+
+// ImageLayerMediaTypes lists all layer media types LoadImageLayers understands.
+var ImageLayerMediaTypes = []string{
+	MediaTypeImageLayer,
+	MediaTypeImageLayerGzip,
+	MediaTypeDockerLayerGzip,
+}
+
+// LoadImageLayers flattens the given image layers, lowest layer first,
+// and decodes all .yaml, .yml and .json files of the resulting filesystem
+// in lexical order of their paths.
+func LoadImageLayers(layers [][]byte) ([]*unstructured.Unstructured, error) {
+	files := map[string][]byte{}
+	var total int64
+	for i, layer := range layers {
+		if err := readLayer(layer, files, &total); err != nil {
+			return nil, fmt.Errorf("reading layer %d: %w", i, err)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var objs []*unstructured.Unstructured
+	for _, p := range paths {
+		decoded, err := Decode(files[p])
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", p, err)
+		}
+		objs = append(objs, decoded...)
+	}
+	return objs, nil
+}
+
+// readLayer applies a single layer on top of files.
+// Whiteouts only hide files of lower layers, independent of their position in the layer.
+func readLayer(layer []byte, files map[string][]byte, total *int64) error {
+	r, err := decompress(layer)
+	if err != nil {
+		return err
+	}
+
+	var (
+		added     = map[string][]byte{}
+		whiteouts []string
+		tr        = tar.NewReader(r)
+	)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean("/" + hdr.Name)
+		dir, base := path.Split(name)
+		switch {
+		case base == whiteoutOpaqueDirectory:
+			whiteouts = append(whiteouts, dir)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			// Removes a file or a whole directory of that name.
+			deleted := path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))
+			whiteouts = append(whiteouts, deleted, deleted+"/")
+			continue
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !isManifestFile(base) {
+			continue
+		}
+		*total += hdr.Size
+		if *total > maxImageManifestsSize {
+			return fmt.Errorf("manifests exceed the limit of %d bytes", maxImageManifestsSize)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		added[name] = data
+	}
+
+	for p := range files {
+		for _, whiteout := range whiteouts {
+			// Directories end with "/" and hide everything below them.
+			if p == whiteout || strings.HasSuffix(whiteout, "/") && strings.HasPrefix(p, whiteout) {
+				delete(files, p)
+			}
+		}
+	}
+	for p, data := range added {
+		files[p] = data
+	}
+	return nil
+}
+
+// decompress transparently handles gzip compressed and uncompressed layers.
+func decompress(layer []byte) (io.Reader, error) {
+	br := bufio.NewReader(bytes.NewReader(layer))
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("opening gzip stream: %w", err)
+		}
+		return gz, nil
+	}
+	return br, nil
+}
+
+func isManifestFile(name string) bool {
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,81 @@
+package manifests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFile struct {
+	name    string
+	content string
+}
+
+func newTestLayer(t *testing.T, compress bool, files ...testFile) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     f.name,
+			Mode:     0o644,
+			Size:     int64(len(f.content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buf.Bytes()
+}
+
+func configMapManifest(name string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"
+}
+
+func TestLoadImageLayers(t *testing.T) {
+	lower := newTestLayer(t, true,
+		testFile{name: "manifests/b.yaml", content: configMapManifest("b")},
+		testFile{name: "manifests/a.yml", content: configMapManifest("a-old")},
+		testFile{name: "manifests/removed.yaml", content: configMapManifest("removed")},
+		testFile{name: "manifests/old/c.json", content: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c"}}`},
+		testFile{name: "README.md", content: "not a manifest"},
+	)
+	upper := newTestLayer(t, false,
+		testFile{name: "manifests/a.yml", content: configMapManifest("a")},
+		testFile{name: "manifests/.wh.removed.yaml"},
+		testFile{name: "manifests/old/.wh..wh..opq"},
+	)
+
+	objs, err := LoadImageLayers([][]byte{lower, upper})
+	require.NoError(t, err)
+
+	names := make([]string, len(objs))
+	for i := range objs {
+		names[i] = objs[i].GetName()
+	}
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestLoadImageLayers_InvalidManifest(t *testing.T) {
+	layer := newTestLayer(t, true, testFile{name: "broken.yaml", content: "kind: ConfigMap\n"})
+
+	_, err := LoadImageLayers([][]byte{layer})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/broken.yaml")
+}
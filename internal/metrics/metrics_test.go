@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -250,3 +251,62 @@ func TestInjectAddonServiceAPIRequestDuration(t *testing.T) {
 	// Verify that the injected summary is correctly assigned
 	assert.Equal(t, summary, r.addonServiceAPIRequestDuration, "Expected injected addon service API request duration summary to be %v", summary)
 }
+
+func TestRecordAddonUpgradeDuration(t *testing.T) {
+	recorder := NewRecorder(false, "test")
+	recorder.RecordAddonUpgradeDuration("addon-1", "Succeeded", 90*time.Second)
+	recorder.RecordAddonUpgradeDuration("addon-1", "Succeeded", 10*time.Minute)
+	assert.NoError(t, testutil.CollectAndCompare(recorder.addonUpgradeDuration, strings.NewReader(`
+# HELP addon_operator_addon_upgrade_duration_seconds Duration of finished Addon installs and upgrades in seconds
+# TYPE addon_operator_addon_upgrade_duration_seconds histogram
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="30"} 0
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="60"} 0
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="120"} 1
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="240"} 1
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="480"} 1
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="960"} 2
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="1920"} 2
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="3840"} 2
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="7680"} 2
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="15360"} 2
+addon_operator_addon_upgrade_duration_seconds_bucket{_id="test",name="addon-1",outcome="Succeeded",le="+Inf"} 2
+addon_operator_addon_upgrade_duration_seconds_sum{_id="test",name="addon-1",outcome="Succeeded"} 690
+addon_operator_addon_upgrade_duration_seconds_count{_id="test",name="addon-1",outcome="Succeeded"} 2
+`), "addon_operator_addon_upgrade_duration_seconds"))
+
+	recorder.RecordAddonUpgradeDuration("addon-1", "RolledBack", time.Hour)
+	assert.Equal(t, 2, testutil.CollectAndCount(recorder.addonUpgradeDuration))
+}
+
+func TestAddonMetrics_Status(t *testing.T) {
+	recorder := NewRecorder(false, "test")
+	addon := newTestAddon("uid-1", []metav1.Condition{
+		{Type: addonsv1alpha1.Available, Status: metav1.ConditionFalse},
+		{Type: addonsv1alpha1.Installed, Status: metav1.ConditionTrue},
+	})
+	addon.Name = "addon-1"
+	addon.Status.Phase = addonsv1alpha1.PhasePending
+	recorder.RecordAddonMetrics(addon, mockAddonHealth{})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(recorder.addonPhase.WithLabelValues("addon-1", "Pending")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		recorder.addonCondition.WithLabelValues("addon-1", addonsv1alpha1.Available, "false")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(
+		recorder.addonCondition.WithLabelValues("addon-1", addonsv1alpha1.Available, "true")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		recorder.addonCondition.WithLabelValues("addon-1", addonsv1alpha1.Installed, "true")))
+
+	// Previous phases are dropped.
+	addon.Status.Phase = addonsv1alpha1.PhaseReady
+	recorder.RecordAddonMetrics(addon, mockAddonHealth{})
+	assert.Equal(t, 1, testutil.CollectAndCount(recorder.addonPhase))
+	assert.Equal(t, 6, testutil.CollectAndCount(recorder.addonCondition))
+
+	recorder.RecordAddonReconcileDuration("addon-1", time.Second)
+	recorder.IncAddonRequeues("addon-1", RequeueTypeDelayed)
+	recorder.DeleteAddonMetrics("addon-1")
+	assert.Equal(t, 0, testutil.CollectAndCount(recorder.addonPhase))
+	assert.Equal(t, 0, testutil.CollectAndCount(recorder.addonCondition))
+	assert.Equal(t, 0, testutil.CollectAndCount(recorder.addonReconcileDuration))
+	assert.Equal(t, 0, testutil.CollectAndCount(recorder.addonRequeues))
+}
@@ -3,7 +3,9 @@ package metrics
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -39,7 +41,20 @@ type Recorder struct {
 	addonOperatorPaused            prometheus.Gauge // 0 - Not paused , 1 - Paused
 	ocmAPIRequestDuration          prometheus.Summary
 	addonServiceAPIRequestDuration prometheus.Summary
+	ocmAPIRetries                  prometheus.Counter
+	ocmAPIShortCircuits            prometheus.Counter
+	ocmCircuitBreakerState         *prometheus.GaugeVec
+	ocmOutboxBacklog               prometheus.Gauge
+	ocmOutboxCoalesced             prometheus.Counter
+	ocmOutboxDropped               *prometheus.CounterVec
 	addonHealthInfo                *prometheus.GaugeVec
+	addonUpgradeDuration           *prometheus.HistogramVec
+	addonPhase                     *prometheus.GaugeVec
+	addonCondition                 *prometheus.GaugeVec
+	addonReconcileDuration         *prometheus.HistogramVec
+	addonSubReconcilerDuration     *prometheus.HistogramVec
+	addonTimeToAvailable           *prometheus.HistogramVec
+	addonRequeues                  *prometheus.CounterVec
 	reconcileError                 *prometheus.CounterVec
 	// .. TODO: More metrics!
 }
@@ -60,6 +75,16 @@ var (
 	total     addonCountLabel = "total"
 )
 
+// 30s up to ~4h
+var upgradeDurationBuckets = prometheus.ExponentialBuckets(30, 2, 10)
+
+// Requeue types of the addon_operator_addon_requeues_total metric.
+const (
+	RequeueTypeError     = "error"
+	RequeueTypeImmediate = "immediate"
+	RequeueTypeDelayed   = "delayed"
+)
+
 func NewRecorder(register bool, clusterId string) *Recorder {
 
 	addonsCount := prometheus.NewGaugeVec(
@@ -95,6 +120,48 @@ func NewRecorder(register bool, clusterId string) *Recorder {
 		},
 	)
 
+	ocmAPIRetries := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name:        "addon_operator_ocm_api_retries_total",
+			Help:        "Number of retried OCM API requests",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		})
+
+	ocmAPIShortCircuits := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name:        "addon_operator_ocm_api_short_circuits_total",
+			Help:        "Number of OCM API requests rejected by the open circuit breaker",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		})
+
+	ocmCircuitBreakerState := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        "addon_operator_ocm_circuit_breaker_state",
+			Help:        "State of the OCM API circuit breaker, 1 for the current state",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{"state"})
+
+	ocmOutboxBacklog := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "addon_operator_ocm_outbox_backlog",
+			Help:        "Number of reports waiting for delivery to OCM",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		})
+
+	ocmOutboxCoalesced := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name:        "addon_operator_ocm_outbox_coalesced_total",
+			Help:        "Number of reports to OCM replaced by a newer report for the same Addon before delivery",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		})
+
+	ocmOutboxDropped := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "addon_operator_ocm_outbox_dropped_total",
+			Help:        "Number of reports to OCM dropped without delivery, by 'full' and 'undeliverable' reason",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{"reason"})
+
 	addonHealthInfo := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name:        "addon_operator_addon_health_info",
@@ -107,6 +174,87 @@ func NewRecorder(register bool, clusterId string) *Recorder {
 		},
 	)
 
+	addonUpgradeDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:        "addon_operator_addon_upgrade_duration_seconds",
+			Help:        "Duration of finished Addon installs and upgrades in seconds",
+			Buckets:     upgradeDurationBuckets,
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+			"outcome",
+		},
+	)
+
+	addonPhase := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        "addon_operator_addon_phase",
+			Help:        "Phase of the Addon, 1 for the current phase",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+			"phase",
+		},
+	)
+
+	addonCondition := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        "addon_operator_addon_condition",
+			Help:        "Status conditions of the Addon, 1 for the current status of each condition",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+			"condition",
+			"status",
+		},
+	)
+
+	addonReconcileDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:        "addon_operator_addon_reconcile_duration_seconds",
+			Help:        "Duration of Addon reconciles in seconds",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+		},
+	)
+
+	addonSubReconcilerDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:        "addon_operator_addon_subreconciler_duration_seconds",
+			Help:        "Duration of Addon sub-reconciler runs in seconds",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+			"reconciler",
+		},
+	)
+
+	addonTimeToAvailable := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:        "addon_operator_addon_time_to_available_seconds",
+			Help:        "Time from the start of an Addon install or upgrade until the Addon is Available in seconds",
+			Buckets:     upgradeDurationBuckets,
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+			"trigger",
+		},
+	)
+
+	addonRequeues := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "addon_operator_addon_requeues_total",
+			Help:        "Number of requeued Addon reconciles, by 'error', 'immediate' and 'delayed' requeues",
+			ConstLabels: prometheus.Labels{"_id": clusterId},
+		}, []string{
+			"name",
+			"type",
+		},
+	)
+
 	reconcileError := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name:        "addon_operator_reconcile_error",
@@ -128,7 +276,20 @@ func NewRecorder(register bool, clusterId string) *Recorder {
 			addonOperatorPaused,
 			ocmAPIReqDuration,
 			addonServiceAPIReqDuration,
+			ocmAPIRetries,
+			ocmAPIShortCircuits,
+			ocmCircuitBreakerState,
+			ocmOutboxBacklog,
+			ocmOutboxCoalesced,
+			ocmOutboxDropped,
 			addonHealthInfo,
+			addonUpgradeDuration,
+			addonPhase,
+			addonCondition,
+			addonReconcileDuration,
+			addonSubReconcilerDuration,
+			addonTimeToAvailable,
+			addonRequeues,
 			reconcileError,
 		)
 	}
@@ -141,7 +302,20 @@ func NewRecorder(register bool, clusterId string) *Recorder {
 		addonOperatorPaused:            addonOperatorPaused,
 		ocmAPIRequestDuration:          ocmAPIReqDuration,
 		addonServiceAPIRequestDuration: addonServiceAPIReqDuration,
+		ocmAPIRetries:                  ocmAPIRetries,
+		ocmAPIShortCircuits:            ocmAPIShortCircuits,
+		ocmCircuitBreakerState:         ocmCircuitBreakerState,
+		ocmOutboxBacklog:               ocmOutboxBacklog,
+		ocmOutboxCoalesced:             ocmOutboxCoalesced,
+		ocmOutboxDropped:               ocmOutboxDropped,
 		addonHealthInfo:                addonHealthInfo,
+		addonUpgradeDuration:           addonUpgradeDuration,
+		addonPhase:                     addonPhase,
+		addonCondition:                 addonCondition,
+		addonReconcileDuration:         addonReconcileDuration,
+		addonSubReconcilerDuration:     addonSubReconcilerDuration,
+		addonTimeToAvailable:           addonTimeToAvailable,
+		addonRequeues:                  addonRequeues,
 		reconcileError:                 reconcileError,
 	}
 }
@@ -184,6 +358,57 @@ func (r *Recorder) RecordOCMAPIRequests(us float64) {
 	r.ocmAPIRequestDuration.Observe(us)
 }
 
+func (r *Recorder) RecordOCMAPIRetry() {
+	r.ocmAPIRetries.Inc()
+}
+
+func (r *Recorder) RecordOCMAPIShortCircuit() {
+	r.ocmAPIShortCircuits.Inc()
+}
+
+// SetOCMCircuitBreakerState sets the `addon_operator_ocm_circuit_breaker_state` metric
+// 1 - current state, no series for other states
+func (r *Recorder) SetOCMCircuitBreakerState(state string) {
+	r.ocmCircuitBreakerState.Reset()
+	r.ocmCircuitBreakerState.WithLabelValues(state).Set(1)
+}
+
+func (r *Recorder) SetOCMOutboxBacklog(size int) {
+	r.ocmOutboxBacklog.Set(float64(size))
+}
+
+func (r *Recorder) IncOCMOutboxCoalesced() {
+	r.ocmOutboxCoalesced.Inc()
+}
+
+func (r *Recorder) AddOCMOutboxDropped(reason string, count int) {
+	r.ocmOutboxDropped.WithLabelValues(reason).Add(float64(count))
+}
+
+// RecordAddonUpgradeDuration observes the duration of a finished version transition.
+func (r *Recorder) RecordAddonUpgradeDuration(name, outcome string, d time.Duration) {
+	r.addonUpgradeDuration.WithLabelValues(name, outcome).Observe(d.Seconds())
+}
+
+// RecordAddonTimeToAvailable observes the time an Addon took to become Available
+// after an install or upgrade started.
+func (r *Recorder) RecordAddonTimeToAvailable(name, trigger string, d time.Duration) {
+	r.addonTimeToAvailable.WithLabelValues(name, trigger).Observe(d.Seconds())
+}
+
+func (r *Recorder) RecordAddonReconcileDuration(name string, d time.Duration) {
+	r.addonReconcileDuration.WithLabelValues(name).Observe(d.Seconds())
+}
+
+func (r *Recorder) RecordAddonSubReconcilerDuration(name, reconciler string, d time.Duration) {
+	r.addonSubReconcilerDuration.WithLabelValues(name, reconciler).Observe(d.Seconds())
+}
+
+// IncAddonRequeues counts a requeued reconcile of the Addon by one of the RequeueType* types.
+func (r *Recorder) IncAddonRequeues(name, requeueType string) {
+	r.addonRequeues.WithLabelValues(name, requeueType).Inc()
+}
+
 func (r *Recorder) RecordAddonServiceAPIRequests(us float64) {
 	r.addonServiceAPIRequestDuration.Observe(us)
 }
@@ -208,6 +433,8 @@ type AddonHealth interface {
 // - addon_operator_addons_paused
 // - addon_operator_addons_total
 // - addon_operator_addon_health_info
+// - addon_operator_addon_phase
+// - addon_operator_addon_condition
 func (r *Recorder) RecordAddonMetrics(
 	addon *addonsv1alpha1.Addon,
 	addonHealth AddonHealth,
@@ -218,6 +445,9 @@ func (r *Recorder) RecordAddonMetrics(
 	// record addon_operator_addon_health_info
 	r.recordAddonHealthInfo(addon, addonHealth)
 
+	// record addon_operator_addon_(phase|condition)
+	r.recordAddonStatus(addon)
+
 	// reconcile addon_operator_addons_(available|paused|total)
 	currCondition := addonConditions{
 		available: meta.IsStatusConditionTrue(addon.Status.Conditions, addonsv1alpha1.Available),
@@ -277,6 +507,41 @@ func (r *Recorder) RecordAddonMetrics(
 	}
 }
 
+func (r *Recorder) recordAddonStatus(addon *addonsv1alpha1.Addon) {
+	addonLabels := prometheus.Labels{"name": addon.Name}
+
+	r.addonPhase.DeletePartialMatch(addonLabels)
+	if len(addon.Status.Phase) > 0 {
+		r.addonPhase.WithLabelValues(addon.Name, string(addon.Status.Phase)).Set(1)
+	}
+
+	r.addonCondition.DeletePartialMatch(addonLabels)
+	for _, cond := range addon.Status.Conditions {
+		for _, status := range []metav1.ConditionStatus{
+			metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown,
+		} {
+			var value float64
+			if cond.Status == status {
+				value = 1
+			}
+			r.addonCondition.WithLabelValues(addon.Name, cond.Type, strings.ToLower(string(status))).Set(value)
+		}
+	}
+}
+
+// DeleteAddonMetrics drops all series of an Addon, once it is gone.
+func (r *Recorder) DeleteAddonMetrics(name string) {
+	addonLabels := prometheus.Labels{"name": name}
+	r.addonHealthInfo.DeletePartialMatch(addonLabels)
+	r.addonPhase.DeletePartialMatch(addonLabels)
+	r.addonCondition.DeletePartialMatch(addonLabels)
+	r.addonUpgradeDuration.DeletePartialMatch(addonLabels)
+	r.addonReconcileDuration.DeletePartialMatch(addonLabels)
+	r.addonSubReconcilerDuration.DeletePartialMatch(addonLabels)
+	r.addonTimeToAvailable.DeletePartialMatch(addonLabels)
+	r.addonRequeues.DeletePartialMatch(addonLabels)
+}
+
 func (r *Recorder) recordAddonHealthInfo(
 	addon *addonsv1alpha1.Addon,
 	addonHealth AddonHealth,
@@ -0,0 +1,67 @@
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+type dockerConfig struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// credential looks up username and password for the given registry host
+// in the configured dockerconfigjson.
+func (c *Client) credential(_ context.Context, hostport string) (auth.Credential, error) {
+	if len(c.opts.DockerConfigJSON) == 0 {
+		return auth.EmptyCredential, nil
+	}
+
+	cfg := dockerConfig{}
+	if err := json.Unmarshal(c.opts.DockerConfigJSON, &cfg); err != nil {
+		return auth.EmptyCredential, fmt.Errorf("unmarshal dockerconfigjson: %w", err)
+	}
+
+	registry := normalizeRegistryKey(hostport)
+	for key, entry := range cfg.Auths {
+		if normalizeRegistryKey(key) != registry {
+			continue
+		}
+		if len(entry.Auth) == 0 {
+			return auth.Credential{Username: entry.Username, Password: entry.Password}, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return auth.EmptyCredential, fmt.Errorf("decoding auth of registry %s: %w", key, err)
+		}
+		username, password, found := strings.Cut(string(decoded), ":")
+		if !found {
+			return auth.EmptyCredential, fmt.Errorf("malformed auth of registry %s", key)
+		}
+		return auth.Credential{Username: username, Password: password}, nil
+	}
+	return auth.EmptyCredential, nil
+}
+
+// normalizeRegistryKey strips scheme and path from dockerconfigjson keys,
+// which may be stored as e.g. "https://index.docker.io/v1/".
+func normalizeRegistryKey(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	key, _, _ = strings.Cut(key, "/")
+	if key == "index.docker.io" || key == dockerHubRegistryHost {
+		return dockerHubRegistry
+	}
+	return key
+}
@@ -0,0 +1,214 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+	"oras.land/oras-go/v2/registry/remote/retry"
+
+	"github.com/openshift/addon-operator/internal/version"
+)
+
+const (
+	// Upper bound of a pulled manifest.
+	maxManifestSize = 4 << 20
+	// Upper bound of a single pulled blob, to protect the operator from oversized artifacts.
+	maxBlobSize = 32 << 20
+)
+
+// Client pulls layers from OCI artifacts.
+type Client struct {
+	opts       ClientOptions
+	httpClient *http.Client
+}
+
+// Creates a new OCI client with the given options.
+func NewClient(opts ...Option) *Client {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+
+	c.httpClient = retry.DefaultClient
+	return c
+}
+
+type ClientOptions struct {
+	// Content of a kubernetes.io/dockerconfigjson secret
+	// to look up registry credentials in.
+	DockerConfigJSON []byte
+	// Talk plain HTTP to registries instead of HTTPS.
+	PlainHTTP bool
+}
+
+type Option func(o *ClientOptions)
+
+func WithDockerConfigJSON(dockerConfigJSON []byte) Option {
+	return func(o *ClientOptions) {
+		o.DockerConfigJSON = dockerConfigJSON
+	}
+}
+
+func WithPlainHTTP(plainHTTP bool) Option {
+	return func(o *ClientOptions) {
+		o.PlainHTTP = plainHTTP
+	}
+}
+
+type RegistryError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e RegistryError) Error() string {
+	if len(e.URL) == 0 {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("HTTP %d: %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// Resolve returns the digest of the manifest the given reference points to.
+// Tags can be moved to other artifacts, digests can't.
+func (c *Client) Resolve(ctx context.Context, ref Reference) (string, error) {
+	desc, err := c.repository(ref).Resolve(ctx, ref.Tag)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, registryError(err))
+	}
+	return desc.Digest.String(), nil
+}
+
+// PullLayer returns the content of the first layer of the given artifact,
+// that matches one of the given media types.
+func (c *Client) PullLayer(ctx context.Context, ref Reference, mediaTypes ...string) ([]byte, error) {
+	repo := c.repository(ref)
+
+	manifest, err := fetchManifest(ctx, repo, ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest of %s: %w", ref, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if !slices.Contains(mediaTypes, layer.MediaType) {
+			continue
+		}
+		content, err := fetchBlob(ctx, repo, layer)
+		if err != nil {
+			return nil, fmt.Errorf("fetching layer %s of %s: %w", layer.Digest, ref, err)
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf("%s has no layer of media type %s", ref, strings.Join(mediaTypes, ", "))
+}
+
+// PullLayers returns the content of all layers of the given artifact
+// that match one of the given media types, in the order of the manifest.
+func (c *Client) PullLayers(ctx context.Context, ref Reference, mediaTypes ...string) ([][]byte, error) {
+	repo := c.repository(ref)
+
+	manifest, err := fetchManifest(ctx, repo, ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest of %s: %w", ref, err)
+	}
+
+	var (
+		layers [][]byte
+		total  int64
+	)
+	for _, layer := range manifest.Layers {
+		if !slices.Contains(mediaTypes, layer.MediaType) {
+			continue
+		}
+		total += layer.Size
+		if total > maxBlobSize {
+			return nil, fmt.Errorf("layers of %s exceed the limit of %d bytes", ref, maxBlobSize)
+		}
+
+		content, err := fetchBlob(ctx, repo, layer)
+		if err != nil {
+			return nil, fmt.Errorf("fetching layer %s of %s: %w", layer.Digest, ref, err)
+		}
+		layers = append(layers, content)
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("%s has no layer of media type %s", ref, strings.Join(mediaTypes, ", "))
+	}
+	return layers, nil
+}
+
+func (c *Client) repository(ref Reference) *remote.Repository {
+	return &remote.Repository{
+		Client: &auth.Client{
+			Client: c.httpClient,
+			Header: http.Header{
+				"User-Agent": {fmt.Sprintf("AddonOperator/%s", version.Version)},
+			},
+			Cache:      auth.NewCache(),
+			Credential: c.credential,
+		},
+		Reference: registry.Reference{
+			Registry:   ref.Registry,
+			Repository: ref.Repository,
+			Reference:  ref.Tag,
+		},
+		PlainHTTP:        c.opts.PlainHTTP,
+		MaxMetadataBytes: maxManifestSize,
+	}
+}
+
+func fetchManifest(ctx context.Context, repo *remote.Repository, ref Reference) (*ocispec.Manifest, error) {
+	_, body, err := oras.FetchBytes(ctx, repo, ref.Tag, oras.FetchBytesOptions{MaxBytes: maxManifestSize})
+	if err != nil {
+		return nil, registryError(err)
+	}
+
+	manifest := &ocispec.Manifest{}
+	if err := json.Unmarshal(body, manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// fetchBlob pulls the given blob and verifies its size and digest.
+func fetchBlob(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) ([]byte, error) {
+	if desc.Size > maxBlobSize {
+		return nil, fmt.Errorf("blob size %d exceeds the limit of %d bytes", desc.Size, maxBlobSize)
+	}
+
+	body, err := content.FetchAll(ctx, repo.Blobs(), desc)
+	if err != nil {
+		return nil, registryError(err)
+	}
+	return body, nil
+}
+
+// registryError converts error responses of the registry into RegistryError,
+// so callers don't depend on the underlying registry client.
+func registryError(err error) error {
+	var errResp *errcode.ErrorResponse
+	if !errors.As(err, &errResp) {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return RegistryError{StatusCode: http.StatusNotFound, Body: err.Error()}
+		}
+		return err
+	}
+
+	regErr := RegistryError{StatusCode: errResp.StatusCode, Body: errResp.Errors.Error()}
+	if errResp.URL != nil {
+		regErr.URL = errResp.URL.String()
+	}
+	return regErr
+}
@@ -0,0 +1,212 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLayerMediaType = "application/vnd.test.layer.v1"
+
+type testRegistry struct {
+	layer    []byte
+	digest   string
+	username string
+	password string
+	token    string
+}
+
+func newTestRegistry(layer []byte) *testRegistry {
+	sum := sha256.Sum256(layer)
+	return &testRegistry{
+		layer:  layer,
+		digest: "sha256:" + hex.EncodeToString(sum[:]),
+	}
+}
+
+func (r *testRegistry) handler(host func() string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, req *http.Request) {
+		username, password, _ := req.BasicAuth()
+		if username != r.username || password != r.password {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.URL.Query().Get("scope") != "repository:charts/test:pull" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(rw, `{"token":%q}`, r.token)
+	})
+	mux.HandleFunc("/v2/charts/test/", func(rw http.ResponseWriter, req *http.Request) {
+		if len(r.token) > 0 && req.Header.Get("Authorization") != "Bearer "+r.token {
+			rw.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, host()))
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		manifest, manifestDigest := r.manifest()
+		switch req.URL.Path {
+		case "/v2/charts/test/manifests/v1", "/v2/charts/test/manifests/" + manifestDigest:
+			rw.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			rw.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+			rw.Header().Set("Docker-Content-Digest", manifestDigest)
+			_, _ = rw.Write(manifest)
+		case "/v2/charts/test/blobs/" + r.digest:
+			_, _ = rw.Write(r.layer)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return mux
+}
+
+func (r *testRegistry) manifest() ([]byte, string) {
+	manifest, _ := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Layers: []ocispec.Descriptor{
+			{MediaType: "application/vnd.other", Digest: "sha256:0", Size: 1},
+			{MediaType: testLayerMediaType, Digest: digest.Digest(r.digest), Size: int64(len(r.layer))},
+		},
+	})
+	return manifest, digest.FromBytes(manifest).String()
+}
+
+func startTestRegistry(t *testing.T, r *testRegistry) Reference {
+	t.Helper()
+
+	var s *httptest.Server
+	s = httptest.NewServer(r.handler(func() string { return s.Listener.Addr().String() }))
+	t.Cleanup(s.Close)
+
+	ref, err := ParseReference(s.Listener.Addr().String() + "/charts/test:v1")
+	require.NoError(t, err)
+	return ref
+}
+
+func TestClientPullLayer(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	ref := startTestRegistry(t, registry)
+
+	c := NewClient(WithPlainHTTP(true))
+	content, err := c.PullLayer(context.Background(), ref, testLayerMediaType)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("layer content"), content)
+}
+
+func TestClientResolve(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	ref := startTestRegistry(t, registry)
+	_, manifestDigest := registry.manifest()
+
+	c := NewClient(WithPlainHTTP(true))
+	resolved, err := c.Resolve(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, manifestDigest, resolved)
+
+	// Pulling by digest gets the same artifact.
+	ref.Tag = resolved
+	content, err := c.PullLayer(context.Background(), ref, testLayerMediaType)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("layer content"), content)
+
+	ref.Tag = "v2"
+	_, err = c.Resolve(context.Background(), ref)
+	var registryErr RegistryError
+	require.ErrorAs(t, err, &registryErr)
+	assert.Equal(t, http.StatusNotFound, registryErr.StatusCode)
+}
+
+func TestClientPullLayer_BearerToken(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	registry.username = "user"
+	registry.password = "pass"
+	registry.token = "t0ken"
+	ref := startTestRegistry(t, registry)
+
+	dockerConfigJSON := fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`,
+		ref.Registry, base64.StdEncoding.EncodeToString([]byte("user:pass")))
+
+	c := NewClient(WithPlainHTTP(true), WithDockerConfigJSON([]byte(dockerConfigJSON)))
+	content, err := c.PullLayer(context.Background(), ref, testLayerMediaType)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("layer content"), content)
+}
+
+func TestClientPullLayer_WrongCredentials(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	registry.username = "user"
+	registry.password = "pass"
+	registry.token = "t0ken"
+	ref := startTestRegistry(t, registry)
+
+	dockerConfigJSON := fmt.Sprintf(`{"auths":{%q:{"username":"user","password":"wrong"}}}`, ref.Registry)
+
+	c := NewClient(WithPlainHTTP(true), WithDockerConfigJSON([]byte(dockerConfigJSON)))
+	_, err := c.PullLayer(context.Background(), ref, testLayerMediaType)
+	var registryErr RegistryError
+	require.ErrorAs(t, err, &registryErr)
+	assert.Equal(t, http.StatusUnauthorized, registryErr.StatusCode)
+}
+
+func TestClientPullLayers(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	ref := startTestRegistry(t, registry)
+
+	c := NewClient(WithPlainHTTP(true))
+	layers, err := c.PullLayers(context.Background(), ref, testLayerMediaType, "application/vnd.unused")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("layer content")}, layers)
+
+	_, err = c.PullLayers(context.Background(), ref, "application/vnd.missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no layer of media type")
+}
+
+func TestClientPullLayer_MissingMediaType(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	ref := startTestRegistry(t, registry)
+
+	c := NewClient(WithPlainHTTP(true))
+	_, err := c.PullLayer(context.Background(), ref, "application/vnd.missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no layer of media type")
+}
+
+func TestClientPullLayer_NotFound(t *testing.T) {
+	registry := newTestRegistry([]byte("layer content"))
+	ref := startTestRegistry(t, registry)
+	ref.Tag = "v2"
+
+	c := NewClient(WithPlainHTTP(true))
+	_, err := c.PullLayer(context.Background(), ref, testLayerMediaType)
+	var registryErr RegistryError
+	require.ErrorAs(t, err, &registryErr)
+	assert.Equal(t, http.StatusNotFound, registryErr.StatusCode)
+}
+
+func TestNormalizeRegistryKey(t *testing.T) {
+	for key, expected := range map[string]string{
+		"https://index.docker.io/v1/": "docker.io",
+		"registry-1.docker.io":        "docker.io",
+		"quay.io":                     "quay.io",
+		"http://localhost:5000/":      "localhost:5000",
+	} {
+		assert.Equal(t, expected, normalizeRegistryKey(key), key)
+	}
+	assert.False(t, strings.Contains(normalizeRegistryKey("https://quay.io/v2"), "/"))
+}
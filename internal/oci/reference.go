@@ -0,0 +1,69 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHubRegistry     = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+var errEmptyReference = errors.New("empty OCI reference")
+
+// Reference points to a single artifact in an OCI registry.
+type Reference struct {
+	// Registry host, optionally including a port.
+	Registry string
+	// Repository path within the registry.
+	Repository string
+	// Tag or digest of the artifact.
+	Tag string
+}
+
+// ParseReference parses references of the form
+// [oci://]registry/repository[:tag|@digest].
+// The tag defaults to "latest" when neither tag nor digest is given.
+func ParseReference(ref string) (Reference, error) {
+	ref = strings.TrimPrefix(ref, "oci://")
+	if len(ref) == 0 {
+		return Reference{}, errEmptyReference
+	}
+
+	var r Reference
+	name := ref
+	if i := strings.Index(ref, "@"); i != -1 {
+		name, r.Tag = ref[:i], ref[i+1:]
+	} else if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		name, r.Tag = ref[:i], ref[i+1:]
+	}
+	if len(r.Tag) == 0 {
+		r.Tag = "latest"
+	}
+
+	registry, repository, found := strings.Cut(name, "/")
+	if !found || !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		registry, repository = dockerHubRegistry, name
+	}
+	if registry == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	if len(repository) == 0 {
+		return Reference{}, fmt.Errorf("missing repository in OCI reference %q", ref)
+	}
+	if repository != strings.ToLower(repository) {
+		return Reference{}, fmt.Errorf("repository in OCI reference %q must be lowercase", ref)
+	}
+
+	r.Registry, r.Repository = registry, repository
+	return r, nil
+}
+
+func (r Reference) String() string {
+	if strings.Contains(r.Tag, ":") {
+		return fmt.Sprintf("%s/%s@%s", r.Registry, r.Repository, r.Tag)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Tag)
+}
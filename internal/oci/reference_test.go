@@ -0,0 +1,99 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      string
+		expected Reference
+	}{
+		{
+			name: "full reference",
+			ref:  "oci://quay.io/osd-addons/charts/reference-addon:1.0.0",
+			expected: Reference{
+				Registry:   "quay.io",
+				Repository: "osd-addons/charts/reference-addon",
+				Tag:        "1.0.0",
+			},
+		},
+		{
+			name: "default tag",
+			ref:  "quay.io/osd-addons/reference-addon",
+			expected: Reference{
+				Registry:   "quay.io",
+				Repository: "osd-addons/reference-addon",
+				Tag:        "latest",
+			},
+		},
+		{
+			name: "registry with port",
+			ref:  "localhost:5000/charts/test:v1",
+			expected: Reference{
+				Registry:   "localhost:5000",
+				Repository: "charts/test",
+				Tag:        "v1",
+			},
+		},
+		{
+			name: "digest",
+			ref:  "quay.io/charts/test@sha256:abc",
+			expected: Reference{
+				Registry:   "quay.io",
+				Repository: "charts/test",
+				Tag:        "sha256:abc",
+			},
+		},
+		{
+			name: "docker hub official image",
+			ref:  "nginx:1.25",
+			expected: Reference{
+				Registry:   "docker.io",
+				Repository: "library/nginx",
+				Tag:        "1.25",
+			},
+		},
+		{
+			name: "docker hub user repository",
+			ref:  "bitnami/nginx",
+			expected: Reference{
+				Registry:   "docker.io",
+				Repository: "bitnami/nginx",
+				Tag:        "latest",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref, err := ParseReference(test.ref)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ref)
+		})
+	}
+}
+
+func TestParseReference_Invalid(t *testing.T) {
+	for _, ref := range []string{
+		"",
+		"oci://",
+		"quay.io/Charts/Test:v1",
+	} {
+		t.Run(ref, func(t *testing.T) {
+			_, err := ParseReference(ref)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReference_String(t *testing.T) {
+	assert.Equal(t, "quay.io/charts/test:v1",
+		Reference{Registry: "quay.io", Repository: "charts/test", Tag: "v1"}.String())
+	assert.Equal(t, "quay.io/charts/test@sha256:abc",
+		Reference{Registry: "quay.io", Repository: "charts/test", Tag: "sha256:abc"}.String())
+}
@@ -0,0 +1,79 @@
+package ocm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+)
+
+// Number of addon installations requested per page.
+const addonInstallationsPageSize = 100
+
+type AddonInstallationState string
+
+const (
+	AddonInstallationStateInstalling AddonInstallationState = "installing"
+	AddonInstallationStateReady      AddonInstallationState = "ready"
+	AddonInstallationStateFailed     AddonInstallationState = "failed"
+	AddonInstallationStateDeleting   AddonInstallationState = "deleting"
+)
+
+type AddonInstallationListRequest struct{}
+
+type AddonInstallationListResponse struct {
+	Kind  string              `json:"kind"`
+	Page  int                 `json:"page"`
+	Size  int                 `json:"size"`
+	Total int                 `json:"total"`
+	Items []AddonInstallation `json:"items"`
+}
+
+// AddonInstallation is an addon installed on the cluster in OCM.
+type AddonInstallation struct {
+	// ID of the addon, used as name of the Addon object.
+	ID    string                 `json:"id"`
+	State AddonInstallationState `json:"state"`
+	// Version of the addon to install.
+	AddonVersion AddonInstallationVersion `json:"addon_version"`
+	// Spec of the Addon object rendered by the Addon Service for this cluster.
+	AddonSpec addonsv1alpha1.AddonSpec `json:"addon_spec"`
+}
+
+type AddonInstallationVersion struct {
+	ID string `json:"id"`
+}
+
+// ListAddonInstallations returns all addons installed on the cluster,
+// fetching every page.
+func (c *Client) ListAddonInstallations(ctx context.Context) ([]AddonInstallation, error) {
+	var installations []AddonInstallation
+	for page := 1; ; page++ {
+		urlParams := url.Values{}
+		urlParams.Add("page", strconv.Itoa(page))
+		urlParams.Add("size", strconv.Itoa(addonInstallationsPageSize))
+
+		res := AddonInstallationListResponse{}
+		if err := c.do(ctx, http.MethodGet,
+			fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/addons", c.clusterID()),
+			urlParams,
+			AddonInstallationListRequest{},
+			&res,
+		); err != nil {
+			return nil, err
+		}
+
+		installations = append(installations, res.Items...)
+		if len(installations) >= res.Total {
+			return installations, nil
+		}
+		// A short result would look like uninstalled addons to the caller.
+		if len(res.Items) == 0 {
+			return nil, fmt.Errorf("page %d is empty after %d of %d addon installations",
+				page, len(installations), res.Total)
+		}
+	}
+}
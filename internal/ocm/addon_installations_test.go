@@ -0,0 +1,80 @@
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListAddonInstallations(t *testing.T) {
+	const total = addonInstallationsPageSize + 1
+
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/addons_mgmt/v1/clusters/1ou/addons", r.URL.Path)
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		require.NoError(t, err)
+		size, err := strconv.Atoi(r.URL.Query().Get("size"))
+		require.NoError(t, err)
+
+		res := AddonInstallationListResponse{Kind: "AddonInstallationList", Page: page, Total: total}
+		for i := (page - 1) * size; i < min(page*size, total); i++ {
+			res.Items = append(res.Items, AddonInstallation{ID: fmt.Sprintf("addon-%d", i)})
+		}
+		res.Size = len(res.Items)
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	c.opts.ClusterID = "1ou"
+	installations, err := c.ListAddonInstallations(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"1", "2"}, pages)
+	require.Len(t, installations, total)
+	assert.Equal(t, "addon-0", installations[0].ID)
+	assert.Equal(t, fmt.Sprintf("addon-%d", total-1), installations[total-1].ID)
+}
+
+func TestClient_ListAddonInstallations_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(w, `{"code":"not found","reason":"cluster not found"}`)
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	c.opts.ClusterID = "1ou"
+	_, err := c.ListAddonInstallations(context.Background())
+
+	var ocmErr OCMError
+	require.ErrorAs(t, err, &ocmErr)
+	assert.Equal(t, http.StatusNotFound, ocmErr.StatusCode)
+}
+
+func TestClient_ListAddonInstallations_ShortPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := AddonInstallationListResponse{Kind: "AddonInstallationList", Total: 3}
+		if r.URL.Query().Get("page") == "1" {
+			res.Items = []AddonInstallation{{ID: "addon-0"}}
+		}
+		res.Size = len(res.Items)
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	c.opts.ClusterID = "1ou"
+	_, err := c.ListAddonInstallations(context.Background())
+	assert.EqualError(t, err, "page 2 is empty after 1 of 3 addon installations")
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/url"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 )
 
@@ -17,6 +19,42 @@ type AddOnStatusPostRequest struct {
 	AddonVersion string `json:"version"`
 	// Reported addon status conditions
 	StatusConditions []addonsv1alpha1.AddOnStatusCondition `json:"status_conditions"`
+	AddOnStatusDetails
+}
+
+// Health details and workload inventory reported along with the status conditions.
+type AddOnStatusDetails struct {
+	// ClusterServiceVersion installed by the Subscription of OLM addons.
+	InstalledCSV *AddOnInstalledCSV `json:"installed_csv,omitempty"`
+	// Health reported by the addon through its AddonInstance.
+	AddonInstance *AddOnInstanceHealth `json:"addon_instance,omitempty"`
+	// Number of pods in the install namespace that cannot be scheduled.
+	UnschedulablePods int `json:"unschedulable_pods,omitempty"`
+	// Objects owned by the addon.
+	Resources []AddOnResourceStatus `json:"resources,omitempty"`
+}
+
+type AddOnInstalledCSV struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Phase   string `json:"phase,omitempty"`
+}
+
+type AddOnInstanceHealth struct {
+	// Time of the last heartbeat, unset until the first heartbeat.
+	LastHeartbeatTime *metav1.Time `json:"last_heartbeat_time,omitempty"`
+	// Whether the last heartbeat was received within the heartbeat timeout.
+	HeartbeatFresh bool `json:"heartbeat_fresh"`
+	// Degraded condition of the AddonInstance, if reported by the addon.
+	Degraded *addonsv1alpha1.AddOnStatusCondition `json:"degraded,omitempty"`
+}
+
+type AddOnResourceStatus struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Ready      bool   `json:"ready"`
 }
 
 type AddOnStatusPatchRequest struct {
@@ -28,6 +66,20 @@ type AddOnStatusPatchRequest struct {
 	StatusConditions []addonsv1alpha1.AddOnStatusCondition `json:"status_conditions"`
 }
 
+// Implemented by request payloads carrying correlation IDs,
+// which are recorded on the trace of the request.
+type correlatedRequest interface {
+	correlationIDs() []string
+}
+
+func (r AddOnStatusPostRequest) correlationIDs() []string {
+	return []string{r.CorrelationID}
+}
+
+func (r AddOnStatusPatchRequest) correlationIDs() []string {
+	return []string{r.CorrelationID}
+}
+
 type AddOnStatusGetRequest struct{}
 
 type AddOnStatusResponse struct {
@@ -39,6 +91,7 @@ type AddOnStatusResponse struct {
 	AddonVersion string `json:"version"`
 	// Reported addon status conditions
 	StatusConditions []addonsv1alpha1.AddOnStatusCondition `json:"status_conditions"`
+	AddOnStatusDetails
 }
 
 func (c *Client) GetAddOnStatus(ctx context.Context, addonID string) (AddOnStatusResponse, error) {
@@ -46,7 +99,7 @@ func (c *Client) GetAddOnStatus(ctx context.Context, addonID string) (AddOnStatu
 	err := c.do(
 		ctx,
 		http.MethodGet,
-		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status/%s", c.opts.ClusterID, addonID),
+		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status/%s", c.clusterID(), addonID),
 		url.Values{},
 		AddOnStatusGetRequest{},
 		res,
@@ -62,7 +115,7 @@ func (c *Client) PostAddOnStatus(ctx context.Context, payload AddOnStatusPostReq
 	err := c.do(
 		ctx,
 		http.MethodPost,
-		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status", c.opts.ClusterID),
+		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status", c.clusterID()),
 		url.Values{},
 		payload,
 		res,
@@ -73,12 +126,50 @@ func (c *Client) PostAddOnStatus(ctx context.Context, payload AddOnStatusPostReq
 	return *res, nil
 }
 
+type AddOnStatusBulkPostRequest struct {
+	Items []AddOnStatusPostRequest `json:"items"`
+}
+
+func (r AddOnStatusBulkPostRequest) correlationIDs() []string {
+	ids := make([]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		ids = append(ids, item.CorrelationID)
+	}
+	return ids
+}
+
+type AddOnStatusBulkResponse struct {
+	Kind  string                `json:"kind"`
+	Items []AddOnStatusResponse `json:"items"`
+}
+
+// PostAddOnStatusBulk reports the status of multiple addons with a single request.
+// Not every OCM environment serves the bulk endpoint,
+// callers fall back to PostAddOnStatus when it answers with 404 Not Found.
+func (c *Client) PostAddOnStatusBulk(
+	ctx context.Context, payload AddOnStatusBulkPostRequest,
+) (AddOnStatusBulkResponse, error) {
+	res := &AddOnStatusBulkResponse{}
+	err := c.do(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status/bulk", c.clusterID()),
+		url.Values{},
+		payload,
+		res,
+	)
+	if err != nil {
+		return AddOnStatusBulkResponse{}, err
+	}
+	return *res, nil
+}
+
 func (c *Client) PatchAddOnStatus(ctx context.Context, addonID string, payload AddOnStatusPatchRequest) (AddOnStatusResponse, error) {
 	res := &AddOnStatusResponse{}
 	err := c.do(
 		ctx,
 		http.MethodPatch,
-		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status/%s", c.opts.ClusterID, addonID),
+		fmt.Sprintf("/api/addons_mgmt/v1/clusters/%s/status/%s", c.clusterID(), addonID),
 		url.Values{},
 		payload,
 		res,
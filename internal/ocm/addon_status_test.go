@@ -2,10 +2,12 @@ package ocm
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -247,3 +249,37 @@ func TestClient_PatchAddOnStatus(t *testing.T) {
 	// Check the error
 	require.Error(t, err, "Client.PatchAddOnStatus() should return an error for server error")
 }
+
+func TestClient_PostAddOnStatusBulk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/addons_mgmt/v1/clusters/1ou/status/bulk", r.URL.Path)
+
+		req := AddOnStatusBulkPostRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res := AddOnStatusBulkResponse{Kind: "AddOnStatusBulkResponse"}
+		for _, item := range req.Items {
+			res.Items = append(res.Items, AddOnStatusResponse{
+				Kind:         "AddOnStatusResponse",
+				AddonID:      item.AddonID,
+				AddonVersion: item.AddonVersion,
+			})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	c.opts.ClusterID = "1ou"
+	res, err := c.PostAddOnStatusBulk(context.Background(), AddOnStatusBulkPostRequest{
+		Items: []AddOnStatusPostRequest{
+			{AddonID: "addon-1", AddonVersion: "1.0.0"},
+			{AddonID: "addon-2", AddonVersion: "2.0.0"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Items, 2)
+	assert.Equal(t, "addon-1", res.Items[0].AddonID)
+	assert.Equal(t, "2.0.0", res.Items[1].AddonVersion)
+}
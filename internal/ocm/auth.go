@@ -0,0 +1,133 @@
+package ocm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// TokenSource provides bearer tokens to authenticate to the OCM API.
+type TokenSource interface {
+	// Token returns a valid token,
+	// fetching a new one when the cached token expired.
+	Token(ctx context.Context) (string, error)
+	// Invalidate drops the cached token after OCM rejected it.
+	Invalidate(token string)
+}
+
+// DefaultOfflineTokenClientID is the OAuth2 client offline tokens are issued to.
+const DefaultOfflineTokenClientID = "cloud-services"
+
+const tokenRequestTimeout = 30 * time.Second
+
+// NewClientCredentialsTokenSource fetches tokens
+// with the OAuth2 client credentials grant.
+func NewClientCredentialsTokenSource(
+	tokenURL, clientID, clientSecret string, scopes []string,
+) TokenSource {
+	config := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       scopes,
+	}
+	return newOAuth2TokenSource(func(ctx context.Context, _ *oauth2.Token) (*oauth2.Token, error) {
+		return config.Token(ctx)
+	})
+}
+
+// NewOfflineTokenSource exchanges an offline token for short lived tokens
+// with the OAuth2 refresh token grant.
+// Refresh tokens rotated by the token endpoint are used for following exchanges.
+func NewOfflineTokenSource(
+	tokenURL, clientID, offlineToken string, scopes []string,
+) TokenSource {
+	config := &oauth2.Config{
+		ClientID: clientID,
+		Endpoint: oauth2.Endpoint{TokenURL: tokenURL},
+		Scopes:   scopes,
+	}
+	return newOAuth2TokenSource(func(ctx context.Context, last *oauth2.Token) (*oauth2.Token, error) {
+		refreshToken := offlineToken
+		if last != nil && len(last.RefreshToken) > 0 {
+			refreshToken = last.RefreshToken
+		}
+		return config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	})
+}
+
+// Caches tokens until they expire or are invalidated.
+type oauth2TokenSource struct {
+	fetch      func(ctx context.Context, last *oauth2.Token) (*oauth2.Token, error)
+	httpClient *http.Client
+
+	lock  sync.Mutex
+	token *oauth2.Token
+}
+
+func newOAuth2TokenSource(
+	fetch func(ctx context.Context, last *oauth2.Token) (*oauth2.Token, error),
+) *oauth2TokenSource {
+	return &oauth2TokenSource{
+		fetch:      fetch,
+		httpClient: &http.Client{Timeout: tokenRequestTimeout},
+	}
+}
+
+func (s *oauth2TokenSource) Token(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token.Valid() {
+		return s.token.AccessToken, nil
+	}
+
+	token, err := s.fetch(context.WithValue(ctx, oauth2.HTTPClient, s.httpClient), s.token)
+	if err != nil {
+		return "", fmt.Errorf("fetching token: %w", err)
+	}
+	s.token = token
+	return token.AccessToken, nil
+}
+
+func (s *oauth2TokenSource) Invalidate(token string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token == nil || s.token.AccessToken != token {
+		return
+	}
+	// Keep the refresh token for the next exchange.
+	expired := *s.token
+	expired.Expiry = time.Unix(1, 0)
+	s.token = &expired
+}
+
+// NewClientCertificateTLSConfig authenticates with the PEM encoded client certificate.
+// The optional CA bundle replaces the system roots to verify the OCM API.
+func NewClientCertificateTLSConfig(certPEM, keyPEM, caPEM []byte) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no certificates found in CA bundle")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
@@ -0,0 +1,168 @@
+package ocm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Issues numbered tokens and records the token requests.
+func newTokenServer(t *testing.T) (*httptest.Server, *[]http.Request) {
+	t.Helper()
+
+	var requests []http.Request
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, *r)
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw,
+			`{"access_token":"token-%d","refresh_token":"refresh-%d","token_type":"Bearer","expires_in":300}`,
+			len(requests), len(requests))
+	}))
+	t.Cleanup(s.Close)
+	return s, &requests
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	s, requests := newTokenServer(t)
+	ts := NewClientCredentialsTokenSource(s.URL, "client", "secret", []string{"openid"})
+	ctx := context.Background()
+
+	token, err := ts.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// Cached until invalidated.
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	require.Len(t, *requests, 1)
+
+	ts.Invalidate("token-1")
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	req := (*requests)[0]
+	assert.Equal(t, "client_credentials", req.PostForm.Get("grant_type"))
+	assert.Equal(t, "openid", req.PostForm.Get("scope"))
+	clientID, clientSecret, _ := req.BasicAuth()
+	assert.Equal(t, "client", clientID)
+	assert.Equal(t, "secret", clientSecret)
+}
+
+func TestOfflineTokenSource(t *testing.T) {
+	s, requests := newTokenServer(t)
+	ts := NewOfflineTokenSource(s.URL, DefaultOfflineTokenClientID, "offline", nil)
+	ctx := context.Background()
+
+	token, err := ts.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// Stale tokens are not invalidated.
+	ts.Invalidate("token-0")
+	_, err = ts.Token(ctx)
+	require.NoError(t, err)
+	require.Len(t, *requests, 1)
+
+	ts.Invalidate("token-1")
+	token, err = ts.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	require.Len(t, *requests, 2)
+	assert.Equal(t, "refresh_token", (*requests)[0].PostForm.Get("grant_type"))
+	assert.Equal(t, "offline", (*requests)[0].PostForm.Get("refresh_token"))
+	// Rotated refresh token is used for the next exchange.
+	assert.Equal(t, "refresh-1", (*requests)[1].PostForm.Get("refresh_token"))
+}
+
+func TestClientDo_TokenSource(t *testing.T) {
+	tokenServer, _ := newTokenServer(t)
+
+	var authorizations []string
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(rw, `{"code":"unauthorized","reason":"token revoked"}`)
+			return
+		}
+		fmt.Fprintln(rw, `{}`)
+	}))
+	defer s.Close()
+
+	c := newTestClient(s.URL, WithTokenSource(
+		NewClientCredentialsTokenSource(tokenServer.URL, "client", "secret", nil)))
+
+	require.NoError(t, c.do(context.Background(), http.MethodGet, "test", nil, nil, nil))
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authorizations)
+}
+
+func TestClientDo_ClientCertificate(t *testing.T) {
+	certPEM, keyPEM := newTestCertificate(t)
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		if assert.Len(t, r.TLS.PeerCertificates, 1) {
+			assert.Equal(t, "addon-operator", r.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+		fmt.Fprintln(rw, `{}`)
+	}))
+	s.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	s.StartTLS()
+	defer s.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	tlsConfig, err := NewClientCertificateTLSConfig(certPEM, keyPEM, caPEM)
+	require.NoError(t, err)
+
+	c := New(WithEndpoint(s.URL), WithTLSConfig(tlsConfig), WithRetry(RetryOptions{}))
+	require.NoError(t, c.do(context.Background(), http.MethodGet, "test", nil, nil, nil))
+}
+
+func TestNewClientCertificateTLSConfig_Invalid(t *testing.T) {
+	certPEM, keyPEM := newTestCertificate(t)
+
+	_, err := NewClientCertificateTLSConfig(certPEM, nil, nil)
+	assert.ErrorContains(t, err, "loading client certificate")
+
+	_, err = NewClientCertificateTLSConfig(certPEM, keyPEM, []byte("garbage"))
+	assert.ErrorContains(t, err, "no certificates found in CA bundle")
+}
+
+func newTestCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "addon-operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
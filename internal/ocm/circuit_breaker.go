@@ -0,0 +1,127 @@
+package ocm
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting OCM,
+// while the circuit breaker considers the OCM API to be down.
+var ErrCircuitOpen = errors.New("ocm circuit breaker is open")
+
+type CircuitBreakerState string
+
+const (
+	// Requests pass through.
+	CircuitBreakerClosed CircuitBreakerState = "closed"
+	// Requests are rejected with ErrCircuitOpen.
+	CircuitBreakerOpen CircuitBreakerState = "open"
+	// A single trial request is let through to probe OCM.
+	CircuitBreakerHalfOpen CircuitBreakerState = "half_open"
+)
+
+// CircuitBreaker opens after a number of consecutive failed requests
+// and lets a trial request through after a cooldown.
+// It is safe for concurrent use and meant to be shared
+// by all clients talking to the same OCM API.
+type CircuitBreaker struct {
+	failureThreshold int
+	cooldown         time.Duration
+	metrics          Metrics
+	now              func() time.Time
+
+	lock     sync.Mutex
+	state    CircuitBreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// Creates a CircuitBreaker opening after failureThreshold consecutive failures
+// and probing OCM again after the cooldown.
+func NewCircuitBreaker(failureThreshold int, cooldown time.Duration, metrics Metrics) *CircuitBreaker {
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
+	cb := &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		metrics:          metrics,
+		now:              time.Now,
+	}
+	cb.setState(CircuitBreakerClosed)
+	return cb
+}
+
+// State returns the current state of the circuit breaker.
+func (cb *CircuitBreaker) State() CircuitBreakerState {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	return cb.state
+}
+
+// Allow reports whether a request may be sent.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case CircuitBreakerOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.setState(CircuitBreakerHalfOpen)
+		cb.probing = true
+		return true
+	case CircuitBreakerHalfOpen:
+		// Only one trial request at a time.
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a request OCM answered.
+func (cb *CircuitBreaker) Success() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.failures = 0
+	cb.probing = false
+	if cb.state != CircuitBreakerClosed {
+		cb.setState(CircuitBreakerClosed)
+	}
+}
+
+// Failure records a request failing because of OCM.
+func (cb *CircuitBreaker) Failure() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.failures++
+	cb.probing = false
+	if cb.state == CircuitBreakerHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.openedAt = cb.now()
+		if cb.state != CircuitBreakerOpen {
+			cb.setState(CircuitBreakerOpen)
+		}
+	}
+}
+
+// Releases the trial request of a half-open circuit breaker,
+// when it did not complete for reasons unrelated to OCM.
+func (cb *CircuitBreaker) abort() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	cb.probing = false
+}
+
+func (cb *CircuitBreaker) setState(state CircuitBreakerState) {
+	cb.state = state
+	cb.metrics.SetOCMCircuitBreakerState(string(state))
+}
@@ -0,0 +1,53 @@
+package ocm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	m := &testMetrics{}
+	cb := NewCircuitBreaker(2, time.Minute, m)
+	cb.now = func() time.Time { return now }
+
+	assert.Equal(t, CircuitBreakerClosed, cb.State())
+
+	// Successes reset the consecutive failures.
+	cb.Failure()
+	cb.Success()
+	cb.Failure()
+	assert.True(t, cb.Allow())
+	assert.Equal(t, CircuitBreakerClosed, cb.State())
+
+	cb.Failure()
+	assert.Equal(t, CircuitBreakerOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	// Single trial request after the cooldown.
+	now = now.Add(time.Minute)
+	assert.True(t, cb.Allow())
+	assert.Equal(t, CircuitBreakerHalfOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	// Failed trial opens the circuit again.
+	cb.Failure()
+	assert.Equal(t, CircuitBreakerOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	// Aborted trial does not block the next one.
+	now = now.Add(time.Minute)
+	assert.True(t, cb.Allow())
+	cb.abort()
+	assert.True(t, cb.Allow())
+
+	cb.Success()
+	assert.Equal(t, CircuitBreakerClosed, cb.State())
+	assert.True(t, cb.Allow())
+
+	assert.Equal(t, []string{
+		"closed", "open", "half_open", "open", "half_open", "closed",
+	}, m.states)
+}
@@ -3,42 +3,167 @@ package ocm
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openshift/addon-operator/internal/tracing"
 	"github.com/openshift/addon-operator/internal/version"
 )
 
 type Client struct {
 	opts       ClientOptions
 	httpClient *http.Client
+
+	// Guards the credentials and cluster information in opts,
+	// which may change while the client is in use.
+	lock sync.RWMutex
+	// Serializes access token refreshes after 401 responses.
+	refreshLock sync.Mutex
 }
 
-// Creates a new OCM client with the given options.
+const defaultRequestTimeout = 30 * time.Second
+
+var defaultRetryOptions = RetryOptions{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// Creates a new OCM client with the given options
+// and looks up the cluster in OCM.
 func NewClient(ctx context.Context, opts ...Option) (*Client, error) {
-	c := &Client{}
+	c := New(opts...)
+	if err := c.Connect(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Creates a new OCM client with the given options.
+// Connect has to be called before using the client.
+func New(opts ...Option) *Client {
+	c := &Client{
+		opts: ClientOptions{
+			RequestTimeout: defaultRequestTimeout,
+			Retry:          defaultRetryOptions,
+		},
+	}
 	for _, opt := range opts {
 		opt(&c.opts)
 	}
 
 	c.httpClient = &http.Client{}
+	if c.opts.TLSConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = c.opts.TLSConfig
+		c.httpClient.Transport = transport
+	}
+	return c
+}
 
+// Connect looks up the internal ID and name of the cluster in OCM,
+// which verifies the endpoint and credentials.
+func (c *Client) Connect(ctx context.Context) error {
 	// Getting the Cluster Internal ID from the External ID
 	clusterInfo, err := c.GetCluster(ctx, ClusterGetRequest{})
 	if err != nil {
-		return nil, fmt.Errorf("getting cluster info: %w", err)
+		return fmt.Errorf("getting cluster info: %w", err)
 	}
 	if len(clusterInfo.Items) == 0 {
-		return nil, fmt.Errorf("cluster %s not found", c.opts.ClusterExternalID)
+		return fmt.Errorf("cluster %s not found", c.opts.ClusterExternalID)
 	}
 
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	c.opts.ClusterID = clusterInfo.Items[0].Id
 	c.opts.ClusterName = clusterInfo.Items[0].Name
-	return c, nil
+	return nil
+}
+
+// SetAccessToken replaces the access token used for all following requests.
+func (c *Client) SetAccessToken(accessToken string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.opts.AccessToken = accessToken
+}
+
+func (c *Client) accessToken() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.opts.AccessToken
+}
+
+func (c *Client) clusterID() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.opts.ClusterID
+}
+
+// Returns the Authorization header and the credential it carries.
+func (c *Client) authorization(ctx context.Context) (header, credential string, err error) {
+	if c.opts.TokenSource != nil {
+		token, err := c.opts.TokenSource.Token(ctx)
+		if err != nil {
+			return "", "", fmt.Errorf("getting token: %w", err)
+		}
+		return "Bearer " + token, token, nil
+	}
+
+	accessToken := c.accessToken()
+	if len(accessToken) == 0 && c.opts.TLSConfig != nil {
+		// Authenticated by the client certificate alone.
+		return "", "", nil
+	}
+	return fmt.Sprintf("AccessToken %s:%s", c.clusterID(), accessToken), accessToken, nil
+}
+
+// Renews the credential OCM rejected.
+// Returns true, when the request should be sent again.
+func (c *Client) refreshCredentials(ctx context.Context, rejected string) (bool, error) {
+	if c.opts.TokenSource != nil {
+		c.opts.TokenSource.Invalidate(rejected)
+		return true, nil
+	}
+	return c.refreshAccessToken(ctx, rejected)
+}
+
+// Re-reads the access token after OCM rejected it.
+// Returns true, when the token changed and the request should be sent again.
+func (c *Client) refreshAccessToken(ctx context.Context, rejected string) (bool, error) {
+	if c.opts.AccessTokenRefresher == nil {
+		return false, nil
+	}
+
+	c.refreshLock.Lock()
+	defer c.refreshLock.Unlock()
+
+	// Refreshed by a concurrent request.
+	if current := c.accessToken(); current != rejected {
+		return true, nil
+	}
+
+	accessToken, err := c.opts.AccessTokenRefresher(ctx)
+	if err != nil {
+		return false, fmt.Errorf("refreshing access token: %w", err)
+	}
+	if accessToken == rejected {
+		return false, nil
+	}
+	c.SetAccessToken(accessToken)
+	return true, nil
 }
 
 type ClientOptions struct {
@@ -47,6 +172,19 @@ type ClientOptions struct {
 	ClusterID         string
 	ClusterName       string
 	AccessToken       string
+	// Optional, called to re-read the access token
+	// when OCM responds with 401 Unauthorized.
+	AccessTokenRefresher func(ctx context.Context) (string, error)
+	// Optional, authenticates with bearer tokens instead of the AccessToken.
+	TokenSource TokenSource
+	// Optional, e.g. to authenticate with a client certificate.
+	TLSConfig *tls.Config
+	// Timeout of a single attempt of a request.
+	RequestTimeout time.Duration
+	Retry          RetryOptions
+	// Optional, no circuit breaking when unset.
+	CircuitBreaker *CircuitBreaker
+	Metrics        Metrics
 }
 
 type Option func(o *ClientOptions)
@@ -70,6 +208,61 @@ func WithAccessToken(accessToken string) Option {
 	}
 }
 
+func WithAccessTokenRefresher(refresher func(ctx context.Context) (string, error)) Option {
+	return func(o *ClientOptions) {
+		o.AccessTokenRefresher = refresher
+	}
+}
+
+func WithTokenSource(tokenSource TokenSource) Option {
+	return func(o *ClientOptions) {
+		o.TokenSource = tokenSource
+	}
+}
+
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *ClientOptions) {
+		o.TLSConfig = config
+	}
+}
+
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *ClientOptions) {
+		o.RequestTimeout = timeout
+	}
+}
+
+func WithRetry(retry RetryOptions) Option {
+	return func(o *ClientOptions) {
+		o.Retry = retry
+	}
+}
+
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(o *ClientOptions) {
+		o.CircuitBreaker = cb
+	}
+}
+
+func WithMetrics(metrics Metrics) Option {
+	return func(o *ClientOptions) {
+		o.Metrics = metrics
+	}
+}
+
+// Metrics records retries and circuit breaker activity of the client.
+type Metrics interface {
+	RecordOCMAPIRetry()
+	RecordOCMAPIShortCircuit()
+	SetOCMCircuitBreakerState(state string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) RecordOCMAPIRetry()               {}
+func (noopMetrics) RecordOCMAPIShortCircuit()        {}
+func (noopMetrics) SetOCMCircuitBreakerState(string) {}
+
 type OCMError struct {
 	StatusCode int    `json:"-"`
 	Code       string `json:"code"`
@@ -77,6 +270,9 @@ type OCMError struct {
 }
 
 func (e OCMError) Error() string {
+	if len(e.Code) == 0 {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Reason)
+	}
 	return fmt.Sprintf("HTTP %d: %s: %s", e.StatusCode, e.Code, e.Reason)
 }
 
@@ -86,7 +282,17 @@ func (c *Client) do(
 	path string,
 	params url.Values,
 	payload, result interface{},
-) error {
+) (err error) {
+	ctx, span := tracing.Start(ctx, "OCM "+httpMethod, attribute.String("http.request.method", httpMethod))
+	defer func() { tracing.End(span, err) }()
+	if p, ok := payload.(correlatedRequest); ok {
+		if ids := p.correlationIDs(); len(ids) == 1 {
+			span.SetAttributes(attribute.String("ocm.correlation_id", ids[0]))
+		} else if len(ids) > 1 {
+			span.SetAttributes(attribute.StringSlice("ocm.correlation_id", ids))
+		}
+	}
+
 	// Build URL
 	reqURL, err := url.Parse(c.opts.Endpoint)
 	if err != nil {
@@ -96,15 +302,13 @@ func (c *Client) do(
 		Path: strings.TrimLeft(path, "/"), // trim first slash to always be relative to baseURL
 	})
 
-	// Payload
-	var resBody io.Reader
+	// Payload, encoded once to be sent with every attempt.
+	var reqBody []byte
 	if payload != nil {
-		j, err := json.Marshal(payload)
+		reqBody, err = json.Marshal(payload)
 		if err != nil {
 			return fmt.Errorf("marshaling json: %w", err)
 		}
-
-		resBody = bytes.NewBuffer(j)
 	}
 
 	var fullUrl string
@@ -113,51 +317,158 @@ func (c *Client) do(
 	} else {
 		fullUrl = reqURL.String()
 	}
+	span.SetAttributes(attribute.String("url.full", fullUrl))
 
-	httpReq, err := http.NewRequestWithContext(ctx, httpMethod, fullUrl, resBody)
+	var refreshed bool
+	for retry := 0; ; retry++ {
+		authorization, credential, err := c.authorization(ctx)
+		if err != nil {
+			return err
+		}
+		statusCode, retryAfter, err := c.attempt(ctx, httpMethod, fullUrl, authorization, reqBody, result)
+		if statusCode > 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		}
+		if err == nil {
+			return nil
+		}
+		if statusCode == http.StatusUnauthorized && !refreshed {
+			// Credentials may have been rotated, try once more with fresh ones.
+			refreshed = true
+			ok, refreshErr := c.refreshCredentials(ctx, credential)
+			if refreshErr != nil {
+				return errors.Join(err, refreshErr)
+			}
+			if ok {
+				retry--
+				continue
+			}
+			return err
+		}
+		if retry >= c.opts.Retry.MaxRetries ||
+			errors.Is(err, ErrCircuitOpen) ||
+			ctx.Err() != nil ||
+			!shouldRetry(httpMethod, statusCode) {
+			return err
+		}
+
+		c.metrics().RecordOCMAPIRetry()
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("http.request.resend_count", retry+1),
+			attribute.String("error.message", err.Error()),
+		))
+		timer := time.NewTimer(c.opts.Retry.delay(retry, retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Sends a single request, guarded by the circuit breaker.
+// Returns the HTTP status code, or 0 if no response was received,
+// and the delay requested by the server via the Retry-After header.
+func (c *Client) attempt(
+	ctx context.Context,
+	httpMethod, fullUrl, authorization string,
+	reqBody []byte,
+	result interface{},
+) (statusCode int, retryAfter time.Duration, err error) {
+	cb := c.opts.CircuitBreaker
+	if cb == nil {
+		return c.send(ctx, httpMethod, fullUrl, authorization, reqBody, result)
+	}
+
+	if !cb.Allow() {
+		c.metrics().RecordOCMAPIShortCircuit()
+		return 0, 0, ErrCircuitOpen
+	}
+	statusCode, retryAfter, err = c.send(ctx, httpMethod, fullUrl, authorization, reqBody, result)
+	switch {
+	case statusCode == 0 && ctx.Err() != nil:
+		// Canceled by the caller, says nothing about OCM.
+		cb.abort()
+	case statusCode == 0 || statusCode >= 500 || statusCode == http.StatusTooManyRequests:
+		cb.Failure()
+	default:
+		cb.Success()
+	}
+	return statusCode, retryAfter, err
+}
+
+func (c *Client) send(
+	ctx context.Context,
+	httpMethod, fullUrl, authorization string,
+	reqBody []byte,
+	result interface{},
+) (statusCode int, retryAfter time.Duration, err error) {
+	if c.opts.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.RequestTimeout)
+		defer cancel()
+	}
+
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, httpMethod, fullUrl, body)
 	if err != nil {
-		return fmt.Errorf("creating http request: %w", err)
+		return 0, 0, fmt.Errorf("creating http request: %w", err)
 	}
 
 	// Headers
-	httpReq.Header.Add("Authorization", fmt.Sprintf(
-		"AccessToken %s:%s", c.opts.ClusterID, c.opts.AccessToken))
+	if len(authorization) > 0 {
+		httpReq.Header.Add("Authorization", authorization)
+	}
 	httpReq.Header.Add("User-Agent", fmt.Sprintf("AddonOperator/%s", version.Version))
 	httpReq.Header.Add("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
 
 	httpRes, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("executing http request: %w", err)
+		return 0, 0, fmt.Errorf("executing http request: %w", err)
 	}
 	defer httpRes.Body.Close()
 
 	// HTTP Error handling
 	if httpRes.StatusCode >= 400 && httpRes.StatusCode <= 599 {
+		retryAfter := parseRetryAfter(httpRes.Header.Get("Retry-After"), time.Now())
 		body, err := io.ReadAll(httpRes.Body)
 		if err != nil {
-			return fmt.Errorf("reading error response body %s: %w", fullUrl, err)
+			return httpRes.StatusCode, retryAfter, fmt.Errorf("reading error response body %s: %w", fullUrl, err)
 		}
 
 		var ocmErr OCMError
 		if err := json.Unmarshal(body, &ocmErr); err != nil {
-			return fmt.Errorf(
-				"HTTP %d: unmarshal json error response %s: %w", httpRes.StatusCode, string(body), err)
+			// Gateways and proxies answer with HTML or empty bodies,
+			// the status code still has to be available to callers.
+			ocmErr = OCMError{Reason: strings.TrimSpace(string(body))}
 		}
 		ocmErr.StatusCode = httpRes.StatusCode
-		return ocmErr
+		return httpRes.StatusCode, retryAfter, ocmErr
 	}
 
 	// Read response
 	if result != nil {
 		body, err := io.ReadAll(httpRes.Body)
 		if err != nil {
-			return fmt.Errorf("reading response body %s: %w", fullUrl, err)
+			return httpRes.StatusCode, 0, fmt.Errorf("reading response body %s: %w", fullUrl, err)
 		}
 
 		if err := json.Unmarshal(body, result); err != nil {
-			return fmt.Errorf("unmarshal json response %s: %w", fullUrl, err)
+			return httpRes.StatusCode, 0, fmt.Errorf("unmarshal json response %s: %w", fullUrl, err)
 		}
 	}
 
-	return nil
+	return httpRes.StatusCode, 0, nil
+}
+
+func (c *Client) metrics() Metrics {
+	if c.opts.Metrics == nil {
+		return noopMetrics{}
+	}
+	return c.opts.Metrics
 }
@@ -9,9 +9,15 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestClientDo_Success(t *testing.T) {
@@ -135,6 +141,7 @@ func TestClientDo_Error(t *testing.T) {
 		WithAccessToken("access-token"),
 		WithClusterExternalID("123"),
 		WithEndpoint(s.URL+"/proxy/apis"), // test existing path + trailing / handling
+		WithRetry(RetryOptions{}),
 	)
 	require.NoError(t, ocmClientError)
 
@@ -142,3 +149,262 @@ func TestClientDo_Error(t *testing.T) {
 		ctx, http.MethodPatch, "/broken", nil, nil, nil)
 	assert.EqualError(t, err, "HTTP 500: swordfish: olm dance")
 }
+
+func TestClientDo_NonJSONError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/html")
+		rw.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(rw, `<html><body>404 Not Found</body></html>`)
+	}))
+	defer s.Close()
+
+	c := newTestClient(s.URL)
+	err := c.do(context.Background(), http.MethodPost, "/missing", nil, nil, nil)
+
+	var ocmErr OCMError
+	require.ErrorAs(t, err, &ocmErr)
+	assert.Equal(t, http.StatusNotFound, ocmErr.StatusCode)
+	assert.EqualError(t, err, "HTTP 404: <html><body>404 Not Found</body></html>")
+}
+
+type testMetrics struct {
+	retries, shortCircuits int
+	states                 []string
+}
+
+func (m *testMetrics) RecordOCMAPIRetry()                     { m.retries++ }
+func (m *testMetrics) RecordOCMAPIShortCircuit()              { m.shortCircuits++ }
+func (m *testMetrics) SetOCMCircuitBreakerState(state string) { m.states = append(m.states, state) }
+
+// Answers requests with the given status codes in order,
+// followed by 200 OK.
+func newFlakyServer(t *testing.T, header http.Header, statusCodes ...int) (*httptest.Server, *int) {
+	t.Helper()
+
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method == http.MethodPost {
+			assert.Equal(t, `{"request":"payload!"}`, string(body))
+		}
+		if requests > len(statusCodes) {
+			fmt.Fprintln(rw, `{"response":"works!"}`)
+			return
+		}
+		for k, v := range header {
+			rw.Header()[k] = v
+		}
+		rw.WriteHeader(statusCodes[requests-1])
+		fmt.Fprintln(rw, `{"code":"swordfish","reason":"olm dance"}`)
+	}))
+	t.Cleanup(s.Close)
+	return s, &requests
+}
+
+func newTestClient(endpoint string, opts ...Option) *Client {
+	c := &Client{
+		opts: ClientOptions{
+			Endpoint:       endpoint,
+			RequestTimeout: time.Second,
+			Retry: RetryOptions{
+				MaxRetries: 3,
+				BaseDelay:  time.Millisecond,
+				MaxDelay:   10 * time.Millisecond,
+			},
+		},
+		httpClient: &http.Client{},
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	return c
+}
+
+func TestClientDo_Retry(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		header      http.Header
+		statusCodes []int
+
+		expectedRequests int
+		expectedError    string
+	}{
+		{
+			name:             "retries server errors",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusBadGateway},
+			expectedRequests: 3,
+		},
+		{
+			name:             "honors Retry-After",
+			method:           http.MethodGet,
+			header:           http.Header{"Retry-After": []string{"0"}},
+			statusCodes:      []int{http.StatusTooManyRequests},
+			expectedRequests: 2,
+		},
+		{
+			name:             "gives up after max retries",
+			method:           http.MethodGet,
+			statusCodes:      []int{500, 500, 500, 500, 500},
+			expectedRequests: 4,
+			expectedError:    "HTTP 500: swordfish: olm dance",
+		},
+		{
+			name:             "no retry on client errors",
+			method:           http.MethodGet,
+			statusCodes:      []int{http.StatusNotFound},
+			expectedRequests: 1,
+			expectedError:    "HTTP 404: swordfish: olm dance",
+		},
+		{
+			name:             "POST not retried on internal errors",
+			method:           http.MethodPost,
+			statusCodes:      []int{http.StatusInternalServerError},
+			expectedRequests: 1,
+			expectedError:    "HTTP 500: swordfish: olm dance",
+		},
+		{
+			name:             "POST retried when unavailable",
+			method:           http.MethodPost,
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			expectedRequests: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, requests := newFlakyServer(t, test.header, test.statusCodes...)
+			m := &testMetrics{}
+			c := newTestClient(s.URL, WithMetrics(m))
+
+			err := c.do(context.Background(), test.method, "test", nil,
+				map[string]string{"request": "payload!"}, nil)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedRequests, *requests)
+			assert.Equal(t, test.expectedRequests-1, m.retries)
+		})
+	}
+}
+
+func TestClientDo_RequestTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer s.Close()
+
+	c := newTestClient(s.URL,
+		WithRequestTimeout(10*time.Millisecond), WithRetry(RetryOptions{}))
+
+	err := c.do(context.Background(), http.MethodGet, "test", nil, nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientDo_CircuitBreaker(t *testing.T) {
+	s, requests := newFlakyServer(t, nil, 500, 500, 500)
+	m := &testMetrics{}
+	cb := NewCircuitBreaker(2, time.Hour, m)
+	c := newTestClient(s.URL, WithCircuitBreaker(cb), WithMetrics(m))
+
+	// Opens after the second failure and short-circuits the remaining retries.
+	err := c.do(context.Background(), http.MethodGet, "test", nil, nil, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, *requests)
+	assert.Equal(t, CircuitBreakerOpen, cb.State())
+
+	err = c.do(context.Background(), http.MethodGet, "test", nil, nil, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, *requests)
+	assert.Equal(t, 2, m.shortCircuits)
+	assert.Equal(t, []string{"closed", "open"}, m.states)
+}
+
+func TestClientDo_RefreshAccessToken(t *testing.T) {
+	var authorizations []string
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "AccessToken 1ou:rotated" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(rw, `{"code":"unauthorized","reason":"token expired"}`)
+			return
+		}
+		fmt.Fprintln(rw, `{}`)
+	}))
+	defer s.Close()
+
+	var refreshes int
+	c := newTestClient(s.URL,
+		WithAccessToken("expired"),
+		WithAccessTokenRefresher(func(context.Context) (string, error) {
+			refreshes++
+			return "rotated", nil
+		}),
+	)
+	c.opts.ClusterID = "1ou"
+
+	require.NoError(t, c.do(context.Background(), http.MethodGet, "test", nil, nil, nil))
+	require.NoError(t, c.do(context.Background(), http.MethodGet, "test", nil, nil, nil))
+	assert.Equal(t, 1, refreshes)
+	assert.Equal(t, []string{
+		"AccessToken 1ou:expired",
+		"AccessToken 1ou:rotated",
+		"AccessToken 1ou:rotated",
+	}, authorizations)
+
+	// Unchanged credentials are not tried again.
+	c.SetAccessToken("revoked")
+	c.opts.AccessTokenRefresher = func(context.Context) (string, error) {
+		return "revoked", nil
+	}
+	err := c.do(context.Background(), http.MethodGet, "test", nil, nil, nil)
+	assert.EqualError(t, err, "HTTP 401: unauthorized: token expired")
+	assert.Len(t, authorizations, 4)
+}
+
+func TestClientDo_Tracing(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var traceparent string
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		fmt.Fprintln(rw, `{}`)
+	}))
+	defer s.Close()
+
+	c := New(WithAccessToken("access-token"), WithEndpoint(s.URL))
+
+	_, err := c.PostAddOnStatus(context.Background(), AddOnStatusPostRequest{
+		AddonID:       "addon-1",
+		CorrelationID: "correlation-1",
+	})
+	require.NoError(t, err)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "OCM POST", span.Name())
+	assert.Equal(t,
+		fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID()),
+		traceparent)
+
+	attrs := attribute.NewSet(span.Attributes()...)
+	correlationID, _ := attrs.Value("ocm.correlation_id")
+	assert.Equal(t, "correlation-1", correlationID.AsString())
+	statusCode, _ := attrs.Value("http.response.status_code")
+	assert.Equal(t, int64(http.StatusOK), statusCode.AsInt64())
+}
@@ -20,6 +20,8 @@ type Cluster struct {
 }
 
 func (c *Client) GetClusterIDAndName() (string, string) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 	return c.opts.ClusterID, c.opts.ClusterName
 }
 
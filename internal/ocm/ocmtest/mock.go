@@ -61,6 +61,15 @@ func (c *Client) PostAddOnStatus(
 		args.Error(1)
 }
 
+func (c *Client) PostAddOnStatusBulk(
+	ctx context.Context,
+	req ocm.AddOnStatusBulkPostRequest,
+) (ocm.AddOnStatusBulkResponse, error) {
+	args := c.Called(ctx, req)
+	return args.Get(0).(ocm.AddOnStatusBulkResponse),
+		args.Error(1)
+}
+
 func (c *Client) PatchAddOnStatus(
 	ctx context.Context,
 	addonID string,
@@ -79,3 +88,11 @@ func (c *Client) GetAddOnStatus(
 	return args.Get(0).(ocm.AddOnStatusResponse),
 		args.Error(1)
 }
+
+func (c *Client) ListAddonInstallations(
+	ctx context.Context,
+) ([]ocm.AddonInstallation, error) {
+	args := c.Called(ctx)
+	return args.Get(0).([]ocm.AddonInstallation),
+		args.Error(1)
+}
@@ -0,0 +1,58 @@
+package ocmtest
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Scenario scripts faults injected by the api-mock into OCM API requests.
+// Set with PUT /scenario or loaded at startup with the -scenario flag.
+type Scenario struct {
+	// Rules are matched in order, the first matching rule applies.
+	Rules []FaultRule `json:"rules"`
+}
+
+// FaultRule changes how matching requests are handled.
+// Requests passing all checks are handled as usual.
+type FaultRule struct {
+	// Shown in the recorded requests the rule applied to.
+	Name string `json:"name,omitempty"`
+	// Regular expression matched against the request path.
+	Path string `json:"path"`
+	// HTTP method to match, all methods match when empty.
+	Method string `json:"method,omitempty"`
+	// Number of requests the rule applies to, without limit when 0.
+	Times int `json:"times,omitempty"`
+
+	// Delay before the request is handled.
+	Latency metav1.Duration `json:"latency,omitempty"`
+	// Only requests carrying one of these access tokens or bearer tokens pass,
+	// others are rejected with 401.
+	Tokens []string `json:"tokens,omitempty"`
+	// Closes the connection without a response.
+	DropConnection bool `json:"dropConnection,omitempty"`
+	// Responds with this status code instead of handling the request.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Body sent with StatusCode, defaults to an OCM error.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Sets the Retry-After header with StatusCode.
+	RetryAfter metav1.Duration `json:"retryAfter,omitempty"`
+}
+
+// RecordedRequest is an OCM API request received by the api-mock,
+// listed with GET /requests.
+type RecordedRequest struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Query  string    `json:"query,omitempty"`
+	// Token of the Authorization header.
+	Token string `json:"token,omitempty"`
+	Body  string `json:"body,omitempty"`
+	// Status code of the response, 0 for dropped connections.
+	StatusCode int `json:"statusCode"`
+	// Name or index of the rule applied to the request.
+	Rule string `json:"rule,omitempty"`
+}
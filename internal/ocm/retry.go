@@ -0,0 +1,75 @@
+package ocm
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Controls how failed requests are retried.
+type RetryOptions struct {
+	// Number of retries after the initial attempt.
+	MaxRetries int
+	// Upper bound of the delay before the first retry,
+	// doubled with every following retry.
+	BaseDelay time.Duration
+	// Upper bound of any delay, including Retry-After.
+	MaxDelay time.Duration
+}
+
+// Whether a request may be sent again after it failed with the given status code.
+// Requests failing with a status code of 0 did not receive a response.
+func shouldRetry(method string, statusCode int) bool {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		// The request was rejected before it was processed.
+		return true
+	case statusCode == http.StatusServiceUnavailable && method == http.MethodPost:
+		return true
+	case statusCode == 0 || statusCode >= 500:
+		// The request may have been processed,
+		// creating an object twice has to be avoided.
+		return method != http.MethodPost
+	default:
+		return false
+	}
+}
+
+// Delay before the given retry, starting at 0.
+// Uses "full jitter" exponential backoff,
+// unless the server asked for a specific delay via the Retry-After header.
+func (o RetryOptions) delay(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return o.capDelay(retryAfter)
+	}
+	if o.BaseDelay <= 0 {
+		return 0
+	}
+
+	backoff := o.capDelay(o.BaseDelay << min(retry, 30))
+	//nolint:gosec // no need for a cryptographically secure jitter
+	return time.Duration(rand.Int64N(int64(backoff) + 1))
+}
+
+func (o RetryOptions) capDelay(d time.Duration) time.Duration {
+	if o.MaxDelay > 0 && d > o.MaxDelay {
+		return o.MaxDelay
+	}
+	return d
+}
+
+// Parses the Retry-After header,
+// which is either a number of seconds or an HTTP date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if len(header) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
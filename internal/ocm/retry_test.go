@@ -0,0 +1,64 @@
+package ocm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldRetry(t *testing.T) {
+	tests := []struct {
+		method     string
+		statusCode int
+		expected   bool
+	}{
+		{http.MethodGet, 0, true},
+		{http.MethodGet, http.StatusInternalServerError, true},
+		{http.MethodGet, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusNotFound, false},
+		{http.MethodPatch, http.StatusBadGateway, true},
+		{http.MethodPost, 0, false},
+		{http.MethodPost, http.StatusInternalServerError, false},
+		{http.MethodPost, http.StatusServiceUnavailable, true},
+		{http.MethodPost, http.StatusTooManyRequests, true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, shouldRetry(test.method, test.statusCode),
+			"%s %d", test.method, test.statusCode)
+	}
+}
+
+func TestRetryOptions_Delay(t *testing.T) {
+	o := RetryOptions{
+		BaseDelay: time.Second,
+		MaxDelay:  5 * time.Second,
+	}
+
+	for retry, upper := range []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+	} {
+		d := o.delay(retry, 0)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, upper)
+	}
+
+	assert.Equal(t, 3*time.Second, o.delay(0, 3*time.Second))
+	assert.Equal(t, 5*time.Second, o.delay(0, time.Minute))
+	assert.Equal(t, time.Duration(0), RetryOptions{}.delay(2, 0))
+	assert.Equal(t, time.Minute, RetryOptions{}.delay(0, time.Minute))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 7*time.Second, parseRetryAfter("7", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-1", now))
+	assert.Equal(t, 30*time.Second,
+		parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0),
+		parseRetryAfter(now.Add(-time.Hour).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
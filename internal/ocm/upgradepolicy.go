@@ -15,6 +15,8 @@ const (
 	UpgradePolicyValueScheduled UpgradePolicyValue = "scheduled"
 	UpgradePolicyValueStarted   UpgradePolicyValue = "started"
 	UpgradePolicyValueCompleted UpgradePolicyValue = "completed"
+	UpgradePolicyValueFailed    UpgradePolicyValue = "failed"
+	UpgradePolicyValueDelayed   UpgradePolicyValue = "delayed"
 )
 
 type UpgradePolicyPatchRequest struct {
@@ -32,7 +34,7 @@ func (c *Client) PatchUpgradePolicy(
 	urlParams := url.Values{}
 	return res, c.do(ctx, http.MethodPatch, fmt.Sprintf(
 		"api/clusters_mgmt/v1/clusters/%s/addon_upgrade_policies/%s/state",
-		c.opts.ClusterID,
+		c.clusterID(),
 		req.ID,
 	),
 		urlParams,
@@ -57,7 +59,7 @@ func (c *Client) GetUpgradePolicy(
 	urlParams := url.Values{}
 	return res, c.do(ctx, http.MethodGet, fmt.Sprintf(
 		"api/clusters_mgmt/v1/clusters/%s/addon_upgrade_policies/%s/state",
-		c.opts.ClusterID,
+		c.clusterID(),
 		req.ID,
 	),
 		urlParams,
@@ -0,0 +1,659 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Key of the ConfigMap holding the JSON encoded entries.
+const EntriesKey = "entries"
+
+// ErrUndeliverable marks delivery errors that retrying will not fix.
+// Entries failing with it are dropped.
+var ErrUndeliverable = errors.New("undeliverable")
+
+// Reasons for dropping entries without delivering them.
+const (
+	// The outbox exceeded its size limit.
+	DropReasonFull = "full"
+	// Delivering the entry failed with ErrUndeliverable.
+	DropReasonUndeliverable = "undeliverable"
+)
+
+// Entry is a single queued report.
+type Entry struct {
+	// Orders entries, increasing with every enqueued entry.
+	Sequence uint64 `json:"sequence"`
+	// Type of the report, interpreted by the DeliverFunc.
+	Kind string `json:"kind"`
+	// Name of the Addon the report is about.
+	Addon   string          `json:"addon"`
+	Payload json.RawMessage `json:"payload"`
+	Created metav1.Time     `json:"created"`
+}
+
+// DeliverFunc sends an entry to its destination.
+type DeliverFunc func(ctx context.Context, entry Entry) error
+
+// BatchDeliverFunc sends consecutive entries of the same kind with a single request.
+// The batch is retried or dropped as a whole.
+type BatchDeliverFunc func(ctx context.Context, entries []Entry) error
+
+// DropFunc is called with entries dropped without being delivered.
+type DropFunc func(reason string, entries []Entry)
+
+// Metrics records the size of the backlog and how many entries were coalesced or dropped.
+type Metrics interface {
+	SetOCMOutboxBacklog(size int)
+	IncOCMOutboxCoalesced()
+	AddOCMOutboxDropped(reason string, count int)
+}
+
+// Throttle paces the delivery of entries.
+// The zero value delivers entries one at a time and as fast as possible.
+type Throttle struct {
+	// Sustained deliveries per second, unlimited when zero.
+	Rate float64
+	// Deliveries allowed in a row before Rate applies.
+	Burst int
+	// Entries of coalesced kinds are held back for this long after they were first enqueued,
+	// newer entries for the same Addon replace them in the meantime.
+	CoalesceWindow time.Duration
+	// Maximum number of entries of a batched kind delivered with a single request.
+	MaxBatchSize int
+}
+
+// Outbox is a durable queue of reports, persisted in a ConfigMap.
+// Entries of the same Addon are delivered in order,
+// a failing entry is retried with exponential backoff
+// and blocks the entries of its Addon enqueued after it,
+// while entries of other Addons are delivered in the meantime.
+//
+// The queue is read from the ConfigMap once and then owned by this process,
+// so it must only be started by the leader.
+type Outbox struct {
+	client  client.Client
+	key     client.ObjectKey
+	deliver DeliverFunc
+	opts    Options
+	limiter *rate.Limiter
+
+	lock         sync.Mutex
+	loaded       bool
+	entries      []Entry
+	nextSequence uint64
+	throttle     Throttle
+	// Addons whose last delivery failed, kept in memory only.
+	backoffs map[string]addonBackoff
+	notify   chan struct{}
+}
+
+type addonBackoff struct {
+	delay time.Duration
+	until time.Time
+}
+
+type Options struct {
+	Log     logr.Logger
+	Metrics Metrics
+	// Oldest entries are dropped when their JSON encoding exceeds this many bytes,
+	// to stay well within the 1 MiB size limit of a ConfigMap.
+	MaxBytes   int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Kinds of which only the latest entry per Addon within the
+	// coalesce window of the Throttle is kept, because it supersedes all earlier ones.
+	CoalescedKinds []string
+	// Kinds delivered in batches through BatchDeliver.
+	BatchedKinds []string
+	BatchDeliver BatchDeliverFunc
+	// Initial pace of deliveries, see SetThrottle.
+	Throttle Throttle
+	// Notified about dropped entries, e.g. to enqueue them again later.
+	OnDrop DropFunc
+}
+
+type Option func(o *Options)
+
+func WithLog(log logr.Logger) Option {
+	return func(o *Options) {
+		o.Log = log
+	}
+}
+
+func WithMetrics(metrics Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = metrics
+	}
+}
+
+func WithMaxBytes(maxBytes int) Option {
+	return func(o *Options) {
+		o.MaxBytes = maxBytes
+	}
+}
+
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(o *Options) {
+		o.MinBackoff = minBackoff
+		o.MaxBackoff = maxBackoff
+	}
+}
+
+// Replaces pending entries of the given kinds with newer entries for the same Addon,
+// while the pending entries are within the coalesce window.
+func WithCoalescing(kinds ...string) Option {
+	return func(o *Options) {
+		o.CoalescedKinds = kinds
+	}
+}
+
+// Delivers entries of the given kinds through deliver,
+// when the Throttle allows batches of more than one entry.
+func WithBatchDelivery(deliver BatchDeliverFunc, kinds ...string) Option {
+	return func(o *Options) {
+		o.BatchDeliver = deliver
+		o.BatchedKinds = kinds
+	}
+}
+
+func WithThrottle(throttle Throttle) Option {
+	return func(o *Options) {
+		o.Throttle = throttle
+	}
+}
+
+func WithDropHandler(onDrop DropFunc) Option {
+	return func(o *Options) {
+		o.OnDrop = onDrop
+	}
+}
+
+// Creates an Outbox persisted in the ConfigMap with the given key.
+func New(c client.Client, key client.ObjectKey, deliver DeliverFunc, opts ...Option) *Outbox {
+	o := &Outbox{
+		client:  c,
+		key:     key,
+		deliver: deliver,
+		opts: Options{
+			Log:        logr.Discard(),
+			MaxBytes:   512 << 10,
+			MinBackoff: time.Second,
+			MaxBackoff: 5 * time.Minute,
+		},
+		backoffs: map[string]addonBackoff{},
+		notify:   make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(&o.opts)
+	}
+	// Starts with a full bucket.
+	o.limiter = rate.NewLimiter(throttleLimit(o.opts.Throttle))
+	o.throttle = o.opts.Throttle
+	return o
+}
+
+// SetThrottle changes the pace of deliveries, also while the Outbox is running.
+func (o *Outbox) SetThrottle(throttle Throttle) {
+	o.lock.Lock()
+	o.throttle = throttle
+	o.lock.Unlock()
+
+	limit, burst := throttleLimit(throttle)
+	o.limiter.SetLimit(limit)
+	o.limiter.SetBurst(burst)
+	o.wake()
+}
+
+func throttleLimit(throttle Throttle) (rate.Limit, int) {
+	if throttle.Rate <= 0 {
+		return rate.Inf, 0
+	}
+	return rate.Limit(throttle.Rate), max(throttle.Burst, 1)
+}
+
+// Enqueue persists a new entry with the JSON encoded payload.
+// The entry is discarded again, if it could not be persisted.
+func (o *Outbox) Enqueue(ctx context.Context, kind, addon string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+	if o.opts.MaxBytes > 0 && len(data) > o.opts.MaxBytes {
+		return fmt.Errorf("payload of %d bytes exceeds the outbox limit of %d bytes", len(data), o.opts.MaxBytes)
+	}
+
+	dropped, err := o.enqueue(ctx, kind, addon, data)
+	if err != nil {
+		return err
+	}
+	if len(dropped) > 0 {
+		o.opts.Log.Info("outbox full, dropped oldest entries", "dropped", len(dropped))
+		o.drop(DropReasonFull, dropped)
+	}
+	return nil
+}
+
+// Persists the new entry and returns the entries dropped to make room for it.
+func (o *Outbox) enqueue(ctx context.Context, kind, addon string, data []byte) ([]Entry, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if err := o.load(ctx); err != nil {
+		return nil, err
+	}
+
+	previous := o.entries
+	i := o.pendingIndex(kind, addon)
+	if i >= 0 {
+		// Keeps the position and creation time of the pending entry,
+		// so frequent changes can't hold back the report forever.
+		// The new sequence keeps an in-flight delivery of the old payload
+		// from removing the entry.
+		o.entries = slices.Clone(o.entries)
+		o.entries[i].Payload = data
+		o.entries[i].Sequence = o.nextSequence
+	} else {
+		o.entries = append(o.entries[:len(o.entries):len(o.entries)], Entry{
+			Sequence: o.nextSequence,
+			Kind:     kind,
+			Addon:    addon,
+			Payload:  data,
+			Created:  metav1.Now(),
+		})
+	}
+	untrimmed := o.entries
+	dropped, err := o.trim()
+	if err != nil {
+		o.entries = previous
+		return nil, err
+	}
+	if err := o.persist(ctx); err != nil {
+		o.entries = previous
+		return nil, err
+	}
+	o.nextSequence++
+
+	if i >= 0 && o.opts.Metrics != nil {
+		o.opts.Metrics.IncOCMOutboxCoalesced()
+	}
+	o.recordBacklog()
+	o.wake()
+	return untrimmed[:dropped], nil
+}
+
+// Drops the oldest entries until the JSON encoded entries fit into MaxBytes,
+// the newest entry is always kept.
+// Returns the number of dropped entries.
+// Must be called with the lock held.
+func (o *Outbox) trim() (int, error) {
+	if o.opts.MaxBytes <= 0 {
+		return 0, nil
+	}
+
+	// Brackets of the list, plus a separator per entry.
+	total := 2
+	sizes := make([]int, len(o.entries))
+	for i, entry := range o.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return 0, fmt.Errorf("marshaling outbox entry: %w", err)
+		}
+		sizes[i] = len(data) + 1
+		total += sizes[i]
+	}
+
+	var dropped int
+	for total > o.opts.MaxBytes && dropped < len(o.entries)-1 {
+		total -= sizes[dropped]
+		dropped++
+	}
+	o.entries = o.entries[dropped:]
+	return dropped, nil
+}
+
+// Returns the index of the pending entry the given entry may replace, or -1.
+// Only the last entry of the Addon is replaced, when it is of the same kind,
+// so the replacement can't overtake entries of other kinds enqueued in between.
+// Only entries still within their coalesce window are replaced,
+// older ones are kept, so every change outside of the window is delivered.
+// Must be called with the lock held.
+func (o *Outbox) pendingIndex(kind, addon string) int {
+	if !slices.Contains(o.opts.CoalescedKinds, kind) {
+		return -1
+	}
+	for i := len(o.entries) - 1; i >= 0; i-- {
+		if o.entries[i].Addon != addon {
+			continue
+		}
+		if o.entries[i].Kind != kind || o.heldBack(o.entries[i]) <= 0 {
+			return -1
+		}
+		return i
+	}
+	return -1
+}
+
+func (o *Outbox) wake() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the number of entries waiting for delivery.
+func (o *Outbox) Len() int {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return len(o.entries)
+}
+
+// Start delivers entries until the context is canceled.
+// Implements manager.Runnable.
+func (o *Outbox) Start(ctx context.Context) error {
+	log := o.opts.Log
+
+	for {
+		batch, wait, err := o.next(ctx)
+		switch {
+		case err != nil:
+			log.Error(err, "loading outbox")
+			if !o.sleep(ctx, o.opts.MinBackoff) {
+				return nil
+			}
+			continue
+		case len(batch) == 0:
+			// Empty or all entries are held back or backing off,
+			// new entries and throttle changes are picked up right away.
+			if !o.sleep(ctx, wait) {
+				return nil
+			}
+			continue
+		}
+
+		// Waiting for a token also picks up throttle changes
+		// and entries coalesced in the meantime.
+		if wait := o.reserve(); wait > 0 {
+			if !o.sleep(ctx, wait) {
+				return nil
+			}
+			continue
+		}
+		head := batch[0]
+		err = o.deliverBatch(ctx, batch)
+		if errors.Is(err, ErrUndeliverable) {
+			log.Error(err, "dropping undeliverable outbox entries",
+				"kind", head.Kind, "addon", head.Addon, "entries", len(batch))
+			o.drop(DropReasonUndeliverable, batch)
+			err = nil
+		}
+		if err == nil {
+			o.remove(ctx, batch)
+			continue
+		}
+		backoff := o.backOff(batch)
+		log.Info("delivering outbox entries failed, retrying",
+			"kind", head.Kind, "addon", head.Addon, "entries", len(batch),
+			"backoff", backoff, "error", err.Error())
+	}
+}
+
+// Delays further deliveries for the Addons of a failed batch
+// with exponential backoff and returns the longest delay.
+func (o *Outbox) backOff(batch []Entry) time.Duration {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	var longest time.Duration
+	seen := map[string]struct{}{}
+	for _, entry := range batch {
+		if _, ok := seen[entry.Addon]; ok {
+			continue
+		}
+		seen[entry.Addon] = struct{}{}
+
+		b, ok := o.backoffs[entry.Addon]
+		if ok {
+			b.delay = min(b.delay*2, o.opts.MaxBackoff)
+		} else {
+			b.delay = o.opts.MinBackoff
+		}
+		b.until = time.Now().Add(b.delay)
+		o.backoffs[entry.Addon] = b
+		longest = max(longest, b.delay)
+	}
+	return longest
+}
+
+// Takes a token for the next delivery,
+// or returns how long it takes until one is available.
+func (o *Outbox) reserve() time.Duration {
+	r := o.limiter.Reserve()
+	if delay := r.Delay(); delay > 0 {
+		r.Cancel()
+		return delay
+	}
+	return 0
+}
+
+// Waits for a notification, the context or the timeout, if positive.
+// Returns false when the context is canceled.
+func (o *Outbox) sleep(ctx context.Context, timeout time.Duration) bool {
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-o.notify:
+	case <-timeoutC:
+	}
+	return true
+}
+
+func (o *Outbox) deliverBatch(ctx context.Context, batch []Entry) error {
+	if len(batch) == 1 {
+		return o.deliver(ctx, batch[0])
+	}
+	return o.opts.BatchDeliver(ctx, batch)
+}
+
+// Returns the entries to deliver next, or how long to wait
+// when all entries are held back within the coalesce window or backing off.
+// Blocked entries also block the later entries of their Addon,
+// so the entries of every Addon are delivered in order.
+func (o *Outbox) next(ctx context.Context) ([]Entry, time.Duration, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if err := o.load(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		batch   []Entry
+		wait    time.Duration
+		blocked = map[string]struct{}{}
+	)
+	for _, entry := range o.entries {
+		if _, ok := blocked[entry.Addon]; ok {
+			continue
+		}
+		if entryWait := o.blockedFor(entry); entryWait > 0 {
+			blocked[entry.Addon] = struct{}{}
+			if wait == 0 || entryWait < wait {
+				wait = entryWait
+			}
+			continue
+		}
+
+		if len(batch) == 0 {
+			batch = append(batch, entry)
+			if o.opts.BatchDeliver == nil || !slices.Contains(o.opts.BatchedKinds, entry.Kind) {
+				return batch, 0, nil
+			}
+			continue
+		}
+		if len(batch) >= o.throttle.MaxBatchSize {
+			break
+		}
+		if entry.Kind != batch[0].Kind {
+			// Later entries of this Addon must not overtake it.
+			blocked[entry.Addon] = struct{}{}
+			continue
+		}
+		batch = append(batch, entry)
+	}
+	if len(batch) > 0 {
+		return batch, 0, nil
+	}
+	return nil, wait, nil
+}
+
+// Returns how long the entry can't be delivered yet,
+// because it is held back or its Addon is backing off.
+// Must be called with the lock held.
+func (o *Outbox) blockedFor(entry Entry) time.Duration {
+	wait := o.heldBack(entry)
+	if b, ok := o.backoffs[entry.Addon]; ok {
+		wait = max(wait, time.Until(b.until))
+	}
+	return wait
+}
+
+// Returns the time left in the coalesce window of the entry.
+// Must be called with the lock held.
+func (o *Outbox) heldBack(entry Entry) time.Duration {
+	if o.throttle.CoalesceWindow <= 0 || !slices.Contains(o.opts.CoalescedKinds, entry.Kind) {
+		return 0
+	}
+	return time.Until(entry.Created.Add(o.throttle.CoalesceWindow))
+}
+
+// Removes delivered entries.
+// Persisting errors are only logged, the entries are written out
+// with the next change and redelivered after a restart at worst.
+func (o *Outbox) remove(ctx context.Context, delivered []Entry) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for _, entry := range delivered {
+		delete(o.backoffs, entry.Addon)
+	}
+
+	// Entries may have been dropped or replaced while they were being delivered.
+	remaining := slices.DeleteFunc(slices.Clone(o.entries), func(entry Entry) bool {
+		return slices.ContainsFunc(delivered, func(d Entry) bool {
+			return d.Sequence == entry.Sequence
+		})
+	})
+	if len(remaining) == len(o.entries) {
+		return
+	}
+	o.entries = remaining
+	o.recordBacklog()
+	if err := o.persist(ctx); err != nil {
+		o.opts.Log.Error(err, "persisting outbox")
+	}
+}
+
+// Reads the entries from the ConfigMap, unless already done.
+// Must be called with the lock held.
+func (o *Outbox) load(ctx context.Context) error {
+	if o.loaded {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := o.client.Get(ctx, o.key, cm)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("getting outbox ConfigMap: %w", err)
+	}
+
+	var entries []Entry
+	if data := cm.Data[EntriesKey]; len(data) > 0 {
+		if err := json.Unmarshal([]byte(data), &entries); err != nil {
+			// Starting over beats blocking all reporting.
+			o.opts.Log.Error(err, "discarding corrupt outbox")
+			entries = nil
+		}
+	}
+
+	o.entries = entries
+	// Coalesced entries take new sequences out of order.
+	for _, entry := range entries {
+		o.nextSequence = max(o.nextSequence, entry.Sequence+1)
+	}
+	o.loaded = true
+	o.recordBacklog()
+	return nil
+}
+
+// Writes the entries to the ConfigMap.
+// Must be called with the lock held.
+func (o *Outbox) persist(ctx context.Context) error {
+	data, err := json.Marshal(o.entries)
+	if err != nil {
+		return fmt.Errorf("marshaling outbox entries: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	err = o.client.Get(ctx, o.key, cm)
+	if k8serrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      o.key.Name,
+				Namespace: o.key.Namespace,
+			},
+			Data: map[string]string{EntriesKey: string(data)},
+		}
+		if err := o.client.Create(ctx, cm); err != nil {
+			return fmt.Errorf("creating outbox ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting outbox ConfigMap: %w", err)
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[EntriesKey] = string(data)
+	if err := o.client.Update(ctx, cm); err != nil {
+		return fmt.Errorf("updating outbox ConfigMap: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) recordBacklog() {
+	if o.opts.Metrics != nil {
+		o.opts.Metrics.SetOCMOutboxBacklog(len(o.entries))
+	}
+}
+
+// Records and reports dropped entries.
+// Must be called without the lock held, the handler may enqueue entries again.
+func (o *Outbox) drop(reason string, entries []Entry) {
+	if o.opts.Metrics != nil {
+		o.opts.Metrics.AddOCMOutboxDropped(reason, len(entries))
+	}
+	if o.opts.OnDrop != nil {
+		o.opts.OnDrop(reason, entries)
+	}
+}
@@ -0,0 +1,365 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var testKey = client.ObjectKey{Name: "outbox", Namespace: "addon-operator"}
+
+func newTestClient(t *testing.T) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+type testMetrics struct {
+	lock      sync.Mutex
+	backlog   int
+	coalesced int
+	dropped   map[string]int
+}
+
+func (m *testMetrics) AddOCMOutboxDropped(reason string, count int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.dropped == nil {
+		m.dropped = map[string]int{}
+	}
+	m.dropped[reason] += count
+}
+
+func (m *testMetrics) IncOCMOutboxCoalesced() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.coalesced++
+}
+
+func (m *testMetrics) SetOCMOutboxBacklog(size int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.backlog = size
+}
+
+func TestOutbox_EnqueuePersists(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	o := New(c, testKey, nil)
+	require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", map[string]string{"state": "a"}))
+	require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", map[string]string{"state": "b"}))
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, c.Get(ctx, testKey, cm))
+	assert.Contains(t, cm.Data[EntriesKey], `"payload":{"state":"b"}`)
+
+	// Picked up after a restart.
+	m := &testMetrics{}
+	restarted := New(c, testKey, nil, WithMetrics(m))
+	require.NoError(t, restarted.Enqueue(ctx, "Test", "addon-2", map[string]string{"state": "c"}))
+	assert.Equal(t, 3, restarted.Len())
+	assert.Equal(t, 3, m.backlog)
+
+	batch, _, err := restarted.next(ctx)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, uint64(0), batch[0].Sequence)
+	assert.Equal(t, uint64(2), restarted.entries[2].Sequence)
+}
+
+func TestOutbox_MaxBytes(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	entry, err := json.Marshal(Entry{
+		Kind: "Test", Addon: "addon-1", Payload: json.RawMessage(`"a"`), Created: metav1.Now(),
+	})
+	require.NoError(t, err)
+	// Room for two entries.
+	maxBytes := 2 + 2*(len(entry)+1)
+
+	m := &testMetrics{}
+	var dropped []Entry
+	o := New(c, testKey, nil, WithMetrics(m), WithMaxBytes(maxBytes),
+		WithDropHandler(func(reason string, entries []Entry) {
+			assert.Equal(t, DropReasonFull, reason)
+			dropped = append(dropped, entries...)
+		}))
+	for _, state := range []string{"a", "b", "c"} {
+		require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", state))
+	}
+
+	require.Equal(t, 2, o.Len())
+	assert.Equal(t, `"b"`, string(o.entries[0].Payload))
+	assert.Equal(t, `"c"`, string(o.entries[1].Payload))
+	assert.Equal(t, map[string]int{DropReasonFull: 1}, m.dropped)
+	require.Len(t, dropped, 1)
+	assert.Equal(t, `"a"`, string(dropped[0].Payload))
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, c.Get(ctx, testKey, cm))
+	assert.LessOrEqual(t, len(cm.Data[EntriesKey]), maxBytes)
+
+	// A single payload over the limit is rejected.
+	require.Error(t, o.Enqueue(ctx, "Test", "addon-1", string(make([]byte, maxBytes))))
+	assert.Equal(t, 2, o.Len())
+}
+
+func TestOutbox_Start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		lock      sync.Mutex
+		delivered []string
+		attempts  int
+	)
+	deliver := func(_ context.Context, entry Entry) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		attempts++
+		switch string(entry.Payload) {
+		case `"flaky"`:
+			if attempts == 1 {
+				return errors.New("explosion")
+			}
+		case `"broken"`:
+			return ErrUndeliverable
+		}
+		delivered = append(delivered, string(entry.Payload))
+		return nil
+	}
+
+	m := &testMetrics{}
+	var dropped []string
+	o := New(newTestClient(t), testKey, deliver,
+		WithMetrics(m), WithBackoff(time.Millisecond, time.Millisecond),
+		WithDropHandler(func(reason string, entries []Entry) {
+			assert.Equal(t, DropReasonUndeliverable, reason)
+			for _, entry := range entries {
+				dropped = append(dropped, string(entry.Payload))
+			}
+		}))
+	for _, payload := range []string{"flaky", "broken", "ok"} {
+		require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", payload))
+	}
+
+	done := make(chan error)
+	go func() { done <- o.Start(ctx) }()
+
+	require.Eventually(t, func() bool { return o.Len() == 0 }, 5*time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []string{`"flaky"`, `"ok"`}, delivered)
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, 0, m.backlog)
+	assert.Equal(t, map[string]int{DropReasonUndeliverable: 1}, m.dropped)
+	assert.Equal(t, []string{`"broken"`}, dropped)
+}
+
+func TestOutbox_StartBacksOffPerAddon(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		lock      sync.Mutex
+		delivered []string
+	)
+	deliver := func(_ context.Context, entry Entry) error {
+		if entry.Addon == "addon-1" {
+			return errors.New("explosion")
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		delivered = append(delivered, string(entry.Payload))
+		return nil
+	}
+
+	o := New(newTestClient(t), testKey, deliver, WithBackoff(time.Hour, time.Hour))
+	require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", "a"))
+	require.NoError(t, o.Enqueue(ctx, "Test", "addon-2", "b"))
+	require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", "c"))
+	require.NoError(t, o.Enqueue(ctx, "Test", "addon-2", "d"))
+
+	done := make(chan error)
+	go func() { done <- o.Start(ctx) }()
+
+	// The failing Addon does not block the others.
+	require.Eventually(t, func() bool { return o.Len() == 2 }, 5*time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []string{`"b"`, `"d"`}, delivered)
+	assert.Equal(t, `"a"`, string(o.entries[0].Payload))
+	assert.Equal(t, `"c"`, string(o.entries[1].Payload))
+
+	_, wait, err := o.next(ctx)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, wait, float64(time.Minute))
+}
+
+func TestOutbox_Coalescing(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	m := &testMetrics{}
+	o := New(c, testKey, nil, WithMetrics(m),
+		WithCoalescing("Status"), WithThrottle(Throttle{CoalesceWindow: time.Hour}))
+
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "a"))
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-2", "a"))
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "b"))
+	require.NoError(t, o.Enqueue(ctx, "Policy", "addon-1", "a"))
+	require.NoError(t, o.Enqueue(ctx, "Policy", "addon-1", "b"))
+
+	require.Equal(t, 4, o.Len())
+	assert.Equal(t, 1, m.coalesced)
+	// Replaced in place with a new sequence.
+	assert.Equal(t, "addon-1", o.entries[0].Addon)
+	assert.Equal(t, `"b"`, string(o.entries[0].Payload))
+	assert.Equal(t, uint64(2), o.entries[0].Sequence)
+
+	// Must not overtake the Policy entries enqueued before it.
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "c"))
+	require.Equal(t, 5, o.Len())
+	assert.Equal(t, 1, m.coalesced)
+	assert.Equal(t, "Status", o.entries[4].Kind)
+	assert.Equal(t, `"c"`, string(o.entries[4].Payload))
+
+	// Sequences stay unique after a restart.
+	restarted := New(c, testKey, nil)
+	require.NoError(t, restarted.Enqueue(ctx, "Status", "addon-3", "a"))
+	assert.Equal(t, uint64(6), restarted.entries[5].Sequence)
+}
+
+func TestOutbox_CoalesceWindow(t *testing.T) {
+	ctx := context.Background()
+	o := New(newTestClient(t), testKey, nil,
+		WithCoalescing("Status"), WithThrottle(Throttle{CoalesceWindow: time.Hour}))
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "a"))
+
+	batch, wait, err := o.next(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, batch)
+	assert.InDelta(t, time.Hour, wait, float64(time.Minute))
+
+	o.SetThrottle(Throttle{})
+	batch, _, err = o.next(ctx)
+	require.NoError(t, err)
+	assert.Len(t, batch, 1)
+}
+
+func TestOutbox_CoalesceOnlyWithinWindow(t *testing.T) {
+	ctx := context.Background()
+	m := &testMetrics{}
+	o := New(newTestClient(t), testKey, nil, WithMetrics(m),
+		WithCoalescing("Status"), WithThrottle(Throttle{CoalesceWindow: time.Minute}))
+
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "a"))
+	// Waiting for delivery beyond the window, e.g. while OCM is unavailable.
+	o.entries[0].Created = metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "b"))
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "c"))
+
+	require.Equal(t, 2, o.Len())
+	assert.Equal(t, 1, m.coalesced)
+	assert.Equal(t, `"a"`, string(o.entries[0].Payload))
+	assert.Equal(t, `"c"`, string(o.entries[1].Payload))
+
+	// Without window, every entry is kept.
+	o.SetThrottle(Throttle{})
+	require.NoError(t, o.Enqueue(ctx, "Status", "addon-1", "d"))
+	assert.Equal(t, 3, o.Len())
+}
+
+func TestOutbox_StartBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		lock      sync.Mutex
+		delivered [][]string
+	)
+	record := func(entries ...Entry) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		var payloads []string
+		for _, entry := range entries {
+			payloads = append(payloads, string(entry.Payload))
+		}
+		delivered = append(delivered, payloads)
+	}
+	deliver := func(_ context.Context, entry Entry) error {
+		record(entry)
+		return nil
+	}
+	deliverBatch := func(_ context.Context, entries []Entry) error {
+		record(entries...)
+		return nil
+	}
+
+	o := New(newTestClient(t), testKey, deliver,
+		WithBatchDelivery(deliverBatch, "Status"),
+		WithThrottle(Throttle{MaxBatchSize: 2}))
+	for i, kind := range []string{"Status", "Status", "Status", "Policy", "Status"} {
+		require.NoError(t, o.Enqueue(ctx, kind, "addon-1", i))
+	}
+
+	done := make(chan error)
+	go func() { done <- o.Start(ctx) }()
+
+	require.Eventually(t, func() bool { return o.Len() == 0 }, 5*time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, [][]string{{"0", "1"}, {"2"}, {"3"}, {"4"}}, delivered)
+}
+
+func TestOutbox_StartRateLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var delivered atomic.Int32
+	deliver := func(_ context.Context, _ Entry) error {
+		delivered.Add(1)
+		return nil
+	}
+
+	o := New(newTestClient(t), testKey, deliver,
+		WithThrottle(Throttle{Rate: 0.1, Burst: 2}))
+	for _, payload := range []string{"a", "b", "c"} {
+		require.NoError(t, o.Enqueue(ctx, "Test", "addon-1", payload))
+	}
+
+	done := make(chan error)
+	go func() { done <- o.Start(ctx) }()
+
+	// The burst is delivered right away, the next token takes 10s.
+	require.Eventually(t, func() bool { return delivered.Load() == 2 }, 5*time.Second, time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(2), delivered.Load())
+
+	// Lifting the limit applies to the waiting delivery.
+	o.SetThrottle(Throttle{})
+	require.Eventually(t, func() bool { return o.Len() == 0 }, 5*time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+}
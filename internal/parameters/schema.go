@@ -0,0 +1,72 @@
+package parameters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/yaml"
+)
+
+var ErrNotAnObject = errors.New("parameters must be an object")
+
+// Schema validates Addon parameters.
+// References ($ref) are not supported.
+type Schema struct {
+	schema *spec.Schema
+}
+
+// Parses a JSON or YAML encoded JSON schema.
+func NewSchema(data []byte) (*Schema, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding schema: %w", err)
+	}
+	schema := &spec.Schema{}
+	if err := json.Unmarshal(jsonData, schema); err != nil {
+		return nil, fmt.Errorf("decoding schema: %w", err)
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// Validate checks the JSON encoded parameters against the schema.
+// Empty parameters are validated as an empty object,
+// so required properties are enforced.
+func (s *Schema) Validate(parameters []byte) (err error) {
+	obj, err := Decode(parameters)
+	if err != nil {
+		return err
+	}
+
+	// The validator panics on schemas it does not support.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unsupported schema: %v", r)
+		}
+	}()
+	return validate.AgainstSchema(s.schema, obj, strfmt.Default)
+}
+
+// Decode unmarshals the JSON encoded parameters into a map.
+// Empty parameters decode into an empty map.
+func Decode(parameters []byte) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if len(parameters) == 0 {
+		return obj, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(parameters, &v); err != nil {
+		return nil, fmt.Errorf("decoding parameters: %w", err)
+	}
+	switch o := v.(type) {
+	case map[string]interface{}:
+		return o, nil
+	case nil:
+		return obj, nil
+	default:
+		return nil, ErrNotAnObject
+	}
+}
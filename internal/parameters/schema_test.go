@@ -0,0 +1,85 @@
+package parameters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+type: object
+required: [replicas]
+properties:
+  replicas:
+    type: integer
+    minimum: 1
+  logLevel:
+    type: string
+    enum: [debug, info]
+additionalProperties: false
+`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := NewSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		parameters string
+		valid      bool
+	}{
+		"valid": {
+			parameters: `{"replicas": 2, "logLevel": "info"}`,
+			valid:      true,
+		},
+		"missing required": {
+			parameters: `{"logLevel": "info"}`,
+		},
+		"empty": {},
+		"wrong type": {
+			parameters: `{"replicas": "two"}`,
+		},
+		"below minimum": {
+			parameters: `{"replicas": 0}`,
+		},
+		"unknown property": {
+			parameters: `{"replicas": 1, "color": "blue"}`,
+		},
+		"not an object": {
+			parameters: `[1, 2]`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := schema.Validate([]byte(tc.parameters))
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateUnsupported(t *testing.T) {
+	schema, err := NewSchema([]byte(`{"properties": {"a": {"$ref": "#/definitions/a"}}}`))
+	require.NoError(t, err)
+	assert.ErrorContains(t, schema.Validate([]byte(`{"a": 1}`)), "unsupported schema")
+}
+
+func TestNewSchema_Invalid(t *testing.T) {
+	_, err := NewSchema([]byte(`{"type": 1}`))
+	assert.Error(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	obj, err := Decode(nil)
+	require.NoError(t, err)
+	assert.Empty(t, obj)
+
+	obj, err = Decode([]byte(`{"replicas": 2}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(2)}, obj)
+
+	_, err = Decode([]byte(`"replicas"`))
+	assert.ErrorIs(t, err, ErrNotAnObject)
+}
@@ -0,0 +1,218 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/addon-operator/internal/version"
+)
+
+// Upper bound of a query response, health checks are expected to return a handful of samples.
+const maxResponseSize = 4 << 20
+
+// Client runs instant PromQL queries against the HTTP API
+// of Prometheus or a compatible service like the Thanos Querier.
+type Client struct {
+	address    *url.URL
+	opts       ClientOptions
+	httpClient *http.Client
+}
+
+// Creates a new Prometheus client for the API at the given address.
+func NewClient(address string, opts ...Option) (*Client, error) {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing address: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("address %q: scheme must be http or https", address)
+	}
+	c.address = u
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(c.opts.CAFile) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		ca, err := os.ReadFile(c.opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.opts.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	c.httpClient = &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	}
+	return c, nil
+}
+
+type ClientOptions struct {
+	// File containing a bearer token to authenticate with.
+	// Read on every request, so rotated tokens are picked up.
+	BearerTokenFile string
+	// PEM encoded CA bundle to verify the server certificate with,
+	// in addition to the system trust store.
+	CAFile string
+}
+
+type Option func(o *ClientOptions)
+
+func WithBearerTokenFile(path string) Option {
+	return func(o *ClientOptions) {
+		o.BearerTokenFile = path
+	}
+}
+
+func WithCAFile(path string) Option {
+	return func(o *ClientOptions) {
+		o.CAFile = path
+	}
+}
+
+type APIError struct {
+	StatusCode int
+	ErrorType  string
+	Message    string
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s: %s", e.StatusCode, e.ErrorType, e.Message)
+}
+
+type queryResponse struct {
+	Status    string    `json:"status"`
+	Data      queryData `json:"data"`
+	ErrorType string    `json:"errorType"`
+	Error     string    `json:"error"`
+}
+
+type queryData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type vectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []any             `json:"value"`
+}
+
+// Healthy runs the given query and reports whether it returned
+// at least one sample with a value other than zero.
+// An empty result is treated as unhealthy.
+func (c *Client) Healthy(ctx context.Context, query string) (bool, error) {
+	data, err := c.query(ctx, query)
+	if err != nil {
+		return false, err
+	}
+
+	var values [][]any
+	switch data.ResultType {
+	case "vector":
+		var samples []vectorSample
+		if err := json.Unmarshal(data.Result, &samples); err != nil {
+			return false, fmt.Errorf("decoding vector result: %w", err)
+		}
+		for _, s := range samples {
+			values = append(values, s.Value)
+		}
+	case "scalar":
+		var value []any
+		if err := json.Unmarshal(data.Result, &value); err != nil {
+			return false, fmt.Errorf("decoding scalar result: %w", err)
+		}
+		values = append(values, value)
+	default:
+		return false, fmt.Errorf("unsupported result type %q", data.ResultType)
+	}
+
+	for _, v := range values {
+		f, err := sampleValue(v)
+		if err != nil {
+			return false, err
+		}
+		if f != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *Client) query(ctx context.Context, query string) (queryData, error) {
+	u := c.address.JoinPath("api", "v1", "query")
+	u.RawQuery = url.Values{"query": []string{query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return queryData{}, err
+	}
+	req.Header.Set("User-Agent", "addon-operator/"+version.Version)
+	if len(c.opts.BearerTokenFile) > 0 {
+		token, err := os.ReadFile(c.opts.BearerTokenFile)
+		if err != nil {
+			return queryData{}, fmt.Errorf("reading bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return queryData{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return queryData{}, fmt.Errorf("reading response: %w", err)
+	}
+
+	var qr queryResponse
+	if err := json.Unmarshal(body, &qr); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return queryData{}, APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		}
+		return queryData{}, fmt.Errorf("decoding response: %w", err)
+	}
+	if qr.Status != "success" {
+		return queryData{}, APIError{
+			StatusCode: resp.StatusCode,
+			ErrorType:  qr.ErrorType,
+			Message:    qr.Error,
+		}
+	}
+	return qr.Data, nil
+}
+
+// Samples are encoded as [<unix time>, "<value>"].
+func sampleValue(v []any) (float64, error) {
+	if len(v) != 2 {
+		return 0, fmt.Errorf("malformed sample %v", v)
+	}
+	s, ok := v[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("malformed sample value %v", v[1])
+	}
+	return strconv.ParseFloat(s, 64)
+}
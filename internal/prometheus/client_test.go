@@ -0,0 +1,95 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestPrometheus(t *testing.T, responses map[string]string) string {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/query" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer t0ken" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[req.URL.Query().Get("query")]
+		if !ok {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		_, _ = rw.Write([]byte(response))
+	}))
+	t.Cleanup(s.Close)
+	return s.URL
+}
+
+func writeTokenFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("t0ken\n"), 0o600))
+	return path
+}
+
+func TestClientHealthy(t *testing.T) {
+	address := startTestPrometheus(t, map[string]string{
+		"up == 1": `{"status":"success","data":{"resultType":"vector","result":[` +
+			`{"metric":{"job":"a"},"value":[1700000000,"0"]},` +
+			`{"metric":{"job":"b"},"value":[1700000000,"1"]}]}}`,
+		"up == 0":   `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"vector(0)": `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"0"]}]}}`,
+		"scalar(1)": `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`,
+	})
+
+	c, err := NewClient(address, WithBearerTokenFile(writeTokenFile(t)))
+	require.NoError(t, err)
+
+	for query, expected := range map[string]bool{
+		"up == 1":   true,
+		"up == 0":   false,
+		"vector(0)": false,
+		"scalar(1)": true,
+	} {
+		t.Run(query, func(t *testing.T) {
+			healthy, err := c.Healthy(context.Background(), query)
+			require.NoError(t, err)
+			assert.Equal(t, expected, healthy)
+		})
+	}
+}
+
+func TestClientHealthy_Errors(t *testing.T) {
+	address := startTestPrometheus(t, map[string]string{})
+
+	c, err := NewClient(address, WithBearerTokenFile(writeTokenFile(t)))
+	require.NoError(t, err)
+	_, err = c.Healthy(context.Background(), "up{")
+	var apiErr APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "bad_data", apiErr.ErrorType)
+
+	unauthenticated, err := NewClient(address)
+	require.NoError(t, err)
+	_, err = unauthenticated.Healthy(context.Background(), "up")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestNewClient_InvalidAddress(t *testing.T) {
+	_, err := NewClient("thanos-querier:9091")
+	require.Error(t, err)
+}
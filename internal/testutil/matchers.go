@@ -7,6 +7,7 @@ import (
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,4 +44,10 @@ var (
 	// misc
 	IsContext   = mock.IsType(context.Background())
 	IsObjectKey = mock.IsType(client.ObjectKey{})
+
+	// tracing
+	// Matches contexts carrying a trace span, as passed down by traced calls.
+	IsTracedContext = mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanFromContext(ctx) != trace.SpanFromContext(context.Background())
+	})
 )
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openshift/addon-operator/internal/version"
+)
+
+const (
+	serviceName = "addon-operator"
+	tracerName  = "github.com/openshift/addon-operator"
+)
+
+// Exporter selects where spans are sent to.
+type Exporter string
+
+const (
+	// Spans are not recorded at all.
+	ExporterNone Exporter = ""
+	// Spans are sent to an OTLP/HTTP endpoint, e.g. an OpenTelemetry Collector.
+	ExporterOTLP Exporter = "otlp"
+	// Spans are printed to stdout, meant for local testing.
+	ExporterStdout Exporter = "stdout"
+)
+
+type Options struct {
+	Exporter Exporter
+	// URL of the OTLP/HTTP endpoint, e.g. "http://otel-collector:4318".
+	// Falls back to the OTEL_EXPORTER_OTLP_* environment variables when empty.
+	Endpoint string
+	// Fraction of new traces that are sampled, from 0 to 1.
+	// Traces started by a sampled parent are always recorded.
+	SampleRatio float64
+	// Overrides the destination of the stdout exporter.
+	Writer io.Writer
+}
+
+// Setup configures the global TracerProvider and W3C trace context propagation.
+// The returned function flushes pending spans and must be called before the process exits.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+
+	case ExporterOTLP:
+		var otlpOpts []otlptracehttp.Option
+		if len(opts.Endpoint) > 0 {
+			otlpOpts = append(otlpOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, otlpOpts...)
+
+	case ExporterStdout:
+		w := opts.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s exporter: %w", opts.Exporter, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version.Version),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// Start creates a span as child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if set, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the ID of the trace in ctx, empty if there is none.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_Stdout(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	out := &bytes.Buffer{}
+	ctx := context.Background()
+	shutdown, err := Setup(ctx, Options{
+		Exporter:    ExporterStdout,
+		SampleRatio: 1,
+		Writer:      out,
+	})
+	require.NoError(t, err)
+
+	spanCtx, span := Start(ctx, "test-span")
+	assert.NotEmpty(t, TraceID(spanCtx))
+	End(span, errors.New("explosion"))
+	require.NoError(t, shutdown(ctx))
+
+	assert.Contains(t, out.String(), `"Name":"test-span"`)
+	assert.Contains(t, out.String(), `"addon-operator"`)
+	assert.Contains(t, out.String(), "explosion")
+	assert.ElementsMatch(t,
+		[]string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+}
+
+func TestSetup_None(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, span := Start(context.Background(), "test-span")
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Options{Exporter: "zipkin"})
+	assert.EqualError(t, err, `unknown tracing exporter "zipkin"`)
+}
@@ -7,6 +7,8 @@ import (
 
 	v1 "k8s.io/api/admission/v1"
 	adminv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 
@@ -15,7 +17,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-// AddonWebhookHandler handles validating Addon objects
+const defaultParametersSchemaKey = "schema.json"
+
+// AddonWebhookHandler handles validating Addon objects.
+// Client must be able to list Addons and get ConfigMaps,
+// see deploy/16_webhook-clusterrole.yaml.
 type AddonWebhookHandler struct {
 	decoder *admission.Decoder
 	Log     logr.Logger
@@ -32,7 +38,7 @@ func (r *AddonWebhookHandler) Handle(ctx context.Context, req admission.Request)
 
 	switch req.Operation {
 	case v1.Operation(adminv1beta1.Create):
-		return r.validateCreate(&obj)
+		return r.validateCreate(ctx, &obj)
 	case v1.Operation(adminv1beta1.Update):
 		oldObj := addonsv1alpha1.Addon{}
 		if r.decoder == nil {
@@ -43,7 +49,9 @@ func (r *AddonWebhookHandler) Handle(ctx context.Context, req admission.Request)
 		if err := decoder.DecodeRaw(req.OldObject, &oldObj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		return r.validateUpdate(&obj, &oldObj)
+		return r.validateUpdate(ctx, &obj, &oldObj)
+	case v1.Operation(adminv1beta1.Delete):
+		return r.validateDelete(ctx, req.Name)
 	default:
 		return admission.Allowed("operation allowed")
 	}
@@ -69,14 +77,17 @@ func (r *AddonWebhookHandler) InjectDecoder(d *admission.Decoder) error {
 	return nil
 }
 
-func (r *AddonWebhookHandler) validateCreate(addon *addonsv1alpha1.Addon) admission.Response {
+func (r *AddonWebhookHandler) validateCreate(ctx context.Context, addon *addonsv1alpha1.Addon) admission.Response {
 	if err := validateAddon(addon); err != nil {
 		return admission.Denied(err.Error())
 	}
-	return admission.Allowed("operation allowed")
+	if resp := r.validateParameters(ctx, addon); !resp.Allowed {
+		return resp
+	}
+	return r.validateDependencies(ctx, addon)
 }
 
-func (r *AddonWebhookHandler) validateUpdate(addon, oldAddon *addonsv1alpha1.Addon) admission.Response {
+func (r *AddonWebhookHandler) validateUpdate(ctx context.Context, addon, oldAddon *addonsv1alpha1.Addon) admission.Response {
 	if err := validateAddon(addon); err != nil {
 		return admission.Denied(err.Error())
 	}
@@ -84,5 +95,82 @@ func (r *AddonWebhookHandler) validateUpdate(addon, oldAddon *addonsv1alpha1.Add
 	if err := validateAddonImmutability(addon, oldAddon); err != nil {
 		return admission.Denied(err.Error())
 	}
+	if resp := r.validateParameters(ctx, addon); !resp.Allowed {
+		return resp
+	}
+	return r.validateDependencies(ctx, addon)
+}
+
+func (r *AddonWebhookHandler) validateDelete(ctx context.Context, name string) admission.Response {
+	addons, err := r.listAddons(ctx)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if err := validateDeletion(name, addons); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("operation allowed")
+}
+
+func (r *AddonWebhookHandler) validateDependencies(ctx context.Context, addon *addonsv1alpha1.Addon) admission.Response {
+	if len(addon.Spec.DependsOn) == 0 {
+		return admission.Allowed("operation allowed")
+	}
+
+	addons, err := r.listAddons(ctx)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if err := validateDependencyCycle(addon, addons); err != nil {
+		return admission.Denied(err.Error())
+	}
 	return admission.Allowed("operation allowed")
 }
+
+func (r *AddonWebhookHandler) validateParameters(ctx context.Context, addon *addonsv1alpha1.Addon) admission.Response {
+	schemaSpec := addon.Spec.ParametersSchema
+	if schemaSpec == nil {
+		return admission.Allowed("operation allowed")
+	}
+
+	var schema []byte
+	if schemaSpec.Inline != nil {
+		schema = schemaSpec.Inline.Raw
+	} else {
+		ref := schemaSpec.ConfigMap
+		key := ref.Key
+		if len(key) == 0 {
+			key = defaultParametersSchemaKey
+		}
+
+		cm := &corev1.ConfigMap{}
+		err := r.Client.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}, cm)
+		if apierrors.IsNotFound(err) {
+			return admission.Denied(fmt.Sprintf("%s: ConfigMap %s/%s not found",
+				errSpecParametersSchemaInvalid, ref.Namespace, ref.Name))
+		}
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError,
+				fmt.Errorf("getting parameters schema ConfigMap: %w", err))
+		}
+		data, ok := cm.Data[key]
+		if !ok {
+			return admission.Denied(fmt.Sprintf("%s: ConfigMap %s/%s has no key %q",
+				errSpecParametersSchemaInvalid, ref.Namespace, ref.Name, key))
+		}
+		schema = []byte(data)
+	}
+
+	if err := validateParameters(addon, schema); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("operation allowed")
+}
+
+func (r *AddonWebhookHandler) listAddons(ctx context.Context) ([]addonsv1alpha1.Addon, error) {
+	addonList := &addonsv1alpha1.AddonList{}
+	if err := r.Client.List(ctx, addonList); err != nil {
+		return nil, fmt.Errorf("listing Addons: %w", err)
+	}
+	return addonList.Items, nil
+}
@@ -3,18 +3,54 @@ package webhooks
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/blang/semver/v4"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
+	"github.com/openshift/addon-operator/internal/dependency"
+	"github.com/openshift/addon-operator/internal/maintenance"
+	"github.com/openshift/addon-operator/internal/oci"
+	"github.com/openshift/addon-operator/internal/parameters"
 )
 
 var (
-	errSpecInstallTypeInvalid               = errors.New("invalid Addon .spec.install.type")
-	errSpecInstallOwnNamespaceRequired      = errors.New(".spec.install.olmOwnNamespace is required when .spec.install.type = OLMOwnNamespace")
-	errSpecInstallAllNamespacesRequired     = errors.New(".spec.install.olmAllNamespaces is required when .spec.install.type = OLMAllNamespaces")
-	errSpecInstallConfigMutuallyExclusive   = errors.New(".spec.install.olmAllNamespaces is mutually exclusive with .spec.install.olmOwnNamespace")
-	errAdditionalCatalogSourceNameCollision = errors.New("additional catalog source name collides with the main catalog source name")
+	errSpecInstallTypeInvalid                = errors.New("invalid Addon .spec.install.type")
+	errSpecInstallOwnNamespaceRequired       = errors.New(".spec.install.olmOwnNamespace is required when .spec.install.type = OLMOwnNamespace")
+	errSpecInstallAllNamespacesRequired      = errors.New(".spec.install.olmAllNamespaces is required when .spec.install.type = OLMAllNamespaces")
+	errSpecInstallConfigMutuallyExclusive    = errors.New(".spec.install.olmAllNamespaces is mutually exclusive with .spec.install.olmOwnNamespace")
+	errSpecInstallHelmRequired               = errors.New(".spec.install.helm is required when .spec.install.type = Helm")
+	errSpecInstallHelmMutuallyExclusive      = errors.New(".spec.install.helm is mutually exclusive with .spec.install.olmAllNamespaces and .spec.install.olmOwnNamespace")
+	errSpecInstallHelmChartInvalid           = errors.New(".spec.install.helm.chart must be an oci:// reference without tag or digest")
+	errSpecInstallManifestsRequired          = errors.New(".spec.install.manifests is required when .spec.install.type = Manifests")
+	errSpecInstallManifestsMutuallyExclusive = errors.New(".spec.install.manifests is mutually exclusive with .spec.install.helm, .spec.install.olmAllNamespaces and .spec.install.olmOwnNamespace")
+	errSpecInstallManifestsSourceRequired    = errors.New("exactly one of .spec.install.manifests.configMapName and .spec.install.manifests.image is required")
+	errSpecInstallManifestsPullSecretImage   = errors.New(".spec.install.manifests.pullSecretName requires .spec.install.manifests.image")
+	errAdditionalCatalogSourceNameCollision  = errors.New("additional catalog source name collides with the main catalog source name")
+	errSpecUpgradeStrategyCanaryOLMOnly      = errors.New(".spec.upgradeStrategy.type = Canary is only supported for .spec.install.type = OLMOwnNamespace or OLMAllNamespaces")
+	errSpecUpgradeStrategyCanaryOnly         = errors.New(".spec.upgradeStrategy.canary requires .spec.upgradeStrategy.type = Canary")
+	errSpecUpgradeStrategyCanaryDurations    = errors.New(".spec.upgradeStrategy.canary.bakeTime and .spec.upgradeStrategy.canary.progressDeadline must not be negative")
+	errSpecUpgradeStrategyRollbackCanary     = errors.New(".spec.upgradeStrategy.rollback is not supported for .spec.upgradeStrategy.type = Canary, canary rollouts always roll back")
+	errSpecUpgradeStrategyRollbackOLMOnly    = errors.New(".spec.upgradeStrategy.rollback is only supported for .spec.install.type = OLMOwnNamespace or OLMAllNamespaces")
+	errSpecUpgradeStrategyRollbackDeadline   = errors.New(".spec.upgradeStrategy.rollback.progressDeadline must not be negative")
+	errInstallPlanApprovalWindowsRequired    = errors.New("installPlanApproval.rules[].maintenanceWindows is required for type MaintenanceWindow")
+	errInstallPlanApprovalWindowsOnly        = errors.New("installPlanApproval.rules[].maintenanceWindows is only supported for type MaintenanceWindow")
+	errInstallPlanApprovalWindowInvalid      = errors.New("invalid installPlanApproval.rules[].maintenanceWindows")
+	errSpecMaintenanceInvalid                = errors.New("invalid .spec.maintenance")
+	errSpecDependsOnMinVersionInvalid        = errors.New(".spec.dependsOn[].minVersion must be a semantic version")
+	errSpecDependsOnDuplicate                = errors.New(".spec.dependsOn must not list an Addon twice")
+	errSpecDependsOnCycle                    = errors.New(".spec.dependsOn must not form a cycle")
+	errAddonDependedOn                       = errors.New("addon is depended on")
+	errSpecParametersSchemaRequired          = errors.New(".spec.parametersSchema is required when .spec.parameters is set")
+	errSpecParametersSchemaSourceRequired    = errors.New("exactly one of .spec.parametersSchema.inline and .spec.parametersSchema.configMap is required")
+	errSpecParametersSchemaInvalid           = errors.New("invalid .spec.parametersSchema")
+	errSpecParametersInvalid                 = errors.New(".spec.parameters do not match .spec.parametersSchema")
+	errSpecInstallConfigInvalid              = errors.New("invalid .spec.install.config")
+	errSpecInstallCatalogSourceConfigInvalid = errors.New("invalid .spec.install catalog source config")
 )
 
 func validateAddon(addon *addonsv1alpha1.Addon) error {
@@ -24,6 +60,144 @@ func validateAddon(addon *addonsv1alpha1.Addon) error {
 	if err := validateSecretPropagation(addon); err != nil {
 		return err
 	}
+	if err := validateUpgradeStrategy(addon); err != nil {
+		return err
+	}
+	if err := validateMaintenance(addon.Spec.Maintenance); err != nil {
+		return err
+	}
+	if err := validateDependsOn(addon.Spec.DependsOn); err != nil {
+		return err
+	}
+	if err := validateParametersSchemaSource(addon); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateParametersSchemaSource(addon *addonsv1alpha1.Addon) error {
+	schemaSpec := addon.Spec.ParametersSchema
+	if schemaSpec == nil {
+		if addon.Spec.Parameters != nil {
+			return errSpecParametersSchemaRequired
+		}
+		return nil
+	}
+	if (schemaSpec.Inline == nil) == (schemaSpec.ConfigMap == nil) {
+		return errSpecParametersSchemaSourceRequired
+	}
+	return nil
+}
+
+// Validates the parameters of the Addon against the JSON or YAML encoded schema.
+func validateParameters(addon *addonsv1alpha1.Addon, schemaData []byte) error {
+	schema, err := parameters.NewSchema(schemaData)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errSpecParametersSchemaInvalid, err)
+	}
+
+	var params []byte
+	if addon.Spec.Parameters != nil {
+		params = addon.Spec.Parameters.Raw
+	}
+	if err := schema.Validate(params); err != nil {
+		return fmt.Errorf("%w: %w", errSpecParametersInvalid, err)
+	}
+	return nil
+}
+
+func validateDependsOn(dependencies []addonsv1alpha1.AddonDependency) error {
+	names := map[string]struct{}{}
+	for _, dep := range dependencies {
+		if _, ok := names[dep.Name]; ok {
+			return errSpecDependsOnDuplicate
+		}
+		names[dep.Name] = struct{}{}
+
+		if len(dep.MinVersion) == 0 {
+			continue
+		}
+		if _, err := semver.ParseTolerant(dep.MinVersion); err != nil {
+			return fmt.Errorf("%w: %w", errSpecDependsOnMinVersionInvalid, err)
+		}
+	}
+	return nil
+}
+
+// Rejects dependencies on Addons that (indirectly) depend on the Addon itself.
+func validateDependencyCycle(addon *addonsv1alpha1.Addon, addons []addonsv1alpha1.Addon) error {
+	if cycle := dependency.FindCycle(addon, addons); cycle != nil {
+		return fmt.Errorf("%w: %s", errSpecDependsOnCycle, strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// Rejects the deletion of Addons that other Addons depend on,
+// unless these are being deleted, too.
+func validateDeletion(name string, addons []addonsv1alpha1.Addon) error {
+	var dependents []string
+	for _, dependent := range dependency.Dependents(name, addons) {
+		if dependent.DeletionTimestamp.IsZero() {
+			dependents = append(dependents, dependent.Name)
+		}
+	}
+	if len(dependents) > 0 {
+		return fmt.Errorf("%w by %s", errAddonDependedOn, strings.Join(dependents, ", "))
+	}
+	return nil
+}
+
+func validateMaintenance(spec *addonsv1alpha1.AddonMaintenance) error {
+	if spec == nil {
+		return nil
+	}
+	if _, err := maintenance.NewSchedule(*spec); err != nil {
+		return fmt.Errorf("%w: %w", errSpecMaintenanceInvalid, err)
+	}
+	return nil
+}
+
+func validateUpgradeStrategy(addon *addonsv1alpha1.Addon) error {
+	strategy := addon.Spec.UpgradeStrategy
+	if strategy == nil {
+		return nil
+	}
+	if strategy.Type != addonsv1alpha1.AddonUpgradeStrategyCanary {
+		if strategy.Canary != nil {
+			return errSpecUpgradeStrategyCanaryOnly
+		}
+		return validateUpgradeRollback(addon)
+	}
+	if strategy.Rollback != nil {
+		return errSpecUpgradeStrategyRollbackCanary
+	}
+
+	switch addon.Spec.Install.Type {
+	case addonsv1alpha1.OLMOwnNamespace, addonsv1alpha1.OLMAllNamespaces:
+	default:
+		return errSpecUpgradeStrategyCanaryOLMOnly
+	}
+	if canary := strategy.Canary; canary != nil &&
+		(canary.BakeTime.Duration < 0 || canary.ProgressDeadline.Duration < 0) {
+		return errSpecUpgradeStrategyCanaryDurations
+	}
+	return nil
+}
+
+func validateUpgradeRollback(addon *addonsv1alpha1.Addon) error {
+	rollback := addon.Spec.UpgradeStrategy.Rollback
+	if rollback == nil {
+		return nil
+	}
+
+	switch addon.Spec.Install.Type {
+	case addonsv1alpha1.OLMOwnNamespace, addonsv1alpha1.OLMAllNamespaces:
+	default:
+		return errSpecUpgradeStrategyRollbackOLMOnly
+	}
+	if rollback.ProgressDeadline.Duration < 0 {
+		return errSpecUpgradeStrategyRollbackDeadline
+	}
 	return nil
 }
 
@@ -51,11 +225,176 @@ func validateSecretPropagation(addon *addonsv1alpha1.Addon) error {
 	return fmt.Errorf("pullSecretName %q not found as destination in secretPropagation", pullSecretName)
 }
 
+func validateInstallPlanApproval(policy *addonsv1alpha1.AddonInstallPlanApprovalPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	for _, rule := range policy.Rules {
+		if rule.Type != addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow {
+			if len(rule.MaintenanceWindows) > 0 {
+				return errInstallPlanApprovalWindowsOnly
+			}
+			continue
+		}
+
+		if len(rule.MaintenanceWindows) == 0 {
+			return errInstallPlanApprovalWindowsRequired
+		}
+		for _, window := range rule.MaintenanceWindows {
+			if _, err := maintenance.NewWindow(window); err != nil {
+				return fmt.Errorf("%w: %w", errInstallPlanApprovalWindowInvalid, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateSubscriptionConfig(config *addonsv1alpha1.SubscriptionConfig) error {
+	if config == nil {
+		return nil
+	}
+
+	var errs []string
+	for i, env := range config.EnvironmentVariables {
+		if (len(env.Value) == 0) == (env.ValueFrom == nil) {
+			errs = append(errs, fmt.Sprintf("env[%d]: exactly one of value and valueFrom is required", i))
+		}
+	}
+	for i, envFrom := range config.EnvFrom {
+		if (envFrom.ConfigMapRef == nil) == (envFrom.SecretRef == nil) {
+			errs = append(errs, fmt.Sprintf("envFrom[%d]: exactly one of configMapRef and secretRef is required", i))
+		}
+	}
+	if config.Resources != nil {
+		for name, request := range config.Resources.Requests {
+			limit, ok := config.Resources.Limits[name]
+			if ok && request.Cmp(limit) > 0 {
+				errs = append(errs, fmt.Sprintf("resources: %s request must not exceed its limit", name))
+			}
+		}
+	}
+	errs = append(errs, validateNodeSelector(config.NodeSelector)...)
+	for i, toleration := range config.Tolerations {
+		errs = append(errs, validateToleration(i, toleration)...)
+	}
+
+	volumes := map[string]struct{}{}
+	for _, volume := range config.Volumes {
+		if _, ok := volumes[volume.Name]; ok {
+			errs = append(errs, fmt.Sprintf("volume %q is defined twice", volume.Name))
+		}
+		volumes[volume.Name] = struct{}{}
+		for _, msg := range validation.IsDNS1123Label(volume.Name) {
+			errs = append(errs, fmt.Sprintf("volume %q: %s", volume.Name, msg))
+		}
+	}
+
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return fmt.Errorf("%w: %s", errSpecInstallConfigInvalid, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func validateCatalogSourceConfig(config *addonsv1alpha1.CatalogSourceConfig) error {
+	if config == nil {
+		return nil
+	}
+
+	var errs []string
+	if poll := config.UpdateStrategy; poll != nil && poll.RegistryPoll != nil &&
+		poll.RegistryPoll.Interval.Duration <= 0 {
+		errs = append(errs, "updateStrategy.registryPoll.interval must be positive")
+	}
+	if podConfig := config.GrpcPodConfig; podConfig != nil {
+		errs = append(errs, validateNodeSelector(podConfig.NodeSelector)...)
+		for i, toleration := range podConfig.Tolerations {
+			errs = append(errs, validateToleration(i, toleration)...)
+		}
+		if len(podConfig.PriorityClassName) > 0 {
+			for _, msg := range validation.IsDNS1123Subdomain(podConfig.PriorityClassName) {
+				errs = append(errs, fmt.Sprintf("priorityClassName: %s", msg))
+			}
+		}
+		if podConfig.MemoryTarget != nil && podConfig.MemoryTarget.Sign() <= 0 {
+			errs = append(errs, "memoryTarget must be positive")
+		}
+	}
+
+	if len(errs) > 0 {
+		slices.Sort(errs)
+		return fmt.Errorf("%w: %s", errSpecInstallCatalogSourceConfigInvalid, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Validates the main and additional CatalogSource settings of OLM based Addons.
+func validateCatalogSourceConfigs(common addonsv1alpha1.AddonInstallOLMCommon) error {
+	if err := validateCatalogSourceConfig(common.CatalogSourceConfig); err != nil {
+		return err
+	}
+	for _, additional := range common.AdditionalCatalogSources {
+		if err := validateCatalogSourceConfig(additional.Config); err != nil {
+			return fmt.Errorf("additional catalog source %q: %w", additional.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateNodeSelector(selector map[string]string) []string {
+	var errs []string
+	for key, value := range selector {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Sprintf("nodeSelector key %q: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, fmt.Sprintf("nodeSelector value %q: %s", value, msg))
+		}
+	}
+	return errs
+}
+
+func validateToleration(i int, toleration corev1.Toleration) []string {
+	var errs []string
+	switch toleration.Operator {
+	case corev1.TolerationOpExists:
+		if len(toleration.Value) > 0 {
+			errs = append(errs, fmt.Sprintf("tolerations[%d]: value must be empty for operator Exists", i))
+		}
+	case corev1.TolerationOpEqual, "":
+		if len(toleration.Key) == 0 {
+			errs = append(errs, fmt.Sprintf("tolerations[%d]: operator must be Exists when key is empty", i))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("tolerations[%d]: unsupported operator %q", i, toleration.Operator))
+	}
+
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		errs = append(errs, fmt.Sprintf("tolerations[%d]: unsupported effect %q", i, toleration.Effect))
+	}
+	if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+		errs = append(errs, fmt.Sprintf("tolerations[%d]: tolerationSeconds requires effect NoExecute", i))
+	}
+	return errs
+}
+
 func validateInstallSpec(addonSpecInstall addonsv1alpha1.AddonInstallSpec, addonName string) error {
 	if addonSpecInstall.OLMAllNamespaces != nil &&
 		addonSpecInstall.OLMOwnNamespace != nil {
 		return errSpecInstallConfigMutuallyExclusive
 	}
+	if addonSpecInstall.Helm != nil &&
+		(addonSpecInstall.OLMAllNamespaces != nil || addonSpecInstall.OLMOwnNamespace != nil) {
+		return errSpecInstallHelmMutuallyExclusive
+	}
+	if addonSpecInstall.Manifests != nil &&
+		(addonSpecInstall.Helm != nil ||
+			addonSpecInstall.OLMAllNamespaces != nil || addonSpecInstall.OLMOwnNamespace != nil) {
+		return errSpecInstallManifestsMutuallyExclusive
+	}
 
 	switch addonSpecInstall.Type {
 	case addonsv1alpha1.OLMOwnNamespace:
@@ -73,7 +412,13 @@ func validateInstallSpec(addonSpecInstall addonsv1alpha1.AddonInstallSpec, addon
 			}
 		}
 
-		return nil
+		if err := validateSubscriptionConfig(addonSpecInstall.OLMOwnNamespace.Config); err != nil {
+			return err
+		}
+		if err := validateCatalogSourceConfigs(addonSpecInstall.OLMOwnNamespace.AddonInstallOLMCommon); err != nil {
+			return err
+		}
+		return validateInstallPlanApproval(addonSpecInstall.OLMOwnNamespace.InstallPlanApproval)
 
 	case addonsv1alpha1.OLMAllNamespaces:
 		if addonSpecInstall.OLMAllNamespaces == nil {
@@ -90,7 +435,27 @@ func validateInstallSpec(addonSpecInstall addonsv1alpha1.AddonInstallSpec, addon
 			}
 		}
 
-		return nil
+		if err := validateSubscriptionConfig(addonSpecInstall.OLMAllNamespaces.Config); err != nil {
+			return err
+		}
+		if err := validateCatalogSourceConfigs(addonSpecInstall.OLMAllNamespaces.AddonInstallOLMCommon); err != nil {
+			return err
+		}
+		return validateInstallPlanApproval(addonSpecInstall.OLMAllNamespaces.InstallPlanApproval)
+
+	case addonsv1alpha1.Helm:
+		if addonSpecInstall.Helm == nil {
+			// missing configuration
+			return errSpecInstallHelmRequired
+		}
+		return validateHelmChart(addonSpecInstall.Helm.Chart)
+
+	case addonsv1alpha1.Manifests:
+		if addonSpecInstall.Manifests == nil {
+			// missing configuration
+			return errSpecInstallManifestsRequired
+		}
+		return validateManifestsSource(addonSpecInstall.Manifests)
 
 	default:
 		// Unsupported Install Type
@@ -100,6 +465,35 @@ func validateInstallSpec(addonSpecInstall addonsv1alpha1.AddonInstallSpec, addon
 	}
 }
 
+// The chart version is configured separately and used as tag,
+// so the chart reference must not carry one itself.
+func validateHelmChart(chart string) error {
+	name, found := strings.CutPrefix(chart, "oci://")
+	if !found || strings.Contains(name, "@") ||
+		strings.LastIndex(name, ":") > strings.LastIndex(name, "/") {
+		return errSpecInstallHelmChartInvalid
+	}
+	if _, err := oci.ParseReference(name); err != nil {
+		return fmt.Errorf("%w: %w", errSpecInstallHelmChartInvalid, err)
+	}
+	return nil
+}
+
+func validateManifestsSource(manifests *addonsv1alpha1.AddonInstallManifests) error {
+	if (len(manifests.ConfigMapName) == 0) == (len(manifests.Image) == 0) {
+		return errSpecInstallManifestsSourceRequired
+	}
+	if len(manifests.PullSecretName) > 0 && len(manifests.Image) == 0 {
+		return errSpecInstallManifestsPullSecretImage
+	}
+	if len(manifests.Image) > 0 {
+		if _, err := oci.ParseReference(manifests.Image); err != nil {
+			return fmt.Errorf(".spec.install.manifests.image is invalid: %w", err)
+		}
+	}
+	return nil
+}
+
 var (
 	errInstallTypeImmutable = errors.New(".spec.install.type is immutable")
 	errInstallImmutable     = errors.New(".spec.install is immutable, except for .catalogSourceImage")
@@ -118,6 +512,7 @@ func validateAddonImmutability(addon, oldAddon *addonsv1alpha1.Addon) error {
 		oldSpecInstall.OLMAllNamespaces.PullSecretName = ""
 		oldSpecInstall.OLMAllNamespaces.AdditionalCatalogSources = nil
 		oldSpecInstall.OLMAllNamespaces.Channel = ""
+		oldSpecInstall.OLMAllNamespaces.InstallPlanApproval = nil
 	}
 	if oldSpecInstall.OLMOwnNamespace != nil {
 		oldSpecInstall.OLMOwnNamespace.CatalogSourceImage = ""
@@ -125,6 +520,13 @@ func validateAddonImmutability(addon, oldAddon *addonsv1alpha1.Addon) error {
 		oldSpecInstall.OLMOwnNamespace.PullSecretName = ""
 		oldSpecInstall.OLMOwnNamespace.AdditionalCatalogSources = nil
 		oldSpecInstall.OLMOwnNamespace.Channel = ""
+		oldSpecInstall.OLMOwnNamespace.InstallPlanApproval = nil
+	}
+	if oldSpecInstall.Helm != nil {
+		blankMutableHelmFields(oldSpecInstall.Helm)
+	}
+	if oldSpecInstall.Manifests != nil {
+		blankMutableManifestsFields(oldSpecInstall.Manifests)
 	}
 
 	specInstall := addon.Spec.Install.DeepCopy()
@@ -134,6 +536,7 @@ func validateAddonImmutability(addon, oldAddon *addonsv1alpha1.Addon) error {
 		specInstall.OLMAllNamespaces.PullSecretName = ""
 		specInstall.OLMAllNamespaces.AdditionalCatalogSources = nil
 		specInstall.OLMAllNamespaces.Channel = ""
+		specInstall.OLMAllNamespaces.InstallPlanApproval = nil
 	}
 	if specInstall.OLMOwnNamespace != nil {
 		specInstall.OLMOwnNamespace.CatalogSourceImage = ""
@@ -141,6 +544,13 @@ func validateAddonImmutability(addon, oldAddon *addonsv1alpha1.Addon) error {
 		specInstall.OLMOwnNamespace.PullSecretName = ""
 		specInstall.OLMOwnNamespace.AdditionalCatalogSources = nil
 		specInstall.OLMOwnNamespace.Channel = ""
+		specInstall.OLMOwnNamespace.InstallPlanApproval = nil
+	}
+	if specInstall.Helm != nil {
+		blankMutableHelmFields(specInstall.Helm)
+	}
+	if specInstall.Manifests != nil {
+		blankMutableManifestsFields(specInstall.Manifests)
 	}
 
 	// Do semantic DeepEqual instead of reflect.DeepEqual
@@ -149,3 +559,19 @@ func validateAddonImmutability(addon, oldAddon *addonsv1alpha1.Addon) error {
 	}
 	return nil
 }
+
+// Chart, version, values and pull secret may change to upgrade or reconfigure the addon,
+// the install namespace may not.
+func blankMutableHelmFields(helm *addonsv1alpha1.AddonInstallHelm) {
+	helm.Chart = ""
+	helm.ChartVersion = ""
+	helm.Values = nil
+	helm.PullSecretName = ""
+}
+
+// Switching the bundle source, e.g. to a newer image, upgrades the addon.
+func blankMutableManifestsFields(manifests *addonsv1alpha1.AddonInstallManifests) {
+	manifests.ConfigMapName = ""
+	manifests.Image = ""
+	manifests.PullSecretName = ""
+}
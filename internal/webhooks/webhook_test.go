@@ -1,12 +1,22 @@
 package webhooks
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	addonsv1alpha1 "github.com/openshift/addon-operator/api/v1alpha1"
 	"github.com/openshift/addon-operator/internal/testutil"
@@ -76,6 +86,116 @@ func Test_validateInstallSpec(t *testing.T) {
 			addonName:   "test-2",
 			expectedErr: errAdditionalCatalogSourceNameCollision,
 		},
+		{
+			name: "spec.install.helm required",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Helm,
+			},
+			expectedErr: errSpecInstallHelmRequired,
+		},
+		{
+			name: "spec.install.helm and *.ownNamespace mutually exclusive",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type:            addonsv1alpha1.Helm,
+				Helm:            &addonsv1alpha1.AddonInstallHelm{},
+				OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{},
+			},
+			expectedErr: errSpecInstallHelmMutuallyExclusive,
+		},
+		{
+			name: "spec.install.helm.chart without oci scheme",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Helm,
+				Helm: &addonsv1alpha1.AddonInstallHelm{
+					Chart: "quay.io/osd-addons/charts/reference-addon",
+				},
+			},
+			expectedErr: errSpecInstallHelmChartInvalid,
+		},
+		{
+			name: "spec.install.helm.chart with tag",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Helm,
+				Helm: &addonsv1alpha1.AddonInstallHelm{
+					Chart: "oci://quay.io/osd-addons/charts/reference-addon:1.0.0",
+				},
+			},
+			expectedErr: errSpecInstallHelmChartInvalid,
+		},
+		{
+			name: "spec.install.manifests required",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Manifests,
+			},
+			expectedErr: errSpecInstallManifestsRequired,
+		},
+		{
+			name: "spec.install.manifests and *.helm mutually exclusive",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type:      addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{},
+				Helm:      &addonsv1alpha1.AddonInstallHelm{},
+			},
+			expectedErr: errSpecInstallManifestsMutuallyExclusive,
+		},
+		{
+			name: "spec.install.manifests without source",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{
+					Namespace: "reference-addon",
+				},
+			},
+			expectedErr: errSpecInstallManifestsSourceRequired,
+		},
+		{
+			name: "spec.install.manifests with configMapName and image",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{
+					Namespace:     "reference-addon",
+					ConfigMapName: "reference-addon-manifests",
+					Image:         "quay.io/osd-addons/reference-addon-manifests:v1",
+				},
+			},
+			expectedErr: errSpecInstallManifestsSourceRequired,
+		},
+		{
+			name: "spec.install.manifests.pullSecretName without image",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{
+					Namespace:      "reference-addon",
+					ConfigMapName:  "reference-addon-manifests",
+					PullSecretName: "pull-secret",
+				},
+			},
+			expectedErr: errSpecInstallManifestsPullSecretImage,
+		},
+		{
+			name: "valid manifests install",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Manifests,
+				Manifests: &addonsv1alpha1.AddonInstallManifests{
+					Namespace:      "reference-addon",
+					Image:          "quay.io/osd-addons/reference-addon-manifests:v1",
+					PullSecretName: "pull-secret",
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "valid helm install",
+			addonInstallSpec: addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.Helm,
+				Helm: &addonsv1alpha1.AddonInstallHelm{
+					Namespace:    "reference-addon",
+					Chart:        "oci://localhost:5000/charts/reference-addon",
+					ChartVersion: "1.0.0",
+				},
+			},
+			expectedErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -86,6 +206,554 @@ func Test_validateInstallSpec(t *testing.T) {
 	}
 }
 
+func TestValidateInstallPlanApproval(t *testing.T) {
+	saturdays := addonsv1alpha1.AddonMaintenanceWindow{
+		Schedule: "0 2 * * 6",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Berlin",
+	}
+
+	for name, tc := range map[string]struct {
+		rules       []addonsv1alpha1.AddonInstallPlanApprovalRule
+		expectedErr error
+	}{
+		"valid": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion},
+				{
+					Type:               addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow,
+					MaintenanceWindows: []addonsv1alpha1.AddonMaintenanceWindow{saturdays},
+				},
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRuleUpgradePolicyScheduled},
+			},
+		},
+		"missing maintenance windows": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{Type: addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow},
+			},
+			expectedErr: errInstallPlanApprovalWindowsRequired,
+		},
+		"maintenance windows of other rule": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{
+					Type:               addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion,
+					MaintenanceWindows: []addonsv1alpha1.AddonMaintenanceWindow{saturdays},
+				},
+			},
+			expectedErr: errInstallPlanApprovalWindowsOnly,
+		},
+		"invalid maintenance window": {
+			rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+				{
+					Type: addonsv1alpha1.AddonInstallPlanApprovalRuleMaintenanceWindow,
+					MaintenanceWindows: []addonsv1alpha1.AddonMaintenanceWindow{
+						{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"},
+					},
+				},
+			},
+			expectedErr: errInstallPlanApprovalWindowInvalid,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateInstallSpec(addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.OLMOwnNamespace,
+				OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{
+					AddonInstallOLMCommon: addonsv1alpha1.AddonInstallOLMCommon{
+						InstallPlanApproval: &addonsv1alpha1.AddonInstallPlanApprovalPolicy{Rules: tc.rules},
+					},
+				},
+			}, "test-addon")
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestValidateMaintenance(t *testing.T) {
+	weekdays := addonsv1alpha1.AddonMaintenanceWindow{
+		Schedule: "0 22 * * 1-5",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "America/New_York",
+	}
+
+	for name, tc := range map[string]struct {
+		spec        *addonsv1alpha1.AddonMaintenance
+		expectedErr error
+	}{
+		"unset": {},
+		"valid": {
+			spec: &addonsv1alpha1.AddonMaintenance{
+				Windows:       []addonsv1alpha1.AddonMaintenanceWindow{weekdays},
+				BlackoutDates: []string{"2024-12-24"},
+			},
+		},
+		"invalid window": {
+			spec: &addonsv1alpha1.AddonMaintenance{
+				Windows: []addonsv1alpha1.AddonMaintenanceWindow{{Schedule: "weekdays"}},
+			},
+			expectedErr: errSpecMaintenanceInvalid,
+		},
+		"invalid blackout date": {
+			spec: &addonsv1alpha1.AddonMaintenance{
+				Windows:       []addonsv1alpha1.AddonMaintenanceWindow{weekdays},
+				BlackoutDates: []string{"2024-13-01"},
+			},
+			expectedErr: errSpecMaintenanceInvalid,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateMaintenance(tc.spec)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestValidateDependsOn(t *testing.T) {
+	for name, tc := range map[string]struct {
+		dependencies []addonsv1alpha1.AddonDependency
+		expectedErr  error
+	}{
+		"valid": {
+			dependencies: []addonsv1alpha1.AddonDependency{
+				{Name: "monitoring", MinVersion: "1.2"},
+				{Name: "logging"},
+			},
+		},
+		"invalid min version": {
+			dependencies: []addonsv1alpha1.AddonDependency{
+				{Name: "monitoring", MinVersion: "latest"},
+			},
+			expectedErr: errSpecDependsOnMinVersionInvalid,
+		},
+		"duplicate": {
+			dependencies: []addonsv1alpha1.AddonDependency{
+				{Name: "monitoring"},
+				{Name: "monitoring", MinVersion: "1.2.0"},
+			},
+			expectedErr: errSpecDependsOnDuplicate,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateDependsOn(tc.dependencies)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestValidateDependencyCycle(t *testing.T) {
+	addons := []addonsv1alpha1.Addon{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+			Spec: addonsv1alpha1.AddonSpec{
+				DependsOn: []addonsv1alpha1.AddonDependency{{Name: "monitoring"}},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+	}
+
+	monitoring := &addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "monitoring"},
+		Spec: addonsv1alpha1.AddonSpec{
+			DependsOn: []addonsv1alpha1.AddonDependency{{Name: "logging"}},
+		},
+	}
+	err := validateDependencyCycle(monitoring, addons)
+	assert.ErrorIs(t, err, errSpecDependsOnCycle)
+	assert.ErrorContains(t, err, "monitoring -> logging -> monitoring")
+
+	monitoring.Spec.DependsOn = nil
+	assert.NoError(t, validateDependencyCycle(monitoring, addons))
+}
+
+func TestValidateSubscriptionConfig(t *testing.T) {
+	secretRef := &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+			Key:                  "token",
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		config      *addonsv1alpha1.SubscriptionConfig
+		expectedMsg string
+	}{
+		"unset": {},
+		"valid": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				EnvironmentVariables: []addonsv1alpha1.EnvObject{
+					{Name: "LOG_LEVEL", Value: "debug"},
+					{Name: "TOKEN", ValueFrom: secretRef},
+				},
+				EnvFrom: []corev1.EnvFromSource{{
+					ConfigMapRef: &corev1.ConfigMapEnvSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+					},
+				}},
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations: []corev1.Toleration{{
+					Key:      "node-role.kubernetes.io/infra",
+					Operator: corev1.TolerationOpExists,
+					Effect:   corev1.TaintEffectNoSchedule,
+				}},
+				Volumes: []corev1.Volume{{Name: "cache"}},
+			},
+		},
+		"value and valueFrom": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				EnvironmentVariables: []addonsv1alpha1.EnvObject{
+					{Name: "TOKEN", Value: "abc", ValueFrom: secretRef},
+				},
+			},
+			expectedMsg: "env[0]: exactly one of value and valueFrom is required",
+		},
+		"empty envFrom": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				EnvFrom: []corev1.EnvFromSource{{Prefix: "ADDON_"}},
+			},
+			expectedMsg: "envFrom[0]: exactly one of configMapRef and secretRef is required",
+		},
+		"request exceeds limit": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				},
+			},
+			expectedMsg: "resources: cpu request must not exceed its limit",
+		},
+		"invalid node selector": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				NodeSelector: map[string]string{"infra node": "true"},
+			},
+			expectedMsg: `nodeSelector key "infra node"`,
+		},
+		"toleration without key": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				Tolerations: []corev1.Toleration{{Value: "infra"}},
+			},
+			expectedMsg: "tolerations[0]: operator must be Exists when key is empty",
+		},
+		"toleration seconds": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				Tolerations: []corev1.Toleration{{
+					Key:               "infra",
+					Effect:            corev1.TaintEffectNoSchedule,
+					TolerationSeconds: ptr.To[int64](10),
+				}},
+			},
+			expectedMsg: "tolerations[0]: tolerationSeconds requires effect NoExecute",
+		},
+		"duplicate volume": {
+			config: &addonsv1alpha1.SubscriptionConfig{
+				Volumes: []corev1.Volume{{Name: "cache"}, {Name: "cache"}},
+			},
+			expectedMsg: `volume "cache" is defined twice`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateSubscriptionConfig(tc.config)
+			if len(tc.expectedMsg) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, errSpecInstallConfigInvalid)
+			assert.ErrorContains(t, err, tc.expectedMsg)
+		})
+	}
+}
+
+func TestValidateCatalogSourceConfigs(t *testing.T) {
+	negativeMemory := resource.MustParse("-1Mi")
+
+	for name, tc := range map[string]struct {
+		common      addonsv1alpha1.AddonInstallOLMCommon
+		expectedMsg string
+	}{
+		"unset": {},
+		"valid": {
+			common: addonsv1alpha1.AddonInstallOLMCommon{
+				CatalogSourceConfig: &addonsv1alpha1.CatalogSourceConfig{
+					UpdateStrategy: &addonsv1alpha1.CatalogSourceUpdateStrategy{
+						RegistryPoll: &addonsv1alpha1.CatalogSourceRegistryPoll{
+							Interval: metav1.Duration{Duration: 30 * time.Minute},
+						},
+					},
+					Priority: -100,
+					GrpcPodConfig: &addonsv1alpha1.CatalogSourceGrpcPodConfig{
+						NodeSelector:      map[string]string{"node-role.kubernetes.io/infra": ""},
+						PriorityClassName: "system-cluster-critical",
+					},
+				},
+				AdditionalCatalogSources: []addonsv1alpha1.AdditionalCatalogSource{
+					{Name: "extra", Image: "quay.io/osd-addons/extra:latest"},
+				},
+			},
+		},
+		"zero poll interval": {
+			common: addonsv1alpha1.AddonInstallOLMCommon{
+				CatalogSourceConfig: &addonsv1alpha1.CatalogSourceConfig{
+					UpdateStrategy: &addonsv1alpha1.CatalogSourceUpdateStrategy{
+						RegistryPoll: &addonsv1alpha1.CatalogSourceRegistryPoll{},
+					},
+				},
+			},
+			expectedMsg: "updateStrategy.registryPoll.interval must be positive",
+		},
+		"invalid priority class": {
+			common: addonsv1alpha1.AddonInstallOLMCommon{
+				CatalogSourceConfig: &addonsv1alpha1.CatalogSourceConfig{
+					GrpcPodConfig: &addonsv1alpha1.CatalogSourceGrpcPodConfig{
+						PriorityClassName: "System Critical",
+					},
+				},
+			},
+			expectedMsg: "priorityClassName",
+		},
+		"additional catalog source": {
+			common: addonsv1alpha1.AddonInstallOLMCommon{
+				AdditionalCatalogSources: []addonsv1alpha1.AdditionalCatalogSource{{
+					Name:  "extra",
+					Image: "quay.io/osd-addons/extra:latest",
+					Config: &addonsv1alpha1.CatalogSourceConfig{
+						GrpcPodConfig: &addonsv1alpha1.CatalogSourceGrpcPodConfig{
+							MemoryTarget: &negativeMemory,
+						},
+					},
+				}},
+			},
+			expectedMsg: `additional catalog source "extra": invalid .spec.install catalog source config: memoryTarget must be positive`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateCatalogSourceConfigs(tc.common)
+			if len(tc.expectedMsg) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, errSpecInstallCatalogSourceConfigInvalid)
+			assert.ErrorContains(t, err, tc.expectedMsg)
+		})
+	}
+}
+
+func TestValidateParametersSchemaSource(t *testing.T) {
+	inline := &runtime.RawExtension{Raw: []byte(`{"type": "object"}`)}
+	configMap := &addonsv1alpha1.AddonParametersSchemaConfigMapReference{Name: "schema", Namespace: "addon-operator"}
+
+	for name, tc := range map[string]struct {
+		spec        addonsv1alpha1.AddonSpec
+		expectedErr error
+	}{
+		"unset": {},
+		"inline": {
+			spec: addonsv1alpha1.AddonSpec{
+				Parameters:       &runtime.RawExtension{Raw: []byte(`{}`)},
+				ParametersSchema: &addonsv1alpha1.AddonParametersSchema{Inline: inline},
+			},
+		},
+		"parameters without schema": {
+			spec: addonsv1alpha1.AddonSpec{
+				Parameters: &runtime.RawExtension{Raw: []byte(`{}`)},
+			},
+			expectedErr: errSpecParametersSchemaRequired,
+		},
+		"no source": {
+			spec: addonsv1alpha1.AddonSpec{
+				ParametersSchema: &addonsv1alpha1.AddonParametersSchema{},
+			},
+			expectedErr: errSpecParametersSchemaSourceRequired,
+		},
+		"both sources": {
+			spec: addonsv1alpha1.AddonSpec{
+				ParametersSchema: &addonsv1alpha1.AddonParametersSchema{Inline: inline, ConfigMap: configMap},
+			},
+			expectedErr: errSpecParametersSchemaSourceRequired,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := validateParametersSchemaSource(&addonsv1alpha1.Addon{Spec: tc.spec})
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	const schema = `{"type": "object", "required": ["replicas"], "properties": {"replicas": {"type": "integer"}}}`
+
+	for name, tc := range map[string]struct {
+		parameters  *runtime.RawExtension
+		schema      string
+		expectedErr error
+	}{
+		"valid": {
+			parameters: &runtime.RawExtension{Raw: []byte(`{"replicas": 3}`)},
+			schema:     schema,
+		},
+		"invalid": {
+			parameters:  &runtime.RawExtension{Raw: []byte(`{"replicas": "3"}`)},
+			schema:      schema,
+			expectedErr: errSpecParametersInvalid,
+		},
+		"required parameter missing": {
+			schema:      schema,
+			expectedErr: errSpecParametersInvalid,
+		},
+		"invalid schema": {
+			schema:      `{"type": `,
+			expectedErr: errSpecParametersSchemaInvalid,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addon := &addonsv1alpha1.Addon{Spec: addonsv1alpha1.AddonSpec{Parameters: tc.parameters}}
+			err := validateParameters(addon, []byte(tc.schema))
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestAddonWebhookHandler_ValidateParameters(t *testing.T) {
+	c := testutil.NewClient()
+	c.On("Get", testutil.IsContext, client.ObjectKey{Name: "schema", Namespace: "addon-operator"},
+		mock.IsType(&corev1.ConfigMap{}), mock.Anything,
+	).Run(func(args mock.Arguments) {
+		cm := args.Get(2).(*corev1.ConfigMap)
+		cm.Data = map[string]string{"schema.yaml": "type: object\nrequired: [replicas]\n"}
+	}).Return(nil)
+	c.On("Get", testutil.IsContext, client.ObjectKey{Name: "missing", Namespace: "addon-operator"},
+		mock.IsType(&corev1.ConfigMap{}), mock.Anything,
+	).Return(testutil.NewTestErrNotFound())
+	r := &AddonWebhookHandler{Client: c}
+
+	for name, tc := range map[string]struct {
+		ref        addonsv1alpha1.AddonParametersSchemaConfigMapReference
+		parameters string
+		allowed    bool
+	}{
+		"valid": {
+			ref:        addonsv1alpha1.AddonParametersSchemaConfigMapReference{Name: "schema", Namespace: "addon-operator", Key: "schema.yaml"},
+			parameters: `{"replicas": 1}`,
+			allowed:    true,
+		},
+		"invalid": {
+			ref:        addonsv1alpha1.AddonParametersSchemaConfigMapReference{Name: "schema", Namespace: "addon-operator", Key: "schema.yaml"},
+			parameters: `{}`,
+		},
+		"key missing": {
+			ref:        addonsv1alpha1.AddonParametersSchemaConfigMapReference{Name: "schema", Namespace: "addon-operator"},
+			parameters: `{"replicas": 1}`,
+		},
+		"ConfigMap missing": {
+			ref:        addonsv1alpha1.AddonParametersSchemaConfigMapReference{Name: "missing", Namespace: "addon-operator"},
+			parameters: `{"replicas": 1}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			addon := &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Parameters:       &runtime.RawExtension{Raw: []byte(tc.parameters)},
+					ParametersSchema: &addonsv1alpha1.AddonParametersSchema{ConfigMap: &tc.ref},
+				},
+			}
+			resp := r.validateParameters(context.Background(), addon)
+			assert.Equal(t, tc.allowed, resp.Allowed, resp.Result)
+		})
+	}
+}
+
+func TestValidateDeletion(t *testing.T) {
+	now := metav1.Now()
+	logging := addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+		Spec: addonsv1alpha1.AddonSpec{
+			DependsOn: []addonsv1alpha1.AddonDependency{{Name: "monitoring"}},
+		},
+	}
+
+	err := validateDeletion("monitoring", []addonsv1alpha1.Addon{logging})
+	assert.ErrorIs(t, err, errAddonDependedOn)
+	assert.ErrorContains(t, err, "by logging")
+
+	// Dependents that are being deleted do not block the deletion.
+	logging.DeletionTimestamp = &now
+	assert.NoError(t, validateDeletion("monitoring", []addonsv1alpha1.Addon{logging}))
+	assert.NoError(t, validateDeletion("logging", []addonsv1alpha1.Addon{logging}))
+}
+
+func TestAddonWebhookHandler_Delete(t *testing.T) {
+	logging := addonsv1alpha1.Addon{
+		ObjectMeta: metav1.ObjectMeta{Name: "logging"},
+		Spec: addonsv1alpha1.AddonSpec{
+			DependsOn: []addonsv1alpha1.AddonDependency{{Name: "monitoring"}},
+		},
+	}
+	c := testutil.NewClient()
+	c.On("List", testutil.IsContext, mock.IsType(&addonsv1alpha1.AddonList{}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			list := args.Get(1).(*addonsv1alpha1.AddonList)
+			list.Items = []addonsv1alpha1.Addon{logging}
+		}).
+		Return(nil)
+	// No decoder, DELETE requests must not decode the empty object.
+	r := &AddonWebhookHandler{Client: c}
+
+	for name, tc := range map[string]struct {
+		addon   string
+		allowed bool
+	}{
+		"depended on":     {addon: "monitoring"},
+		"not depended on": {addon: "logging", allowed: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := r.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Delete,
+					Name:      tc.addon,
+				},
+			})
+			assert.Equal(t, tc.allowed, resp.Allowed, resp.Result)
+		})
+	}
+
+	t.Run("list error", func(t *testing.T) {
+		c := testutil.NewClient()
+		c.On("List", testutil.IsContext, mock.IsType(&addonsv1alpha1.AddonList{}), mock.Anything).
+			Return(fmt.Errorf("forbidden"))
+		r := &AddonWebhookHandler{Client: c}
+
+		resp := r.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				Name:      "monitoring",
+			},
+		})
+		assert.False(t, resp.Allowed)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.Result.Code)
+	})
+}
+
 func TestValidateAddonInstallImmutability(t *testing.T) {
 	var (
 		addonName     = "test-addon"
@@ -124,6 +792,24 @@ func TestValidateAddonInstallImmutability(t *testing.T) {
 		},
 	}, addonName)
 
+	newHelmAddon := func(helm addonsv1alpha1.AddonInstallHelm) *addonsv1alpha1.Addon {
+		return testutil.NewAddonWithInstallSpec(addonsv1alpha1.AddonInstallSpec{
+			Type: addonsv1alpha1.Helm,
+			Helm: &helm,
+		}, addonName)
+	}
+	newManifestsAddon := func(manifests addonsv1alpha1.AddonInstallManifests) *addonsv1alpha1.Addon {
+		return testutil.NewAddonWithInstallSpec(addonsv1alpha1.AddonInstallSpec{
+			Type:      addonsv1alpha1.Manifests,
+			Manifests: &manifests,
+		}, addonName)
+	}
+	baseAddon_helm := newHelmAddon(addonsv1alpha1.AddonInstallHelm{
+		Namespace:    "reference-addon",
+		Chart:        "oci://quay.io/osd-addons/charts/reference-addon",
+		ChartVersion: "1.0.0",
+	})
+
 	testCases := []struct {
 		baseAddon    *addonsv1alpha1.Addon
 		updatedAddon *addonsv1alpha1.Addon
@@ -164,6 +850,26 @@ func TestValidateAddonInstallImmutability(t *testing.T) {
 			}, addonName),
 			expectedErr: nil,
 		},
+		{
+			baseAddon: baseAddon,
+			updatedAddon: testutil.NewAddonWithInstallSpec(addonsv1alpha1.AddonInstallSpec{
+				Type: addonsv1alpha1.OLMAllNamespaces,
+				OLMAllNamespaces: &addonsv1alpha1.AddonInstallOLMAllNamespaces{
+					AddonInstallOLMCommon: addonsv1alpha1.AddonInstallOLMCommon{
+						Namespace:          "reference-addon",
+						PackageName:        addonName,
+						Channel:            "alpha",
+						CatalogSourceImage: catalogSource,
+						InstallPlanApproval: &addonsv1alpha1.AddonInstallPlanApprovalPolicy{ // changed (added)
+							Rules: []addonsv1alpha1.AddonInstallPlanApprovalRule{
+								{Type: addonsv1alpha1.AddonInstallPlanApprovalRulePatchVersion},
+							},
+						},
+					},
+				},
+			}, addonName),
+			expectedErr: nil,
+		},
 		{
 			baseAddon: baseAddon,
 			updatedAddon: testutil.NewAddonWithInstallSpec(addonsv1alpha1.AddonInstallSpec{
@@ -219,6 +925,49 @@ func TestValidateAddonInstallImmutability(t *testing.T) {
 			}, addonName),
 			expectedErr: nil,
 		},
+		{
+			baseAddon: baseAddon_helm,
+			updatedAddon: newHelmAddon(addonsv1alpha1.AddonInstallHelm{
+				Namespace:      "reference-addon",
+				Chart:          "oci://quay.io/osd-addons/charts/reference-addon-v2", // changed
+				ChartVersion:   "2.0.0",                                              // changed
+				PullSecretName: "pull-secret",                                        // changed (added)
+				Values:         &runtime.RawExtension{Raw: []byte(`{"a":"b"}`)},      // changed (added)
+			}),
+			expectedErr: nil,
+		},
+		{
+			baseAddon: baseAddon_helm,
+			updatedAddon: newHelmAddon(addonsv1alpha1.AddonInstallHelm{
+				Namespace:    "other-namespace", // changed
+				Chart:        "oci://quay.io/osd-addons/charts/reference-addon",
+				ChartVersion: "1.0.0",
+			}),
+			expectedErr: errInstallImmutable,
+		},
+		{
+			baseAddon: newManifestsAddon(addonsv1alpha1.AddonInstallManifests{
+				Namespace:     "reference-addon",
+				ConfigMapName: "reference-addon-manifests",
+			}),
+			updatedAddon: newManifestsAddon(addonsv1alpha1.AddonInstallManifests{
+				Namespace:      "reference-addon",
+				Image:          "quay.io/osd-addons/reference-addon-manifests:v2", // changed
+				PullSecretName: "pull-secret",                                     // changed (added)
+			}),
+			expectedErr: nil,
+		},
+		{
+			baseAddon: newManifestsAddon(addonsv1alpha1.AddonInstallManifests{
+				Namespace:     "reference-addon",
+				ConfigMapName: "reference-addon-manifests",
+			}),
+			updatedAddon: newManifestsAddon(addonsv1alpha1.AddonInstallManifests{
+				Namespace:     "other-namespace", // changed
+				ConfigMapName: "reference-addon-manifests",
+			}),
+			expectedErr: errInstallImmutable,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -615,6 +1364,140 @@ func TestValidateAddon(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type:             addonsv1alpha1.OLMAllNamespaces,
+						OLMAllNamespaces: &addonsv1alpha1.AddonInstallOLMAllNamespaces{},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type: addonsv1alpha1.AddonUpgradeStrategyCanary,
+						Canary: &addonsv1alpha1.AddonCanaryUpgradeStrategy{
+							BakeTime:        metav1.Duration{Duration: time.Minute},
+							PrometheusQuery: "up",
+						},
+					},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type:             addonsv1alpha1.OLMAllNamespaces,
+						OLMAllNamespaces: &addonsv1alpha1.AddonInstallOLMAllNamespaces{},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type: addonsv1alpha1.AddonUpgradeStrategyCanary,
+						Canary: &addonsv1alpha1.AddonCanaryUpgradeStrategy{
+							ProgressDeadline: metav1.Duration{Duration: -time.Minute},
+						},
+					},
+				},
+			},
+			expectedErr: errSpecUpgradeStrategyCanaryDurations,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type:             addonsv1alpha1.OLMAllNamespaces,
+						OLMAllNamespaces: &addonsv1alpha1.AddonInstallOLMAllNamespaces{},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type:   addonsv1alpha1.AddonUpgradeStrategyImmediate,
+						Canary: &addonsv1alpha1.AddonCanaryUpgradeStrategy{},
+					},
+				},
+			},
+			expectedErr: errSpecUpgradeStrategyCanaryOnly,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type: addonsv1alpha1.Manifests,
+						Manifests: &addonsv1alpha1.AddonInstallManifests{
+							Namespace:     "test",
+							ConfigMapName: "test",
+						},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type: addonsv1alpha1.AddonUpgradeStrategyCanary,
+					},
+				},
+			},
+			expectedErr: errSpecUpgradeStrategyCanaryOLMOnly,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type:            addonsv1alpha1.OLMOwnNamespace,
+						OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type: addonsv1alpha1.AddonUpgradeStrategyImmediate,
+						Rollback: &addonsv1alpha1.AddonUpgradeRollback{
+							ProgressDeadline: metav1.Duration{Duration: 10 * time.Minute},
+						},
+					},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type:            addonsv1alpha1.OLMOwnNamespace,
+						OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type:     addonsv1alpha1.AddonUpgradeStrategyCanary,
+						Rollback: &addonsv1alpha1.AddonUpgradeRollback{},
+					},
+				},
+			},
+			expectedErr: errSpecUpgradeStrategyRollbackCanary,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type: addonsv1alpha1.Manifests,
+						Manifests: &addonsv1alpha1.AddonInstallManifests{
+							Namespace:     "test",
+							ConfigMapName: "test",
+						},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type:     addonsv1alpha1.AddonUpgradeStrategyImmediate,
+						Rollback: &addonsv1alpha1.AddonUpgradeRollback{},
+					},
+				},
+			},
+			expectedErr: errSpecUpgradeStrategyRollbackOLMOnly,
+		},
+		{
+			addon: &addonsv1alpha1.Addon{
+				Spec: addonsv1alpha1.AddonSpec{
+					Install: addonsv1alpha1.AddonInstallSpec{
+						Type:            addonsv1alpha1.OLMOwnNamespace,
+						OLMOwnNamespace: &addonsv1alpha1.AddonInstallOLMOwnNamespace{},
+					},
+					UpgradeStrategy: &addonsv1alpha1.AddonUpgradeStrategy{
+						Type: addonsv1alpha1.AddonUpgradeStrategyImmediate,
+						Rollback: &addonsv1alpha1.AddonUpgradeRollback{
+							ProgressDeadline: metav1.Duration{Duration: -time.Minute},
+						},
+					},
+				},
+			},
+			expectedErr: errSpecUpgradeStrategyRollbackDeadline,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -82,6 +82,16 @@ func (Build) Docgen() {
 	mg.Deps(mg.F(Build.cmd, "docgen", "", ""))
 }
 
+// Builds the addon-must-gather debugging tool
+func (Build) AddonMustGather() {
+	mg.Deps(mg.F(Build.cmd, "addon-must-gather", "", ""))
+}
+
+// Builds the kubectl-addon plugin
+func (Build) KubectlAddon() {
+	mg.Deps(mg.F(Build.cmd, "kubectl-addon", "", ""))
+}
+
 // Builds binaries from /cmd directory.
 func (Build) cmd(cmd, goos, goarch string) error {
 	mg.Deps(Build.init)
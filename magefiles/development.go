@@ -411,6 +411,9 @@ func (d Dev) deployAddonOperatorWebhook(ctx context.Context, cluster *dev.Cluste
 	if err := cluster.CreateAndWaitFromFiles(ctx, []string{
 		// TODO: replace with CreateAndWaitFromFolders when deployment.yaml is gone.
 		"deploy-extras/development/webhook/00-tls-secret.yaml",
+		"deploy/11_webhook-serviceaccount.yaml",
+		"deploy/16_webhook-clusterrole.yaml",
+		"deploy/21_webhook-clusterrolebinding.yaml",
 		"deploy-extras/development/webhook/service.yaml",
 		"deploy-extras/development/webhook/validatingwebhookconfig.yaml",
 	}); err != nil {
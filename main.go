@@ -42,6 +42,9 @@ import (
 	aictrl "github.com/openshift/addon-operator/controllers/addoninstance"
 	aocontroller "github.com/openshift/addon-operator/controllers/addonoperator"
 	"github.com/openshift/addon-operator/internal/featuretoggle"
+	"github.com/openshift/addon-operator/internal/outbox"
+	"github.com/openshift/addon-operator/internal/prometheus"
+	"github.com/openshift/addon-operator/internal/tracing"
 )
 
 var (
@@ -108,6 +111,34 @@ func initReconcilers(mgr ctrl.Manager,
 		return fmt.Errorf("unable to create Addon controller: %w", err)
 	}
 
+	if enableStatusReporting || enableUpgradePolicyStatus {
+		outboxOpts := []outbox.Option{
+			outbox.WithLog(ctrl.Log.WithName("OCMOutbox")),
+		}
+		outboxOpts = append(outboxOpts, addonReconciler.OCMOutboxOptions()...)
+		if recorder != nil {
+			outboxOpts = append(outboxOpts, outbox.WithMetrics(recorder))
+		}
+		ocmOutbox := outbox.New(uncachedClient, client.ObjectKey{
+			Name:      addoncontroller.OCMOutboxConfigMapName,
+			Namespace: namespace,
+		}, addonReconciler.DeliverOCMReport, outboxOpts...)
+		if err := mgr.Add(ocmOutbox); err != nil {
+			return fmt.Errorf("unable to add OCM outbox: %w", err)
+		}
+		addonReconciler.InjectOCMOutbox(ocmOutbox)
+	}
+
+	// Watches the OCM credentials referenced by the AddonOperator.
+	ocmSecretWatch := aocontroller.NewOCMSecretWatch(
+		ctrl.Log.WithName("OCMSecretWatch"), mgr.GetConfig(), cache.Options{
+			Scheme: mgr.GetScheme(),
+			Mapper: mgr.GetRESTMapper(),
+		})
+	if err := mgr.Add(ocmSecretWatch); err != nil {
+		return fmt.Errorf("unable to add OCM secret watch: %w", err)
+	}
+
 	if err := (&aocontroller.AddonOperatorReconciler{
 		Client:              mgr.GetClient(),
 		UncachedClient:      uncachedClient,
@@ -118,6 +149,7 @@ func initReconcilers(mgr ctrl.Manager,
 		Recorder:            recorder,
 		ClusterExternalID:   clusterExternalID,
 		FeatureTogglesState: strings.Split(addonOperatorInCluster.Spec.FeatureFlags, ","),
+		OCMSecretWatch:      ocmSecretWatch,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create AddonOperator controller: %w", err)
 	}
@@ -242,15 +274,42 @@ func setup() error {
 		// Example Command:
 		// $ kubectl exec -it <addon-operator-pod> --container manager bash -- \
 		// curl -sK -v http://localhost:8070/debug/pprof/heap > heap.out
-		PprofAddr: "127.0.0.1:8070",
+		PprofAddr:          "127.0.0.1:8070",
+		TracingSampleRatio: 1,
 	}
 
 	if err := opts.Process(); err != nil {
 		return fmt.Errorf("processing options: %w", err)
 	}
 
+	if len(opts.PrometheusURL) > 0 {
+		promClient, err := newPrometheusClient(opts.PrometheusURL)
+		if err != nil {
+			return fmt.Errorf("setting up prometheus client: %w", err)
+		}
+		addonReconcilerOptions = append(addonReconcilerOptions,
+			addoncontroller.WithPrometheusClient{Client: promClient})
+	}
+
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    tracing.Exporter(opts.TracingExporter),
+		Endpoint:    opts.TracingEndpoint,
+		SampleRatio: opts.TracingSampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+	defer func() {
+		// Fresh context, the manager context is cancelled already.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			setupLog.Error(err, "flushing traces")
+		}
+	}()
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                fetchMetricsOptions(opts),
@@ -270,6 +329,10 @@ func setup() error {
 						controllers.CommonCacheLabel: controllers.CommonCacheValue,
 					}),
 				},
+				// Manifest bundles of Addons are only read from the addon operator namespace.
+				&corev1.ConfigMap{}: {
+					Namespaces: map[string]cache.Config{opts.Namespace: {}},
+				},
 			},
 		},
 	})
@@ -298,6 +361,9 @@ func setup() error {
 		return fmt.Errorf("unable to set up ready check: %w", err)
 	}
 
+	addonReconcilerOptions = append(addonReconcilerOptions,
+		addoncontroller.WithEventRecorder{Recorder: mgr.GetEventRecorder("addon-operator")})
+
 	if err := initReconcilers(mgr, opts.Namespace,
 		opts.EnableMetricsRecorder, addonOperatorObjectInCluster, opts.StatusReportingEnabled, opts.EnableUpgradePolicyStatus, addonReconcilerOptions...); err != nil {
 		return fmt.Errorf("init reconcilers: %w", err)
@@ -310,6 +376,21 @@ func setup() error {
 	return nil
 }
 
+// Authenticates with the ServiceAccount token and trusts the
+// OpenShift service CA, as used by the in-cluster Thanos Querier.
+func newPrometheusClient(url string) (*prometheus.Client, error) {
+	const (
+		tokenPath     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		serviceCAPath = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+	)
+
+	promOpts := []prometheus.Option{prometheus.WithBearerTokenFile(tokenPath)}
+	if _, err := os.Stat(serviceCAPath); err == nil {
+		promOpts = append(promOpts, prometheus.WithCAFile(serviceCAPath))
+	}
+	return prometheus.NewClient(url, promOpts...)
+}
+
 func main() {
 	if err := setup(); err != nil {
 		setupLog.Error(err, "setting up manager")
@@ -19,6 +19,10 @@ type options struct {
 	ProbeAddr                 string
 	StatusReportingEnabled    bool
 	EnableUpgradePolicyStatus bool
+	PrometheusURL             string
+	TracingExporter           string
+	TracingEndpoint           string
+	TracingSampleRatio        float64
 }
 
 // Process retrieves values from flags, environment values,
@@ -87,6 +91,43 @@ func (o *options) parseFlags() {
 		"The address the pprof web endpoint binds to.",
 	)
 
+	flag.StringVar(
+		&o.PrometheusURL,
+		"prometheus-url",
+		o.PrometheusURL,
+		strings.Join([]string{
+			"URL of the Prometheus API to run health queries of canary rollouts against.",
+			"If unset canary rollouts with a Prometheus query never pass their health gates.",
+		}, " "),
+	)
+
+	flag.StringVar(
+		&o.TracingExporter,
+		"tracing-exporter",
+		o.TracingExporter,
+		strings.Join([]string{
+			"Exporter for OpenTelemetry traces, either 'otlp' or 'stdout'.",
+			"If unset no traces are recorded.",
+		}, " "),
+	)
+
+	flag.StringVar(
+		&o.TracingEndpoint,
+		"tracing-endpoint",
+		o.TracingEndpoint,
+		strings.Join([]string{
+			"URL of the OTLP/HTTP endpoint traces are exported to, e.g. http://otel-collector:4318.",
+			"If unset the OTEL_EXPORTER_OTLP_ENDPOINT environment variable is used.",
+		}, " "),
+	)
+
+	flag.Float64Var(
+		&o.TracingSampleRatio,
+		"tracing-sample-ratio",
+		o.TracingSampleRatio,
+		"Fraction of reconciles that are traced, between 0 and 1.",
+	)
+
 	flag.StringVar(
 		&o.ProbeAddr,
 		"health-probe-bind-address",
@@ -153,6 +194,9 @@ func (o *options) validate() error {
 	if o.Namespace == "" {
 		return fmt.Errorf("'Namespace' must not be empty: %w", errInvalidOption)
 	}
+	if o.TracingSampleRatio < 0 || o.TracingSampleRatio > 1 {
+		return fmt.Errorf("'TracingSampleRatio' must be between 0 and 1: %w", errInvalidOption)
+	}
 
 	return nil
 }